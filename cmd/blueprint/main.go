@@ -0,0 +1,47 @@
+// Command blueprint generates projects from the built-in templates offline,
+// without a running Boilerplate Blueprint server, or drives a shared server
+// named by BLUEPRINT_SERVER and BLUEPRINT_API_KEY
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"boilerplate-blueprint/internal/cli"
+	"boilerplate-blueprint/internal/models"
+)
+
+// Build information, set with -ldflags "-X main.Version=... -X main.Commit=...
+// -X main.BuildTime=..." by the Makefile
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	command := cli.New(os.Stdin, os.Stdout, os.Stderr)
+	command.SetInteractive(isTerminal(os.Stdin))
+	command.SetServer(os.Getenv("BLUEPRINT_SERVER"), os.Getenv("BLUEPRINT_API_KEY"))
+	command.SetBuildInfo(models.BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime})
+
+	err := command.Run(ctx, os.Args[1:])
+	if code := cli.ExitCode(err); code != 0 {
+		fmt.Fprintln(os.Stderr, "blueprint:", err)
+		stop()
+		os.Exit(code)
+	}
+}
+
+// isTerminal reports whether f is a character device, so prompts are only
+// shown to a person and never block scripts that pipe input in
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -1,111 +1,165 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"os/signal"
 	"runtime"
+	"strings"
+	"syscall"
+	"time"
 
 	"boilerplate-blueprint/internal/api"
-	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/config"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/server"
+	"boilerplate-blueprint/internal/tracing"
 
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
+// Build information, set with -ldflags "-X main.Version=... -X main.Commit=...
+// -X main.BuildTime=..." by the Makefile and Dockerfile
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildTime = ""
+)
+
 func main() {
 	// Load environment variables
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found, using system environment variables")
 	}
 
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "Print the effective configuration and exit")
+
+	cfg, err := config.Load(flags, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *printConfig {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Route the standard logger through slog too, so every line is structured
+	logger, err := cfg.Logger()
+	if err != nil {
+		log.Fatal(err)
+	}
+	slog.SetDefault(logger)
+	logConfig(cfg)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingSetup())
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	// Check if running in Lambda environment
-	if isLambdaEnvironment() {
+	if config.IsLambda() {
 		log.Println("🚀 Starting Boilerplate Blueprint in AWS Lambda mode...")
 
-		// Initialize services
-		templateService := services.NewTemplateService()
-		projectService := services.NewProjectService(templateService)
-		chatService := services.NewChatService()
-
-		// Initialize handlers
-		handlers := api.NewHandlers(projectService, templateService, chatService)
+		application, err := app.New(appConfig(cfg))
+		if err != nil {
+			log.Fatal("Failed to build application:", err)
+		}
 
-		// Start Lambda handler
-		api.StartLambda(handlers)
+		// Start Lambda handler. It never returns, so spans are flushed after
+		// each invocation instead of by shutdownTracing.
+		api.StartLambda(application)
 		return
 	}
 
 	// Regular server mode
-	startServer()
+	startServer(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Printf("⚠️  Failed to flush traces: %v", err)
+	}
 }
 
-// isLambdaEnvironment checks if we're running in AWS Lambda
-func isLambdaEnvironment() bool {
-	// Check for Lambda environment variables
-	lambdaTaskRoot := os.Getenv("LAMBDA_TASK_ROOT")
-	lambdaRuntimeAPI := os.Getenv("AWS_LAMBDA_RUNTIME_API")
+// appConfig converts the configuration for app.New and adds the build information
+func appConfig(cfg config.Config) app.Config {
+	appConfig := cfg.App()
+	appConfig.Build = models.BuildInfo{Version: Version, Commit: Commit, BuildTime: BuildTime}
+	return appConfig
+}
 
-	return lambdaTaskRoot != "" || lambdaRuntimeAPI != ""
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
 }
 
-// startServer starts the regular HTTP server
-func startServer() {
-	// Set Gin mode
-	if os.Getenv("GIN_MODE") == "" {
-		gin.SetMode(gin.DebugMode)
+// logConfig logs the effective configuration with secrets redacted
+func logConfig(cfg config.Config) {
+	var dump bytes.Buffer
+	if err := cfg.Dump(&dump); err != nil {
+		log.Printf("⚠️  Failed to dump configuration: %v", err)
+		return
 	}
+	log.Printf("⚙️  Effective configuration:\n%s", dump.String())
+}
 
+// startServer runs the HTTP server until SIGINT or SIGTERM, then drains
+// in-flight requests
+func startServer(cfg config.Config) {
 	// Log startup information
-	log.Printf("🚀 Starting Boilerplate Blueprint server...")
+	log.Printf("🚀 Starting Boilerplate Blueprint server %s (commit %s, built %s)...", Version, orUnknown(Commit), orUnknown(BuildTime))
 	log.Printf("📊 Go Version: %s", runtime.Version())
 	log.Printf("🖥️  OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH)
 
-	// Initialize services
-	templateService := services.NewTemplateService()
-	projectService := services.NewProjectService(templateService)
-	chatService := services.NewChatService()
-
-	// Initialize handlers
-	handlers := api.NewHandlers(projectService, templateService, chatService)
-
-	// Create Gin router
-	router := gin.New()
+	application, err := app.New(appConfig(cfg))
+	if err != nil {
+		log.Fatal("Failed to build application:", err)
+	}
 
-	// Add middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		// A second signal kills the process instead of waiting for the drain
+		<-ctx.Done()
+		stop()
+	}()
 
-	// Configure CORS
-	config := cors.DefaultConfig()
-	config.AllowOrigins = []string{
-		"http://localhost:3000",
-		"http://localhost:5173",
-		"https://localhost:3000",
-		"https://localhost:5173",
-	}
-	config.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
-	router.Use(cors.New(config))
+	application.StartBackground(ctx)
 
-	// Setup routes
-	api.SetupRoutes(router, handlers)
+	listener := cfg.HTTPServer()
+	srv := server.New(listener, application)
 
-	// Serve static files (Vue.js build)
-	router.Static("/static", "./web/dist")
-	router.StaticFile("/", "./web/dist/index.html")
+	log.Printf("📡 Server will be available at: %s", displayURL(listener))
+	log.Printf("🌐 Frontend will be available at: %s", displayURL(listener))
 
-	// Get port
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	if err := srv.Run(ctx); err != nil {
+		log.Fatal("Server error:", err)
 	}
+	log.Println("👋 Server stopped")
+}
 
-	log.Printf("📡 Server will be available at: http://localhost:%s", port)
-	log.Printf("🌐 Frontend will be available at: http://localhost:%s", port)
+// displayURL turns the listen address into a URL for the startup logs
+func displayURL(listener server.Config) string {
+	scheme := "http"
+	if listener.TLS.Enabled() {
+		scheme = "https"
+	}
 
-	// Start server
-	if err := router.Run(":" + port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	addr := listener.Addr
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
 	}
+	return fmt.Sprintf("%s://%s", scheme, addr)
 }
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInsufficientRole is returned when the caller can see a resource but
+// their team role does not allow the requested change
+var errInsufficientRole = apperror.New(apperror.KindForbidden, "insufficient_role", "your team role does not allow this")
+
+// callerID returns the authenticated principal's ID, which is empty when
+// authentication is disabled
+func callerID(c *gin.Context) string {
+	return auth.FromContext(c.Request.Context()).ID
+}
+
+// roleFor returns the caller's role on a resource created by ownerID and
+// shared with teamID
+func (h *Handlers) roleFor(c *gin.Context, ownerID, teamID string) models.TeamRole {
+	return resolveRole(callerID(c), ownerID, teamID, func(teamID string) (*models.Team, error) {
+		return h.lookupTeam(c.Request.Context(), teamID)
+	})
+}
+
+// roleResolver returns roleFor for listings, looking each team up only once
+func (h *Handlers) roleResolver(c *gin.Context) func(ownerID, teamID string) models.TeamRole {
+	type lookup struct {
+		team *models.Team
+		err  error
+	}
+	teams := make(map[string]lookup)
+	cached := func(teamID string) (*models.Team, error) {
+		if _, done := teams[teamID]; !done {
+			team, err := h.lookupTeam(c.Request.Context(), teamID)
+			teams[teamID] = lookup{team, err}
+		}
+		return teams[teamID].team, teams[teamID].err
+	}
+
+	caller := callerID(c)
+	return func(ownerID, teamID string) models.TeamRole {
+		return resolveRole(caller, ownerID, teamID, cached)
+	}
+}
+
+func (h *Handlers) lookupTeam(ctx context.Context, teamID string) (*models.Team, error) {
+	if h.teamService == nil {
+		return nil, fmt.Errorf("%w: %s", services.ErrTeamNotFound, teamID)
+	}
+	return h.teamService.GetTeam(ctx, teamID)
+}
+
+// resolveRole applies the sharing rules: resources shared with a team follow
+// the caller's role in it; unshared ones, and those whose team has been
+// deleted, give the principal who created them full control. An empty role
+// means no access at all.
+func resolveRole(caller, ownerID, teamID string, lookup func(teamID string) (*models.Team, error)) models.TeamRole {
+	if teamID != "" {
+		team, err := lookup(teamID)
+		if err == nil {
+			return team.Role(caller)
+		}
+		if !errors.Is(err, services.ErrTeamNotFound) {
+			return ""
+		}
+	}
+	if ownerID == caller {
+		return models.RoleOwner
+	}
+	return ""
+}
+
+// authorize checks a role against the one an operation requires. Callers
+// with no role get notFound, so the existence of other callers' resources is
+// not revealed.
+func authorize(role, required models.TeamRole, notFound error) error {
+	if role == "" {
+		return notFound
+	}
+	if !role.Allows(required) {
+		return fmt.Errorf("%w: %s role required", errInsufficientRole, required)
+	}
+	return nil
+}
+
+// projectFor returns a project the caller holds at least the required role on
+func (h *Handlers) projectFor(c *gin.Context, projectID string, required models.TeamRole) (*models.Project, error) {
+	project, err := h.projectService.GetProject(c.Request.Context(), projectID)
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("%w: %s", services.ErrProjectNotFound, projectID)
+	if err := authorize(h.roleFor(c, project.OwnerID, project.TeamID), required, notFound); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// chatProject checks the caller's role on the project a chat names. Chats
+// may name projects that do not exist (yet), in which case the returned
+// project is nil.
+func (h *Handlers) chatProject(c *gin.Context, projectID string, required models.TeamRole) (*models.Project, error) {
+	if projectID == "" {
+		return nil, nil
+	}
+	project, err := h.projectService.GetProject(c.Request.Context(), projectID)
+	if errors.Is(err, services.ErrProjectNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("%w: %s", services.ErrProjectNotFound, projectID)
+	if err := authorize(h.roleFor(c, project.OwnerID, project.TeamID), required, notFound); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// sessionFor returns a chat session the caller holds at least the required role on
+func (h *Handlers) sessionFor(c *gin.Context, sessionID string, required models.TeamRole) (*models.ChatSession, error) {
+	session, err := h.chatService.GetSession(c.Request.Context(), sessionID)
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("%w: %s", services.ErrSessionNotFound, sessionID)
+	if err := authorize(h.roleFor(c, session.OwnerID, session.TeamID), required, notFound); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// packFor returns a template pack the caller holds at least the required role on
+func (h *Handlers) packFor(c *gin.Context, packID string, required models.TeamRole) (*models.TemplatePack, error) {
+	if h.packService == nil {
+		return nil, fmt.Errorf("%w: %s", services.ErrTemplatePackNotFound, packID)
+	}
+	pack, err := h.packService.GetPack(c.Request.Context(), packID)
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("%w: %s", services.ErrTemplatePackNotFound, packID)
+	if err := authorize(h.roleFor(c, pack.OwnerID, pack.TeamID), required, notFound); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// teamFor returns a team the caller holds at least the required role in.
+// Teams the caller is not a member of are reported as not found.
+func (h *Handlers) teamFor(c *gin.Context, teamID string, required models.TeamRole) (*models.Team, error) {
+	team, err := h.lookupTeam(c.Request.Context(), teamID)
+	if err != nil {
+		return nil, err
+	}
+	notFound := fmt.Errorf("%w: %s", services.ErrTeamNotFound, teamID)
+	if err := authorize(team.Role(callerID(c)), required, notFound); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// shareableTeam checks that the caller may share a new resource with teamID,
+// which takes the editor role in the team. An empty team ID keeps the
+// resource private.
+func (h *Handlers) shareableTeam(c *gin.Context, teamID string) error {
+	if teamID == "" {
+		return nil
+	}
+	_, err := h.teamFor(c, teamID, models.RoleEditor)
+	return err
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"boilerplate-blueprint/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Errors for optional features that are switched off
+var (
+	errTeamsDisabled          = apperror.New(apperror.KindUnsupported, "teams_disabled", "teams are not enabled")
+	errTemplatePacksDisabled  = apperror.New(apperror.KindUnsupported, "template_packs_disabled", "template packs are not enabled")
+	errAssistantToolsDisabled = apperror.New(apperror.KindUnsupported, "assistant_tools_disabled", "assistant tools are not enabled")
+)
+
+// fail writes err as an error response. The error is also recorded on the
+// context so that middleware further out can see the cause.
+func fail(c *gin.Context, err error) {
+	_ = c.Error(err)
+	apperror.Abort(c, err)
+}
+
+// bindJSON decodes the request body into obj, reporting problems as
+// validation errors that name the offending fields
+func bindJSON(c *gin.Context, obj interface{}) error {
+	useJSONFieldNames()
+	if err := c.ShouldBindJSON(obj); err != nil {
+		return invalidBody(err)
+	}
+	return nil
+}
+
+var registerFieldNames sync.Once
+
+// useJSONFieldNames makes validation errors name fields as clients send them
+func useJSONFieldNames() {
+	registerFieldNames.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	})
+}
+
+func invalidBody(err error) error {
+	var tooLarge *http.MaxBytesError
+	var fields validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &tooLarge):
+		return fmt.Errorf("%w: %v", apperror.New(apperror.KindTooLarge, "body_too_large",
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit)), err)
+	case errors.As(err, &fields):
+		details := make([]apperror.FieldError, 0, len(fields))
+		for _, field := range fields {
+			details = append(details, apperror.FieldError{Field: fieldPath(field), Message: describeRule(field)})
+		}
+		return fmt.Errorf("%w: %v", apperror.Validation("invalid_request", "request has invalid fields", details...), err)
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		message := "must be " + jsonType(typeErr.Type)
+		return fmt.Errorf("%w: %v", apperror.Validation("invalid_request", field+" "+message,
+			apperror.FieldError{Field: field, Message: message}), err)
+	case errors.As(err, &syntaxErr), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("%w: %v", apperror.Validation("invalid_json", "request body is not valid JSON"), err)
+	default:
+		return fmt.Errorf("%w: %v", apperror.Validation("invalid_request", "request body could not be read"), err)
+	}
+}
+
+// fieldPath drops the struct name validator puts before the field path
+func fieldPath(field validator.FieldError) string {
+	_, path, found := strings.Cut(field.Namespace(), ".")
+	if !found {
+		return field.Field()
+	}
+	return path
+}
+
+func describeRule(field validator.FieldError) string {
+	switch field.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(field.Param(), " ", ", ")
+	case "min":
+		return "must be at least " + field.Param()
+	case "max":
+		return "must be at most " + field.Param()
+	default:
+		return "failed the " + field.Tag() + " check"
+	}
+}
+
+// jsonType names a Go type the way a JSON client would think of it
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
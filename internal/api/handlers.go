@@ -2,8 +2,14 @@ package api
 
 import (
 	"fmt"
+	"io"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/health"
 	"boilerplate-blueprint/internal/models"
 	"boilerplate-blueprint/internal/services"
 
@@ -14,6 +20,10 @@ type Handlers struct {
 	projectService  *services.ProjectService
 	templateService *services.TemplateService
 	chatService     *services.ChatService
+	teamService     *services.TeamService         // Optional; team endpoints answer 501 without it
+	packService     *services.TemplatePackService // Optional; template pack endpoints answer 501 without it
+	readiness       *health.Checker               // Optional; readiness has no checks without it
+	build           models.BuildInfo
 }
 
 func NewHandlers(projectService *services.ProjectService, templateService *services.TemplateService, chatService *services.ChatService) *Handlers {
@@ -21,68 +31,110 @@ func NewHandlers(projectService *services.ProjectService, templateService *servi
 		projectService:  projectService,
 		templateService: templateService,
 		chatService:     chatService,
+		build:           models.BuildInfo{Version: "dev"},
 	}
 }
 
+// SetTeamService enables teams, so projects, template packs and chat
+// sessions can be shared with roles
+func (h *Handlers) SetTeamService(teamService *services.TeamService) {
+	h.teamService = teamService
+}
+
+// SetTemplatePackService enables template packs
+func (h *Handlers) SetTemplatePackService(packService *services.TemplatePackService) {
+	h.packService = packService
+}
+
+// SetBuildInfo sets the version reported by the health endpoints
+func (h *Handlers) SetBuildInfo(build models.BuildInfo) {
+	h.build = build
+}
+
+// SetReadiness sets the dependency checks run by Readyz
+func (h *Handlers) SetReadiness(checker *health.Checker) {
+	h.readiness = checker
+}
+
 // Health check endpoint
-// @Summary Health check
-// @Description Health check endpoint
-// @Tags Health
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]string
-// @Router /api/health [get]
 func (h *Handlers) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":  "healthy",
-		"service": "boilerplate-blueprint",
-		"version": "1.0.0",
-	})
+	c.JSON(http.StatusOK, h.healthResponse("healthy"))
+}
+
+// Livez reports that the process is up and serving requests. It checks no
+// dependencies, so an orchestrator only restarts the server when the server
+// itself is stuck.
+func (h *Handlers) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, h.healthResponse("alive"))
+}
+
+// Readyz runs the readiness checks and answers 503 when any of them fails,
+// so load balancers stop routing to the server until its dependencies recover
+func (h *Handlers) Readyz(c *gin.Context) {
+	response := models.ReadinessResponse{Status: "ready", Build: h.build, Checks: []models.CheckResult{}}
+	if h.readiness != nil {
+		checks, ready := h.readiness.Run(c.Request.Context())
+		response.Checks = checks
+		if !ready {
+			response.Status = "not_ready"
+		}
+	}
+
+	status := http.StatusOK
+	if response.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
+
+func (h *Handlers) healthResponse(status string) models.HealthResponse {
+	return models.HealthResponse{
+		Status:    status,
+		Service:   "boilerplate-blueprint",
+		Version:   h.build.Version,
+		Commit:    h.build.Commit,
+		BuildTime: h.build.BuildTime,
+	}
 }
 
 // Get available templates
-// @Summary Get templates
-// @Description Get available project templates
-// @Tags Templates
-// @Accept json
-// @Produce json
-// @Success 200 {object} map[string]interface{}
-// @Router /api/templates [get]
 func (h *Handlers) GetTemplates(c *gin.Context) {
 	templates := h.templateService.GetAvailableTemplates()
-	c.JSON(http.StatusOK, gin.H{
-		"success":   true,
-		"templates": templates,
+	c.JSON(http.StatusOK, models.TemplatesResponse{
+		Success:   true,
+		Templates: templates,
 	})
 }
 
 // Create a new project
-// @Summary Create project
-// @Description Create a new project
-// @Tags Projects
-// @Accept json
-// @Produce json
-// @Param request body models.ProjectRequest true "Project creation request"
-// @Success 201 {object} models.ProjectResponse
-// @Failure 400 {object} models.ProjectResponse
-// @Failure 500 {object} models.ProjectResponse
-// @Router /api/projects [post]
 func (h *Handlers) CreateProject(c *gin.Context) {
 	var req models.ProjectRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ProjectResponse{
-			Success: false,
-			Error:   "Invalid request: " + err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
 		return
 	}
 
-	project, err := h.projectService.CreateProject(&req)
+	if err := h.shareableTeam(c, req.TeamID); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if req.PackID != "" {
+		pack, err := h.packFor(c, req.PackID, models.RoleViewer)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		if err := services.ApplyTemplatePack(pack, &req); err != nil {
+			fail(c, err)
+			return
+		}
+	}
+
+	req.OwnerID = callerID(c)
+	project, err := h.projectService.CreateProject(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ProjectResponse{
-			Success: false,
-			Error:   "Failed to create project: " + err.Error(),
-		})
+		fail(c, err)
 		return
 	}
 
@@ -93,23 +145,41 @@ func (h *Handlers) CreateProject(c *gin.Context) {
 	})
 }
 
+// List projects
+func (h *Handlers) ListProjects(c *gin.Context) {
+	teamID := c.Query("team_id")
+	role := h.roleResolver(c)
+
+	projects := []*models.Project{}
+	for _, project := range h.projectService.ListProjects(c.Request.Context()) {
+		if role(project.OwnerID, project.TeamID) == "" || (teamID != "" && project.TeamID != teamID) {
+			continue
+		}
+		listed := *project
+		listed.Files = nil
+		projects = append(projects, &listed)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].CreatedAt.After(projects[j].CreatedAt)
+	})
+
+	c.JSON(http.StatusOK, models.ProjectResponse{
+		Success:  true,
+		Projects: projects,
+	})
+}
+
 // Get project by ID
 func (h *Handlers) GetProject(c *gin.Context) {
 	projectID := c.Param("id")
 	if projectID == "" {
-		c.JSON(http.StatusBadRequest, models.ProjectResponse{
-			Success: false,
-			Error:   "Project ID is required",
-		})
+		fail(c, apperror.InvalidField("id", "project ID is required"))
 		return
 	}
 
-	project, err := h.projectService.GetProject(projectID)
+	project, err := h.projectFor(c, projectID, models.RoleViewer)
 	if err != nil {
-		c.JSON(http.StatusNotFound, models.ProjectResponse{
-			Success: false,
-			Error:   "Project not found",
-		})
+		fail(c, err)
 		return
 	}
 
@@ -123,55 +193,180 @@ func (h *Handlers) GetProject(c *gin.Context) {
 func (h *Handlers) GenerateProject(c *gin.Context) {
 	projectID := c.Param("id")
 	if projectID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Project ID is required",
-		})
+		fail(c, apperror.InvalidField("id", "project ID is required"))
 		return
 	}
 
-	project, err := h.projectService.GetProject(projectID)
+	project, err := h.projectFor(c, projectID, models.RoleEditor)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"success": false,
-			"error":   "Project not found",
-		})
+		fail(c, err)
 		return
 	}
 
-	files, err := h.projectService.GenerateProjectFiles(project)
+	files, err := h.projectService.GenerateProjectFiles(c.Request.Context(), project)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to generate project files: " + err.Error(),
-		})
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"message": "Project files generated successfully",
-		"files":   files,
+	c.JSON(http.StatusOK, models.GenerateResponse{
+		Success: true,
+		Message: "Project files generated successfully",
+		Files:   files,
 	})
 }
 
-// Download project as ZIP
+// Add a component to a generated project, merging it into the existing files
+func (h *Handlers) AddComponent(c *gin.Context) {
+	projectID := c.Param("id")
+	project, err := h.projectFor(c, projectID, models.RoleEditor)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	var req models.ComponentRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	plan, err := h.projectService.AddComponent(c.Request.Context(), project.ID, &req)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	message := "Component added successfully"
+	if !plan.Result.Applied {
+		message = "No changes were made"
+	}
+	c.JSON(http.StatusOK, models.ComponentResponse{
+		Success:  true,
+		Message:  message,
+		Result:   plan.Result,
+		Files:    plan.Files,
+		Revision: plan.Revision,
+	})
+}
+
+// uploadField is the form field upload routes read a file from
+const uploadField = "archive"
+
+// UpgradeProject merges the current templates into an edited copy of a
+// project, uploaded as a ZIP archive. The merged archive is returned in the
+// response, or with delivery=url as a pre-signed URL.
+func (h *Handlers) UpgradeProject(c *gin.Context) {
+	project, err := h.projectFor(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	baseRevision, err := strconv.Atoi(c.DefaultQuery("base_revision", "0"))
+	if err != nil || baseRevision < 0 {
+		fail(c, apperror.InvalidField("base_revision", "base_revision must be a revision number"))
+		return
+	}
+	delivery := c.DefaultQuery("delivery", "inline")
+	if delivery != "inline" && delivery != "url" {
+		fail(c, apperror.InvalidField("delivery", "unsupported delivery: "+delivery))
+		return
+	}
+
+	archive, err := readUpload(c)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	result, err := h.projectService.UpgradeProject(c.Request.Context(), project.ID, baseRevision, archive)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	message := "Project upgraded without conflicts"
+	if result.Report.Conflicts > 0 {
+		message = fmt.Sprintf("Project upgraded with conflicts in %d file(s)", result.Report.Conflicts)
+	}
+	response := models.UpgradeResponse{
+		Success:  true,
+		Message:  message,
+		Report:   result.Report,
+		Filename: result.Filename,
+	}
+	if delivery == "url" {
+		url, err := h.projectService.UpgradeArchiveURL(c.Request.Context(), project.ID, result, archiveURLExpiry)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		expiresAt := time.Now().Add(archiveURLExpiry).UTC()
+		response.URL, response.ExpiresAt = url, &expiresAt
+	} else {
+		response.Archive = result.Archive
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// readUpload returns the file sent as the request body, or in the upload
+// field of a multipart form
+func readUpload(c *gin.Context) ([]byte, error) {
+	body := c.Request.Body
+	if c.ContentType() == "multipart/form-data" {
+		header, err := c.FormFile(uploadField)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperror.InvalidField(uploadField, "form has no "+uploadField+" file"), err)
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, invalidBody(err)
+		}
+		defer file.Close()
+		body = file
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, invalidBody(err)
+	}
+	if len(data) == 0 {
+		return nil, apperror.InvalidField(uploadField, "a ZIP archive of the project is required")
+	}
+	return data, nil
+}
+
+// archiveURLExpiry is how long pre-signed download URLs stay valid
+const archiveURLExpiry = 15 * time.Minute
+
+// Download project as ZIP. With delivery=url the response is a pre-signed
+// URL instead of the archive; delivery=redirect sends the client there.
 func (h *Handlers) DownloadProject(c *gin.Context) {
 	projectID := c.Param("id")
 	if projectID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Project ID is required",
-		})
+		fail(c, apperror.InvalidField("id", "project ID is required"))
 		return
 	}
 
-	zipData, filename, err := h.projectService.CreateProjectZIP(projectID)
+	if _, err := h.projectFor(c, projectID, models.RoleViewer); err != nil {
+		fail(c, err)
+		return
+	}
+
+	switch delivery := c.DefaultQuery("delivery", "bytes"); delivery {
+	case "bytes":
+	case "url", "redirect":
+		h.downloadProjectURL(c, projectID, delivery == "redirect")
+		return
+	default:
+		fail(c, apperror.InvalidField("delivery", "unsupported delivery: "+delivery))
+		return
+	}
+
+	zipData, filename, err := h.projectService.CreateProjectZIP(c.Request.Context(), projectID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to create ZIP: " + err.Error(),
-		})
+		fail(c, err)
 		return
 	}
 
@@ -181,44 +376,487 @@ func (h *Handlers) DownloadProject(c *gin.Context) {
 	c.Data(http.StatusOK, "application/zip", zipData)
 }
 
+func (h *Handlers) downloadProjectURL(c *gin.Context, projectID string, redirect bool) {
+	url, filename, err := h.projectService.ProjectArchiveURL(c.Request.Context(), projectID, archiveURLExpiry)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if redirect {
+		c.Redirect(http.StatusTemporaryRedirect, url)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ArchiveURLResponse{
+		Success:   true,
+		URL:       url,
+		Filename:  filename,
+		ExpiresAt: time.Now().Add(archiveURLExpiry).UTC(),
+	})
+}
+
 // Send chat message
 func (h *Handlers) ChatMessage(c *gin.Context) {
 	var req models.ChatRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, models.ChatResponse{
-			Success: false,
-			Error:   "Invalid request: " + err.Error(),
-		})
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
 		return
 	}
 
-	response, err := h.chatService.ProcessMessage(&req)
+	if req.SessionID != "" {
+		if _, err := h.sessionFor(c, req.SessionID, models.RoleEditor); err != nil {
+			fail(c, err)
+			return
+		}
+	}
+
+	project, err := h.chatProject(c, req.ProjectID, models.RoleEditor)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ChatResponse{
-			Success: false,
-			Error:   "Failed to process message: " + err.Error(),
-		})
+		fail(c, err)
+		return
+	}
+
+	// A conversation started about a shared project is shared with the same team
+	req.OwnerID = callerID(c)
+	if project != nil {
+		req.TeamID = project.TeamID
+	}
+	response, err := h.chatService.ProcessMessage(c.Request.Context(), &req)
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// Create a project from a chat conversation
+func (h *Handlers) CreateProjectFromChat(c *gin.Context) {
+	var req models.ChatProjectRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	session, err := h.sessionFor(c, req.SessionID, models.RoleEditor)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	projectReq, err := h.chatService.DraftProjectRequest(c.Request.Context(), &req)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	// Projects created from a shared conversation are shared with the same team
+	projectReq.OwnerID = callerID(c)
+	projectReq.TeamID = session.TeamID
+	project, err := h.projectService.CreateProject(c.Request.Context(), projectReq)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	response, err := h.chatService.AttachProject(c.Request.Context(), req.SessionID, project)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 // Get chat history
 func (h *Handlers) GetChatHistory(c *gin.Context) {
-	projectID := c.Query("project_id")
+	var history *models.ChatHistory
+	var err error
+
+	if sessionID := c.Query("session_id"); sessionID != "" {
+		if _, err = h.sessionFor(c, sessionID, models.RoleViewer); err == nil {
+			history, err = h.chatService.GetSessionHistory(c.Request.Context(), sessionID)
+		}
+	} else {
+		projectID := c.Query("project_id")
+		if _, err = h.chatProject(c, projectID, models.RoleViewer); err == nil {
+			history, err = h.chatService.GetChatHistory(c.Request.Context(), projectID)
+		}
+		if err == nil && history.SessionID != "" && h.roleFor(c, history.OwnerID, history.TeamID) == "" {
+			err = fmt.Errorf("%w: chat for project %s", services.ErrSessionNotFound, projectID)
+		}
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatHistoryResponse{
+		Success: true,
+		History: history,
+	})
+}
+
+// Export a chat transcript
+func (h *Handlers) ExportChatHistory(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "markdown" {
+		fail(c, apperror.InvalidField("format", "unsupported format: "+format))
+		return
+	}
+
+	sessionID := c.Query("session_id")
+	if sessionID == "" && c.Query("project_id") != "" {
+		history, err := h.chatService.GetChatHistory(c.Request.Context(), c.Query("project_id"))
+		if err == nil {
+			sessionID = history.SessionID
+		}
+	}
+	if sessionID == "" {
+		fail(c, apperror.InvalidField("session_id", "session ID or a project with a chat session is required"))
+		return
+	}
+
+	transcript, err := h.chatService.ExportTranscript(c.Request.Context(), sessionID)
+	if err == nil && h.roleFor(c, transcript.History.OwnerID, transcript.History.TeamID) == "" {
+		err = fmt.Errorf("%w: %s", services.ErrSessionNotFound, sessionID)
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if format == "markdown" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=chat-%s.md", sessionID))
+		c.Data(http.StatusOK, "text/markdown; charset=utf-8", []byte(services.RenderTranscriptMarkdown(transcript)))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=chat-%s.json", sessionID))
+	c.JSON(http.StatusOK, transcript)
+}
+
+// Import a chat transcript
+func (h *Handlers) ImportChatHistory(c *gin.Context) {
+	var transcript models.ChatTranscript
+	if err := bindJSON(c, &transcript); err != nil {
+		fail(c, err)
+		return
+	}
+
+	// Imported sessions belong to the caller, whoever exported them, and stay
+	// shared only with a team the caller edits
+	transcript.History.OwnerID = callerID(c)
+	if h.shareableTeam(c, transcript.History.TeamID) != nil {
+		transcript.History.TeamID = ""
+	}
+
+	// Projects are not part of the transcript, so only keep links to projects known here that the caller edits
+	if projectID := transcript.History.ProjectID; projectID != "" {
+		if _, err := h.projectFor(c, projectID, models.RoleEditor); err != nil {
+			transcript.History.ProjectID = ""
+		}
+	}
+
+	session, err := h.chatService.ImportTranscript(c.Request.Context(), &transcript)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ChatSessionResponse{
+		Success: true,
+		Message: "Chat transcript imported successfully",
+		Session: session,
+	})
+}
+
+// Create a chat session
+func (h *Handlers) CreateChatSession(c *gin.Context) {
+	var req models.ChatSessionRequest
+	if c.Request.ContentLength != 0 {
+		if err := bindJSON(c, &req); err != nil {
+			fail(c, err)
+			return
+		}
+	}
+
+	if err := h.shareableTeam(c, req.TeamID); err != nil {
+		fail(c, err)
+		return
+	}
+	if _, err := h.chatProject(c, req.ProjectID, models.RoleEditor); err != nil {
+		fail(c, err)
+		return
+	}
+
+	req.OwnerID = callerID(c)
+	session, err := h.chatService.CreateSession(c.Request.Context(), &req)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.ChatSessionResponse{
+		Success: true,
+		Message: "Chat session created successfully",
+		Session: session,
+	})
+}
+
+// List chat sessions
+func (h *Handlers) ListChatSessions(c *gin.Context) {
+	teamID := c.Query("team_id")
+	role := h.roleResolver(c)
+
+	sessions := []models.ChatSession{}
+	for _, session := range h.chatService.ListSessions(c.Request.Context()) {
+		if role(session.OwnerID, session.TeamID) != "" && (teamID == "" || session.TeamID == teamID) {
+			sessions = append(sessions, session)
+		}
+	}
+
+	c.JSON(http.StatusOK, models.ChatSessionResponse{
+		Success:  true,
+		Sessions: sessions,
+	})
+}
+
+// Get a chat session
+func (h *Handlers) GetChatSession(c *gin.Context) {
+	session, err := h.sessionFor(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatSessionResponse{
+		Success: true,
+		Session: session,
+	})
+}
+
+// Rename a chat session or link it to a project
+func (h *Handlers) UpdateChatSession(c *gin.Context) {
+	sessionID := c.Param("id")
+
+	var req models.ChatSessionUpdateRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	session, err := h.sessionFor(c, sessionID, models.RoleEditor)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	if req.ProjectID != nil && *req.ProjectID != "" {
+		if _, err := h.projectFor(c, *req.ProjectID, models.RoleEditor); err != nil {
+			// The project is named in the body, so a missing one is a bad request
+			if apperror.Is(err, apperror.KindNotFound) {
+				err = fmt.Errorf("%w: %v", apperror.InvalidField("project_id", "project not found"), err)
+			}
+			fail(c, err)
+			return
+		}
+	}
+
+	if req.Title != nil {
+		session, err = h.chatService.RenameSession(c.Request.Context(), sessionID, *req.Title)
+	}
+	if err == nil && req.ProjectID != nil {
+		session, err = h.chatService.LinkSession(c.Request.Context(), sessionID, *req.ProjectID)
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatSessionResponse{
+		Success: true,
+		Message: "Chat session updated successfully",
+		Session: session,
+	})
+}
+
+// Delete a chat session
+func (h *Handlers) DeleteChatSession(c *gin.Context) {
+	sessionID := c.Param("id")
+	if _, err := h.sessionFor(c, sessionID, models.RoleEditor); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if err := h.chatService.DeleteSession(c.Request.Context(), sessionID); err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatSessionResponse{
+		Success: true,
+		Message: "Chat session deleted successfully",
+	})
+}
+
+// Get a page of a chat session's messages
+func (h *Handlers) GetChatSessionMessages(c *gin.Context) {
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil {
+		fail(c, apperror.InvalidField("offset", "offset must be a number"))
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultMessagePageSize)))
+	if err != nil {
+		fail(c, apperror.InvalidField("limit", "limit must be a number"))
+		return
+	}
+
+	if _, err := h.sessionFor(c, c.Param("id"), models.RoleViewer); err != nil {
+		fail(c, err)
+		return
+	}
+
+	page, err := h.chatService.GetSessionMessages(c.Request.Context(), c.Param("id"), offset, limit)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ChatMessagesResponse{
+		Success: true,
+		Page:    page,
+	})
+}
+
+// List assistant tools
+func (h *Handlers) GetAssistantTools(c *gin.Context) {
+	tools := h.chatService.AssistantTools()
+	if tools == nil {
+		fail(c, errAssistantToolsDisabled)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToolsResponse{
+		Success: true,
+		Tools:   tools.Definitions(),
+	})
+}
+
+// Run an assistant tool against a project
+func (h *Handlers) RunAssistantTool(c *gin.Context) {
+	tools := h.chatService.AssistantTools()
+	if tools == nil {
+		fail(c, errAssistantToolsDisabled)
+		return
+	}
+
+	var req models.ToolCallRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	// Reading files only needs access to the project; proposing changes
+	// needs the role that may confirm them
+	required := models.RoleViewer
+	if req.Name == models.ToolProposeOptionsChange || req.Name == models.ToolProposeFilePatch {
+		required = models.RoleEditor
+	}
+	if _, err := h.projectFor(c, c.Param("id"), required); err != nil {
+		fail(c, err)
+		return
+	}
+	if req.SessionID != "" {
+		if _, err := h.sessionFor(c, req.SessionID, required); err != nil {
+			fail(c, err)
+			return
+		}
+	}
+
+	result, err := tools.Execute(c.Request.Context(), c.Param("id"), req.SessionID, models.ToolCall{Name: req.Name, Arguments: req.Arguments})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ToolResultResponse{
+		Success: true,
+		Result:  result,
+	})
+}
+
+// List a project's change proposals
+func (h *Handlers) ListProposals(c *gin.Context) {
+	tools := h.chatService.AssistantTools()
+	if tools == nil {
+		fail(c, errAssistantToolsDisabled)
+		return
+	}
+
+	projectID := c.Param("id")
+	if _, err := h.projectFor(c, projectID, models.RoleViewer); err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProposalResponse{
+		Success:   true,
+		Proposals: tools.ListProposals(c.Request.Context(), projectID),
+	})
+}
+
+// Confirm a change proposal
+func (h *Handlers) ConfirmProposal(c *gin.Context) {
+	tools := h.chatService.AssistantTools()
+	if tools == nil {
+		fail(c, errAssistantToolsDisabled)
+		return
+	}
+
+	if _, err := h.projectFor(c, c.Param("id"), models.RoleEditor); err != nil {
+		fail(c, err)
+		return
+	}
+
+	proposal, project, err := tools.ConfirmProposal(c.Request.Context(), c.Param("id"), c.Param("proposalId"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProposalResponse{
+		Success:  true,
+		Message:  "Proposal applied successfully",
+		Proposal: proposal,
+		Project:  project,
+	})
+}
+
+// Reject a change proposal
+func (h *Handlers) RejectProposal(c *gin.Context) {
+	tools := h.chatService.AssistantTools()
+	if tools == nil {
+		fail(c, errAssistantToolsDisabled)
+		return
+	}
+
+	if _, err := h.projectFor(c, c.Param("id"), models.RoleEditor); err != nil {
+		fail(c, err)
+		return
+	}
 
-	history, err := h.chatService.GetChatHistory(projectID)
+	proposal, err := tools.RejectProposal(c.Request.Context(), c.Param("id"), c.Param("proposalId"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"success": false,
-			"error":   "Failed to get chat history: " + err.Error(),
-		})
+		fail(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"success": true,
-		"history": history,
+	c.JSON(http.StatusOK, models.ProposalResponse{
+		Success:  true,
+		Message:  "Proposal rejected",
+		Proposal: proposal,
 	})
 }
@@ -1,52 +1,233 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/tracing"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
-	"github.com/gin-gonic/gin"
 )
 
-// StartLambda starts the Lambda handler
-func StartLambda(handlers *Handlers) {
-	// Set Gin to release mode for Lambda
-	gin.SetMode(gin.ReleaseMode)
+// LambdaHandlerFunc handles API Gateway HTTP API (payload format 2.0) events
+type LambdaHandlerFunc func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error)
+
+// StartLambda serves API Gateway events with handler until the Lambda runtime
+// stops. Spans are flushed after every invocation, since the runtime freezes
+// the process between events and never lets it shut down cleanly.
+func StartLambda(handler http.Handler) {
+	lambda.Start(FlushAfter(NewLambdaHandler(handler), tracing.ForceFlush))
+}
+
+// FlushAfter runs flush once handler has answered each event. A failed flush
+// is logged; the response has already been built and is still returned.
+func FlushAfter(handler LambdaHandlerFunc, flush func(context.Context) error) LambdaHandlerFunc {
+	return func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		response, err := handler(ctx, event)
+		if flushErr := flush(ctx); flushErr != nil {
+			logging.FromContext(ctx).Warn("failed to flush traces", "error", flushErr)
+		}
+		return response, err
+	}
+}
+
+// NewLambdaHandler adapts an http.Handler to API Gateway HTTP API events
+func NewLambdaHandler(handler http.Handler) LambdaHandlerFunc {
+	return func(ctx context.Context, event events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
+		req, err := requestFromEvent(ctx, event)
+		if err != nil {
+			return events.APIGatewayV2HTTPResponse{
+				StatusCode: http.StatusBadRequest,
+				Body:       err.Error(),
+				Headers:    map[string]string{"Content-Type": "text/plain; charset=utf-8"},
+			}, nil
+		}
+
+		writer := newLambdaResponseWriter()
+		handler.ServeHTTP(writer, req)
+
+		return writer.response(), nil
+	}
+}
+
+// requestFromEvent builds the http.Request described by an API Gateway event
+func requestFromEvent(ctx context.Context, event events.APIGatewayV2HTTPRequest) (*http.Request, error) {
+	method := event.RequestContext.HTTP.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := event.RawPath
+	if path == "" {
+		path = event.RequestContext.HTTP.Path
+	}
+	if path == "" {
+		path = "/"
+	}
+	// Named stages prefix the raw path; the router only knows the routes themselves
+	if stage := event.RequestContext.Stage; stage != "" && stage != "$default" {
+		prefix := "/" + stage
+		if path == prefix {
+			path = "/"
+		} else if strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+		}
+	}
+
+	rawQuery := event.RawQueryString
+	if rawQuery == "" && len(event.QueryStringParameters) > 0 {
+		values := url.Values{}
+		for key, value := range event.QueryStringParameters {
+			// Payload 2.0 joins repeated parameters with commas
+			for _, part := range strings.Split(value, ",") {
+				values.Add(key, part)
+			}
+		}
+		rawQuery = values.Encode()
+	}
+
+	body := []byte(event.Body)
+	if event.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(event.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 request body: %w", err)
+		}
+		body = decoded
+	}
+
+	// The raw path is still percent-encoded; keeping it as RawPath stops
+	// RequestURI from encoding it a second time
+	unescaped, err := url.PathUnescape(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request path: %w", err)
+	}
+	target := (&url.URL{Path: unescaped, RawPath: path, RawQuery: rawQuery}).RequestURI()
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	for name, value := range event.Headers {
+		req.Header.Set(name, value)
+	}
+	if len(event.Cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(event.Cookies, "; "))
+	}
 
-	// Create router
-	router := gin.New()
+	req.Host = req.Header.Get("Host")
+	if req.Host == "" {
+		req.Host = event.RequestContext.DomainName
+	}
+	req.URL.Host = req.Host
+	if sourceIP := event.RequestContext.HTTP.SourceIP; sourceIP != "" {
+		// API Gateway reports no client port; keep the host:port form handlers expect
+		req.RemoteAddr = net.JoinHostPort(sourceIP, "0")
+	}
+	req.ContentLength = int64(len(body))
+	req.RequestURI = target
 
-	// Add middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	return req, nil
+}
+
+// lambdaResponseWriter records a response so it can be returned to API Gateway
+type lambdaResponseWriter struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newLambdaResponseWriter() *lambdaResponseWriter {
+	return &lambdaResponseWriter{header: http.Header{}}
+}
+
+func (w *lambdaResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *lambdaResponseWriter) Write(data []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(data)
+}
+
+func (w *lambdaResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+// response converts the recorded response, base64-encoding binary bodies
+func (w *lambdaResponseWriter) response() events.APIGatewayV2HTTPResponse {
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if w.header.Get("Content-Type") == "" && w.body.Len() > 0 {
+		w.header.Set("Content-Type", http.DetectContentType(w.body.Bytes()))
+	}
 
-	// Configure CORS for Lambda
-	router.Use(func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+	response := events.APIGatewayV2HTTPResponse{
+		StatusCode: status,
+		Headers:    map[string]string{},
+	}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(http.StatusNoContent)
-			return
+	for name, values := range w.header {
+		if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+			response.Cookies = append(response.Cookies, values...)
+			continue
 		}
+		response.Headers[name] = strings.Join(values, ",")
+	}
+
+	body := w.body.Bytes()
+	if isTextContent(w.header.Get("Content-Type")) && utf8.Valid(body) {
+		response.Body = string(body)
+	} else {
+		response.Body = base64.StdEncoding.EncodeToString(body)
+		response.IsBase64Encoded = true
+	}
+
+	return response
+}
+
+// isTextContent reports whether a content type can be returned to API Gateway as a plain string
+func isTextContent(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/json", "application/javascript", "application/xml",
+		"application/x-www-form-urlencoded", "image/svg+xml":
+		return true
+	}
 
-		c.Next()
-	})
-
-	// Setup routes
-	SetupRoutes(router, handlers)
-
-	// Start Lambda with Gin adapter
-	lambda.Start(func(ctx context.Context, req events.APIGatewayV2HTTPRequest) (events.APIGatewayV2HTTPResponse, error) {
-		// Create a simple response for now
-		return events.APIGatewayV2HTTPResponse{
-			StatusCode: http.StatusOK,
-			Body:       "Lambda integration coming soon. Use local deployment for now.",
-			Headers: map[string]string{
-				"Content-Type": "text/plain",
-			},
-		}, nil
-	})
+	return false
 }
@@ -0,0 +1,216 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// apiDocs serves the OpenAPI document generated from the route table and a
+// page that renders it
+type apiDocs struct {
+	spec []byte
+	err  error // Why the document could not be encoded
+}
+
+// publicRoutes are served without authentication so that load balancers and
+// people exploring the API can reach them
+func publicRoutes(h *Handlers, docs *apiDocs) []Route {
+	return []Route{
+		{
+			Method: http.MethodGet, Path: "/health", Handler: h.Health, Public: true,
+			Tag: "Health", Summary: "Health check", Description: "Health check endpoint",
+			Response: models.HealthResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/openapi.json", Handler: docs.OpenAPISpec, Public: true,
+			Tag: "Documentation", Summary: "OpenAPI document", Description: "This API's OpenAPI 3 document",
+			Produces: []string{"application/json"},
+		},
+		{
+			Method: http.MethodGet, Path: "/docs", Handler: docs.DocsPage, Public: true,
+			Tag: "Documentation", Summary: "API documentation", Description: "Browsable documentation rendered from the OpenAPI document",
+			Produces: []string{"text/html"},
+		},
+	}
+}
+
+// OpenAPISpec serves the OpenAPI document
+func (d *apiDocs) OpenAPISpec(c *gin.Context) {
+	if d.err != nil {
+		apperror.Abort(c, apperror.Internal(fmt.Errorf("failed to encode OpenAPI document: %w", d.err)))
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", d.spec)
+}
+
+// DocsPage serves the documentation page
+func (d *apiDocs) DocsPage(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+func (d *apiDocs) build(routes []Route) {
+	spec, err := json.Marshal(OpenAPI(routes))
+	if err != nil {
+		// Only a model JSON cannot encode gets here, which is a programming
+		// error; it is reported, with the request's logger, when the document
+		// is requested
+		d.err = err
+		return
+	}
+	d.spec = spec
+}
+
+// Security schemes protected routes accept
+var securitySchemes = map[string]openapi.SecurityScheme{
+	"apiKey": {
+		Type: "apiKey", In: "header", Name: "X-API-Key",
+		Description: "API key, required when the server has keys configured",
+	},
+	"bearer": {
+		Type: "http", Scheme: "bearer", BearerFormat: "JWT",
+		Description: "JWT or API key as a bearer token, required when the server has credentials configured",
+	},
+}
+
+// OpenAPI returns the OpenAPI document describing routes, with paths
+// relative to BasePath
+func OpenAPI(routes []Route) *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:       "Boilerplate Blueprint API",
+		Description: "Generate project boilerplate from templates, with a chat assistant to help choose options.",
+		Version:     "1.0.0",
+	})
+	doc.Servers = []openapi.Server{{URL: BasePath}}
+	doc.Components.SecuritySchemes = securitySchemes
+	errorSchema := doc.Define("ErrorResponse", apperror.Response{})
+
+	seenTags := make(map[string]bool)
+	for _, route := range routes {
+		path, pathParams := openapi.Path(route.Path)
+		op := &openapi.Operation{
+			Summary:     route.Summary,
+			Description: route.Description,
+			OperationID: operationID(route.Handler),
+			Parameters:  parameters(route, pathParams),
+			Responses:   make(map[string]openapi.Response),
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+			if !seenTags[route.Tag] {
+				seenTags[route.Tag] = true
+				doc.Tags = append(doc.Tags, openapi.Tag{Name: route.Tag})
+			}
+		}
+		if !route.Public {
+			op.Security = []openapi.Requirement{{"apiKey": {}}, {"bearer": {}}}
+		}
+
+		if route.Body != nil {
+			op.RequestBody = &openapi.RequestBody{
+				Required: !route.BodyOptional,
+				Content:  openapi.JSONContent(doc.SchemaFor(route.Body)),
+			}
+		}
+		if len(route.Consumes) > 0 {
+			op.RequestBody = &openapi.RequestBody{Required: !route.BodyOptional, Content: make(map[string]openapi.MediaType)}
+			for _, contentType := range route.Consumes {
+				op.RequestBody.Content[contentType] = openapi.MediaType{Schema: contentSchema(contentType)}
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := openapi.Response{Description: http.StatusText(status), Content: make(map[string]openapi.MediaType)}
+		if route.Response != nil {
+			success.Content["application/json"] = openapi.MediaType{Schema: doc.SchemaFor(route.Response)}
+		}
+		for _, contentType := range route.Produces {
+			success.Content[contentType] = openapi.MediaType{Schema: contentSchema(contentType)}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = openapi.Response{
+			Description: "Error",
+			Content:     openapi.JSONContent(errorSchema),
+		}
+
+		doc.AddOperation(route.Method, path, op)
+	}
+
+	return doc
+}
+
+// parameters documents a route's path parameters, in path order, followed by
+// its query parameters
+func parameters(route Route, pathParams []string) []openapi.Parameter {
+	documented := make(map[string]Param)
+	for _, param := range route.Params {
+		if param.In == "path" {
+			documented[param.Name] = param
+		}
+	}
+
+	var params []openapi.Parameter
+	for _, name := range pathParams {
+		params = append(params, openapi.Parameter{
+			Name: name, In: "path", Required: true,
+			Description: documented[name].Description,
+			Schema:      &openapi.Schema{Type: "string"},
+		})
+	}
+	for _, param := range route.Params {
+		if param.In != "query" {
+			continue
+		}
+		schema := &openapi.Schema{Type: "string"}
+		if param.Integer {
+			schema = &openapi.Schema{Type: "integer"}
+		}
+		params = append(params, openapi.Parameter{
+			Name: param.Name, In: "query",
+			Description: param.Description,
+			Schema:      schema,
+		})
+	}
+	return params
+}
+
+// operationID names an operation after its handler method
+func operationID(handler gin.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func contentSchema(contentType string) *openapi.Schema {
+	switch {
+	case contentType == "application/json":
+		return &openapi.Schema{Type: "object"}
+	case strings.HasPrefix(contentType, "text/"):
+		return &openapi.Schema{Type: "string"}
+	case contentType == "multipart/form-data":
+		return &openapi.Schema{
+			Type:       "object",
+			Properties: map[string]*openapi.Schema{uploadField: {Type: "string", Format: "binary"}},
+			Required:   []string{uploadField},
+		}
+	default:
+		return &openapi.Schema{Type: "string", Format: "binary"}
+	}
+}
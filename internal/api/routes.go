@@ -1,27 +1,420 @@
 package api
 
 import (
+	"net/http"
+	"strings"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/limits"
+	"boilerplate-blueprint/internal/models"
+
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine, handlers *Handlers) {
-	// API group
-	api := router.Group("/api")
-	{
-		// Health check
-		api.GET("/health", handlers.Health)
+// API base paths. LegacyBasePath serves the same routes as BasePath while
+// clients move over, with responses marked deprecated.
+const (
+	BasePath       = "/api/v1"
+	LegacyBasePath = "/api"
+)
+
+// Route groups with separate rate limit budgets and deadlines
+const (
+	// RouteGroupDefault covers every route not in another group
+	RouteGroupDefault = "default"
+	// RouteGroupGenerate covers the CPU and memory heavy generation and download routes
+	RouteGroupGenerate = "generate"
+	// RouteGroupChat covers the routes that may call an LLM
+	RouteGroupChat = "chat"
+)
+
+// RouteOptions holds the middleware wrapped around the API routes
+type RouteOptions struct {
+	// Middleware, such as authentication, applies to every route except the
+	// health check and the API documentation
+	Middleware []gin.HandlerFunc
+	// RateLimit returns the rate limiting middleware for a route group; nil disables it
+	RateLimit func(group string) gin.HandlerFunc
+	// Timeout returns the deadline middleware for a route group; nil leaves requests without one
+	Timeout func(group string) gin.HandlerFunc
+	// MaxBodyBytes caps request bodies; zero leaves them uncapped
+	MaxBodyBytes int64
+	// MaxUploadBytes caps the bodies of upload routes instead; zero leaves them uncapped
+	MaxUploadBytes int64
+}
+
+// Route is one API endpoint. The route table drives both the router and the
+// OpenAPI document, so a route cannot be served without being documented.
+type Route struct {
+	Method  string
+	Path    string // Relative to the base path, in gin syntax
+	Handler gin.HandlerFunc
+	Group   string // Rate limit and deadline group; RouteGroupDefault when empty
+	Public  bool   // Served without authentication, rate limits, deadlines or body caps
+	Upload  bool   // Takes a file as its body, capped by MaxUploadBytes instead of MaxBodyBytes
+
+	Tag          string
+	Summary      string
+	Description  string
+	Params       []Param
+	Body         interface{} // Request body model; nil when the route takes none
+	BodyOptional bool
+	Consumes     []string    // Request content types of routes whose body is not JSON
+	Status       int         // Success status; 200 when zero
+	Response     interface{} // Success response model; nil when the route does not answer JSON
+	Produces     []string    // Success content types besides JSON
+}
+
+// Param documents a path or query parameter
+type Param struct {
+	Name        string
+	In          string // path or query
+	Description string
+	Integer     bool
+}
+
+func pathParam(name, description string) Param {
+	return Param{Name: name, In: "path", Description: description}
+}
+
+func queryParam(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description}
+}
+
+// SetupRoutes registers the API. The optional middleware, such as
+// authentication, applies to every route except the health check and the
+// API documentation.
+func SetupRoutes(router *gin.Engine, handlers *Handlers, middleware ...gin.HandlerFunc) {
+	SetupRoutesWithOptions(router, handlers, RouteOptions{Middleware: middleware})
+}
+
+// SetupRoutesWithOptions registers the API under BasePath and
+// LegacyBasePath, with rate limiting, deadlines and body size caps, and the
+// /livez and /readyz probes at the root. Rate limits run after the other
+// middleware so that they can key on the authenticated principal; deadlines
+// start last, so that they only time the handler.
+func SetupRoutesWithOptions(router *gin.Engine, handlers *Handlers, options RouteOptions) {
+	// Errors recorded with c.Error but not yet written are reported by
+	// apperror.Middleware
+	middleware := append([]gin.HandlerFunc{apperror.Middleware()}, options.Middleware...)
+	groups := make(map[string][]gin.HandlerFunc)
+	uploads := make(map[string][]gin.HandlerFunc)
+	for _, group := range []string{RouteGroupDefault, RouteGroupGenerate, RouteGroupChat} {
+		groups[group] = groupChain(middleware, options, group, options.MaxBodyBytes)
+		uploads[group] = groupChain(middleware, options, group, options.MaxUploadBytes)
+	}
+
+	// Orchestrator probes live at the root, outside authentication and rate limits
+	router.GET("/livez", handlers.Livez)
+	router.GET("/readyz", handlers.Readyz)
+
+	docs := &apiDocs{}
+	table := append(publicRoutes(handlers, docs), apiRoutes(handlers)...)
+	docs.build(table)
+
+	for _, base := range []*gin.RouterGroup{
+		router.Group(BasePath),
+		router.Group(LegacyBasePath, deprecatedAlias()),
+	} {
+		for _, route := range table {
+			var chain []gin.HandlerFunc
+			if !route.Public {
+				group := route.Group
+				if group == "" {
+					group = RouteGroupDefault
+				}
+				if route.Upload {
+					chain = append(chain, uploads[group]...)
+				} else {
+					chain = append(chain, groups[group]...)
+				}
+			}
+			base.Handle(route.Method, route.Path, append(chain, route.Handler)...)
+		}
+	}
+}
 
+// groupChain returns the middleware of a route group, with request bodies
+// capped at maxBodyBytes
+func groupChain(middleware []gin.HandlerFunc, options RouteOptions, group string, maxBodyBytes int64) []gin.HandlerFunc {
+	chain := append([]gin.HandlerFunc{}, middleware...)
+	if maxBodyBytes > 0 {
+		chain = append(chain, limits.BodyLimit(maxBodyBytes))
+	}
+	if options.RateLimit != nil {
+		chain = append(chain, options.RateLimit(group))
+	}
+	if options.Timeout != nil {
+		chain = append(chain, options.Timeout(group))
+	}
+	return chain
+}
+
+// deprecatedAlias marks responses served under LegacyBasePath as deprecated
+// and points clients at the same route under BasePath
+func deprecatedAlias() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		successor := BasePath + strings.TrimPrefix(c.Request.URL.Path, LegacyBasePath)
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+`>; rel="successor-version"`)
+		c.Next()
+	}
+}
+
+// apiRoutes is the route table of the API proper
+func apiRoutes(h *Handlers) []Route {
+	projectID := pathParam("id", "Project ID")
+	sessionID := pathParam("id", "Session ID")
+	teamID := pathParam("id", "Team ID")
+	packID := pathParam("id", "Template pack ID")
+	proposalID := pathParam("proposalId", "Proposal ID")
+	principalID := pathParam("principalId", "Principal ID: apikey:<name> or jwt:<iss>:<sub>")
+
+	return []Route{
 		// Template endpoints
-		api.GET("/templates", handlers.GetTemplates)
+		{
+			Method: http.MethodGet, Path: "/templates", Handler: h.GetTemplates,
+			Tag: "Templates", Summary: "Get templates", Description: "Get available project templates",
+			Response: models.TemplatesResponse{},
+		},
+
+		// Template pack endpoints
+		{
+			Method: http.MethodPost, Path: "/template-packs", Handler: h.CreateTemplatePack,
+			Tag: "Templates", Summary: "Create template pack",
+			Description: "Save a preset of template options, optionally shared with a team the caller edits",
+			Body:        models.TemplatePackRequest{}, Status: http.StatusCreated, Response: models.TemplatePackResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/template-packs", Handler: h.ListTemplatePacks,
+			Tag: "Templates", Summary: "List template packs",
+			Description: "List the caller's own template packs and those shared with their teams",
+			Params: []Param{
+				queryParam("team_id", "Only list packs shared with this team"),
+				queryParam("language", "Only list packs for this language"),
+			},
+			Response: models.TemplatePackResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/template-packs/:id", Handler: h.GetTemplatePack,
+			Tag: "Templates", Summary: "Get template pack",
+			Params: []Param{packID}, Response: models.TemplatePackResponse{},
+		},
+		{
+			Method: http.MethodPut, Path: "/template-packs/:id", Handler: h.UpdateTemplatePack,
+			Tag: "Templates", Summary: "Update template pack",
+			Description: "Replace a template pack. Requires the editor role; moving it to another team also requires the owner role on the pack and the editor role in the new team.",
+			Params:      []Param{packID}, Body: models.TemplatePackRequest{}, Response: models.TemplatePackResponse{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/template-packs/:id", Handler: h.DeleteTemplatePack,
+			Tag: "Templates", Summary: "Delete template pack",
+			Description: "Delete a template pack. Requires the editor role; projects created from it keep their options.",
+			Params:      []Param{packID}, Response: models.TemplatePackResponse{},
+		},
+
+		// Team endpoints
+		{
+			Method: http.MethodPost, Path: "/teams", Handler: h.CreateTeam,
+			Tag: "Teams", Summary: "Create team", Description: "Create a team with the caller as its owner",
+			Body: models.TeamRequest{}, Status: http.StatusCreated, Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/teams", Handler: h.ListTeams,
+			Tag: "Teams", Summary: "List teams", Description: "List the teams the caller is a member of",
+			Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/teams/:id", Handler: h.GetTeam,
+			Tag: "Teams", Summary: "Get team", Description: "Get a team and its members",
+			Params: []Param{teamID}, Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodPatch, Path: "/teams/:id", Handler: h.UpdateTeam,
+			Tag: "Teams", Summary: "Update team", Description: "Rename a team. Requires the owner role.",
+			Params: []Param{teamID}, Body: models.TeamRequest{}, Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/teams/:id", Handler: h.DeleteTeam,
+			Tag: "Teams", Summary: "Delete team",
+			Description: "Delete a team. Requires the owner role. Resources shared with the team fall back to the principals who created them.",
+			Params:      []Param{teamID}, Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodPut, Path: "/teams/:id/members/*principalId", Handler: h.SetTeamMember,
+			Tag: "Teams", Summary: "Set team member",
+			Description: "Add a principal to a team or change their role. Requires the owner role.",
+			Params:      []Param{teamID, principalID}, Body: models.TeamMemberRequest{}, Response: models.TeamResponse{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/teams/:id/members/*principalId", Handler: h.RemoveTeamMember,
+			Tag: "Teams", Summary: "Remove team member",
+			Description: "Remove a principal from a team. Owners may remove anyone; any member may remove themselves.",
+			Params:      []Param{teamID, principalID}, Response: models.TeamResponse{},
+		},
 
 		// Project endpoints
-		api.POST("/projects", handlers.CreateProject)
-		api.GET("/projects/:id", handlers.GetProject)
-		api.POST("/projects/:id/generate", handlers.GenerateProject)
-		api.GET("/projects/:id/download", handlers.DownloadProject)
+		{
+			Method: http.MethodPost, Path: "/projects", Handler: h.CreateProject,
+			Tag: "Projects", Summary: "Create project", Description: "Create a new project",
+			Body: models.ProjectRequest{}, Status: http.StatusCreated, Response: models.ProjectResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/projects", Handler: h.ListProjects,
+			Tag: "Projects", Summary: "List projects",
+			Description: "List the caller's own projects and those shared with their teams, newest first. Files are omitted.",
+			Params:      []Param{queryParam("team_id", "Only list projects shared with this team")},
+			Response:    models.ProjectResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/projects/:id", Handler: h.GetProject,
+			Tag: "Projects", Summary: "Get project", Description: "Get a project and its files",
+			Params: []Param{projectID}, Response: models.ProjectResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/generate", Handler: h.GenerateProject, Group: RouteGroupGenerate,
+			Tag: "Projects", Summary: "Generate project", Description: "Render the project's files from its template and options",
+			Params: []Param{projectID}, Response: models.GenerateResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/components", Handler: h.AddComponent, Group: RouteGroupGenerate,
+			Tag: "Projects", Summary: "Add component",
+			Description: "Render a utility, OAuth provider or CRUD entity into a Go project and merge its routes into the existing files at their anchors. Conflicts with edited files are reported with 409 and nothing is changed; dry_run reports the changes without applying them.",
+			Params:      []Param{projectID}, Body: models.ComponentRequest{}, Response: models.ComponentResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/upgrade", Handler: h.UpgradeProject, Group: RouteGroupGenerate, Upload: true,
+			Tag: "Projects", Summary: "Upgrade project",
+			Description: "Merge what the current templates change into an edited copy of the project, sent as a ZIP archive in the body or in the archive field of a form. Each file is merged three ways against the files of base_revision; the response holds the merged archive, or with delivery=url a pre-signed URL to it, and a report of conflicts, which are marked in the files with diff3-style markers. The project itself is not changed.",
+			Params: []Param{
+				projectID,
+				{Name: "base_revision", In: "query", Description: "Revision the copy was generated from; the current revision when omitted", Integer: true},
+				queryParam("delivery", "inline (default) or url"),
+			},
+			Consumes: []string{"application/zip", "multipart/form-data"}, Response: models.UpgradeResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/projects/:id/download", Handler: h.DownloadProject, Group: RouteGroupGenerate,
+			Tag: "Projects", Summary: "Download project",
+			Description: "Download the project as a ZIP archive. With delivery=url the response is a pre-signed URL instead; delivery=redirect sends the client there.",
+			Params: []Param{
+				projectID,
+				queryParam("delivery", "bytes (default), url or redirect"),
+			},
+			Response: models.ArchiveURLResponse{}, Produces: []string{"application/zip"},
+		},
+
+		// Assistant tool endpoints
+		{
+			Method: http.MethodGet, Path: "/assistant/tools", Handler: h.GetAssistantTools,
+			Tag: "Assistant", Summary: "List assistant tools",
+			Description: "List the tools the chat assistant can use against a project",
+			Response:    models.ToolsResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/assistant/tools", Handler: h.RunAssistantTool,
+			Tag: "Assistant", Summary: "Run assistant tool",
+			Description: "Run an assistant tool against a project. Propose tools record a change that must be confirmed before it is applied.",
+			Params:      []Param{projectID}, Body: models.ToolCallRequest{}, Response: models.ToolResultResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/projects/:id/proposals", Handler: h.ListProposals,
+			Tag: "Assistant", Summary: "List change proposals",
+			Description: "List the changes the assistant proposed for a project",
+			Params:      []Param{projectID}, Response: models.ProposalResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/proposals/:proposalId/confirm", Handler: h.ConfirmProposal, Group: RouteGroupGenerate,
+			Tag: "Assistant", Summary: "Confirm change proposal", Description: "Apply a pending change proposal to the project",
+			Params: []Param{projectID, proposalID}, Response: models.ProposalResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/projects/:id/proposals/:proposalId/reject", Handler: h.RejectProposal,
+			Tag: "Assistant", Summary: "Reject change proposal", Description: "Discard a pending change proposal",
+			Params: []Param{projectID, proposalID}, Response: models.ProposalResponse{},
+		},
 
 		// Chat endpoints
-		api.POST("/chat/message", handlers.ChatMessage)
-		api.GET("/chat/history", handlers.GetChatHistory)
+		{
+			Method: http.MethodPost, Path: "/chat/message", Handler: h.ChatMessage, Group: RouteGroupChat,
+			Tag: "Chat", Summary: "Send chat message",
+			Description: "Send a message to the assistant, starting a session when none is given",
+			Body:        models.ChatRequest{}, Response: models.ChatResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/chat/history", Handler: h.GetChatHistory,
+			Tag: "Chat", Summary: "Get chat history",
+			Description: "Get the history of a chat session, or of the conversation linked to a project",
+			Params: []Param{
+				queryParam("session_id", "Session ID"),
+				queryParam("project_id", "Project ID, used when no session ID is given"),
+			},
+			Response: models.ChatHistoryResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/chat/history/export", Handler: h.ExportChatHistory,
+			Tag: "Chat", Summary: "Export chat transcript",
+			Description: "Export a chat session as a Markdown or JSON transcript",
+			Params: []Param{
+				queryParam("session_id", "Session ID"),
+				queryParam("project_id", "Project ID, used when no session ID is given"),
+				queryParam("format", "Transcript format: json (default) or markdown"),
+			},
+			Response: models.ChatTranscript{}, Produces: []string{"text/markdown"},
+		},
+		{
+			Method: http.MethodPost, Path: "/chat/history/import", Handler: h.ImportChatHistory,
+			Tag: "Chat", Summary: "Import chat transcript",
+			Description: "Restore a chat session from an exported JSON transcript",
+			Body:        models.ChatTranscript{}, Status: http.StatusCreated, Response: models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodPost, Path: "/chat/projects", Handler: h.CreateProjectFromChat,
+			Tag: "Chat", Summary: "Create project from chat",
+			Description: "Create a project from the configuration gathered in a chat conversation",
+			Body:        models.ChatProjectRequest{}, Status: http.StatusCreated, Response: models.ChatResponse{},
+		},
+
+		// Chat session endpoints
+		{
+			Method: http.MethodPost, Path: "/chat/sessions", Handler: h.CreateChatSession,
+			Tag: "Chat", Summary: "Create chat session",
+			Description: "Start a new chat session, optionally linked to a project",
+			Body:        models.ChatSessionRequest{}, BodyOptional: true,
+			Status: http.StatusCreated, Response: models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/chat/sessions", Handler: h.ListChatSessions,
+			Tag: "Chat", Summary: "List chat sessions",
+			Description: "List the caller's own chat sessions and those shared with their teams, most recently active first",
+			Params:      []Param{queryParam("team_id", "Only list sessions shared with this team")},
+			Response:    models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/chat/sessions/:id", Handler: h.GetChatSession,
+			Tag: "Chat", Summary: "Get chat session", Description: "Get a chat session summary",
+			Params: []Param{sessionID}, Response: models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodPatch, Path: "/chat/sessions/:id", Handler: h.UpdateChatSession,
+			Tag: "Chat", Summary: "Update chat session", Description: "Rename a chat session and/or link it to a project",
+			Params: []Param{sessionID}, Body: models.ChatSessionUpdateRequest{}, Response: models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodDelete, Path: "/chat/sessions/:id", Handler: h.DeleteChatSession,
+			Tag: "Chat", Summary: "Delete chat session", Description: "Delete a chat session and its messages",
+			Params: []Param{sessionID}, Response: models.ChatSessionResponse{},
+		},
+		{
+			Method: http.MethodGet, Path: "/chat/sessions/:id/messages", Handler: h.GetChatSessionMessages,
+			Tag: "Chat", Summary: "Get chat session messages",
+			Description: "Get a page of a chat session's messages, oldest first",
+			Params: []Param{
+				sessionID,
+				{Name: "offset", In: "query", Description: "Number of messages to skip", Integer: true},
+				{Name: "limit", In: "query", Description: "Maximum number of messages to return", Integer: true},
+			},
+			Response: models.ChatMessagesResponse{},
+		},
 	}
 }
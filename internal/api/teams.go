@@ -0,0 +1,341 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// teamsEnabled answers 501 when no team service is configured
+func (h *Handlers) teamsEnabled(c *gin.Context) bool {
+	if h.teamService == nil {
+		fail(c, errTeamsDisabled)
+		return false
+	}
+	return true
+}
+
+// Create a team
+func (h *Handlers) CreateTeam(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	var req models.TeamRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	team, err := h.teamService.CreateTeam(c.Request.Context(), req.Name, callerID(c))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.TeamResponse{
+		Success: true,
+		Message: "Team created successfully",
+		Team:    team,
+	})
+}
+
+// List teams
+func (h *Handlers) ListTeams(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Teams:   h.teamService.ListTeams(c.Request.Context(), callerID(c)),
+	})
+}
+
+// Get a team
+func (h *Handlers) GetTeam(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	team, err := h.teamFor(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Team:    team,
+	})
+}
+
+// Rename a team
+func (h *Handlers) UpdateTeam(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	var req models.TeamRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if _, err := h.teamFor(c, c.Param("id"), models.RoleOwner); err != nil {
+		fail(c, err)
+		return
+	}
+
+	team, err := h.teamService.RenameTeam(c.Request.Context(), c.Param("id"), req.Name)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Message: "Team updated successfully",
+		Team:    team,
+	})
+}
+
+// Delete a team
+func (h *Handlers) DeleteTeam(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	if _, err := h.teamFor(c, c.Param("id"), models.RoleOwner); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if err := h.teamService.DeleteTeam(c.Request.Context(), c.Param("id")); err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Message: "Team deleted successfully",
+	})
+}
+
+// Add a team member or change their role
+func (h *Handlers) SetTeamMember(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	var req models.TeamMemberRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+	if !req.Role.Valid() {
+		fail(c, apperror.InvalidField("role", "role must be owner, editor or viewer"))
+		return
+	}
+	if memberID(c) == "" {
+		fail(c, apperror.InvalidField("principalId", "principal ID is required"))
+		return
+	}
+
+	if _, err := h.teamFor(c, c.Param("id"), models.RoleOwner); err != nil {
+		fail(c, err)
+		return
+	}
+
+	team, err := h.teamService.SetMember(c.Request.Context(), c.Param("id"), memberID(c), req.Role)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Message: "Team member updated successfully",
+		Team:    team,
+	})
+}
+
+// Remove a team member
+func (h *Handlers) RemoveTeamMember(c *gin.Context) {
+	if !h.teamsEnabled(c) {
+		return
+	}
+
+	required := models.RoleOwner
+	if memberID(c) == callerID(c) {
+		required = models.RoleViewer
+	}
+	if _, err := h.teamFor(c, c.Param("id"), required); err != nil {
+		fail(c, err)
+		return
+	}
+
+	team, err := h.teamService.RemoveMember(c.Request.Context(), c.Param("id"), memberID(c))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TeamResponse{
+		Success: true,
+		Message: "Team member removed successfully",
+		Team:    team,
+	})
+}
+
+// memberID returns the principal ID a member route names. The route matches
+// the rest of the path, as JWT principal IDs hold the issuer, often a URL.
+func memberID(c *gin.Context) string {
+	return strings.TrimPrefix(c.Param("principalId"), "/")
+}
+
+// packsEnabled answers 501 when no template pack service is configured
+func (h *Handlers) packsEnabled(c *gin.Context) bool {
+	if h.packService == nil {
+		fail(c, errTemplatePacksDisabled)
+		return false
+	}
+	return true
+}
+
+// Create a template pack
+func (h *Handlers) CreateTemplatePack(c *gin.Context) {
+	if !h.packsEnabled(c) {
+		return
+	}
+
+	var req models.TemplatePackRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if err := h.shareableTeam(c, req.TeamID); err != nil {
+		fail(c, err)
+		return
+	}
+
+	req.OwnerID = callerID(c)
+	pack, err := h.packService.CreatePack(c.Request.Context(), &req)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.TemplatePackResponse{
+		Success: true,
+		Message: "Template pack created successfully",
+		Pack:    pack,
+	})
+}
+
+// List template packs
+func (h *Handlers) ListTemplatePacks(c *gin.Context) {
+	if !h.packsEnabled(c) {
+		return
+	}
+
+	teamID := c.Query("team_id")
+	language := models.ProjectLanguage(c.Query("language"))
+	role := h.roleResolver(c)
+
+	packs := []models.TemplatePack{}
+	for _, pack := range h.packService.ListPacks(c.Request.Context()) {
+		if role(pack.OwnerID, pack.TeamID) == "" ||
+			(teamID != "" && pack.TeamID != teamID) ||
+			(language != "" && pack.Language != language) {
+			continue
+		}
+		packs = append(packs, pack)
+	}
+
+	c.JSON(http.StatusOK, models.TemplatePackResponse{
+		Success: true,
+		Packs:   packs,
+	})
+}
+
+// Get a template pack
+func (h *Handlers) GetTemplatePack(c *gin.Context) {
+	if !h.packsEnabled(c) {
+		return
+	}
+
+	pack, err := h.packFor(c, c.Param("id"), models.RoleViewer)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TemplatePackResponse{
+		Success: true,
+		Pack:    pack,
+	})
+}
+
+// Replace a template pack
+func (h *Handlers) UpdateTemplatePack(c *gin.Context) {
+	if !h.packsEnabled(c) {
+		return
+	}
+
+	var req models.TemplatePackRequest
+	if err := bindJSON(c, &req); err != nil {
+		fail(c, err)
+		return
+	}
+
+	pack, err := h.packFor(c, c.Param("id"), models.RoleEditor)
+	if err == nil && req.TeamID != pack.TeamID {
+		if _, err = h.packFor(c, pack.ID, models.RoleOwner); err == nil {
+			err = h.shareableTeam(c, req.TeamID)
+		}
+	}
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	updated, err := h.packService.UpdatePack(c.Request.Context(), pack.ID, &req)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TemplatePackResponse{
+		Success: true,
+		Message: "Template pack updated successfully",
+		Pack:    updated,
+	})
+}
+
+// Delete a template pack
+func (h *Handlers) DeleteTemplatePack(c *gin.Context) {
+	if !h.packsEnabled(c) {
+		return
+	}
+
+	if _, err := h.packFor(c, c.Param("id"), models.RoleEditor); err != nil {
+		fail(c, err)
+		return
+	}
+
+	if err := h.packService.DeletePack(c.Request.Context(), c.Param("id")); err != nil {
+		fail(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TemplatePackResponse{
+		Success: true,
+		Message: "Template pack deleted successfully",
+	})
+}
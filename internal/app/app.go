@@ -0,0 +1,415 @@
+// Package app assembles the application — services, middleware, CORS policy,
+// static files and routes — from a single Config, so the HTTP server and the
+// Lambda entrypoint behave identically.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/health"
+	"boilerplate-blueprint/internal/limits"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/metrics"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/requestid"
+	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/storage"
+	"boilerplate-blueprint/internal/tracing"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Config describes everything needed to build the application
+type Config struct {
+	// GinMode is debug, release or test; empty keeps gin's own default
+	GinMode string
+
+	// Logger writes request logs and is handed to the services through each
+	// request's context; nil uses slog.Default()
+	Logger *slog.Logger
+
+	CORS CORSConfig
+
+	// StaticDir holds the built frontend; empty disables static file serving
+	StaticDir string
+
+	// TemplateDirs lists directories of file templates added to generated
+	// projects; see services.TemplateService.LoadTemplateDirs
+	TemplateDirs []string
+
+	Chat      services.ChatRetention
+	Artifacts ArtifactConfig
+	Storage   StorageConfig
+
+	// Auth lists accepted API keys and JWT settings; with none, the API is open
+	Auth auth.Config
+
+	RateLimit RateLimitConfig
+	Timeouts  TimeoutConfig
+	Metrics   MetricsConfig
+
+	// MaxBodyBytes caps API request bodies; zero leaves them uncapped
+	MaxBodyBytes int64
+	// MaxUploadBytes caps uploaded archives instead; zero leaves them uncapped
+	MaxUploadBytes int64
+
+	// TrustedProxies lists the proxies whose X-Forwarded-For header is
+	// believed when identifying clients; with none, the peer address is used
+	TrustedProxies []string
+
+	// LLM is the assistant's language model provider, checked for readiness
+	LLM LLMConfig
+	// Build is the version reported by the health endpoints
+	Build models.BuildInfo
+}
+
+// LLMConfig names the LLM provider and how to reach it
+type LLMConfig struct {
+	Provider string // "" or none (the built-in assistant), openai, anthropic or ollama
+	BaseURL  string // Empty uses the provider's public endpoint
+	APIKey   string
+}
+
+// RateLimitConfig sets the request budget per client for each route group
+type RateLimitConfig struct {
+	Enabled  bool
+	Default  limits.Rate
+	Generate limits.Rate // Project generation, downloads and confirmed proposals
+	Chat     limits.Rate // Chat messages, which may call an LLM
+
+	// Limiter holds the buckets; nil keeps them in process memory, which
+	// only limits clients per instance
+	Limiter limits.Limiter
+}
+
+// TimeoutConfig sets how long requests in each route group may run; zero
+// leaves a group without a deadline
+type TimeoutConfig struct {
+	Default  time.Duration
+	Generate time.Duration // Project generation, downloads and confirmed proposals
+	Chat     time.Duration // Chat messages, which may call an LLM
+}
+
+// MetricsConfig controls the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool
+	Path    string // Where metrics are served, outside the API's authentication
+}
+
+// CORSConfig is the cross-origin policy applied to every route
+type CORSConfig struct {
+	// AllowOrigins lists allowed origins; "*" allows any origin
+	AllowOrigins []string
+	AllowMethods []string
+	AllowHeaders []string
+}
+
+// ArtifactConfig selects where generated archives are kept
+type ArtifactConfig struct {
+	Backend string // "" (rebuild on every download), local or s3
+	Dir     string // Used by the local backend
+	S3      artifacts.S3Config
+}
+
+// StorageConfig selects where projects and chat histories are kept
+type StorageConfig struct {
+	Backend  string // "" or memory, dynamodb
+	DynamoDB storage.DynamoDBConfig
+}
+
+// DefaultConfig returns the configuration used for local development
+func DefaultConfig() Config {
+	return Config{
+		CORS: CORSConfig{
+			AllowOrigins: []string{
+				"http://localhost:3000",
+				"http://localhost:5173",
+				"https://localhost:3000",
+				"https://localhost:5173",
+			},
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+			AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key"},
+		},
+		StaticDir: "./web/dist",
+		Chat:      services.DefaultChatRetention(),
+		Artifacts: ArtifactConfig{Dir: "./data/artifacts"},
+		RateLimit: RateLimitConfig{
+			Default:  limits.Rate{RequestsPerMinute: 120, Burst: 30},
+			Generate: limits.Rate{RequestsPerMinute: 20, Burst: 5},
+			Chat:     limits.Rate{RequestsPerMinute: 30, Burst: 10},
+		},
+		Timeouts: TimeoutConfig{
+			Default:  30 * time.Second,
+			Generate: 2 * time.Minute,
+			Chat:     time.Minute,
+		},
+		Metrics:        MetricsConfig{Enabled: true, Path: "/metrics"},
+		MaxBodyBytes:   1 << 20,
+		MaxUploadBytes: 32 << 20,
+		Build:          models.BuildInfo{Version: "dev"},
+	}
+}
+
+// App is an assembled application
+type App struct {
+	Config          Config
+	Router          *gin.Engine
+	Handlers        *api.Handlers
+	TemplateService *services.TemplateService
+	ProjectService  *services.ProjectService
+	ChatService     *services.ChatService
+	TeamService     *services.TeamService
+	PackService     *services.TemplatePackService
+	Metrics         *metrics.Metrics // Nil when metrics are disabled
+	Readiness       *health.Checker  // Dependency checks run by /readyz
+}
+
+// New builds the services, stores and router described by cfg
+func New(cfg Config) (*App, error) {
+	if cfg.GinMode != "" {
+		gin.SetMode(cfg.GinMode)
+	}
+
+	// Initialize services
+	templateService := services.NewTemplateService()
+	if err := templateService.LoadTemplateDirs(cfg.TemplateDirs); err != nil {
+		return nil, fmt.Errorf("failed to load template directories: %w", err)
+	}
+	projectService := services.NewProjectService(templateService)
+	chatService := services.NewChatServiceWithRetention(cfg.Chat)
+	chatService.SetAssistantTools(services.NewAssistantTools(projectService))
+	teamService := services.NewTeamService()
+	packService := services.NewTemplatePackService(templateService)
+
+	artifactStore, err := newArtifactStore(cfg.Artifacts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure artifact store: %w", err)
+	}
+	if artifactStore != nil {
+		projectService.SetArtifactStore(artifactStore)
+	}
+
+	store, err := newStore(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure storage: %w", err)
+	}
+	if store != nil {
+		projectService.SetStore(store)
+		chatService.SetStore(store)
+		if teamStore, ok := store.(storage.TeamStore); ok {
+			teamService.SetStore(teamStore)
+			packService.SetStore(teamStore)
+		}
+	}
+
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+
+	readiness, err := newReadiness(cfg.LLM, templateService, packService, store, artifactStore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure readiness checks: %w", err)
+	}
+
+	// Initialize handlers
+	handlers := api.NewHandlers(projectService, templateService, chatService)
+	handlers.SetTeamService(teamService)
+	handlers.SetTemplatePackService(packService)
+	handlers.SetReadiness(readiness)
+	handlers.SetBuildInfo(cfg.Build)
+
+	// Create router
+	router := gin.New()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
+	// Add middleware
+	router.Use(requestid.Middleware())
+	router.Use(tracing.Middleware())
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = newMetrics(cfg.Metrics, router, templateService, projectService, chatService)
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	router.Use(logging.Middleware(logger))
+	router.Use(gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		apperror.Abort(c, fmt.Errorf("panic: %v", recovered))
+	}))
+	router.Use(newCORS(cfg.CORS))
+
+	// Setup routes
+	api.SetupRoutesWithOptions(router, handlers, api.RouteOptions{
+		Middleware:     []gin.HandlerFunc{auth.Middleware(authenticator)},
+		RateLimit:      newRateLimit(cfg.RateLimit),
+		Timeout:        newTimeout(cfg.Timeouts),
+		MaxBodyBytes:   cfg.MaxBodyBytes,
+		MaxUploadBytes: cfg.MaxUploadBytes,
+	})
+
+	// Serve static files (Vue.js build)
+	if cfg.StaticDir != "" {
+		router.Static("/static", cfg.StaticDir)
+		router.StaticFile("/", filepath.Join(cfg.StaticDir, "index.html"))
+	}
+
+	return &App{
+		Config:          cfg,
+		Router:          router,
+		Handlers:        handlers,
+		TemplateService: templateService,
+		ProjectService:  projectService,
+		ChatService:     chatService,
+		TeamService:     teamService,
+		PackService:     packService,
+		Metrics:         appMetrics,
+		Readiness:       readiness,
+	}, nil
+}
+
+// StartBackground starts long-running maintenance such as expiring idle chat
+// sessions. Long-lived servers call it; Lambda invocations do not.
+func (a *App) StartBackground(ctx context.Context) {
+	a.ChatService.StartJanitor(ctx)
+}
+
+// ServeHTTP makes the application usable as an http.Handler
+func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.Router.ServeHTTP(w, r)
+}
+
+// newMetrics reports the services' work and every request to a new
+// metrics registry, served at cfg.Path
+func newMetrics(cfg MetricsConfig, router *gin.Engine, templateService *services.TemplateService,
+	projectService *services.ProjectService, chatService *services.ChatService) *metrics.Metrics {
+	m := metrics.New(templateService.GetAvailableTemplates())
+	templateService.SetObserver(m)
+	projectService.SetObserver(m)
+	chatService.SetObserver(m)
+
+	router.Use(m.Middleware())
+	router.GET(cfg.Path, gin.WrapH(m.Handler()))
+	return m
+}
+
+// newRateLimit returns the rate limiting middleware for each route group, or
+// nil when rate limiting is disabled
+func newRateLimit(cfg RateLimitConfig) func(group string) gin.HandlerFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	limiter := cfg.Limiter
+	if limiter == nil {
+		limiter = limits.NewMemoryLimiter()
+	}
+	return func(group string) gin.HandlerFunc {
+		rate := cfg.Default
+		switch group {
+		case api.RouteGroupGenerate:
+			rate = cfg.Generate
+		case api.RouteGroupChat:
+			rate = cfg.Chat
+		}
+		return limits.RateLimit(limiter, group, rate)
+	}
+}
+
+// newReadiness registers a check for each configured dependency: the storage
+// backend, the artifact store, template and template pack loading, and the
+// LLM provider
+func newReadiness(llm LLMConfig, templateService *services.TemplateService, packService *services.TemplatePackService,
+	store storage.Store, artifactStore artifacts.Store) (*health.Checker, error) {
+	checker := health.NewChecker(health.DefaultTimeout)
+	if store != nil {
+		checker.Register("storage", health.StorageCheck(store))
+	}
+	if artifactStore != nil {
+		checker.Register("artifacts", health.ArtifactCheck(artifactStore))
+	}
+	checker.Register("templates", func(ctx context.Context) error {
+		if len(templateService.GetAvailableTemplates()) == 0 {
+			return errors.New("no templates available")
+		}
+		_, err := packService.LoadPacks(ctx)
+		return err
+	})
+	if llm.Provider != "" && llm.Provider != "none" {
+		check, err := health.LLMCheck(llm.Provider, llm.BaseURL, llm.APIKey, nil)
+		if err != nil {
+			return nil, err
+		}
+		checker.Register("llm", check)
+	}
+	return checker, nil
+}
+
+// newTimeout returns the deadline middleware for each route group
+func newTimeout(cfg TimeoutConfig) func(group string) gin.HandlerFunc {
+	return func(group string) gin.HandlerFunc {
+		timeout := cfg.Default
+		switch group {
+		case api.RouteGroupGenerate:
+			timeout = cfg.Generate
+		case api.RouteGroupChat:
+			timeout = cfg.Chat
+		}
+		return limits.Timeout(timeout)
+	}
+}
+
+func newCORS(cfg CORSConfig) gin.HandlerFunc {
+	config := cors.DefaultConfig()
+	config.AllowMethods = cfg.AllowMethods
+	config.AllowHeaders = cfg.AllowHeaders
+
+	for _, origin := range cfg.AllowOrigins {
+		if origin == "*" {
+			config.AllowAllOrigins = true
+		}
+	}
+	if !config.AllowAllOrigins {
+		config.AllowOrigins = cfg.AllowOrigins
+	}
+
+	return cors.New(config)
+}
+
+func newArtifactStore(cfg ArtifactConfig) (artifacts.Store, error) {
+	switch cfg.Backend {
+	case "":
+		return nil, nil
+	case "local":
+		return artifacts.NewLocalStore(cfg.Dir)
+	case "s3":
+		return artifacts.NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported artifact backend: %s", cfg.Backend)
+	}
+}
+
+func newStore(cfg StorageConfig) (storage.Store, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return nil, nil
+	case "dynamodb":
+		return storage.NewDynamoDBStore(cfg.DynamoDB)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
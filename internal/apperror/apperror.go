@@ -0,0 +1,148 @@
+// Package apperror defines the errors the API reports to clients. Each one
+// has a kind, which decides the HTTP status, a stable machine-readable code
+// and a message that is safe to show. Anything else is reported as an
+// internal error, so messages from storage backends and other internals
+// never reach clients.
+package apperror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// Kind classifies an error by how the client should react to it
+type Kind string
+
+const (
+	KindValidation   Kind = "validation"
+	KindUnauthorized Kind = "unauthorized"
+	KindForbidden    Kind = "forbidden"
+	KindNotFound     Kind = "not_found"
+	KindConflict     Kind = "conflict"
+	KindTooLarge     Kind = "too_large"
+	KindRateLimited  Kind = "rate_limited"
+	KindUnsupported  Kind = "unsupported"
+	KindTimeout      Kind = "timeout"
+	KindInternal     Kind = "internal"
+)
+
+// Status returns the HTTP status code for errors of kind k
+func (k Kind) Status() int {
+	switch k {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case KindRateLimited:
+		return http.StatusTooManyRequests
+	case KindUnsupported:
+		return http.StatusNotImplemented
+	case KindTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError describes a problem with one field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error that can be reported to API clients. Services declare
+// their sentinel errors as *Error values and wrap them with fmt.Errorf("%w")
+// to add context for logs; only Message is shown to clients.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details []FieldError
+
+	cause error
+}
+
+// New returns an error of the given kind
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NotFound returns an error for a resource that does not exist
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+// Conflict returns an error for a request that clashes with the current state
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+// Validation returns an error for a request that is not acceptable as sent
+func Validation(code, message string, details ...FieldError) *Error {
+	err := New(KindValidation, code, message)
+	err.Details = details
+	return err
+}
+
+// InvalidField returns a validation error about a single request field
+func InvalidField(field, message string) *Error {
+	return Validation("invalid_field", message, FieldError{Field: field, Message: message})
+}
+
+// Internal wraps an unexpected failure. Its cause is kept for logs and never
+// shown to clients.
+func Internal(cause error) *Error {
+	return &Error{Kind: KindInternal, Code: "internal_error", Message: "internal server error", cause: cause}
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause of an internal error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// ErrDeadlineExceeded reports that a request ran out of time, and
+// ErrRequestCancelled that its client went away, before it finished
+var (
+	ErrDeadlineExceeded = New(KindTimeout, "deadline_exceeded", "request took too long to complete")
+	ErrRequestCancelled = New(KindTimeout, "request_cancelled", "request was cancelled")
+)
+
+// From returns the first *Error in err's chain, or an internal error
+// wrapping err when there is none. Context errors become ErrDeadlineExceeded
+// or ErrRequestCancelled rather than internal errors.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return ErrRequestCancelled
+	}
+	return Internal(err)
+}
+
+// Is reports whether err is an *Error of kind
+func Is(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
@@ -0,0 +1,66 @@
+package apperror
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response is the body of every error response. Error holds the message,
+// which keeps the field clients have always read; Code is stable for
+// programs to branch on.
+type Response struct {
+	Success   bool         `json:"success"`
+	Error     string       `json:"error"`
+	Code      string       `json:"code"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// Abort writes err as an error response and stops the handler chain.
+// Internal errors are logged with their cause, through the request's
+// logger, before it is dropped; timeouts are logged as warnings.
+func Abort(c *gin.Context, err error) {
+	appErr := From(err)
+	requestID := requestid.FromContext(c.Request.Context())
+	switch appErr.Kind {
+	case KindInternal:
+		logging.FromContext(c.Request.Context()).Error("request failed", "path", c.Request.URL.Path, "error", err)
+	case KindTimeout:
+		logging.FromContext(c.Request.Context()).Warn("request timed out", "path", c.Request.URL.Path, "error", err)
+	}
+
+	c.AbortWithStatusJSON(appErr.Kind.Status(), Response{
+		Success:   false,
+		Error:     sentence(appErr.Message),
+		Code:      appErr.Code,
+		Details:   appErr.Details,
+		RequestID: requestID,
+	})
+}
+
+// Middleware writes the response for the last error a handler recorded with
+// c.Error, unless the handler already wrote one
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		Abort(c, c.Errors.Last().Err)
+	}
+}
+
+// sentence capitalises a message written in the lower case Go uses for errors
+func sentence(message string) string {
+	first, size := utf8.DecodeRuneInString(message)
+	if first == utf8.RuneError {
+		return message
+	}
+	return string(unicode.ToUpper(first)) + message[size:]
+}
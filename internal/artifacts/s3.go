@@ -0,0 +1,238 @@
+package artifacts
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/awsauth"
+)
+
+// S3Config configures an S3Store
+type S3Config struct {
+	Bucket string
+	Region string
+
+	// Endpoint overrides the AWS endpoint for S3-compatible services such as
+	// MinIO, e.g. http://localhost:9000
+	Endpoint string
+
+	// UsePathStyle addresses objects as endpoint/bucket/key instead of
+	// bucket.endpoint/key; most S3-compatible services need it
+	UsePathStyle bool
+
+	Credentials awsauth.Credentials
+	HTTPClient  *http.Client
+}
+
+// S3Store keeps artifacts in an S3-compatible bucket
+type S3Store struct {
+	bucket       string
+	endpoint     *url.URL
+	usePathStyle bool
+	signer       awsauth.Signer
+	client       *http.Client
+}
+
+// NewS3Store validates the configuration and returns a store for the bucket
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if !cfg.Credentials.Valid() {
+		return nil, fmt.Errorf("S3 credentials are required")
+	}
+
+	rawEndpoint := cfg.Endpoint
+	if rawEndpoint == "" {
+		rawEndpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint: %s", rawEndpoint)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &S3Store{
+		bucket:       cfg.Bucket,
+		endpoint:     endpoint,
+		usePathStyle: cfg.UsePathStyle,
+		signer: awsauth.Signer{
+			Credentials: cfg.Credentials,
+			Region:      cfg.Region,
+			Service:     "s3",
+		},
+		client: client,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, nil, data)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req, data)
+	if err != nil {
+		return fmt.Errorf("failed to store artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to store artifact: %w", s3Error(resp))
+	}
+
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, key)
+	default:
+		return nil, fmt.Errorf("failed to read artifact: %w", s3Error(resp))
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s *S3Store) Exists(ctx context.Context, key string) (bool, error) {
+	req, err := s.newRequest(ctx, http.MethodHead, key, nil, nil)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to check artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("failed to check artifact: %w", s3Error(resp))
+	}
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete artifact: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("failed to delete artifact: %w", s3Error(resp))
+	}
+}
+
+// PresignGet returns a URL that downloads the object directly from the bucket
+func (s *S3Store) PresignGet(ctx context.Context, key, filename string, expires time.Duration) (string, error) {
+	query := url.Values{}
+	if filename != "" {
+		query.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	}
+
+	req, err := s.newRequest(ctx, http.MethodGet, key, query, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return s.signer.Presign(req, expires)
+}
+
+// newRequest builds an unsigned request for an object
+func (s *S3Store) newRequest(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Request, error) {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return nil, fmt.Errorf("invalid artifact key: %s", key)
+	}
+
+	target := *s.endpoint
+	objectPath := "/" + key
+	if s.usePathStyle {
+		objectPath = "/" + s.bucket + objectPath
+	} else {
+		target.Host = s.bucket + "." + target.Host
+	}
+	target.Path = strings.TrimSuffix(target.Path, "/") + objectPath
+	target.RawPath = awsauth.URIEncode(target.Path, false)
+	target.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid artifact request: %w", err)
+	}
+	if body == nil {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+
+	return req, nil
+}
+
+// do signs and sends a request
+func (s *S3Store) do(req *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := awsauth.HashPayload(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	s.signer.Sign(req, payloadHash)
+	return s.client.Do(req)
+}
+
+// s3Error extracts the error code and message from an S3 XML error body
+func s3Error(resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err := xml.Unmarshal(data, &body); err == nil && body.Code != "" {
+		return fmt.Errorf("s3 returned %d %s: %s", resp.StatusCode, body.Code, body.Message)
+	}
+	return fmt.Errorf("s3 returned %d", resp.StatusCode)
+}
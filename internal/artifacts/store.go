@@ -0,0 +1,47 @@
+// Package artifacts stores generated project archives outside process memory
+// so they survive across replicas and Lambda invocations.
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+)
+
+var (
+	// ErrNotFound is returned when no artifact exists for a key
+	ErrNotFound = errors.New("artifact not found")
+
+	// ErrPresignUnsupported is returned by stores that cannot hand out direct download URLs
+	ErrPresignUnsupported = apperror.New(apperror.KindUnsupported, "presign_unsupported", "artifact store does not support pre-signed URLs")
+)
+
+// Store persists binary artifacts under string keys
+type Store interface {
+	// Put stores data under key, replacing any previous artifact
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get returns the artifact stored under key, or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Exists reports whether an artifact is stored under key
+	Exists(ctx context.Context, key string) (bool, error)
+	// Delete removes the artifact stored under key; missing keys are not an error
+	Delete(ctx context.Context, key string) error
+	// PresignGet returns a URL that downloads the artifact as filename until it expires
+	PresignGet(ctx context.Context, key, filename string, expires time.Duration) (string, error)
+}
+
+// ArchiveKey is the key a project archive is stored under. Each revision gets
+// its own key, so a stored archive never goes stale.
+func ArchiveKey(projectID string, revision int) string {
+	return fmt.Sprintf("archives/%s/r%d.zip", projectID, revision)
+}
+
+// UpgradeKey is the key a merged upgrade archive is stored under. It is
+// derived from the archive's digest, so uploading the same copy again reuses
+// the stored archive.
+func UpgradeKey(projectID, digest string) string {
+	return fmt.Sprintf("upgrades/%s/%s.zip", projectID, digest)
+}
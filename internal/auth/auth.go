@@ -0,0 +1,153 @@
+// Package auth authenticates API callers with static API keys (for CI and
+// other automation) or bearer JWTs (for users), and carries the resulting
+// principal through the request context.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMissingCredentials is returned when a request carries no credentials
+	ErrMissingCredentials = errors.New("authentication required")
+	// ErrInvalidCredentials is returned when an API key or token is not accepted
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authentication methods recorded on a Principal
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+// Principal is an authenticated caller. Its ID is what projects and chat
+// sessions record as their owner: "apikey:<name>" for API keys and
+// "jwt:<iss>:<sub>" for JWTs, so a key cannot share ownership with a token
+// whose subject matches its name, nor tokens from different issuers with
+// each other. Teams are how principals share resources.
+type Principal struct {
+	ID     string `json:"id"`
+	Name   string `json:"name,omitempty"`
+	Method string `json:"method"`
+}
+
+// Anonymous reports whether the principal is the unauthenticated caller used
+// when authentication is disabled
+func (p Principal) Anonymous() bool {
+	return p.ID == ""
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal stored in ctx, or the anonymous principal
+func FromContext(ctx context.Context) Principal {
+	p, _ := ctx.Value(principalKey{}).(Principal)
+	return p
+}
+
+// APIKey maps a static key to the principal it authenticates
+type APIKey struct {
+	Name string
+	Key  string
+}
+
+// Config lists the accepted credentials. Authentication is disabled when it
+// lists none.
+type Config struct {
+	APIKeys []APIKey
+	JWT     JWTConfig
+}
+
+// Authenticator checks request credentials
+type Authenticator struct {
+	apiKeys map[[sha256.Size]byte]string
+	jwt     *jwtVerifier
+	now     func() time.Time
+}
+
+// minAPIKeyLength rejects keys short enough to guess
+const minAPIKeyLength = 16
+
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{
+		apiKeys: make(map[[sha256.Size]byte]string),
+		now:     time.Now,
+	}
+
+	for _, key := range cfg.APIKeys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("API key name is required")
+		}
+		if len(key.Key) < minAPIKeyLength {
+			return nil, fmt.Errorf("API key %s must be at least %d characters", key.Name, minAPIKeyLength)
+		}
+		digest := sha256.Sum256([]byte(key.Key))
+		if _, exists := a.apiKeys[digest]; exists {
+			return nil, fmt.Errorf("API key %s is configured twice", key.Name)
+		}
+		a.apiKeys[digest] = key.Name
+	}
+
+	if cfg.JWT.enabled() {
+		verifier, err := newJWTVerifier(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	return a, nil
+}
+
+// Enabled reports whether any credentials are configured
+func (a *Authenticator) Enabled() bool {
+	return len(a.apiKeys) > 0 || a.jwt != nil
+}
+
+// Authenticate returns the principal for a request's X-API-Key header or
+// Authorization bearer credential. Bearer values shaped like a JWT are
+// verified as tokens; anything else is looked up as an API key.
+func (a *Authenticator) Authenticate(r *http.Request) (Principal, error) {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return a.authenticateKey(key)
+	}
+
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return Principal{}, ErrMissingCredentials
+	}
+	scheme, credential, _ := strings.Cut(header, " ")
+	credential = strings.TrimSpace(credential)
+	if !strings.EqualFold(scheme, "Bearer") || credential == "" {
+		return Principal{}, fmt.Errorf("%w: expected a Bearer token", ErrInvalidCredentials)
+	}
+
+	if strings.Count(credential, ".") == 2 {
+		if a.jwt == nil {
+			return Principal{}, fmt.Errorf("%w: bearer tokens are not accepted", ErrInvalidCredentials)
+		}
+		return a.jwt.verify(credential, a.now())
+	}
+	return a.authenticateKey(credential)
+}
+
+// authenticateKey looks keys up by digest, so comparison time does not depend
+// on how much of a guessed key is right
+func (a *Authenticator) authenticateKey(key string) (Principal, error) {
+	name, exists := a.apiKeys[sha256.Sum256([]byte(key))]
+	if !exists {
+		return Principal{}, fmt.Errorf("%w: unknown API key", ErrInvalidCredentials)
+	}
+	return Principal{ID: "apikey:" + name, Name: name, Method: MethodAPIKey}, nil
+}
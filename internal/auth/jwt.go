@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// JWTConfig configures bearer token verification. Tokens are signed either
+// with a shared secret (HS256) or with a private key whose public half is in
+// PublicKeyFile (RS256 or ES256 with P-256).
+type JWTConfig struct {
+	Secret        string
+	PublicKeyFile string
+
+	// Issuer and Audience, when set, must match the iss and aud claims
+	Issuer   string
+	Audience string
+
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+}
+
+func (c JWTConfig) enabled() bool {
+	return c.Secret != "" || c.PublicKeyFile != ""
+}
+
+// minJWTSecretLength follows RFC 7518's requirement of a key at least as long as the HS256 output
+const minJWTSecretLength = 32
+
+type jwtVerifier struct {
+	cfg       JWTConfig
+	secret    []byte
+	publicKey crypto.PublicKey
+}
+
+func newJWTVerifier(cfg JWTConfig) (*jwtVerifier, error) {
+	if cfg.Secret != "" && cfg.PublicKeyFile != "" {
+		return nil, fmt.Errorf("configure either a JWT secret or a JWT public key, not both")
+	}
+
+	verifier := &jwtVerifier{cfg: cfg}
+	if cfg.Secret != "" {
+		if len(cfg.Secret) < minJWTSecretLength {
+			return nil, fmt.Errorf("JWT secret must be at least %d bytes", minJWTSecretLength)
+		}
+		verifier.secret = []byte(cfg.Secret)
+		return verifier, nil
+	}
+
+	data, err := os.ReadFile(cfg.PublicKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT public key %s is not PEM encoded", cfg.PublicKeyFile)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT public key: %w", err)
+	}
+	switch key := publicKey.(type) {
+	case *rsa.PublicKey:
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("JWT ECDSA public key must use P-256")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT public key type %T", publicKey)
+	}
+	verifier.publicKey = publicKey
+
+	return verifier, nil
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+type jwtClaims struct {
+	Subject   string       `json:"sub"`
+	Name      string       `json:"name"`
+	Issuer    string       `json:"iss"`
+	Audience  jwtAudience  `json:"aud"`
+	ExpiresAt *json.Number `json:"exp"`
+	NotBefore *json.Number `json:"nbf"`
+}
+
+// jwtAudience accepts the aud claim as a single string or a list
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (v *jwtVerifier) verify(token string, now time.Time) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed token header", ErrInvalidCredentials)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed token signature", ErrInvalidCredentials)
+	}
+	if err := v.verifySignature(header.Algorithm, parts[0]+"."+parts[1], signature); err != nil {
+		return Principal{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed token claims", ErrInvalidCredentials)
+	}
+	if err := v.checkClaims(claims, now); err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{ID: "jwt:" + claims.Issuer + ":" + claims.Subject, Name: claims.Name, Method: MethodJWT}, nil
+}
+
+// verifySignature only accepts the algorithm matching the configured key, so
+// a token cannot pick a weaker algorithm or "none"
+func (v *jwtVerifier) verifySignature(algorithm, signingInput string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+
+	switch key := v.publicKey.(type) {
+	case nil:
+		if algorithm == "HS256" {
+			mac := hmac.New(sha256.New, v.secret)
+			mac.Write([]byte(signingInput))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return nil
+			}
+			return fmt.Errorf("%w: bad token signature", ErrInvalidCredentials)
+		}
+	case *rsa.PublicKey:
+		if algorithm == "RS256" {
+			if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil {
+				return nil
+			}
+			return fmt.Errorf("%w: bad token signature", ErrInvalidCredentials)
+		}
+	case *ecdsa.PublicKey:
+		if algorithm == "ES256" {
+			// JWS encodes ECDSA signatures as the fixed-width concatenation r || s
+			if len(signature) == 64 {
+				r := new(big.Int).SetBytes(signature[:32])
+				s := new(big.Int).SetBytes(signature[32:])
+				if ecdsa.Verify(key, digest[:], r, s) {
+					return nil
+				}
+			}
+			return fmt.Errorf("%w: bad token signature", ErrInvalidCredentials)
+		}
+	}
+
+	return fmt.Errorf("%w: unexpected token algorithm %q", ErrInvalidCredentials, algorithm)
+}
+
+func (v *jwtVerifier) checkClaims(claims jwtClaims, now time.Time) error {
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+	expiresAt, err := claims.ExpiresAt.Float64()
+	if err != nil {
+		return fmt.Errorf("%w: invalid exp claim", ErrInvalidCredentials)
+	}
+	if now.Add(-v.cfg.Leeway).After(unixTime(expiresAt)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidCredentials)
+	}
+
+	if claims.NotBefore != nil {
+		notBefore, err := claims.NotBefore.Float64()
+		if err != nil {
+			return fmt.Errorf("%w: invalid nbf claim", ErrInvalidCredentials)
+		}
+		if now.Add(v.cfg.Leeway).Before(unixTime(notBefore)) {
+			return fmt.Errorf("%w: token not valid yet", ErrInvalidCredentials)
+		}
+	}
+
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected token issuer", ErrInvalidCredentials)
+	}
+	if v.cfg.Audience != "" && !containsString(claims.Audience, v.cfg.Audience) {
+		return fmt.Errorf("%w: token not issued for this audience", ErrInvalidCredentials)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"boilerplate-blueprint/internal/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors reported to callers that fail authentication
+var (
+	errAuthenticationRequired = apperror.New(apperror.KindUnauthorized, "authentication_required", "authentication required")
+	errCredentialsRejected    = apperror.New(apperror.KindUnauthorized, "invalid_credentials", "invalid credentials")
+)
+
+// Middleware rejects requests without valid credentials and stores the
+// caller's principal in the request context. When the authenticator has no
+// credentials configured every request passes as the anonymous principal.
+func Middleware(a *Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil || !a.Enabled() {
+			c.Next()
+			return
+		}
+
+		principal, err := a.Authenticate(c.Request)
+		if err != nil {
+			challenge := `Bearer realm="blueprint"`
+			failure := errAuthenticationRequired
+			if !errors.Is(err, ErrMissingCredentials) {
+				challenge += `, error="invalid_token"`
+				failure = errCredentialsRejected
+			}
+			c.Header("WWW-Authenticate", challenge)
+			apperror.Abort(c, fmt.Errorf("%w: %v", failure, err))
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
@@ -0,0 +1,237 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/client"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+)
+
+// skippedDirs are not read when a local project is loaded
+var skippedDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// parseComponent reads a component given as type:name, such as entity:order
+func parseComponent(spec string) (models.ComponentRequest, error) {
+	componentType, name, ok := strings.Cut(spec, ":")
+	if !ok || componentType == "" || name == "" {
+		return models.ComponentRequest{}, usagef("invalid component %q: expected type:name, such as utility:cache, oauth:github or entity:order", spec)
+	}
+	return models.ComponentRequest{Type: componentType, Name: name}, nil
+}
+
+// runAdd adds a component to a project generated into a local directory
+func (c *CLI) runAdd(ctx context.Context, args []string) error {
+	flags := c.flagSet("add", "add [flags] <type>:<name>")
+	dir := flags.String("dir", ".", "Directory of the generated project")
+	var options optionFlags
+	flags.Var(&options, "option", "Option the project was generated with, as key=value (framework defaults to the one the router uses)")
+	dryRun := flags.Bool("dry-run", false, "Report the changes without writing them")
+	asJSON := flags.Bool("json", false, "Print the result as JSON")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("add needs exactly one component, such as entity:order")
+	}
+	req, err := parseComponent(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	req.DryRun = *dryRun
+
+	root, err := filepath.Abs(*dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", *dir, err)
+	}
+	project := &models.Project{Name: filepath.Base(root), Language: models.LanguageGo}
+	for _, option := range options {
+		key, value, _ := strings.Cut(option, "=")
+		if err := services.SetOption(&project.Options, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return usagef("invalid -option %q: %v", option, err)
+		}
+	}
+	if err := c.templates.ValidateOptions(project.Language, project.Options); err != nil {
+		return err
+	}
+	if project.Files, err = readProject(root); err != nil {
+		return err
+	}
+	if project.Options.Framework == "" {
+		project.Options.Framework = routerFramework(project.Files)
+	}
+
+	// Paths in the plan start with the project directory's name
+	parent := filepath.Dir(root)
+	plan, err := c.templates.RenderComponent(ctx, project, &req)
+	if err != nil {
+		writeConflicts(c.errOut, apperror.From(err).Details)
+		return err
+	}
+
+	if !req.DryRun {
+		for _, file := range plan.Files {
+			target, err := safePath(parent, file.Path)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(target), err)
+			}
+			if err := os.WriteFile(target, []byte(file.Content), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", target, err)
+			}
+		}
+		plan.Result.Applied = len(plan.Files) > 0
+	}
+
+	if *asJSON {
+		return writeJSON(c.out, plan.Result)
+	}
+	return writeComponentResult(c.out, plan.Result, req.DryRun)
+}
+
+// runProjectsAdd adds a component to a project on the server
+func (c *CLI) runProjectsAdd(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects add", "projects add [flags] <project-id> <type>:<name>")
+	remote := c.addRemoteFlags(flags)
+	dryRun := flags.Bool("dry-run", false, "Report the changes without applying them")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usagef("projects add needs a project ID and a component, such as entity:order")
+	}
+	req, err := parseComponent(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	req.DryRun = *dryRun
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	response, err := api.AddComponent(ctx, flags.Arg(0), &req)
+	if err != nil {
+		var apiErr *client.Error
+		if errors.As(err, &apiErr) && apiErr.Code == "component_conflict" {
+			writeConflicts(c.errOut, apiErr.Details)
+			return errors.New(apiErr.Message)
+		}
+		return err
+	}
+
+	if *remote.json {
+		return writeJSON(c.out, response)
+	}
+	if err := writeComponentResult(c.out, response.Result, req.DryRun); err != nil {
+		return err
+	}
+	if response.Result.Applied {
+		fmt.Fprintf(c.out, "Project is now at revision %d\n", response.Revision)
+	}
+	return nil
+}
+
+// readProject loads the files under root, with paths that start with root's
+// name like those of a generated project
+func readProject(root string) ([]models.ProjectFile, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+
+	parent := filepath.Dir(root)
+	var files []models.ProjectFile
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(parent, path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case entry.IsDir() && skippedDirs[entry.Name()]:
+			return filepath.SkipDir
+		case entry.IsDir():
+			files = append(files, models.ProjectFile{Path: rel, IsDirectory: true})
+		case entry.Type().IsRegular():
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			files = append(files, models.ProjectFile{Path: rel, Content: string(content)})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project: %w", err)
+	}
+	return files, nil
+}
+
+// routerFrameworks maps the imports of a generated router to its framework
+var routerFrameworks = []struct{ importPath, framework string }{
+	{`"github.com/gin-gonic/gin"`, "gin"},
+	{`"github.com/go-chi/chi/v5"`, "chi"},
+	{`"github.com/labstack/echo/v4"`, "echo"},
+	{`"net/http"`, "standard"},
+}
+
+// routerFramework works out which framework a generated project's router
+// uses, or returns "" when there is no router to tell
+func routerFramework(files []models.ProjectFile) string {
+	for _, file := range files {
+		if filepath.Base(file.Path) != "router.go" || filepath.Base(filepath.Dir(file.Path)) != "routes" {
+			continue
+		}
+		for _, candidate := range routerFrameworks {
+			if strings.Contains(file.Content, candidate.importPath) {
+				return candidate.framework
+			}
+		}
+	}
+	return ""
+}
+
+func writeComponentResult(w io.Writer, result models.ComponentResult, dryRun bool) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "FILE\tACTION")
+	for _, file := range result.Files {
+		fmt.Fprintf(table, "%s\t%s\n", file.Path, file.Action)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	switch {
+	case dryRun:
+		fmt.Fprintf(w, "Dry run: %s was not added\n", result.Component)
+	case result.Applied:
+		fmt.Fprintf(w, "Added %s\n", result.Component)
+	default:
+		fmt.Fprintf(w, "%s is already in the project\n", result.Component)
+	}
+	return nil
+}
+
+// writeConflicts lists the files a component could not be merged into
+func writeConflicts(w io.Writer, conflicts []apperror.FieldError) {
+	for _, conflict := range conflicts {
+		fmt.Fprintf(w, "conflict: %s: %s\n", conflict.Field, conflict.Message)
+	}
+}
@@ -0,0 +1,176 @@
+// Package cli implements the blueprint command, which generates projects
+// from the built-in templates without a running server, or drives a remote
+// server through its REST API
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+)
+
+// usageError is a mistake in how the command was invoked, as opposed to a
+// failure while running it
+type usageError struct {
+	message string
+}
+
+func (e *usageError) Error() string { return e.message }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{message: fmt.Sprintf(format, args...)}
+}
+
+// ExitCode is the process exit status for an error returned by Run: 0 for
+// none, 2 for usage errors and 1 for everything else
+func ExitCode(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usage):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// CLI runs blueprint commands against a TemplateService
+type CLI struct {
+	templates   *services.TemplateService
+	in          io.Reader
+	out         io.Writer
+	errOut      io.Writer
+	interactive bool
+	build       models.BuildInfo
+	server      string // Default server of the client commands
+	apiKey      string
+}
+
+func New(in io.Reader, out, errOut io.Writer) *CLI {
+	return &CLI{
+		templates: services.NewTemplateService(),
+		in:        in,
+		out:       out,
+		errOut:    errOut,
+		build:     models.BuildInfo{Version: "dev"},
+	}
+}
+
+// SetInteractive makes commands prompt on the input for values that were
+// not given as flags. It should only be enabled when the input is a terminal.
+func (c *CLI) SetInteractive(interactive bool) {
+	c.interactive = interactive
+}
+
+// SetServer sets the server URL and API key the client commands use unless
+// their -server and -api-key flags say otherwise
+func (c *CLI) SetServer(serverURL, apiKey string) {
+	c.server = serverURL
+	c.apiKey = apiKey
+}
+
+// SetBuildInfo sets the version reported by the version command
+func (c *CLI) SetBuildInfo(build models.BuildInfo) {
+	c.build = build
+}
+
+// Run executes the command named by args, which excludes the program name
+func (c *CLI) Run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		c.usage(c.errOut)
+		return usagef("no command given")
+	}
+
+	switch args[0] {
+	case "new":
+		return c.runNew(ctx, args[1:])
+	case "templates":
+		return c.runTemplates(args[1:])
+	case "add":
+		return c.runAdd(ctx, args[1:])
+	case "projects":
+		return c.runProjects(ctx, args[1:])
+	case "chat":
+		return c.runChat(ctx, args[1:])
+	case "version":
+		c.version()
+		return nil
+	case "help", "-h", "-help", "--help":
+		c.usage(c.out)
+		return nil
+	default:
+		c.usage(c.errOut)
+		return usagef("unknown command %q", args[0])
+	}
+}
+
+func (c *CLI) usage(w io.Writer) {
+	fmt.Fprint(w, `Usage: blueprint <command> [flags]
+
+Commands:
+  new                     Generate a project locally
+  add <type>:<name>       Add a utility, OAuth provider or entity to a generated project
+  templates list          List the available templates
+  templates show <name>   Show a template and its options
+  version                 Print the version
+  help                    Show this help
+
+Server commands (need -server or BLUEPRINT_SERVER):
+  projects list           List projects
+  projects show <id>      Show a project and its files
+  projects create         Create a project, optionally generating it
+  projects generate <id>  Generate a project's files
+  projects add <id> <type>:<name>
+                          Add a component to a project
+  projects upgrade <id>   Merge template updates into a local copy of a project
+  projects download <id>  Download a project and extract it
+  chat [message]          Talk to the assistant
+
+Run "blueprint <command> -h" for the flags of a command.
+`)
+}
+
+func (c *CLI) version() {
+	fmt.Fprintf(c.out, "blueprint %s", c.build.Version)
+	var details []string
+	if c.build.Commit != "" {
+		details = append(details, "commit "+c.build.Commit)
+	}
+	if c.build.BuildTime != "" {
+		details = append(details, "built "+c.build.BuildTime)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(c.out, " (%s)", strings.Join(details, ", "))
+	}
+	fmt.Fprintln(c.out)
+}
+
+// flagSet returns a flag set for a command that reports to the CLI's error
+// output instead of exiting
+func (c *CLI) flagSet(name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.errOut)
+	flags.Usage = func() {
+		fmt.Fprintf(c.errOut, "Usage: blueprint %s\n\nFlags:\n", usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parse parses args into flags, turning parse failures into usage errors
+func parse(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return &usageError{message: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// errInputEnded is returned when the input ends while waiting for an answer
+var errInputEnded = errors.New("input ended")
+
+// optionFlags collects repeated -option key=value flags
+type optionFlags []string
+
+func (o *optionFlags) String() string { return strings.Join(*o, " ") }
+
+func (o *optionFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// answers is the YAML file read by new -answers. Options are keyed like the
+// template options; list options take a YAML list or a comma-separated string.
+type answers struct {
+	Name        string                 `yaml:"name"`
+	Language    string                 `yaml:"language"`
+	Description string                 `yaml:"description"`
+	Options     map[string]interface{} `yaml:"options"`
+}
+
+// projectFlags are the flags that describe a project, shared by the new and
+// projects create commands
+type projectFlags struct {
+	flags       *flag.FlagSet
+	name        *string
+	description *string
+	language    *string
+	answers     *string
+	options     optionFlags
+}
+
+func addProjectFlags(flags *flag.FlagSet) *projectFlags {
+	p := &projectFlags{flags: flags}
+	p.name = flags.String("name", "", "Project name")
+	p.description = flags.String("description", "", "Project description")
+	p.language = flags.String("language", "", "Template language: go or php")
+	flags.Var(&p.options, "option", "Template option as key=value; repeat for several, and separate list values with commas")
+	p.answers = flags.String("answers", "", "YAML file with the project's name, language, description and options")
+	return p
+}
+
+// request builds the project request from the answers file, if any, with the
+// flags that were set overriding it
+func (p *projectFlags) request() (models.ProjectRequest, error) {
+	req := models.ProjectRequest{}
+	if *p.answers != "" {
+		if err := loadAnswers(*p.answers, &req); err != nil {
+			return req, err
+		}
+	}
+	p.flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			req.Name = *p.name
+		case "description":
+			req.Description = *p.description
+		case "language":
+			req.Language = models.ProjectLanguage(strings.ToLower(*p.language))
+		}
+	})
+	for _, option := range p.options {
+		key, value, _ := strings.Cut(option, "=")
+		if err := services.SetOption(&req.Options, strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return req, usagef("invalid -option %q: %v", option, err)
+		}
+	}
+	return req, nil
+}
+
+// requireNameAndLanguage trims req's name and checks that it has one and a language
+func requireNameAndLanguage(req *models.ProjectRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return usagef("a project name is required: pass -name or set name in the answers file")
+	}
+	if req.Language == "" {
+		return usagef("a language is required: pass -language or set language in the answers file")
+	}
+	return nil
+}
+
+func (c *CLI) runNew(ctx context.Context, args []string) error {
+	flags := c.flagSet("new", "new [flags]")
+	described := addProjectFlags(flags)
+	output := flags.String("output", "", "Directory to write the project into, or the archive to create (default: the current directory, or <name>-<language>.<format>)")
+	format := flags.String("format", "", "Output format: dir, zip or tar.gz (default: from the output's extension, otherwise dir)")
+	force := flags.Bool("force", false, "Overwrite existing files")
+	yes := flags.Bool("yes", false, "Never prompt; options that are not given take the template defaults")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("new takes no arguments, got %q", flags.Arg(0))
+	}
+
+	req, err := described.request()
+	if err != nil {
+		return err
+	}
+
+	if c.interactive && !*yes {
+		if err := c.prompt(&req); err != nil {
+			return err
+		}
+	}
+
+	if err := requireNameAndLanguage(&req); err != nil {
+		return err
+	}
+	if err := c.templates.ValidateOptions(req.Language, req.Options); err != nil {
+		return err
+	}
+
+	resolved, err := resolveOutput(*output, *format, req)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	project := &models.Project{
+		ID:          uuid.New().String(),
+		Name:        req.Name,
+		Language:    req.Language,
+		Description: req.Description,
+		Options:     req.Options,
+		Revision:    1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	c.templates.ApplyDefaults(project)
+
+	project.Files, err = c.templates.GenerateProject(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed to generate project: %w", err)
+	}
+
+	if err := c.writeProject(ctx, project, resolved, *force); err != nil {
+		return err
+	}
+	fmt.Fprintf(c.out, "Generated %s (%s, %d files) in %s\n", project.Name, project.Language, countFiles(project.Files), resolved.path)
+	return nil
+}
+
+// loadAnswers reads an answers file into req
+func loadAnswers(path string, req *models.ProjectRequest) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read answers file: %w", err)
+	}
+
+	var file answers
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse answers file %s: %w", path, err)
+	}
+
+	req.Name = file.Name
+	req.Language = models.ProjectLanguage(strings.ToLower(file.Language))
+	req.Description = file.Description
+
+	// Sorted so that the first bad option reported is always the same one
+	keys := make([]string, 0, len(file.Options))
+	for key := range file.Options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := services.SetOption(&req.Options, key, answerValue(file.Options[key])); err != nil {
+			return fmt.Errorf("invalid answers file %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// answerValue writes a YAML option value the way it would be given as a flag
+func answerValue(value interface{}) string {
+	list, ok := value.([]interface{})
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	items := make([]string, 0, len(list))
+	for _, item := range list {
+		items = append(items, fmt.Sprint(item))
+	}
+	return strings.Join(items, ",")
+}
+
+// prompt asks for the name, language, description and options req leaves
+// unset. Empty answers take the default shown in parentheses.
+func (c *CLI) prompt(req *models.ProjectRequest) error {
+	input := bufio.NewReader(c.in)
+	var err error
+
+	if strings.TrimSpace(req.Name) == "" {
+		if req.Name, err = c.ask(input, "Project name", "", nil); err != nil {
+			return err
+		}
+	}
+	if req.Language == "" {
+		var languages []string
+		for _, template := range c.templates.GetAvailableTemplates() {
+			languages = append(languages, string(template.Language))
+		}
+		language, err := c.ask(input, "Language", languages[0], languages)
+		if err != nil {
+			return err
+		}
+		req.Language = models.ProjectLanguage(language)
+	}
+	if req.Description == "" {
+		if req.Description, err = c.askOptional(input, "Description"); err != nil {
+			return err
+		}
+	}
+
+	template, ok := c.templates.Template(req.Language)
+	if !ok {
+		return nil // Reported by validation, with the list of languages
+	}
+	for _, option := range template.Options {
+		if services.OptionValue(req.Options, option.Key) != "" {
+			continue
+		}
+		value, err := c.ask(input, option.Label, option.Default, option.Options)
+		if err != nil {
+			return err
+		}
+		if err := services.SetOption(&req.Options, option.Key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ask prompts until it reads an answer that is one of choices, when there
+// are any, falling back to fallback on empty input. An answer is required
+// when there is no fallback.
+func (c *CLI) ask(input *bufio.Reader, label, fallback string, choices []string) (string, error) {
+	question := label
+	if len(choices) > 0 {
+		question += " [" + strings.Join(choices, "/") + "]"
+	}
+	if fallback != "" {
+		question += " (" + fallback + ")"
+	}
+
+	for {
+		answer, err := c.readLine(input, question+": ")
+		if err != nil {
+			return "", fmt.Errorf("%w before %s was answered", err, strings.ToLower(label))
+		}
+		if answer == "" {
+			answer = fallback
+		}
+		switch {
+		case answer == "":
+			fmt.Fprintf(c.errOut, "%s is required\n", label)
+		case len(choices) > 0 && !contains(choices, answer):
+			fmt.Fprintf(c.errOut, "Choose one of %s\n", strings.Join(choices, ", "))
+		default:
+			return answer, nil
+		}
+	}
+}
+
+func (c *CLI) askOptional(input *bufio.Reader, label string) (string, error) {
+	answer, err := c.readLine(input, label+" (optional): ")
+	if err != nil {
+		return "", fmt.Errorf("%w before %s was answered", err, strings.ToLower(label))
+	}
+	return answer, nil
+}
+
+// readLine shows prompt and reads a line of input, trimmed. It returns
+// errInputEnded once the input is exhausted.
+func (c *CLI) readLine(input *bufio.Reader, prompt string) (string, error) {
+	fmt.Fprint(c.errOut, prompt)
+	line, err := input.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return "", errInputEnded
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+func contains(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+func countFiles(files []models.ProjectFile) int {
+	count := 0
+	for _, file := range files {
+		if !file.IsDirectory {
+			count++
+		}
+	}
+	return count
+}
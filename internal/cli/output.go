@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// Output formats of the new command
+const (
+	formatDir   = "dir"
+	formatZIP   = "zip"
+	formatTarGz = "tar.gz"
+)
+
+// outputTarget is where and how a generated project is written
+type outputTarget struct {
+	format string
+	path   string
+}
+
+// resolveOutput picks the output format, from the flag or else the output's
+// extension, and the default output path for it
+func resolveOutput(output, format string, req models.ProjectRequest) (outputTarget, error) {
+	switch {
+	case format != "":
+	case strings.HasSuffix(output, ".zip"):
+		format = formatZIP
+	case strings.HasSuffix(output, ".tar.gz"), strings.HasSuffix(output, ".tgz"):
+		format = formatTarGz
+	default:
+		format = formatDir
+	}
+
+	switch format {
+	case formatDir:
+		if output == "" {
+			output = "."
+		}
+	case formatZIP, formatTarGz:
+		if output == "" {
+			output = fmt.Sprintf("%s-%s.%s", req.Name, req.Language, format)
+		}
+	default:
+		return outputTarget{}, usagef("unknown output format %q: expected dir, zip or tar.gz", format)
+	}
+	return outputTarget{format: format, path: output}, nil
+}
+
+func (c *CLI) writeProject(ctx context.Context, project *models.Project, target outputTarget, force bool) error {
+	if target.format == formatDir {
+		return writeDir(target.path, project.Files, force)
+	}
+
+	if !force {
+		if _, err := os.Stat(target.path); err == nil {
+			return fmt.Errorf("%s already exists; pass -force to overwrite it", target.path)
+		}
+	}
+
+	var data []byte
+	var err error
+	if target.format == formatZIP {
+		data, err = c.templates.CreateZIPArchive(ctx, project)
+	} else {
+		data, err = tarGz(ctx, project.Files)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(target.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", target.path, err)
+	}
+	return nil
+}
+
+// writeDir writes files under root. Nothing is written when any file already
+// exists, unless force is set, or when a path cannot be written even with
+// force: a symlink on the way to it, or a file where a directory must go and
+// the other way round.
+func writeDir(root string, files []models.ProjectFile, force bool) error {
+	targets := make([]string, len(files))
+	var conflicts, blocked []string
+	for i, file := range files {
+		target, err := safePath(root, file.Path)
+		if err != nil {
+			return err
+		}
+		targets[i] = target
+
+		exists, err := checkTarget(root, target, file.IsDirectory)
+		if err != nil {
+			// Every file below a symlinked directory reports the same link
+			if !slices.Contains(blocked, err.Error()) {
+				blocked = append(blocked, err.Error())
+			}
+			continue
+		}
+		if exists && !file.IsDirectory && !force {
+			conflicts = append(conflicts, target)
+		}
+	}
+	if len(blocked) > 0 {
+		return blockedError(blocked)
+	}
+	if len(conflicts) > 0 {
+		return conflictError(conflicts)
+	}
+
+	for i, file := range files {
+		if file.IsDirectory {
+			if err := os.MkdirAll(targets[i], 0o755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", targets[i], err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(targets[i]), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(targets[i]), err)
+		}
+		if err := os.WriteFile(targets[i], []byte(file.Content), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", targets[i], err)
+		}
+	}
+	return nil
+}
+
+// checkTarget reports whether target, below root, already exists. Every path
+// element after root is inspected without following links, so that nothing
+// is written through a symlink or onto an entry of the wrong kind.
+func checkTarget(root, target string, dir bool) (bool, error) {
+	rel, err := filepath.Rel(root, target)
+	if err != nil || rel == "." {
+		return true, nil
+	}
+
+	parts := strings.Split(rel, string(filepath.Separator))
+	current := root
+	for i, part := range parts {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("cannot inspect %s: %w", current, err)
+		}
+
+		switch last := i == len(parts)-1; {
+		case info.Mode()&os.ModeSymlink != 0:
+			return false, fmt.Errorf("%s is a symlink", current)
+		case !last && !info.IsDir(), last && dir && !info.IsDir():
+			return false, fmt.Errorf("%s is a file where a directory is needed", current)
+		case last && !dir && info.IsDir():
+			return false, fmt.Errorf("%s is a directory where a file is needed", current)
+		}
+	}
+	return true, nil
+}
+
+// conflictError lists the files that would be overwritten
+func conflictError(conflicts []string) error {
+	return errors.New(listMessage(fmt.Sprintf("%d files already exist", len(conflicts)), conflicts) + "; pass -force to overwrite them")
+}
+
+// blockedError lists the paths that cannot be written, even with -force
+func blockedError(blocked []string) error {
+	return errors.New(listMessage(fmt.Sprintf("refusing to write %d paths", len(blocked)), blocked) + "; move them out of the way first")
+}
+
+// listMessage joins the first few items onto summary
+func listMessage(summary string, items []string) string {
+	const shown = 5
+	message := summary + ": " + strings.Join(items[:min(len(items), shown)], ", ")
+	if len(items) > shown {
+		message += fmt.Sprintf(" and %d more", len(items)-shown)
+	}
+	return message
+}
+
+// safePath joins rel onto root, refusing paths that would leave root
+func safePath(root, rel string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to write %s outside %s", rel, root)
+	}
+	return filepath.Join(root, cleaned), nil
+}
+
+// maxExtractedBytes caps the size of a downloaded archive once extracted
+const maxExtractedBytes = 512 << 20
+
+// unzip reads the files of a ZIP archive
+func unzip(data []byte) ([]models.ProjectFile, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []models.ProjectFile
+	var total int64
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			files = append(files, models.ProjectFile{Path: strings.TrimSuffix(entry.Name, "/"), IsDirectory: true})
+			continue
+		}
+
+		content, err := readEntry(entry, maxExtractedBytes-total)
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(content))
+		files = append(files, models.ProjectFile{Path: entry.Name, Content: string(content)})
+	}
+	return files, nil
+}
+
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("archive expands to more than %d bytes", maxExtractedBytes)
+	}
+	return content, nil
+}
+
+// tarGz packs files into a gzip-compressed tar archive
+func tarGz(ctx context.Context, files []models.ProjectFile) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	archive := tar.NewWriter(gz)
+	now := time.Now()
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("archiving stopped before %s: %w", file.Path, err)
+		}
+
+		header := &tar.Header{Name: filepath.ToSlash(file.Path), ModTime: now, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(file.Content))}
+		if file.IsDirectory {
+			header = &tar.Header{Name: filepath.ToSlash(file.Path) + "/", ModTime: now, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := archive.WriteHeader(header); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", file.Path, err)
+		}
+		if !file.IsDirectory {
+			if _, err := io.WriteString(archive, file.Content); err != nil {
+				return nil, fmt.Errorf("failed to write %s: %w", file.Path, err)
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close tar writer: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
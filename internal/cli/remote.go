@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"boilerplate-blueprint/internal/client"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+)
+
+// remoteFlags are the flags of commands that talk to a server
+type remoteFlags struct {
+	server *string
+	apiKey *string
+	json   *bool
+}
+
+func (c *CLI) addRemoteFlags(flags *flag.FlagSet) *remoteFlags {
+	return &remoteFlags{
+		server: flags.String("server", c.server, "Blueprint server URL (default from BLUEPRINT_SERVER)"),
+		apiKey: flags.String("api-key", c.apiKey, "API key for the server (default from BLUEPRINT_API_KEY)"),
+		json:   flags.Bool("json", false, "Print the result as JSON"),
+	}
+}
+
+// client returns an API client for the server named by the flags
+func (c *CLI) client(remote *remoteFlags) (*client.Client, error) {
+	if *remote.server == "" {
+		return nil, usagef("no server configured: pass -server or set BLUEPRINT_SERVER")
+	}
+	api, err := client.New(*remote.server, *remote.apiKey)
+	if err != nil {
+		return nil, usagef("%v", err)
+	}
+	api.SetUserAgent("blueprint/" + c.build.Version)
+	return api, nil
+}
+
+func (c *CLI) runProjects(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return usagef("projects needs a subcommand: list, show, create, generate, add, upgrade or download")
+	}
+
+	switch args[0] {
+	case "list":
+		return c.runProjectsList(ctx, args[1:])
+	case "show":
+		return c.runProjectsShow(ctx, args[1:])
+	case "create":
+		return c.runProjectsCreate(ctx, args[1:])
+	case "generate":
+		return c.runProjectsGenerate(ctx, args[1:])
+	case "add":
+		return c.runProjectsAdd(ctx, args[1:])
+	case "upgrade":
+		return c.runProjectsUpgrade(ctx, args[1:])
+	case "download":
+		return c.runProjectsDownload(ctx, args[1:])
+	default:
+		return usagef("unknown projects subcommand %q", args[0])
+	}
+}
+
+func (c *CLI) runProjectsList(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects list", "projects list [flags]")
+	remote := c.addRemoteFlags(flags)
+	teamID := flags.String("team", "", "Only list projects shared with this team")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("projects list takes no arguments")
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	projects, err := api.ListProjects(ctx, *teamID)
+	if err != nil {
+		return err
+	}
+	if *remote.json {
+		return writeJSON(c.out, projects)
+	}
+
+	table := tabwriter.NewWriter(c.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tNAME\tLANGUAGE\tREVISION\tUPDATED")
+	for _, project := range projects {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d\t%s\n", project.ID, project.Name, project.Language, project.Revision, project.UpdatedAt.Local().Format(time.DateTime))
+	}
+	return table.Flush()
+}
+
+func (c *CLI) runProjectsShow(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects show", "projects show [flags] <project-id>")
+	remote := c.addRemoteFlags(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("projects show needs exactly one project ID")
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	project, err := api.GetProject(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *remote.json {
+		return writeJSON(c.out, project)
+	}
+
+	writeProjectSummary(c.out, project)
+	if len(project.Files) > 0 {
+		fmt.Fprintln(c.out, "\nFiles:")
+		for _, file := range project.Files {
+			if !file.IsDirectory {
+				fmt.Fprintf(c.out, "  %s\n", file.Path)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *CLI) runProjectsCreate(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects create", "projects create [flags]")
+	remote := c.addRemoteFlags(flags)
+	described := addProjectFlags(flags)
+	packID := flags.String("pack", "", "Template pack whose options fill in the ones not given")
+	teamID := flags.String("team", "", "Share the project with this team")
+	generate := flags.Bool("generate", false, "Generate the project's files after creating it")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return usagef("projects create takes no arguments, got %q", flags.Arg(0))
+	}
+
+	req, err := described.request()
+	if err != nil {
+		return err
+	}
+	if err := requireNameAndLanguage(&req); err != nil {
+		return err
+	}
+	req.PackID = *packID
+	req.TeamID = *teamID
+
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+	project, err := api.CreateProject(ctx, &req)
+	if err != nil {
+		return err
+	}
+	if *generate {
+		if project.Files, err = api.GenerateProject(ctx, project.ID); err != nil {
+			return fmt.Errorf("project %s was created but not generated: %w", project.ID, err)
+		}
+	}
+
+	if *remote.json {
+		return writeJSON(c.out, project)
+	}
+	writeProjectSummary(c.out, project)
+	return nil
+}
+
+func (c *CLI) runProjectsGenerate(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects generate", "projects generate [flags] <project-id>")
+	remote := c.addRemoteFlags(flags)
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("projects generate needs exactly one project ID")
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	files, err := api.GenerateProject(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *remote.json {
+		return writeJSON(c.out, files)
+	}
+	fmt.Fprintf(c.out, "Generated %d files for project %s\n", countFiles(files), flags.Arg(0))
+	return nil
+}
+
+func (c *CLI) runProjectsDownload(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects download", "projects download [flags] <project-id>")
+	remote := c.addRemoteFlags(flags)
+	output := flags.String("output", ".", "Directory to extract the project into")
+	force := flags.Bool("force", false, "Overwrite existing files")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("projects download needs exactly one project ID")
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	data, filename, err := api.DownloadProject(ctx, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	files, err := unzip(data)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if err := writeDir(*output, files, *force); err != nil {
+		return err
+	}
+
+	if *remote.json {
+		return writeJSON(c.out, map[string]interface{}{"archive": filename, "output": *output, "files": countFiles(files)})
+	}
+	fmt.Fprintf(c.out, "Extracted %d files from %s into %s\n", countFiles(files), filename, *output)
+	return nil
+}
+
+// runChat sends the message given as arguments, or read from the input. On
+// a terminal without a message it holds a conversation until the input ends.
+func (c *CLI) runChat(ctx context.Context, args []string) error {
+	flags := c.flagSet("chat", "chat [flags] [message]")
+	remote := c.addRemoteFlags(flags)
+	sessionID := flags.String("session", "", "Continue this chat session instead of starting one")
+	projectID := flags.String("project", "", "Project the conversation is about")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	req := models.ChatRequest{SessionID: *sessionID, ProjectID: *projectID}
+	if flags.NArg() > 0 || !c.interactive {
+		req.Message = strings.Join(flags.Args(), " ")
+		if flags.NArg() == 0 {
+			input, err := io.ReadAll(c.in)
+			if err != nil {
+				return fmt.Errorf("failed to read message: %w", err)
+			}
+			req.Message = string(input)
+		}
+		if strings.TrimSpace(req.Message) == "" {
+			return usagef("no message given")
+		}
+		_, err := c.sendChat(ctx, api, &req, *remote.json)
+		return err
+	}
+
+	fmt.Fprintln(c.errOut, "Chatting with the assistant; end the input (Ctrl-D) to stop.")
+	input := bufio.NewReader(c.in)
+	for {
+		line, err := c.readLine(input, "> ")
+		if err != nil {
+			// The conversation ends with the input
+			if errors.Is(err, errInputEnded) {
+				fmt.Fprintln(c.errOut)
+				return nil
+			}
+			return err
+		}
+		if line == "" {
+			continue
+		}
+
+		req.Message = line
+		response, err := c.sendChat(ctx, api, &req, *remote.json)
+		if err != nil {
+			return err
+		}
+		req.SessionID = response.SessionID
+	}
+}
+
+func (c *CLI) sendChat(ctx context.Context, api *client.Client, req *models.ChatRequest, asJSON bool) (*models.ChatResponse, error) {
+	response, err := api.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if asJSON {
+		return response, writeJSON(c.out, response)
+	}
+
+	if response.Message != nil {
+		fmt.Fprintln(c.out, response.Message.Content)
+	}
+	for _, suggestion := range response.Suggestions {
+		fmt.Fprintf(c.out, "  - %s: %s (%s)\n", suggestion.Type, suggestion.Value, suggestion.Reason)
+	}
+	if req.SessionID == "" && response.SessionID != "" {
+		fmt.Fprintf(c.errOut, "Session: %s\n", response.SessionID)
+	}
+	return response, nil
+}
+
+func writeProjectSummary(w io.Writer, project *models.Project) {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(table, "ID:\t%s\n", project.ID)
+	fmt.Fprintf(table, "Name:\t%s\n", project.Name)
+	fmt.Fprintf(table, "Language:\t%s\n", project.Language)
+	if project.Description != "" {
+		fmt.Fprintf(table, "Description:\t%s\n", project.Description)
+	}
+	fmt.Fprintf(table, "Revision:\t%d\n", project.Revision)
+	if project.TeamID != "" {
+		fmt.Fprintf(table, "Team:\t%s\n", project.TeamID)
+	}
+	for _, key := range []string{"framework", "database", "authentication", "ci_version", "frontend", "utilities", "components", "features"} {
+		if value := services.OptionValue(project.Options, key); value != "" {
+			fmt.Fprintf(table, "%s:\t%s\n", key, value)
+		}
+	}
+	fmt.Fprintf(table, "Files:\t%d\n", countFiles(project.Files))
+	table.Flush()
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+func (c *CLI) runTemplates(args []string) error {
+	if len(args) == 0 {
+		return usagef("templates needs a subcommand: list or show")
+	}
+
+	switch args[0] {
+	case "list":
+		flags := c.flagSet("templates list", "templates list [-json]")
+		asJSON := flags.Bool("json", false, "Print the templates as JSON")
+		if err := parse(flags, args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() > 0 {
+			return usagef("templates list takes no arguments")
+		}
+
+		templates := c.templates.GetAvailableTemplates()
+		if *asJSON {
+			return writeJSON(c.out, templates)
+		}
+		return writeTemplateTable(c.out, templates)
+
+	case "show":
+		flags := c.flagSet("templates show", "templates show [-json] <name|language>")
+		asJSON := flags.Bool("json", false, "Print the template as JSON")
+		if err := parse(flags, args[1:]); err != nil {
+			return err
+		}
+		if flags.NArg() != 1 {
+			return usagef("templates show needs exactly one template name or language")
+		}
+
+		template, ok := c.findTemplate(flags.Arg(0))
+		if !ok {
+			return fmt.Errorf("unknown template %q; run \"blueprint templates list\" to see the available ones", flags.Arg(0))
+		}
+		if *asJSON {
+			return writeJSON(c.out, template)
+		}
+		return writeTemplateDetails(c.out, template)
+
+	default:
+		return usagef("unknown templates subcommand %q", args[0])
+	}
+}
+
+// findTemplate looks a template up by language or, ignoring case, by name
+func (c *CLI) findTemplate(name string) (models.TemplateInfo, bool) {
+	if template, ok := c.templates.Template(models.ProjectLanguage(strings.ToLower(name))); ok {
+		return template, true
+	}
+	for _, template := range c.templates.GetAvailableTemplates() {
+		if strings.EqualFold(template.Name, name) {
+			return template, true
+		}
+	}
+	return models.TemplateInfo{}, false
+}
+
+func writeTemplateTable(w io.Writer, templates []models.TemplateInfo) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "LANGUAGE\tNAME\tDESCRIPTION")
+	for _, template := range templates {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", template.Language, template.Name, template.Description)
+	}
+	return table.Flush()
+}
+
+func writeTemplateDetails(w io.Writer, template models.TemplateInfo) error {
+	fmt.Fprintf(w, "%s (%s)\n%s\n\n", template.Name, template.Language, template.Description)
+
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "OPTION\tLABEL\tDEFAULT\tCHOICES")
+	for _, option := range template.Options {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\n", option.Key, option.Label, option.Default, strings.Join(option.Options, ", "))
+	}
+	return table.Flush()
+}
+
+func writeJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
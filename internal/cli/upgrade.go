@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// runProjectsUpgrade merges what the server's current templates change in a
+// project into a local copy of it, in place unless -output names another
+// directory
+func (c *CLI) runProjectsUpgrade(ctx context.Context, args []string) error {
+	flags := c.flagSet("projects upgrade", "projects upgrade [flags] <project-id>")
+	remote := c.addRemoteFlags(flags)
+	dir := flags.String("dir", ".", "Directory of the local copy of the project")
+	base := flags.Int("base", 0, "Revision the local copy was generated from (default the project's current revision)")
+	output := flags.String("output", "", "Extract the merged project into this directory instead of updating -dir")
+	dryRun := flags.Bool("dry-run", false, "Report what the upgrade would do without writing anything")
+	if err := parse(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usagef("projects upgrade needs exactly one project ID")
+	}
+	if *base < 0 {
+		return usagef("-base must be a revision number")
+	}
+	api, err := c.client(remote)
+	if err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(*dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", *dir, err)
+	}
+	local, err := readProject(root)
+	if err != nil {
+		return err
+	}
+	archive, err := c.templates.CreateZIPArchive(ctx, &models.Project{Name: filepath.Base(root), Files: local})
+	if err != nil {
+		return err
+	}
+
+	response, err := api.UpgradeProject(ctx, flags.Arg(0), *base, archive)
+	if err != nil {
+		return err
+	}
+
+	if !*dryRun {
+		merged, err := unzip(response.Archive)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", response.Filename, err)
+		}
+		if *output != "" {
+			if err := writeDir(*output, merged, false); err != nil {
+				return err
+			}
+		} else if err := updateInPlace(root, merged, response.Report); err != nil {
+			return err
+		}
+	}
+
+	if *remote.json {
+		if err := writeJSON(c.out, response.Report); err != nil {
+			return err
+		}
+	} else if err := writeUpgradeReport(c.out, response.Report, *dryRun); err != nil {
+		return err
+	}
+	if response.Report.Conflicts > 0 {
+		return fmt.Errorf("%d file(s) have conflicts; resolve the <<<<<<< markers in them", response.Report.Conflicts)
+	}
+	return nil
+}
+
+// updateInPlace writes the merged project over the local copy at root and
+// deletes the files the upgrade removed. The merged paths start with the
+// project's name, which root's may differ from.
+func updateInPlace(root string, merged []models.ProjectFile, report models.UpgradeReport) error {
+	for i := range merged {
+		merged[i].Path = withinRoot(merged[i].Path)
+	}
+	if err := writeDir(root, merged, true); err != nil {
+		return err
+	}
+
+	for _, file := range report.Files {
+		if file.Status != models.UpgradeFileRemoved {
+			continue
+		}
+		target, err := safePath(root, withinRoot(file.Path))
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// withinRoot drops the project directory a path starts with
+func withinRoot(path string) string {
+	_, rel, found := strings.Cut(filepath.ToSlash(path), "/")
+	if !found {
+		return "."
+	}
+	return rel
+}
+
+func writeUpgradeReport(w io.Writer, report models.UpgradeReport, dryRun bool) error {
+	table := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "FILE\tSTATUS\tREASON")
+	for _, file := range report.Files {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", file.Path, file.Status, file.Reason)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	var counts []string
+	for _, status := range []string{
+		models.UpgradeFileUpdated, models.UpgradeFileAdded, models.UpgradeFileRemoved,
+		models.UpgradeFileMerged, models.UpgradeFileConflict, models.UpgradeFileKept, models.UpgradeFileUnchanged,
+	} {
+		if count := report.Summary[status]; count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", count, status))
+		}
+	}
+	prefix := "Upgraded from"
+	if dryRun {
+		prefix = "Dry run: upgrade from"
+	}
+	fmt.Fprintf(w, "%s revision %d: %s\n", prefix, report.BaseRevision, strings.Join(counts, ", "))
+	return nil
+}
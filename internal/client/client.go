@@ -0,0 +1,273 @@
+// Package client talks to a Boilerplate Blueprint server over its REST API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+)
+
+// DefaultTimeout bounds requests made with the default HTTP client. Generation
+// and downloads can be slow, so it is longer than the server's own deadlines.
+const DefaultTimeout = 5 * time.Minute
+
+// maxErrorBody caps how much of an error response is read
+const maxErrorBody = 64 << 10
+
+// Error is an error response from the server
+type Error struct {
+	Status    int
+	Code      string
+	Message   string
+	Details   []apperror.FieldError
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.Status)
+	}
+	for _, detail := range e.Details {
+		message += fmt.Sprintf("; %s: %s", detail.Field, detail.Message)
+	}
+	if e.RequestID != "" {
+		message += fmt.Sprintf(" (request %s)", e.RequestID)
+	}
+	return fmt.Sprintf("server returned %d: %s", e.Status, message)
+}
+
+// Client calls the API of one server
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	userAgent  string
+}
+
+// New returns a client for the server at serverURL, such as
+// https://blueprint.example.com. The API base path is added to it unless it
+// already ends in one. apiKey may be empty when the server is open.
+func New(serverURL, apiKey string) (*Client, error) {
+	parsed, err := url.Parse(strings.TrimRight(serverURL, "/"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid server URL %q: expected http(s)://host[:port]", serverURL)
+	}
+	if !strings.HasSuffix(parsed.Path, "/api/v1") {
+		parsed.Path += "/api/v1"
+	}
+
+	return &Client{
+		baseURL:    parsed.String(),
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+		userAgent:  "blueprint-cli",
+	}, nil
+}
+
+// SetHTTPClient replaces the HTTP client requests are sent with
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetUserAgent sets the User-Agent header sent with every request
+func (c *Client) SetUserAgent(userAgent string) {
+	c.userAgent = userAgent
+}
+
+// Templates lists the templates the server offers
+func (c *Client) Templates(ctx context.Context) ([]models.TemplateInfo, error) {
+	var response models.TemplatesResponse
+	if err := c.do(ctx, http.MethodGet, "/templates", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Templates, nil
+}
+
+// CreateProject creates a project. Its files are not generated yet.
+func (c *Client) CreateProject(ctx context.Context, req *models.ProjectRequest) (*models.Project, error) {
+	var response models.ProjectResponse
+	if err := c.do(ctx, http.MethodPost, "/projects", req, &response); err != nil {
+		return nil, err
+	}
+	return response.Project, nil
+}
+
+// ListProjects lists the projects the caller can see, newest first, without
+// their files. teamID, when set, limits them to one team's.
+func (c *Client) ListProjects(ctx context.Context, teamID string) ([]*models.Project, error) {
+	path := "/projects"
+	if teamID != "" {
+		path += "?" + url.Values{"team_id": {teamID}}.Encode()
+	}
+
+	var response models.ProjectResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Projects, nil
+}
+
+// GetProject returns a project and its files
+func (c *Client) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	var response models.ProjectResponse
+	if err := c.do(ctx, http.MethodGet, "/projects/"+url.PathEscape(projectID), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Project, nil
+}
+
+// GenerateProject renders a project's files on the server
+func (c *Client) GenerateProject(ctx context.Context, projectID string) ([]models.ProjectFile, error) {
+	var response models.GenerateResponse
+	if err := c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/generate", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Files, nil
+}
+
+// AddComponent adds a component to a project. Conflicts with the project's
+// files are returned as an *Error with code component_conflict, whose
+// details name the files.
+func (c *Client) AddComponent(ctx context.Context, projectID string, req *models.ComponentRequest) (*models.ComponentResponse, error) {
+	var response models.ComponentResponse
+	if err := c.do(ctx, http.MethodPost, "/projects/"+url.PathEscape(projectID)+"/components", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// UpgradeProject merges the current templates into an edited copy of a
+// project, given as a ZIP archive. baseRevision is the revision the copy was
+// generated from; 0 means the project's current one.
+func (c *Client) UpgradeProject(ctx context.Context, projectID string, baseRevision int, archive []byte) (*models.UpgradeResponse, error) {
+	path := "/projects/" + url.PathEscape(projectID) + "/upgrade"
+	if baseRevision > 0 {
+		path += "?" + url.Values{"base_revision": {strconv.Itoa(baseRevision)}}.Encode()
+	}
+
+	var response models.UpgradeResponse
+	if err := c.do(ctx, http.MethodPost, path, upload{data: archive, contentType: "application/zip"}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DownloadProject returns a project's ZIP archive and its file name
+func (c *Client) DownloadProject(ctx context.Context, projectID string) ([]byte, string, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/projects/"+url.PathEscape(projectID)+"/download", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	filename := projectID + ".zip"
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil && params["filename"] != "" {
+		filename = params["filename"]
+	}
+	return data, filename, nil
+}
+
+// Chat sends a message to the assistant. An empty session ID starts a new
+// session, whose ID is in the response.
+func (c *Client) Chat(ctx context.Context, req *models.ChatRequest) (*models.ChatResponse, error) {
+	var response models.ChatResponse
+	if err := c.do(ctx, http.MethodPost, "/chat/message", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// upload is a request body sent as is rather than as JSON
+type upload struct {
+	data        []byte
+	contentType string
+}
+
+// do sends body, as JSON unless it is an upload, and decodes the JSON
+// response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response from %s %s: %w", method, path, err)
+	}
+	return nil
+}
+
+// send makes a request and returns the response when it succeeded. Error
+// responses are returned as *Error.
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	contentType := "application/json"
+	switch body := body.(type) {
+	case nil:
+	case upload:
+		reader = bytes.NewReader(body.data)
+		contentType = body.contentType
+	default:
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	// Sent as a bearer token, which HTTP clients drop when following a
+	// redirect to another host, such as a pre-signed archive URL
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", c.baseURL, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	return nil, decodeError(resp)
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{Status: resp.StatusCode}
+	var body apperror.Response
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err := json.Unmarshal(data, &body); err == nil {
+		apiErr.Code = body.Code
+		apiErr.Message = body.Error
+		apiErr.Details = body.Details
+		apiErr.RequestID = body.RequestID
+	}
+	return apiErr
+}
@@ -0,0 +1,441 @@
+// Package config holds the typed application configuration. It is loaded from
+// an optional YAML or TOML file, then environment variables, then command-line
+// flags, each source overriding the previous one, and validated before the
+// application starts.
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/awsauth"
+	"boilerplate-blueprint/internal/limits"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/server"
+	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/storage"
+	"boilerplate-blueprint/internal/tracing"
+)
+
+// Config is the complete application configuration. The yaml tags name the
+// keys used by both YAML and TOML files; env tags name the environment
+// variables. Fields tagged secret are redacted when the configuration is dumped.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	TLS       TLSConfig       `yaml:"tls"`
+	CORS      CORSConfig      `yaml:"cors"`
+	StaticDir string          `yaml:"static_dir" env:"STATIC_DIR,allowempty"`
+	Chat      ChatConfig      `yaml:"chat"`
+	Artifacts ArtifactsConfig `yaml:"artifacts"`
+	Storage   StorageConfig   `yaml:"storage"`
+	AWS       AWSConfig       `yaml:"aws"`
+	Auth      AuthConfig      `yaml:"auth"`
+	LLM       LLMConfig       `yaml:"llm"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Log       LogConfig       `yaml:"log"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Templates TemplatesConfig `yaml:"templates"`
+}
+
+// ServerConfig controls the HTTP listener
+type ServerConfig struct {
+	// Addr is the listen address; PORT is honoured as a shorthand for ":<port>"
+	Addr    string `yaml:"addr" env:"SERVER_ADDR"`
+	GinMode string `yaml:"gin_mode" env:"GIN_MODE"`
+
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+	ReadTimeout       Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout      Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout       Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" env:"SERVER_SHUTDOWN_TIMEOUT"`
+	MaxHeaderBytes    int      `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+	// Request deadlines per route group; generation and chat have their own,
+	// every other route shares the default. Zero leaves requests without one.
+	RequestTimeout         Duration `yaml:"request_timeout" env:"SERVER_REQUEST_TIMEOUT"`
+	GenerateRequestTimeout Duration `yaml:"generate_request_timeout" env:"SERVER_GENERATE_REQUEST_TIMEOUT"`
+	ChatRequestTimeout     Duration `yaml:"chat_request_timeout" env:"SERVER_CHAT_REQUEST_TIMEOUT"`
+	// MaxBodyBytes caps API request bodies; larger ones are rejected with 413
+	MaxBodyBytes int `yaml:"max_body_bytes" env:"SERVER_MAX_BODY_BYTES"`
+	// MaxUploadBytes caps uploaded archives, such as projects sent for upgrade
+	MaxUploadBytes int `yaml:"max_upload_bytes" env:"SERVER_MAX_UPLOAD_BYTES"`
+	// TrustedProxies lists proxy addresses or CIDRs whose X-Forwarded-For is believed
+	TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+}
+
+// TLSConfig enables HTTPS, either from certificate files or with a
+// self-signed certificate generated at startup for development
+type TLSConfig struct {
+	CertFile   string `yaml:"cert_file" env:"TLS_CERT_FILE"`
+	KeyFile    string `yaml:"key_file" env:"TLS_KEY_FILE"`
+	SelfSigned bool   `yaml:"self_signed" env:"TLS_SELF_SIGNED"`
+}
+
+// CORSConfig is the cross-origin policy applied to every route
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins" env:"CORS_ALLOW_ORIGINS"`
+	AllowMethods []string `yaml:"allow_methods" env:"CORS_ALLOW_METHODS"`
+	AllowHeaders []string `yaml:"allow_headers" env:"CORS_ALLOW_HEADERS"`
+}
+
+// ChatConfig controls chat history retention
+type ChatConfig struct {
+	MaxMessages   int      `yaml:"max_messages" env:"CHAT_MAX_MESSAGES"`
+	IdleTimeout   Duration `yaml:"session_idle_timeout" env:"CHAT_SESSION_IDLE_TIMEOUT"`
+	SweepInterval Duration `yaml:"sweep_interval" env:"CHAT_SWEEP_INTERVAL"`
+}
+
+// ArtifactsConfig selects where generated archives are kept
+type ArtifactsConfig struct {
+	Backend string   `yaml:"backend" env:"ARTIFACT_STORE"` // "" (rebuild on every download), local or s3
+	Dir     string   `yaml:"dir" env:"ARTIFACT_DIR"`
+	S3      S3Config `yaml:"s3"`
+}
+
+// S3Config locates the bucket used by the s3 artifact backend
+type S3Config struct {
+	Bucket    string `yaml:"bucket" env:"ARTIFACT_S3_BUCKET"`
+	Region    string `yaml:"region" env:"ARTIFACT_S3_REGION"` // Defaults to aws.region
+	Endpoint  string `yaml:"endpoint" env:"ARTIFACT_S3_ENDPOINT"`
+	PathStyle bool   `yaml:"path_style" env:"ARTIFACT_S3_PATH_STYLE"`
+}
+
+// StorageConfig selects where projects and chat histories are kept
+type StorageConfig struct {
+	Backend  string         `yaml:"backend" env:"STORAGE_BACKEND"` // "" or memory, dynamodb
+	DynamoDB DynamoDBConfig `yaml:"dynamodb"`
+}
+
+// DynamoDBConfig locates the table used by the dynamodb storage backend
+type DynamoDBConfig struct {
+	Table    string `yaml:"table" env:"DYNAMODB_TABLE"`
+	Region   string `yaml:"region" env:"DYNAMODB_REGION"` // Defaults to aws.region
+	Endpoint string `yaml:"endpoint" env:"DYNAMODB_ENDPOINT"`
+}
+
+// AWSConfig holds the region and credentials shared by the AWS backends
+type AWSConfig struct {
+	Region          string `yaml:"region" env:"AWS_REGION"`
+	AccessKeyID     string `yaml:"access_key_id" env:"AWS_ACCESS_KEY_ID"`
+	SecretAccessKey string `yaml:"secret_access_key" env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	SessionToken    string `yaml:"session_token" env:"AWS_SESSION_TOKEN" secret:"true"`
+}
+
+// Credentials returns the configured AWS credentials
+func (c AWSConfig) Credentials() awsauth.Credentials {
+	return awsauth.Credentials{
+		AccessKeyID:     c.AccessKeyID,
+		SecretAccessKey: c.SecretAccessKey,
+		SessionToken:    c.SessionToken,
+	}
+}
+
+// AuthConfig lists the accepted credentials; the API is open when none are set
+type AuthConfig struct {
+	// APIKeys are "name:key" pairs; the name becomes the owner of what the key creates
+	APIKeys []string `yaml:"api_keys" env:"AUTH_API_KEYS" secret:"true"`
+
+	JWTSecret        string   `yaml:"jwt_secret" env:"AUTH_JWT_SECRET" secret:"true"`
+	JWTPublicKeyFile string   `yaml:"jwt_public_key_file" env:"AUTH_JWT_PUBLIC_KEY_FILE"`
+	JWTIssuer        string   `yaml:"jwt_issuer" env:"AUTH_JWT_ISSUER"`
+	JWTAudience      string   `yaml:"jwt_audience" env:"AUTH_JWT_AUDIENCE"`
+	JWTLeeway        Duration `yaml:"jwt_leeway" env:"AUTH_JWT_LEEWAY"`
+}
+
+// Auth converts the configuration into the authenticator settings. Entries
+// that are not "name:key" pairs are reported by Validate.
+func (c AuthConfig) Auth() auth.Config {
+	keys := make([]auth.APIKey, 0, len(c.APIKeys))
+	for _, entry := range c.APIKeys {
+		if name, key, ok := strings.Cut(entry, ":"); ok {
+			keys = append(keys, auth.APIKey{Name: strings.TrimSpace(name), Key: strings.TrimSpace(key)})
+		}
+	}
+
+	return auth.Config{
+		APIKeys: keys,
+		JWT: auth.JWTConfig{
+			Secret:        c.JWTSecret,
+			PublicKeyFile: c.JWTPublicKeyFile,
+			Issuer:        c.JWTIssuer,
+			Audience:      c.JWTAudience,
+			Leeway:        time.Duration(c.JWTLeeway),
+		},
+	}
+}
+
+// LLMConfig selects the language model behind the chat assistant. The
+// built-in rule-based assistant is used when Provider is empty or none.
+type LLMConfig struct {
+	Provider string   `yaml:"provider" env:"LLM_PROVIDER"` // none, openai, anthropic or ollama
+	Model    string   `yaml:"model" env:"LLM_MODEL"`
+	BaseURL  string   `yaml:"base_url" env:"LLM_BASE_URL"`
+	APIKey   string   `yaml:"api_key" env:"LLM_API_KEY" secret:"true"`
+	Timeout  Duration `yaml:"timeout" env:"LLM_TIMEOUT"`
+}
+
+// RateLimitConfig sets the request budget per client. Generation and chat
+// have budgets of their own; every other route shares the default one.
+type RateLimitConfig struct {
+	Enabled           bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	RequestsPerMinute int  `yaml:"requests_per_minute" env:"RATE_LIMIT_REQUESTS_PER_MINUTE"`
+	Burst             int  `yaml:"burst" env:"RATE_LIMIT_BURST"`
+
+	GenerateRequestsPerMinute int `yaml:"generate_requests_per_minute" env:"RATE_LIMIT_GENERATE_REQUESTS_PER_MINUTE"`
+	GenerateBurst             int `yaml:"generate_burst" env:"RATE_LIMIT_GENERATE_BURST"`
+	ChatRequestsPerMinute     int `yaml:"chat_requests_per_minute" env:"RATE_LIMIT_CHAT_REQUESTS_PER_MINUTE"`
+	ChatBurst                 int `yaml:"chat_burst" env:"RATE_LIMIT_CHAT_BURST"`
+}
+
+// MetricsConfig controls the Prometheus metrics endpoint
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED"`
+	Path    string `yaml:"path" env:"METRICS_PATH"`
+}
+
+// LogConfig controls the structured logs written to stderr
+type LogConfig struct {
+	Format string `yaml:"format" env:"LOG_FORMAT"` // json or text
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // debug, info, warn or error
+}
+
+// TracingConfig controls OpenTelemetry trace export. The standard OTEL_*
+// variables, such as OTEL_EXPORTER_OTLP_HEADERS and OTEL_SERVICE_NAME, are
+// honoured as well.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER"` // none, otlp or stdout
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+// TemplatesConfig lists directories of file templates added to generated
+// projects, with one subdirectory per language (go, php)
+type TemplatesConfig struct {
+	Dirs []string `yaml:"dirs" env:"TEMPLATE_DIRS"`
+}
+
+// Duration is a time.Duration written as a string such as "90s" or "24h" in
+// files, environment variables and flags
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Duration) UnmarshalText(text []byte) error {
+	return d.Set(string(text))
+}
+
+// Set implements flag.Value
+func (d *Duration) Set(value string) error {
+	parsed, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// String implements flag.Value
+func (d *Duration) String() string {
+	if d == nil {
+		return "0s"
+	}
+	return time.Duration(*d).String()
+}
+
+// Default returns the configuration used when no source overrides a setting
+func Default() Config {
+	defaults := app.DefaultConfig()
+	listener := server.DefaultConfig()
+	retention := services.DefaultChatRetention()
+
+	return Config{
+		Server: ServerConfig{
+			Addr:              listener.Addr,
+			ReadHeaderTimeout: Duration(listener.ReadHeaderTimeout),
+			ReadTimeout:       Duration(listener.ReadTimeout),
+			WriteTimeout:      Duration(listener.WriteTimeout),
+			IdleTimeout:       Duration(listener.IdleTimeout),
+			ShutdownTimeout:   Duration(listener.ShutdownTimeout),
+			MaxHeaderBytes:    listener.MaxHeaderBytes,
+			MaxBodyBytes:      int(defaults.MaxBodyBytes),
+			MaxUploadBytes:    int(defaults.MaxUploadBytes),
+
+			RequestTimeout:         Duration(defaults.Timeouts.Default),
+			GenerateRequestTimeout: Duration(defaults.Timeouts.Generate),
+			ChatRequestTimeout:     Duration(defaults.Timeouts.Chat),
+		},
+		CORS: CORSConfig{
+			AllowOrigins: defaults.CORS.AllowOrigins,
+			AllowMethods: defaults.CORS.AllowMethods,
+			AllowHeaders: defaults.CORS.AllowHeaders,
+		},
+		StaticDir: defaults.StaticDir,
+		Chat: ChatConfig{
+			MaxMessages:   retention.MaxMessages,
+			IdleTimeout:   Duration(retention.IdleTimeout),
+			SweepInterval: Duration(retention.SweepInterval),
+		},
+		Artifacts: ArtifactsConfig{Dir: defaults.Artifacts.Dir},
+		Auth:      AuthConfig{JWTLeeway: Duration(time.Minute)},
+		LLM: LLMConfig{
+			Provider: "none",
+			Timeout:  Duration(60 * time.Second),
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerMinute:         defaults.RateLimit.Default.RequestsPerMinute,
+			Burst:                     defaults.RateLimit.Default.Burst,
+			GenerateRequestsPerMinute: defaults.RateLimit.Generate.RequestsPerMinute,
+			GenerateBurst:             defaults.RateLimit.Generate.Burst,
+			ChatRequestsPerMinute:     defaults.RateLimit.Chat.RequestsPerMinute,
+			ChatBurst:                 defaults.RateLimit.Chat.Burst,
+		},
+		Metrics: MetricsConfig{
+			Enabled: defaults.Metrics.Enabled,
+			Path:    defaults.Metrics.Path,
+		},
+		Log: LogConfig{
+			Format: logging.FormatJSON,
+			Level:  "info",
+		},
+		Tracing: TracingConfig{
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+		},
+	}
+}
+
+// IsLambda reports whether the process runs inside AWS Lambda
+func IsLambda() bool {
+	return os.Getenv("LAMBDA_TASK_ROOT") != "" || os.Getenv("AWS_LAMBDA_RUNTIME_API") != ""
+}
+
+// resolve fills settings that depend on other settings once every source has
+// been applied
+func (c *Config) resolve(lambda bool) {
+	if c.Server.GinMode == "" {
+		c.Server.GinMode = "debug"
+		if lambda {
+			c.Server.GinMode = "release"
+		}
+	}
+
+	// DynamoDB is picked automatically in Lambda once a table is configured
+	if c.Storage.Backend == "" && lambda && c.Storage.DynamoDB.Table != "" {
+		c.Storage.Backend = "dynamodb"
+	}
+
+	if c.Artifacts.S3.Region == "" {
+		c.Artifacts.S3.Region = c.AWS.Region
+	}
+	if c.Storage.DynamoDB.Region == "" {
+		c.Storage.DynamoDB.Region = c.AWS.Region
+	}
+}
+
+// App converts the configuration into the settings used by the application builder
+func (c Config) App() app.Config {
+	credentials := c.AWS.Credentials()
+
+	return app.Config{
+		GinMode: c.Server.GinMode,
+		CORS: app.CORSConfig{
+			AllowOrigins: c.CORS.AllowOrigins,
+			AllowMethods: c.CORS.AllowMethods,
+			AllowHeaders: c.CORS.AllowHeaders,
+		},
+		StaticDir: c.StaticDir,
+		Chat: services.ChatRetention{
+			MaxMessages:   c.Chat.MaxMessages,
+			IdleTimeout:   time.Duration(c.Chat.IdleTimeout),
+			SweepInterval: time.Duration(c.Chat.SweepInterval),
+		},
+		Artifacts: app.ArtifactConfig{
+			Backend: c.Artifacts.Backend,
+			Dir:     c.Artifacts.Dir,
+			S3: artifacts.S3Config{
+				Bucket:       c.Artifacts.S3.Bucket,
+				Region:       c.Artifacts.S3.Region,
+				Endpoint:     c.Artifacts.S3.Endpoint,
+				UsePathStyle: c.Artifacts.S3.PathStyle,
+				Credentials:  credentials,
+			},
+		},
+		Storage: app.StorageConfig{
+			Backend: c.Storage.Backend,
+			DynamoDB: storage.DynamoDBConfig{
+				Table:       c.Storage.DynamoDB.Table,
+				Region:      c.Storage.DynamoDB.Region,
+				Endpoint:    c.Storage.DynamoDB.Endpoint,
+				Credentials: credentials,
+			},
+		},
+		Auth: c.Auth.Auth(),
+		RateLimit: app.RateLimitConfig{
+			Enabled:  c.RateLimit.Enabled,
+			Default:  limits.Rate{RequestsPerMinute: c.RateLimit.RequestsPerMinute, Burst: c.RateLimit.Burst},
+			Generate: limits.Rate{RequestsPerMinute: c.RateLimit.GenerateRequestsPerMinute, Burst: c.RateLimit.GenerateBurst},
+			Chat:     limits.Rate{RequestsPerMinute: c.RateLimit.ChatRequestsPerMinute, Burst: c.RateLimit.ChatBurst},
+		},
+		Timeouts: app.TimeoutConfig{
+			Default:  time.Duration(c.Server.RequestTimeout),
+			Generate: time.Duration(c.Server.GenerateRequestTimeout),
+			Chat:     time.Duration(c.Server.ChatRequestTimeout),
+		},
+		Metrics: app.MetricsConfig{
+			Enabled: c.Metrics.Enabled,
+			Path:    c.Metrics.Path,
+		},
+		MaxBodyBytes:   int64(c.Server.MaxBodyBytes),
+		MaxUploadBytes: int64(c.Server.MaxUploadBytes),
+		TrustedProxies: c.Server.TrustedProxies,
+		TemplateDirs:   c.Templates.Dirs,
+		LLM: app.LLMConfig{
+			Provider: c.LLM.Provider,
+			BaseURL:  c.LLM.BaseURL,
+			APIKey:   c.LLM.APIKey,
+		},
+	}
+}
+
+// Logger builds the structured logger the configuration describes, writing to stderr
+func (c Config) Logger() (*slog.Logger, error) {
+	return logging.New(logging.Config{Format: c.Log.Format, Level: c.Log.Level}, os.Stderr)
+}
+
+// TracingSetup converts the configuration into trace exporter settings
+func (c Config) TracingSetup() tracing.Config {
+	return tracing.Config{
+		Exporter:    c.Tracing.Exporter,
+		Endpoint:    c.Tracing.Endpoint,
+		Insecure:    c.Tracing.Insecure,
+		SampleRatio: c.Tracing.SampleRatio,
+	}
+}
+
+// HTTPServer converts the configuration into the HTTP listener settings
+func (c Config) HTTPServer() server.Config {
+	return server.Config{
+		Addr:              c.Server.Addr,
+		ReadHeaderTimeout: time.Duration(c.Server.ReadHeaderTimeout),
+		ReadTimeout:       time.Duration(c.Server.ReadTimeout),
+		WriteTimeout:      time.Duration(c.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(c.Server.IdleTimeout),
+		ShutdownTimeout:   time.Duration(c.Server.ShutdownTimeout),
+		MaxHeaderBytes:    c.Server.MaxHeaderBytes,
+		TLS: server.TLSConfig{
+			CertFile:   c.TLS.CertFile,
+			KeyFile:    c.TLS.KeyFile,
+			SelfSigned: c.TLS.SelfSigned,
+		},
+	}
+}
@@ -0,0 +1,245 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from defaults, the optional file named by
+// -config or CONFIG_FILE, environment variables and the flags in args, in
+// that order of precedence, and validates the result. The config flags are
+// registered on fs, so callers can add flags of their own before calling Load.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	cfg := Default()
+
+	path, _ := os.LookupEnv("CONFIG_FILE")
+	if value, ok := configFlag(args); ok {
+		path = value
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+
+	cfg.bindFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return cfg, err
+	}
+
+	cfg.resolve(IsLambda())
+
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// loadFile overlays a YAML (.yaml, .yml) or TOML (.toml) file. Unknown keys
+// are rejected so that typos do not silently fall back to defaults.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	case ".toml":
+		// TOML documents are re-encoded as YAML so that one set of struct tags
+		// and one strict decoder serve both formats
+		var document map[string]interface{}
+		if err := toml.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if data, err = yaml.Marshal(document); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file format: %s (use .yaml, .yml or .toml)", path)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// loadEnv overlays every field that has an env tag. Empty variables are
+// ignored unless the tag allows them, so a blank line in .env does not wipe a
+// default. PORT is accepted as a shorthand for SERVER_ADDR=":<port>".
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	if port, ok := lookup("PORT"); ok && port != "" {
+		c.Server.Addr = ":" + port
+	}
+	return loadEnvFields(reflect.ValueOf(c).Elem(), lookup)
+}
+
+func loadEnvFields(value reflect.Value, lookup func(string) (string, bool)) error {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		tag := value.Type().Field(i).Tag.Get("env")
+
+		if tag == "" {
+			if field.Kind() == reflect.Struct {
+				if err := loadEnvFields(field, lookup); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		name, option, _ := strings.Cut(tag, ",")
+		raw, ok := lookup(name)
+		if !ok || (raw == "" && option != "allowempty") {
+			continue
+		}
+		if err := setField(field, raw); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", name, raw, err)
+		}
+	}
+	return nil
+}
+
+// setField parses raw into a string, bool, int, string list or Duration field
+func setField(field reflect.Value, raw string) error {
+	if duration, ok := field.Addr().Interface().(*Duration); ok {
+		return duration.Set(raw)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(parsed))
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		field.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// bindFlags registers the command-line flags. Each flag defaults to the value
+// loaded from the file and environment, so only flags that are passed override.
+func (c *Config) bindFlags(fs *flag.FlagSet) {
+	fs.String("config", "", "Path to a YAML or TOML config file (also CONFIG_FILE)")
+
+	fs.StringVar(&c.Server.Addr, "addr", c.Server.Addr, "Listen address")
+	fs.StringVar(&c.Server.GinMode, "gin-mode", c.Server.GinMode, "Gin mode: debug, release or test")
+	fs.Var(&c.Server.WriteTimeout, "write-timeout", "Maximum time to write a response, including archive downloads")
+	fs.Var(&c.Server.RequestTimeout, "request-timeout", "Deadline for API requests outside generation and chat; 0 disables it")
+	fs.Var(&c.Server.GenerateRequestTimeout, "generate-request-timeout", "Deadline for generation, download and proposal requests; 0 disables it")
+	fs.Var(&c.Server.ChatRequestTimeout, "chat-request-timeout", "Deadline for chat message requests; 0 disables it")
+	fs.Var(&c.Server.ShutdownTimeout, "shutdown-timeout", "Time allowed for in-flight requests to finish on shutdown")
+	fs.IntVar(&c.Server.MaxBodyBytes, "max-body-bytes", c.Server.MaxBodyBytes, "Largest API request body accepted; 0 disables the cap")
+	fs.IntVar(&c.Server.MaxUploadBytes, "max-upload-bytes", c.Server.MaxUploadBytes, "Largest uploaded archive accepted; 0 disables the cap")
+
+	fs.StringVar(&c.TLS.CertFile, "tls-cert", c.TLS.CertFile, "TLS certificate file")
+	fs.StringVar(&c.TLS.KeyFile, "tls-key", c.TLS.KeyFile, "TLS private key file")
+	fs.BoolVar(&c.TLS.SelfSigned, "tls-self-signed", c.TLS.SelfSigned, "Serve HTTPS with a generated self-signed certificate (development only)")
+
+	fs.Var((*listValue)(&c.CORS.AllowOrigins), "cors-origins", "Comma-separated allowed origins, or * for any")
+	fs.StringVar(&c.StaticDir, "static-dir", c.StaticDir, "Directory of the built frontend; empty disables static files")
+
+	fs.StringVar(&c.Storage.Backend, "storage", c.Storage.Backend, "State storage backend: memory or dynamodb")
+	fs.StringVar(&c.Artifacts.Backend, "artifact-store", c.Artifacts.Backend, "Archive store: local or s3; empty rebuilds on every download")
+	fs.StringVar(&c.Artifacts.Dir, "artifact-dir", c.Artifacts.Dir, "Directory used by the local archive store")
+
+	fs.StringVar(&c.LLM.Provider, "llm-provider", c.LLM.Provider, "Assistant LLM provider: none, openai, anthropic or ollama")
+	fs.StringVar(&c.LLM.Model, "llm-model", c.LLM.Model, "Assistant LLM model")
+
+	fs.BoolVar(&c.RateLimit.Enabled, "rate-limit", c.RateLimit.Enabled, "Enable per-client rate limiting")
+	fs.IntVar(&c.RateLimit.RequestsPerMinute, "rate-limit-rpm", c.RateLimit.RequestsPerMinute, "Requests per minute allowed per client")
+	fs.IntVar(&c.RateLimit.Burst, "rate-limit-burst", c.RateLimit.Burst, "Requests a client may burst above the steady rate")
+
+	fs.BoolVar(&c.Metrics.Enabled, "metrics", c.Metrics.Enabled, "Serve Prometheus metrics")
+	fs.StringVar(&c.Metrics.Path, "metrics-path", c.Metrics.Path, "Path Prometheus metrics are served at")
+
+	fs.StringVar(&c.Tracing.Exporter, "trace-exporter", c.Tracing.Exporter, "Trace exporter: none, otlp or stdout")
+	fs.StringVar(&c.Tracing.Endpoint, "trace-endpoint", c.Tracing.Endpoint, "OTLP/HTTP collector host:port")
+
+	fs.StringVar(&c.Log.Format, "log-format", c.Log.Format, "Log format: json or text")
+	fs.StringVar(&c.Log.Level, "log-level", c.Log.Level, "Lowest level logged: debug, info, warn or error")
+
+	fs.Var((*listValue)(&c.Templates.Dirs), "template-dirs", "Comma-separated extra template directories")
+}
+
+// configFlag finds -config or --config in args without parsing the others,
+// since the file has to be loaded before flags are bound
+func configFlag(args []string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name := strings.TrimLeft(arg, "-")
+		if name == arg || len(arg)-len(name) > 2 {
+			continue
+		}
+		if value, ok := strings.CutPrefix(name, "config="); ok {
+			return value, true
+		}
+		if name == "config" && i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	return "", false
+}
+
+// listValue is a flag.Value for comma-separated lists
+type listValue []string
+
+func (l *listValue) Set(value string) error {
+	*l = splitList(value)
+	return nil
+}
+
+func (l *listValue) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,290 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/tracing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Validate reports every invalid setting at once
+func (c Config) Validate() error {
+	var problems []error
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		fail("server.addr %q is not a host:port address", c.Server.Addr)
+	}
+	switch c.Server.GinMode {
+	case "", "debug", "release", "test":
+	default:
+		fail("server.gin_mode must be debug, release or test, got %q", c.Server.GinMode)
+	}
+	for _, timeout := range []struct {
+		name  string
+		value Duration
+	}{
+		{"read_header_timeout", c.Server.ReadHeaderTimeout},
+		{"read_timeout", c.Server.ReadTimeout},
+		{"write_timeout", c.Server.WriteTimeout},
+		{"idle_timeout", c.Server.IdleTimeout},
+		{"request_timeout", c.Server.RequestTimeout},
+		{"generate_request_timeout", c.Server.GenerateRequestTimeout},
+		{"chat_request_timeout", c.Server.ChatRequestTimeout},
+	} {
+		if timeout.value < 0 {
+			fail("server.%s cannot be negative", timeout.name)
+		}
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		fail("server.shutdown_timeout must be positive")
+	}
+	if c.Server.MaxHeaderBytes <= 0 {
+		fail("server.max_header_bytes must be positive")
+	}
+	if c.Server.MaxBodyBytes < 0 {
+		fail("server.max_body_bytes cannot be negative")
+	}
+	if c.Server.MaxUploadBytes < 0 {
+		fail("server.max_upload_bytes cannot be negative")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("server.trusted_proxies entry %q is not an IP address or CIDR", proxy)
+			}
+		}
+	}
+
+	if c.TLS.SelfSigned && (c.TLS.CertFile != "" || c.TLS.KeyFile != "") {
+		fail("tls.self_signed cannot be combined with tls.cert_file and tls.key_file")
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		fail("tls.cert_file and tls.key_file must be set together")
+	}
+	for _, path := range []string{c.TLS.CertFile, c.TLS.KeyFile} {
+		if path != "" {
+			if _, err := os.Stat(path); err != nil {
+				fail("tls file %s: %v", path, err)
+			}
+		}
+	}
+
+	if len(c.CORS.AllowOrigins) == 0 {
+		fail("cors.allow_origins must list at least one origin, or *")
+	}
+	for _, origin := range c.CORS.AllowOrigins {
+		if origin != "*" && !strings.HasPrefix(origin, "http://") && !strings.HasPrefix(origin, "https://") {
+			fail("cors.allow_origins entry %q must be * or start with http:// or https://", origin)
+		}
+	}
+
+	if c.Chat.MaxMessages < 0 {
+		fail("chat.max_messages cannot be negative")
+	}
+	if c.Chat.IdleTimeout < 0 {
+		fail("chat.session_idle_timeout cannot be negative")
+	}
+	if c.Chat.SweepInterval <= 0 {
+		fail("chat.sweep_interval must be positive")
+	}
+
+	switch c.Artifacts.Backend {
+	case "":
+	case "local":
+		if c.Artifacts.Dir == "" {
+			fail("artifacts.dir is required by the local backend")
+		}
+	case "s3":
+		if c.Artifacts.S3.Bucket == "" {
+			fail("artifacts.s3.bucket is required by the s3 backend")
+		}
+		if c.Artifacts.S3.Endpoint != "" && !validURL(c.Artifacts.S3.Endpoint) {
+			fail("artifacts.s3.endpoint %q is not an http(s) URL", c.Artifacts.S3.Endpoint)
+		}
+	default:
+		fail("artifacts.backend must be local or s3, got %q", c.Artifacts.Backend)
+	}
+
+	switch c.Storage.Backend {
+	case "", "memory":
+	case "dynamodb":
+		if c.Storage.DynamoDB.Table == "" {
+			fail("storage.dynamodb.table is required by the dynamodb backend")
+		}
+		if c.Storage.DynamoDB.Endpoint != "" && !validURL(c.Storage.DynamoDB.Endpoint) {
+			fail("storage.dynamodb.endpoint %q is not an http(s) URL", c.Storage.DynamoDB.Endpoint)
+		}
+	default:
+		fail("storage.backend must be memory or dynamodb, got %q", c.Storage.Backend)
+	}
+
+	if (c.Artifacts.Backend == "s3" || c.Storage.Backend == "dynamodb") && !c.AWS.Credentials().Valid() {
+		fail("aws.access_key_id and aws.secret_access_key are required by the s3 and dynamodb backends")
+	}
+
+	authProblems := len(problems)
+	for i, entry := range c.Auth.APIKeys {
+		name, key, ok := strings.Cut(entry, ":")
+		if !ok || strings.TrimSpace(name) == "" || strings.TrimSpace(key) == "" {
+			// The entry holds a secret, so it is identified by position only
+			fail("auth.api_keys entry %d must be a name:key pair", i+1)
+		}
+	}
+	if c.Auth.JWTSecret != "" && c.Auth.JWTPublicKeyFile != "" {
+		fail("auth.jwt_secret and auth.jwt_public_key_file cannot both be set")
+	}
+	if c.Auth.JWTPublicKeyFile != "" {
+		if _, err := os.Stat(c.Auth.JWTPublicKeyFile); err != nil {
+			fail("auth.jwt_public_key_file: %v", err)
+		}
+	}
+	if c.Auth.JWTLeeway < 0 {
+		fail("auth.jwt_leeway cannot be negative")
+	}
+	if len(problems) == authProblems {
+		// Key lengths, duplicates and the key file contents are checked by the authenticator itself
+		if _, err := auth.New(c.Auth.Auth()); err != nil {
+			fail("auth: %v", err)
+		}
+	}
+
+	switch c.LLM.Provider {
+	case "", "none":
+	case "openai", "anthropic", "ollama":
+		if c.LLM.Model == "" {
+			fail("llm.model is required by the %s provider", c.LLM.Provider)
+		}
+		if c.LLM.Provider != "ollama" && c.LLM.APIKey == "" {
+			fail("llm.api_key is required by the %s provider", c.LLM.Provider)
+		}
+		if c.LLM.BaseURL != "" && !validURL(c.LLM.BaseURL) {
+			fail("llm.base_url %q is not an http(s) URL", c.LLM.BaseURL)
+		}
+		if c.LLM.Timeout <= 0 {
+			fail("llm.timeout must be positive")
+		}
+	default:
+		fail("llm.provider must be none, openai, anthropic or ollama, got %q", c.LLM.Provider)
+	}
+
+	if c.RateLimit.Enabled {
+		budgets := []struct {
+			prefix            string
+			requestsPerMinute int
+			burst             int
+		}{
+			{"", c.RateLimit.RequestsPerMinute, c.RateLimit.Burst},
+			{"generate_", c.RateLimit.GenerateRequestsPerMinute, c.RateLimit.GenerateBurst},
+			{"chat_", c.RateLimit.ChatRequestsPerMinute, c.RateLimit.ChatBurst},
+		}
+		for _, budget := range budgets {
+			if budget.requestsPerMinute <= 0 {
+				fail("rate_limit.%srequests_per_minute must be positive", budget.prefix)
+			}
+			if budget.burst <= 0 {
+				fail("rate_limit.%sburst must be positive", budget.prefix)
+			}
+		}
+	}
+
+	if c.Metrics.Enabled {
+		switch path := c.Metrics.Path; {
+		case !strings.HasPrefix(path, "/") || path == "/":
+			fail("metrics.path must be an absolute path other than /, got %q", path)
+		case path == "/api" || strings.HasPrefix(path, "/api/") || strings.HasPrefix(path, "/static/"):
+			fail("metrics.path %q clashes with the API or static files", path)
+		}
+	}
+
+	switch strings.ToLower(c.Log.Format) {
+	case logging.FormatJSON, logging.FormatText:
+	default:
+		fail("log.format must be json or text, got %q", c.Log.Format)
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level must be debug, info, warn or error, got %q", c.Log.Level)
+	}
+
+	switch c.Tracing.Exporter {
+	case "", tracing.ExporterNone, tracing.ExporterStdout:
+	case tracing.ExporterOTLP:
+		if strings.Contains(c.Tracing.Endpoint, "://") {
+			fail("tracing.endpoint must be host:port without a scheme, got %q", c.Tracing.Endpoint)
+		}
+	default:
+		fail("tracing.exporter must be none, otlp or stdout, got %q", c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		fail("tracing.sample_ratio must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+
+	for _, dir := range c.Templates.Dirs {
+		if info, err := os.Stat(dir); err != nil {
+			fail("templates.dirs entry %s: %v", dir, err)
+		} else if !info.IsDir() {
+			fail("templates.dirs entry %s is not a directory", dir)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+func validURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// Redacted returns a copy with every secret field that is set replaced by a placeholder
+func (c Config) Redacted() Config {
+	redactFields(reflect.ValueOf(&c).Elem())
+	return c
+}
+
+func redactFields(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		switch {
+		case value.Type().Field(i).Tag.Get("secret") == "true" && field.Kind() == reflect.Slice:
+			// Copy so the original configuration keeps its values
+			masked := make([]string, field.Len())
+			for j := range masked {
+				masked[j] = redacted
+			}
+			field.Set(reflect.ValueOf(masked))
+		case value.Type().Field(i).Tag.Get("secret") == "true":
+			if field.String() != "" {
+				field.SetString(redacted)
+			}
+		case field.Kind() == reflect.Struct:
+			redactFields(field)
+		}
+	}
+}
+
+// Dump writes the effective configuration as YAML with secrets redacted
+func (c Config) Dump(w io.Writer) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -0,0 +1,138 @@
+// Package health runs the dependency checks behind the readiness endpoint.
+// Each check reports whether one dependency, such as the storage backend or
+// the LLM provider, can currently serve requests.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+)
+
+// DefaultTimeout bounds each check unless the checker is given another limit
+const DefaultTimeout = 5 * time.Second
+
+// probeKey names an item that is never stored. Looking it up exercises a
+// backend without reading or writing real data.
+const probeKey = "readiness-probe"
+
+// Check reports whether a dependency is usable; nil means it is
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker holds the registered readiness checks
+type Checker struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []namedCheck
+}
+
+// NewChecker returns a checker that gives each check timeout to finish;
+// zero means DefaultTimeout
+func NewChecker(timeout time.Duration) *Checker {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Checker{timeout: timeout}
+}
+
+// Register adds a check, replacing any earlier one with the same name
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i := range c.checks {
+		if c.checks[i].name == name {
+			c.checks[i].check = check
+			return
+		}
+	}
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run runs every check concurrently and returns the results in registration
+// order, and whether all of them passed. Failures are logged with their
+// cause; results only say whether a check failed or timed out, since the
+// endpoint that serves them is public.
+func (c *Checker) Run(ctx context.Context) ([]models.CheckResult, bool) {
+	c.mu.RLock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.RUnlock()
+
+	results := make([]models.CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check namedCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, result := range results {
+		if result.Status != models.CheckUp {
+			ready = false
+		}
+	}
+	return results, ready
+}
+
+func (c *Checker) run(ctx context.Context, check namedCheck) models.CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.check(ctx)
+	result := models.CheckResult{
+		Name:       check.name,
+		Status:     models.CheckUp,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err == nil {
+		return result
+	}
+
+	logging.FromContext(ctx).Warn("readiness check failed", "check", check.name, "error", err)
+	result.Status = models.CheckDown
+	result.Error = "check failed"
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+		result.Error = "check timed out"
+	}
+	return result
+}
+
+// StorageCheck looks up an item that does not exist, which succeeds as long
+// as the backend answers
+func StorageCheck(store storage.Store) Check {
+	return func(ctx context.Context) error {
+		_, err := store.GetProject(ctx, probeKey)
+		if err == nil || errors.Is(err, storage.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("storage lookup failed: %w", err)
+	}
+}
+
+// ArtifactCheck asks the artifact store whether a probe artifact exists
+func ArtifactCheck(store artifacts.Store) Check {
+	return func(ctx context.Context) error {
+		if _, err := store.Exists(ctx, probeKey); err != nil {
+			return fmt.Errorf("artifact lookup failed: %w", err)
+		}
+		return nil
+	}
+}
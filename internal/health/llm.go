@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// LLM providers with a readiness check
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderOllama    = "ollama"
+)
+
+// Default API endpoints, used when no base URL is configured. The OpenAI and
+// Anthropic ones include the API version, as custom base URLs must.
+var defaultBaseURLs = map[string]string{
+	ProviderOpenAI:    "https://api.openai.com/v1",
+	ProviderAnthropic: "https://api.anthropic.com/v1",
+	ProviderOllama:    "http://localhost:11434",
+}
+
+// LLMCheck lists the provider's models, which needs no tokens but proves the
+// endpoint is reachable and accepts the API key. A nil client means
+// http.DefaultClient.
+func LLMCheck(provider, baseURL, apiKey string, client *http.Client) (Check, error) {
+	if baseURL == "" {
+		baseURL = defaultBaseURLs[provider]
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var path string
+	header := http.Header{}
+	switch provider {
+	case ProviderOpenAI:
+		path = "/models"
+		header.Set("Authorization", "Bearer "+apiKey)
+	case ProviderAnthropic:
+		path = "/models"
+		header.Set("X-Api-Key", apiKey)
+		header.Set("Anthropic-Version", "2023-06-01")
+	case ProviderOllama:
+		path = "/api/tags"
+	default:
+		return nil, fmt.Errorf("no readiness check for LLM provider: %s", provider)
+	}
+
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+		if err != nil {
+			return err
+		}
+		req.Header = header.Clone()
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("%s unreachable: %w", provider, err)
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+			return fmt.Errorf("%s rejected the API key: %s", provider, resp.Status)
+		case resp.StatusCode >= 300:
+			return fmt.Errorf("%s answered %s", provider, resp.Status)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,133 @@
+// Package limits protects the API from runaway clients: token-bucket rate
+// limiting keyed by principal or client IP, and caps on request body sizes.
+package limits
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate is a token-bucket budget. A client may make Burst requests at once,
+// after which tokens refill at RequestsPerMinute.
+type Rate struct {
+	RequestsPerMinute int
+	Burst             int
+}
+
+// Decision is a limiter's verdict on one request
+type Decision struct {
+	Allowed bool
+	// Limit is the bucket size, reported as RateLimit-Limit
+	Limit int
+	// Remaining is the number of requests the client may still make at once
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed; zero when Allowed
+	RetryAfter time.Duration
+}
+
+// Limiter takes one token from a client's bucket. The in-memory limiter
+// suits a single instance; deployments with several instances (or Lambda)
+// need an implementation backed by a shared store such as Redis so that every
+// instance draws from the same budget.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rate Rate) (Decision, error)
+}
+
+// How often idle buckets are dropped by the in-memory limiter
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updated   time.Time
+	perSecond float64
+	capacity  float64
+}
+
+// level returns the bucket's tokens at now
+func (b *bucket) level(now time.Time) float64 {
+	tokens := b.tokens
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		tokens += elapsed.Seconds() * b.perSecond
+	}
+	return math.Min(tokens, b.capacity)
+}
+
+// MemoryLimiter is a Limiter that keeps buckets in process memory
+type MemoryLimiter struct {
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+	mu        sync.Mutex
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// SetClock replaces the limiter's time source, so tests can move time forward
+func (l *MemoryLimiter) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.now = now
+}
+
+// Allow implements Limiter
+func (l *MemoryLimiter) Allow(ctx context.Context, key string, rate Rate) (Decision, error) {
+	perSecond := float64(rate.RequestsPerMinute) / 60
+	capacity := float64(rate.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweepLocked(now)
+
+	b, exists := l.buckets[key]
+	if !exists {
+		b = &bucket{tokens: capacity, updated: now}
+		l.buckets[key] = b
+	}
+	b.perSecond, b.capacity = perSecond, capacity
+	b.tokens = b.level(now)
+	b.updated = now
+
+	decision := Decision{Limit: rate.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = secondsToDuration((1 - b.tokens) / perSecond)
+	}
+	decision.Remaining = int(math.Floor(b.tokens))
+	decision.Reset = secondsToDuration((capacity - b.tokens) / perSecond)
+	return decision, nil
+}
+
+// sweepLocked drops buckets that have refilled completely, since a new
+// bucket starts full anyway. The caller must hold the lock.
+func (l *MemoryLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, b := range l.buckets {
+		if b.level(now) >= b.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 || math.IsInf(seconds, 0) || math.IsNaN(seconds) {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
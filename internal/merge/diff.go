@@ -0,0 +1,111 @@
+package merge
+
+// matches pairs each line of a with the line of b it is kept as in a
+// shortest edit script from a to b, or -1 when the line is deleted. It uses
+// Myers' O(ND) algorithm, so files that mostly agree are cheap to compare.
+func matches(a, b []string) []int {
+	// Lines both ends share need no search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	matched := make([]int, len(a))
+	for i := range matched {
+		matched[i] = -1
+	}
+	for i := 0; i < prefix; i++ {
+		matched[i] = i
+	}
+	for i := 0; i < suffix; i++ {
+		matched[len(a)-1-i] = len(b) - 1 - i
+	}
+
+	middle := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for i, j := range middle {
+		if j >= 0 {
+			matched[prefix+i] = prefix + j
+		}
+	}
+	return matched
+}
+
+// maxEdits bounds the edit distance myers searches. Beyond it the lines are
+// treated as entirely different, which keeps memory bounded for rewrites.
+const maxEdits = 2000
+
+// myers returns matches for a and b, which share no prefix or suffix
+func myers(a, b []string) []int {
+	n, m := len(a), len(b)
+	matched := make([]int, n)
+	for i := range matched {
+		matched[i] = -1
+	}
+	if n == 0 || m == 0 {
+		return matched
+	}
+
+	// v[k+offset] is the furthest x reached on diagonal k. trace[d] keeps
+	// diagonals -d..d of v as they were before round d, to walk the path back.
+	offset := n + m
+	v := make([]int, 2*offset+2)
+	var trace [][]int
+	for d := 0; d <= min(n+m, maxEdits); d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[k-1+offset] < v[k+1+offset]) {
+				x = v[k+1+offset]
+			} else {
+				x = v[k-1+offset] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[k+offset] = x
+			if x >= n && y >= m {
+				backtrack(trace, n, m, matched)
+				return matched
+			}
+		}
+	}
+	return matched
+}
+
+// backtrack walks the rounds of myers back from the end, recording the
+// diagonal moves as matches
+func backtrack(trace [][]int, n, m int, matched []int) {
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		// furthest returns the x diagonal k had reached before round d
+		furthest := func(k int) int { return trace[d][k+d] }
+		k := x - y
+
+		prevK := k - 1
+		if k == -d || (k != d && furthest(k-1) < furthest(k+1)) {
+			prevK = k + 1
+		}
+		prevX := furthest(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			matched[x] = y
+		}
+		x, y = prevX, prevY
+	}
+
+	// The first round only follows the diagonal from the start
+	for x > 0 && y > 0 {
+		x--
+		y--
+		matched[x] = y
+	}
+}
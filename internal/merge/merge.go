@@ -0,0 +1,141 @@
+// Package merge performs line-based three-way merges of text files, the way
+// diff3 and git do: changes made on only one side since the common base are
+// taken, and changes made on both sides to the same lines are reported as
+// conflicts with diff3-style markers.
+package merge
+
+import "strings"
+
+// Conflict markers, as written by diff3 -m and git's diff3 conflict style
+const (
+	MarkerOurs   = "<<<<<<<"
+	MarkerBase   = "|||||||"
+	MarkerSplit  = "======="
+	MarkerTheirs = ">>>>>>>"
+)
+
+// Labels name the three versions in conflict markers
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// Result is the outcome of merging one file
+type Result struct {
+	// Content is the merged text, with conflict markers around each conflict
+	Content string
+	// Conflicts holds each conflicting region as it appears in Content,
+	// markers included
+	Conflicts []string
+}
+
+// Clean reports whether the merge needed no conflict markers
+func (r Result) Clean() bool {
+	return len(r.Conflicts) == 0
+}
+
+// Merge combines the changes ours and theirs each made to base
+func Merge(base, ours, theirs string, labels Labels) Result {
+	b, o, t := splitLines(base), splitLines(ours), splitLines(theirs)
+	toOurs, toTheirs := matches(b, o), matches(b, t)
+
+	var content strings.Builder
+	var result Result
+	write := func(lines []string) {
+		for _, line := range lines {
+			content.WriteString(line)
+		}
+	}
+
+	bi, oi, ti := 0, 0, 0
+	for {
+		// Lines all three keep are stable
+		for bi < len(b) && toOurs[bi] == oi && toTheirs[bi] == ti {
+			content.WriteString(b[bi])
+			bi, oi, ti = bi+1, oi+1, ti+1
+		}
+		if bi == len(b) && oi == len(o) && ti == len(t) {
+			break
+		}
+
+		// The unstable chunk runs up to the next base line both sides kept
+		bEnd, oEnd, tEnd := len(b), len(o), len(t)
+		for j := bi; j < len(b); j++ {
+			if toOurs[j] >= 0 && toTheirs[j] >= 0 {
+				bEnd, oEnd, tEnd = j, toOurs[j], toTheirs[j]
+				break
+			}
+		}
+
+		baseChunk, oursChunk, theirsChunk := b[bi:bEnd], o[oi:oEnd], t[ti:tEnd]
+		switch {
+		case equal(oursChunk, baseChunk):
+			write(theirsChunk)
+		case equal(theirsChunk, baseChunk), equal(oursChunk, theirsChunk):
+			write(oursChunk)
+		default:
+			conflict := conflictText(baseChunk, oursChunk, theirsChunk, labels)
+			content.WriteString(conflict)
+			result.Conflicts = append(result.Conflicts, conflict)
+		}
+		bi, oi, ti = bEnd, oEnd, tEnd
+	}
+
+	result.Content = content.String()
+	return result
+}
+
+// conflictText renders a conflict with diff3-style markers
+func conflictText(base, ours, theirs []string, labels Labels) string {
+	var text strings.Builder
+	section := func(marker, label string, lines []string) {
+		text.WriteString(marker)
+		if label != "" {
+			text.WriteString(" " + label)
+		}
+		text.WriteString("\n")
+		for _, line := range lines {
+			text.WriteString(line)
+			// A last line without a newline still needs one before the next marker
+			if !strings.HasSuffix(line, "\n") {
+				text.WriteString("\n")
+			}
+		}
+	}
+
+	section(MarkerOurs, labels.Ours, ours)
+	section(MarkerBase, labels.Base, base)
+	section(MarkerSplit, "", theirs)
+	text.WriteString(MarkerTheirs)
+	if labels.Theirs != "" {
+		text.WriteString(" " + labels.Theirs)
+	}
+	text.WriteString("\n")
+	return text.String()
+}
+
+// splitLines splits text after each newline, so joining the lines gives the
+// text back exactly
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
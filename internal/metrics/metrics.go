@@ -0,0 +1,240 @@
+// Package metrics exposes Prometheus metrics for HTTP traffic, project
+// generation, archives and chat. Metrics implements services.Observer, so
+// the services report to it without depending on Prometheus.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blueprint"
+
+// Label values used when the real value is missing or not one we know, so
+// that client-controlled input cannot create unbounded series
+const (
+	labelNone      = "none"
+	labelOther     = "other"
+	labelUnmatched = "unmatched"
+)
+
+var httpMethods = map[string]bool{
+	http.MethodGet: true, http.MethodHead: true, http.MethodPost: true, http.MethodPut: true,
+	http.MethodPatch: true, http.MethodDelete: true, http.MethodOptions: true,
+}
+
+// suggestionTypes are the suggestion types the assistant produces
+var suggestionTypes = []string{
+	"language", "framework", "database", "authentication",
+	"feature", "utility", "ci_version", "frontend",
+}
+
+// Metrics holds the collectors and the registry they are exposed from
+type Metrics struct {
+	registry *prometheus.Registry
+
+	languages       map[string]bool
+	frameworks      map[string]bool
+	suggestionTypes map[string]bool
+
+	httpDuration       *prometheus.HistogramVec
+	generations        *prometheus.CounterVec
+	generationDuration *prometheus.HistogramVec
+	archiveSize        *prometheus.HistogramVec
+	chatMessages       *prometheus.CounterVec
+	chatDuration       prometheus.Histogram
+	suggestions        *prometheus.CounterVec
+	projects           prometheus.Gauge
+	conversations      prometheus.Gauge
+}
+
+// New registers the collectors. Languages and frameworks are labelled by the
+// values the templates offer; anything else is counted as "other".
+func New(templates []models.TemplateInfo) *Metrics {
+	m := &Metrics{
+		registry:        prometheus.NewRegistry(),
+		languages:       make(map[string]bool),
+		frameworks:      make(map[string]bool),
+		suggestionTypes: make(map[string]bool),
+
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		generations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "generations_total",
+			Help:      "Project file generations, by language, framework and result.",
+		}, []string{"language", "framework", "result"}),
+		generationDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "generation_duration_seconds",
+			Help:      "Time taken to generate project files, by language and framework.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}, []string{"language", "framework"}),
+		archiveSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "archive_size_bytes",
+			Help:      "Size of generated project archives, by language.",
+			Buckets:   prometheus.ExponentialBuckets(16<<10, 4, 8),
+		}, []string{"language"}),
+		chatMessages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "chat_messages_total",
+			Help:      "Chat messages processed, by result.",
+		}, []string{"result"}),
+		chatDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "chat_message_duration_seconds",
+			Help:      "Time taken to answer chat messages.",
+			Buckets:   prometheus.ExponentialBuckets(0.005, 4, 8),
+		}),
+		suggestions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "chat_suggestions_total",
+			Help:      "Project suggestions made in chat replies, by type.",
+		}, []string{"type"}),
+		projects: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "projects",
+			Help:      "Projects created by this instance.",
+		}),
+		conversations: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "conversations",
+			Help:      "Chat conversations created by this instance and not yet removed.",
+		}),
+	}
+
+	for _, info := range templates {
+		m.languages[string(info.Language)] = true
+		for _, option := range info.Options {
+			if option.Key != "framework" {
+				continue
+			}
+			for _, framework := range option.Options {
+				m.frameworks[framework] = true
+			}
+		}
+	}
+	for _, suggestionType := range suggestionTypes {
+		m.suggestionTypes[suggestionType] = true
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.generations,
+		m.generationDuration,
+		m.archiveSize,
+		m.chatMessages,
+		m.chatDuration,
+		m.suggestions,
+		m.projects,
+		m.conversations,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the duration of every request. Requests are labelled
+// by route template, such as /api/v1/projects/:id, rather than by path.
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		method, route := c.Request.Method, c.FullPath()
+		if !httpMethods[method] {
+			method = labelOther
+		}
+		if route == "" {
+			route = labelUnmatched
+		}
+		m.httpDuration.WithLabelValues(method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// ProjectGenerated implements services.Observer
+func (m *Metrics) ProjectGenerated(project *models.Project, duration time.Duration, err error) {
+	language, framework := m.language(project), m.framework(project)
+	m.generations.WithLabelValues(language, framework, result(err)).Inc()
+	m.generationDuration.WithLabelValues(language, framework).Observe(duration.Seconds())
+}
+
+// ArchiveCreated implements services.Observer
+func (m *Metrics) ArchiveCreated(project *models.Project, size int) {
+	m.archiveSize.WithLabelValues(m.language(project)).Observe(float64(size))
+}
+
+// MessageProcessed implements services.Observer
+func (m *Metrics) MessageProcessed(response *models.ChatResponse, duration time.Duration, err error) {
+	m.chatMessages.WithLabelValues(result(err)).Inc()
+	m.chatDuration.Observe(duration.Seconds())
+	if response == nil {
+		return
+	}
+	for _, suggestion := range response.Suggestions {
+		suggestionType := suggestion.Type
+		if !m.suggestionTypes[suggestionType] {
+			suggestionType = labelOther
+		}
+		m.suggestions.WithLabelValues(suggestionType).Inc()
+	}
+}
+
+// ProjectCreated implements services.Observer
+func (m *Metrics) ProjectCreated(project *models.Project) {
+	m.projects.Inc()
+}
+
+// SessionCreated implements services.Observer
+func (m *Metrics) SessionCreated() {
+	m.conversations.Inc()
+}
+
+// SessionsRemoved implements services.Observer
+func (m *Metrics) SessionsRemoved(count int) {
+	m.conversations.Sub(float64(count))
+}
+
+func (m *Metrics) language(project *models.Project) string {
+	if m.languages[string(project.Language)] {
+		return string(project.Language)
+	}
+	return labelOther
+}
+
+func (m *Metrics) framework(project *models.Project) string {
+	switch framework := project.Options.Framework; {
+	case framework == "":
+		return labelNone
+	case m.frameworks[framework]:
+		return framework
+	default:
+		return labelOther
+	}
+}
+
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Assistant tool names
+const (
+	ToolListFiles            = "list_files"
+	ToolReadFile             = "read_file"
+	ToolProposeOptionsChange = "propose_options_change"
+	ToolProposeFilePatch     = "propose_file_patch"
+)
+
+// Change proposal kinds and statuses
+const (
+	ProposalKindOptions = "options"
+	ProposalKindPatch   = "patch"
+
+	ProposalPending  = "pending"
+	ProposalApplied  = "applied"
+	ProposalRejected = "rejected"
+)
+
+// ToolDefinition describes an assistant tool in the JSON Schema form expected by LLM tool-calling APIs
+type ToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// ToolCall represents a request from the assistant to run a tool
+type ToolCall struct {
+	Name      string          `json:"name" binding:"required"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// ToolCallRequest represents an API request to run a tool against a project
+type ToolCallRequest struct {
+	SessionID string          `json:"session_id,omitempty"`
+	Name      string          `json:"name" binding:"required"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// ToolResult represents the outcome of a tool call
+type ToolResult struct {
+	Name     string          `json:"name"`
+	Output   interface{}     `json:"output,omitempty"`
+	Proposal *ChangeProposal `json:"proposal,omitempty"`
+	Error    string          `json:"error,omitempty"`
+}
+
+// FilePatch replaces the content of a single project file
+type FilePatch struct {
+	Path         string `json:"path"`
+	Content      string `json:"content"`
+	BaseChecksum string `json:"base_checksum,omitempty"` // Checksum of the file when proposed, empty for new files
+}
+
+// ChangeProposal is a change suggested by the assistant that is only applied
+// to the project once the user confirms it
+type ChangeProposal struct {
+	ID         string          `json:"id"`
+	ProjectID  string          `json:"project_id"`
+	SessionID  string          `json:"session_id,omitempty"`
+	Kind       string          `json:"kind"` // options, patch
+	Summary    string          `json:"summary"`
+	Options    *ProjectOptions `json:"options,omitempty"`
+	Patch      *FilePatch      `json:"patch,omitempty"`
+	Status     string          `json:"status"` // pending, applied, rejected
+	CreatedAt  time.Time       `json:"created_at"`
+	ResolvedAt *time.Time      `json:"resolved_at,omitempty"`
+}
+
+// ProposalResponse represents the API response for change proposal operations
+type ProposalResponse struct {
+	Success   bool             `json:"success"`
+	Message   string           `json:"message,omitempty"`
+	Proposal  *ChangeProposal  `json:"proposal,omitempty"`
+	Proposals []ChangeProposal `json:"proposals,omitempty"`
+	Project   *Project         `json:"project,omitempty"`
+	Error     string           `json:"error,omitempty"`
+}
+
+// ToolsResponse represents the API response listing assistant tools
+type ToolsResponse struct {
+	Success bool             `json:"success"`
+	Tools   []ToolDefinition `json:"tools"`
+}
+
+// ToolResultResponse represents the API response for running an assistant tool
+type ToolResultResponse struct {
+	Success bool        `json:"success"`
+	Result  *ToolResult `json:"result"`
+}
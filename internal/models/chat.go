@@ -4,26 +4,43 @@ import "time"
 
 // ChatMessage represents a chat message
 type ChatMessage struct {
-	ID        string    `json:"id"`
-	Role      string    `json:"role"` // "user" or "assistant"
-	Content   string    `json:"content"`
-	ProjectID string    `json:"project_id,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string              `json:"id"`
+	Role        string              `json:"role"` // "user", "assistant" or "system" for history summaries
+	Content     string              `json:"content"`
+	SessionID   string              `json:"session_id,omitempty"`
+	ProjectID   string              `json:"project_id,omitempty"`
+	Suggestions []ProjectSuggestion `json:"suggestions,omitempty"` // Suggestions offered with an assistant message
+	CreatedAt   time.Time           `json:"created_at"`
 }
 
 // ChatRequest represents a chat API request
 type ChatRequest struct {
 	Message   string `json:"message" binding:"required"`
+	SessionID string `json:"session_id,omitempty"` // A new session is started when empty
 	ProjectID string `json:"project_id,omitempty"`
 	Context   string `json:"context,omitempty"`
+	OwnerID   string `json:"-"` // Owner of a newly started session, set from the authenticated caller
+	TeamID    string `json:"-"` // Team of a newly started session, set from the linked project
 }
 
 // ChatResponse represents a chat API response
 type ChatResponse struct {
 	Success     bool                `json:"success"`
+	SessionID   string              `json:"session_id,omitempty"`
 	Message     *ChatMessage        `json:"message,omitempty"`
 	Error       string              `json:"error,omitempty"`
 	Suggestions []ProjectSuggestion `json:"suggestions,omitempty"`
+	ToolResults []ToolResult        `json:"tool_results,omitempty"` // Tools the assistant ran against the linked project
+	Project     *Project            `json:"project,omitempty"`
+}
+
+// ChatProjectRequest represents a request to create a project from a conversation
+type ChatProjectRequest struct {
+	SessionID   string          `json:"session_id" binding:"required"` // Conversation to promote
+	Name        string          `json:"name" binding:"required"`
+	Description string          `json:"description"`
+	Language    ProjectLanguage `json:"language,omitempty"` // Overrides the language gathered in the conversation
+	Options     ProjectOptions  `json:"options"`            // Overrides options gathered in the conversation
 }
 
 // ProjectSuggestion represents AI suggestions for project configuration
@@ -38,8 +55,78 @@ type ProjectSuggestion struct {
 
 // ChatHistory represents a conversation history
 type ChatHistory struct {
-	ProjectID string        `json:"project_id"`
+	SessionID string          `json:"session_id"`
+	Title     string          `json:"title"`
+	ProjectID string          `json:"project_id"` // Linked project, empty until linked
+	OwnerID   string          `json:"owner_id,omitempty"`
+	TeamID    string          `json:"team_id,omitempty"`
+	Messages  []ChatMessage   `json:"messages"`
+	Trimmed   int             `json:"trimmed_count,omitempty"`     // Messages condensed into the leading summary
+	Draft     *ProjectRequest `json:"draft,omitempty" binding:"-"` // Configuration gathered from applied suggestions
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ChatSession summarises a conversation without its messages
+type ChatSession struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	ProjectID    string    `json:"project_id,omitempty"`
+	OwnerID      string    `json:"owner_id,omitempty"`
+	TeamID       string    `json:"team_id,omitempty"`
+	MessageCount int       `json:"message_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ChatSessionRequest represents a request to create a chat session
+type ChatSessionRequest struct {
+	Title     string `json:"title"`
+	ProjectID string `json:"project_id,omitempty"`
+	TeamID    string `json:"team_id,omitempty"` // Shares the session with a team the caller edits
+	OwnerID   string `json:"-"`                 // Set from the authenticated caller
+}
+
+// ChatSessionUpdateRequest represents a request to rename or link a chat session.
+// Nil fields are left unchanged.
+type ChatSessionUpdateRequest struct {
+	Title     *string `json:"title,omitempty"`
+	ProjectID *string `json:"project_id,omitempty"`
+}
+
+// ChatSessionResponse represents the API response for chat session operations
+type ChatSessionResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message,omitempty"`
+	Session  *ChatSession  `json:"session,omitempty"`
+	Sessions []ChatSession `json:"sessions,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// ChatHistoryResponse represents the API response for a conversation's history
+type ChatHistoryResponse struct {
+	Success bool         `json:"success"`
+	History *ChatHistory `json:"history"`
+}
+
+// ChatMessagesResponse represents the API response for a page of a session's messages
+type ChatMessagesResponse struct {
+	Success bool             `json:"success"`
+	Page    *ChatMessagePage `json:"page"`
+}
+
+// ChatMessagePage represents a page of a session's messages, oldest first
+type ChatMessagePage struct {
+	SessionID string        `json:"session_id"`
 	Messages  []ChatMessage `json:"messages"`
-	CreatedAt time.Time     `json:"created_at"`
-	UpdatedAt time.Time     `json:"updated_at"`
+	Total     int           `json:"total"`
+	Offset    int           `json:"offset"`
+	Limit     int           `json:"limit"`
+}
+
+// ChatTranscript is a portable export of a chat session
+type ChatTranscript struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	History    ChatHistory `json:"history"`
 }
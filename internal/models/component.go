@@ -0,0 +1,52 @@
+package models
+
+// Component types that can be added to an existing Go project
+const (
+	ComponentUtility = "utility" // A utility package, such as cache
+	ComponentOAuth   = "oauth"   // An OAuth login provider, such as github
+	ComponentEntity  = "entity"  // A CRUD entity with its repository, service, controller and routes
+)
+
+// Actions taken on a file when a component is added
+const (
+	ComponentFileCreated   = "created"   // The file is new
+	ComponentFileMerged    = "merged"    // Lines were inserted at the file's anchors
+	ComponentFileUnchanged = "unchanged" // The file already holds what the component needs
+)
+
+// ComponentRequest asks for a component to be added to a project
+type ComponentRequest struct {
+	Type   string `json:"type" binding:"required"` // utility, oauth or entity
+	Name   string `json:"name" binding:"required"` // Utility package, OAuth provider or entity name
+	DryRun bool   `json:"dry_run,omitempty"`       // Report the changes without applying them
+}
+
+// ComponentFile is a file a component touches
+type ComponentFile struct {
+	Path   string `json:"path"`
+	Action string `json:"action"` // created, merged or unchanged
+}
+
+// ComponentConflict is a file a component cannot be added to without
+// overwriting edits
+type ComponentConflict struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// ComponentResult reports what adding a component did, or would do
+type ComponentResult struct {
+	Component string              `json:"component"` // type:name
+	Applied   bool                `json:"applied"`   // False for dry runs and conflicts
+	Files     []ComponentFile     `json:"files"`
+	Conflicts []ComponentConflict `json:"conflicts,omitempty"`
+}
+
+// ComponentResponse represents the API response for adding a component
+type ComponentResponse struct {
+	Success  bool            `json:"success"`
+	Message  string          `json:"message,omitempty"`
+	Result   ComponentResult `json:"result"`
+	Files    []ProjectFile   `json:"files,omitempty"` // New contents of the created and merged files
+	Revision int             `json:"revision"`        // The project's revision afterwards
+}
@@ -0,0 +1,40 @@
+package models
+
+// HealthResponse represents the API response for the health check
+type HealthResponse struct {
+	Status    string `json:"status"`
+	Service   string `json:"service"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// BuildInfo identifies the running build. The values are injected with
+// -ldflags at build time; local builds report "dev".
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+}
+
+// Readiness check statuses
+const (
+	CheckUp   = "up"
+	CheckDown = "down"
+)
+
+// CheckResult is the outcome of one readiness check
+type CheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"` // up or down
+	Error      string  `json:"error,omitempty"`
+	DurationMS float64 `json:"duration_ms"`
+}
+
+// ReadinessResponse reports whether the server can take traffic, with the
+// result of each dependency check
+type ReadinessResponse struct {
+	Status string        `json:"status"` // ready or not_ready
+	Build  BuildInfo     `json:"build"`
+	Checks []CheckResult `json:"checks"`
+}
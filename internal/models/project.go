@@ -16,6 +16,9 @@ type ProjectRequest struct {
 	Language    ProjectLanguage `json:"language" binding:"required"`
 	Description string          `json:"description"`
 	Options     ProjectOptions  `json:"options"`
+	TeamID      string          `json:"team_id,omitempty"`          // Shares the project with a team the caller edits
+	PackID      string          `json:"template_pack_id,omitempty"` // Template pack whose options fill in unset ones
+	OwnerID     string          `json:"-"`                          // Set from the authenticated caller, never from the request body
 }
 
 // ProjectOptions contains language-specific configuration options
@@ -25,6 +28,7 @@ type ProjectOptions struct {
 	Database       string   `json:"database,omitempty"`       // postgresql, mysql, sqlite, mongodb
 	Authentication string   `json:"authentication,omitempty"` // jwt, oauth, basic
 	Utilities      []string `json:"utilities,omitempty"`      // Selected utility packages
+	Components     []string `json:"components,omitempty"`     // OAuth providers and entities added to the project, as type:name
 
 	// PHP-specific options
 	CIVersion string   `json:"ci_version,omitempty"` // 3, 4
@@ -40,10 +44,22 @@ type Project struct {
 	Description string          `json:"description"`
 	Options     ProjectOptions  `json:"options"`
 	Files       []ProjectFile   `json:"files"`
+	Revision    int             `json:"revision"` // Incremented whenever options or file contents change
+	OwnerID     string          `json:"owner_id,omitempty"`
+	TeamID      string          `json:"team_id,omitempty"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
 }
 
+// ProjectRevision is a snapshot of a project's options and files at one revision
+type ProjectRevision struct {
+	ProjectID string         `json:"project_id"`
+	Revision  int            `json:"revision"`
+	Options   ProjectOptions `json:"options"`
+	Files     []ProjectFile  `json:"files"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
 // ProjectFile represents a file in the generated project
 type ProjectFile struct {
 	Path        string `json:"path"`
@@ -53,10 +69,32 @@ type ProjectFile struct {
 
 // ProjectResponse represents the API response for project operations
 type ProjectResponse struct {
-	Success bool     `json:"success"`
-	Message string   `json:"message,omitempty"`
-	Project *Project `json:"project,omitempty"`
-	Error   string   `json:"error,omitempty"`
+	Success  bool       `json:"success"`
+	Message  string     `json:"message,omitempty"`
+	Project  *Project   `json:"project,omitempty"`
+	Projects []*Project `json:"projects,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// TemplatesResponse represents the API response listing available templates
+type TemplatesResponse struct {
+	Success   bool           `json:"success"`
+	Templates []TemplateInfo `json:"templates"`
+}
+
+// GenerateResponse represents the API response for project file generation
+type GenerateResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message,omitempty"`
+	Files   []ProjectFile `json:"files"`
+}
+
+// ArchiveURLResponse represents the API response for a pre-signed archive download
+type ArchiveURLResponse struct {
+	Success   bool      `json:"success"`
+	URL       string    `json:"url"`
+	Filename  string    `json:"filename"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // TemplateInfo represents information about available templates
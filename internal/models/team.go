@@ -0,0 +1,107 @@
+package models
+
+import "time"
+
+// TeamRole is a member's role in a team. Each role includes the ones below it.
+type TeamRole string
+
+const (
+	RoleViewer TeamRole = "viewer" // Read shared projects, template packs and chat sessions
+	RoleEditor TeamRole = "editor" // Also create and change them
+	RoleOwner  TeamRole = "owner"  // Also manage the team and its members
+)
+
+var teamRoleRank = map[TeamRole]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+// Valid reports whether r is one of the defined roles
+func (r TeamRole) Valid() bool {
+	return teamRoleRank[r] > 0
+}
+
+// Allows reports whether r grants everything required does. The empty role,
+// used for callers with no access at all, allows nothing.
+func (r TeamRole) Allows(required TeamRole) bool {
+	return teamRoleRank[r] > 0 && teamRoleRank[r] >= teamRoleRank[required]
+}
+
+// Team groups principals that share projects, template packs and chat sessions
+type Team struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Members   []TeamMember `json:"members"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// TeamMember is a principal's membership in a team
+type TeamMember struct {
+	PrincipalID string    `json:"principal_id"` // API key name or JWT subject
+	Role        TeamRole  `json:"role"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// Role returns the principal's role in the team, or "" for non-members
+func (t *Team) Role(principalID string) TeamRole {
+	for _, member := range t.Members {
+		if member.PrincipalID == principalID {
+			return member.Role
+		}
+	}
+	return ""
+}
+
+// TeamRequest represents a request to create or rename a team
+type TeamRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// TeamMemberRequest represents a request to add a member or change their role
+type TeamMemberRequest struct {
+	Role TeamRole `json:"role" binding:"required"`
+}
+
+// TeamResponse represents the API response for team operations
+type TeamResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+	Team    *Team  `json:"team,omitempty"`
+	Teams   []Team `json:"teams,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// TemplatePack is a curated preset: a template language with options chosen
+// up front, optionally shared with a team
+type TemplatePack struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Language    ProjectLanguage `json:"language"`
+	Options     ProjectOptions  `json:"options"`
+	TeamID      string          `json:"team_id,omitempty"`
+	OwnerID     string          `json:"owner_id,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// TemplatePackRequest represents a request to create or replace a template pack
+type TemplatePackRequest struct {
+	Name        string          `json:"name" binding:"required"`
+	Description string          `json:"description"`
+	Language    ProjectLanguage `json:"language" binding:"required"`
+	Options     ProjectOptions  `json:"options"`
+	TeamID      string          `json:"team_id,omitempty"` // Shares the pack with a team the caller edits
+	OwnerID     string          `json:"-"`                 // Set from the authenticated caller
+}
+
+// TemplatePackResponse represents the API response for template pack operations
+type TemplatePackResponse struct {
+	Success bool           `json:"success"`
+	Message string         `json:"message,omitempty"`
+	Pack    *TemplatePack  `json:"template_pack,omitempty"`
+	Packs   []TemplatePack `json:"template_packs,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Upgrade file statuses
+const (
+	UpgradeFileUnchanged = "unchanged" // The local file already matches the template
+	UpgradeFileUpdated   = "updated"   // Template changes taken into a file not changed locally
+	UpgradeFileAdded     = "added"     // New in the template
+	UpgradeFileRemoved   = "removed"   // Dropped by the template and not changed locally
+	UpgradeFileKept      = "kept"      // Local changes kept; the template did not change the file
+	UpgradeFileMerged    = "merged"    // Local and template changes merged without conflicts
+	UpgradeFileConflict  = "conflict"  // Local and template changes overlap
+)
+
+// UpgradeFile is what an upgrade did to one file
+type UpgradeFile struct {
+	Path      string   `json:"path"`
+	Status    string   `json:"status"`
+	Reason    string   `json:"reason,omitempty"`    // Why the file conflicts as a whole, such as one side deleting it
+	Conflicts []string `json:"conflicts,omitempty"` // Conflicting regions, with diff3-style markers as they appear in the file
+}
+
+// UpgradeReport describes a template upgrade of a project
+type UpgradeReport struct {
+	BaseRevision int            `json:"base_revision"`
+	Files        []UpgradeFile  `json:"files"`     // Files the upgrade changed or could not merge; unchanged and kept files are only counted
+	Summary      map[string]int `json:"summary"`   // Number of files per status
+	Conflicts    int            `json:"conflicts"` // Number of files with conflicts
+}
+
+// UpgradeResponse represents the API response for a template upgrade
+type UpgradeResponse struct {
+	Success  bool          `json:"success"`
+	Message  string        `json:"message,omitempty"`
+	Report   UpgradeReport `json:"report"`
+	Filename string        `json:"filename"`
+	Archive  []byte        `json:"archive,omitempty"` // ZIP of the merged project, base64-encoded in JSON
+	// URL downloads the archive instead, until ExpiresAt, with delivery=url
+	URL       string     `json:"url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
@@ -0,0 +1,57 @@
+// Package requestid gives every request an ID that is echoed to the client
+// and reported in error responses, so a failure can be matched to its logs.
+package requestid
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Header carries the request ID in requests and responses
+const Header = "X-Request-ID"
+
+// Longest client-supplied ID that is kept; longer ones are replaced
+const maxLength = 128
+
+type idKey struct{}
+
+// WithID returns a copy of ctx carrying id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the request ID stored in ctx, or an empty string
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// Middleware keeps the caller's X-Request-ID when it is a reasonable token,
+// so IDs assigned by a proxy carry through, and generates one otherwise
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(Header)
+		if !valid(id) {
+			id = uuid.New().String()
+		}
+
+		c.Header(Header, id)
+		c.Request = c.Request.WithContext(WithID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// valid accepts printable ASCII IDs without spaces, which are safe to log and echo
+func valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
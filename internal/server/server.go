@@ -0,0 +1,191 @@
+// Package server runs the application behind an explicit http.Server with
+// timeouts, optional TLS and graceful, signal-driven shutdown.
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config holds the listener settings
+type Config struct {
+	Addr string
+
+	// ReadHeaderTimeout bounds how long a client may take to send headers
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout bounds reading the whole request, body included
+	ReadTimeout time.Duration
+	// WriteTimeout bounds writing the response; it must cover the largest archive download
+	WriteTimeout time.Duration
+	// IdleTimeout closes keep-alive connections that stay idle
+	IdleTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests get to finish after a shutdown signal
+	ShutdownTimeout time.Duration
+
+	MaxHeaderBytes int
+
+	TLS TLSConfig
+}
+
+// TLSConfig enables HTTPS from certificate files, or from a certificate
+// generated at startup when SelfSigned is set
+type TLSConfig struct {
+	CertFile   string
+	KeyFile    string
+	SelfSigned bool
+}
+
+// Enabled reports whether the server listens with TLS
+func (c TLSConfig) Enabled() bool {
+	return c.SelfSigned || c.CertFile != "" || c.KeyFile != ""
+}
+
+// DefaultConfig returns timeouts suited to the API and archive downloads
+func DefaultConfig() Config {
+	return Config{
+		Addr:              ":8080",
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      2 * time.Minute,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+		MaxHeaderBytes:    1 << 20,
+	}
+}
+
+// ShutdownFunc runs once the server has stopped accepting requests, e.g. to
+// flush buffered telemetry
+type ShutdownFunc func(ctx context.Context) error
+
+// Server serves a handler until its context is cancelled
+type Server struct {
+	cfg        Config
+	httpServer *http.Server
+	onShutdown []ShutdownFunc
+	mu         sync.Mutex
+}
+
+func New(cfg Config, handler http.Handler) *Server {
+	return &Server{
+		cfg: cfg,
+		httpServer: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           handler,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			ReadTimeout:       cfg.ReadTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		},
+	}
+}
+
+// OnShutdown registers fn to run after connections have been drained
+func (s *Server) OnShutdown(fn ShutdownFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onShutdown = append(s.onShutdown, fn)
+}
+
+// Run listens on the configured address and serves until ctx is cancelled
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.cfg.Addr, err)
+	}
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves on listener until ctx is cancelled, then stops accepting
+// connections, waits up to ShutdownTimeout for in-flight requests and runs
+// the shutdown hooks. Connections still open after the timeout are closed.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	serve := s.httpServer.Serve
+	if s.cfg.TLS.Enabled() {
+		tlsConfig, err := s.tlsConfig()
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		s.httpServer.TLSConfig = tlsConfig
+		serve = func(listener net.Listener) error {
+			return s.httpServer.ServeTLS(listener, "", "")
+		}
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- serve(listener)
+	}()
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("server stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	log.Printf("🛑 Shutting down, draining connections (up to %s)...", s.cfg.ShutdownTimeout)
+
+	drainCtx, cancelDrain := s.shutdownContext()
+	defer cancelDrain()
+
+	var errs []error
+	if err := s.httpServer.Shutdown(drainCtx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain connections: %w", err))
+		s.httpServer.Close()
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	s.mu.Lock()
+	hooks := append([]ShutdownFunc(nil), s.onShutdown...)
+	s.mu.Unlock()
+
+	// Hooks get their own window so that a slow drain cannot starve a flush
+	hookCtx, cancelHooks := s.shutdownContext()
+	defer cancelHooks()
+
+	for _, hook := range hooks {
+		if err := hook(hookCtx); err != nil {
+			errs = append(errs, fmt.Errorf("shutdown hook failed: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Server) shutdownContext() (context.Context, context.CancelFunc) {
+	if s.cfg.ShutdownTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+}
+
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	var (
+		certificate tls.Certificate
+		err         error
+	)
+	if s.cfg.TLS.SelfSigned {
+		log.Printf("⚠️  Serving HTTPS with a self-signed certificate; do not use this in production")
+		certificate, err = SelfSignedCertificate()
+	} else {
+		certificate, err = tls.LoadX509KeyPair(s.cfg.TLS.CertFile, s.cfg.TLS.KeyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Maximum file size returned by the read_file tool
+const maxToolFileSize = 64 * 1024
+
+var (
+	// ErrUnknownTool is returned when a tool call names a tool that does not exist
+	ErrUnknownTool = apperror.Validation("unknown_tool", "unknown assistant tool")
+	// ErrProposalNotFound is returned when a change proposal does not exist
+	ErrProposalNotFound = apperror.NotFound("proposal_not_found", "change proposal not found")
+	// ErrProposalResolved is returned when a proposal was already applied or rejected
+	ErrProposalResolved = apperror.Conflict("proposal_resolved", "change proposal already resolved")
+	// ErrProposalConflict is returned when the file a patch targets changed after it was proposed
+	ErrProposalConflict = apperror.Conflict("proposal_conflict", "file changed since the patch was proposed")
+)
+
+// ProposalRetention controls how many change proposals are kept in memory
+type ProposalRetention struct {
+	TTL           time.Duration // Proposals older than this are dropped, resolved or not; 0 keeps them
+	MaxPerProject int           // A project's oldest proposals beyond this many are dropped; 0 keeps them all
+}
+
+// DefaultProposalRetention returns the retention used by NewAssistantTools
+func DefaultProposalRetention() ProposalRetention {
+	return ProposalRetention{
+		TTL:           24 * time.Hour,
+		MaxPerProject: 50,
+	}
+}
+
+// AssistantTools lets the chat assistant inspect a project's generated files
+// and propose changes. Proposals are only applied once the user confirms them.
+type AssistantTools struct {
+	projectService *ProjectService
+	retention      ProposalRetention
+	proposals      map[string]*models.ChangeProposal
+	mu             sync.RWMutex
+}
+
+func NewAssistantTools(projectService *ProjectService) *AssistantTools {
+	return NewAssistantToolsWithRetention(projectService, DefaultProposalRetention())
+}
+
+// NewAssistantToolsWithRetention creates assistant tools that keep proposals
+// as long as retention allows
+func NewAssistantToolsWithRetention(projectService *ProjectService, retention ProposalRetention) *AssistantTools {
+	return &AssistantTools{
+		projectService: projectService,
+		retention:      retention,
+		proposals:      make(map[string]*models.ChangeProposal),
+	}
+}
+
+type listFilesArgs struct {
+	Prefix string `json:"prefix"`
+}
+
+type readFileArgs struct {
+	Path string `json:"path"`
+}
+
+type proposeOptionsArgs struct {
+	Options models.ProjectOptions `json:"options"`
+	Reason  string                `json:"reason"`
+}
+
+type proposeFilePatchArgs struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Reason  string `json:"reason"`
+}
+
+// Definitions describes the available tools for an LLM tool-calling API
+func (t *AssistantTools) Definitions() []models.ToolDefinition {
+	stringProperty := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+
+	return []models.ToolDefinition{
+		{
+			Name:        models.ToolListFiles,
+			Description: "List the paths of the project's generated files",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"prefix": stringProperty("Only list files whose path starts with this prefix"),
+				},
+			},
+		},
+		{
+			Name:        models.ToolReadFile,
+			Description: "Read the content of a generated project file",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path": stringProperty("Path of the file as returned by list_files"),
+				},
+				"required": []string{"path"},
+			},
+		},
+		{
+			Name:        models.ToolProposeOptionsChange,
+			Description: "Propose new project options. The change is applied only after the user confirms it, and regenerates the project files.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"options": map[string]interface{}{
+						"type":        "object",
+						"description": "Options to change; omitted options keep their current value",
+						"properties": map[string]interface{}{
+							"framework":      stringProperty("HTTP framework (Go)"),
+							"database":       stringProperty("Database"),
+							"authentication": stringProperty("Authentication method (Go)"),
+							"utilities":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+							"ci_version":     stringProperty("CodeIgniter version (PHP)"),
+							"frontend":       stringProperty("Frontend framework (PHP)"),
+							"features":       map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+						},
+					},
+					"reason": stringProperty("Why the change is proposed"),
+				},
+				"required": []string{"options"},
+			},
+		},
+		{
+			Name:        models.ToolProposeFilePatch,
+			Description: "Propose new content for a project file, or a new file. The patch is applied only after the user confirms it.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":    stringProperty("Path of the file to change or create"),
+					"content": stringProperty("Complete new content of the file"),
+					"reason":  stringProperty("Why the change is proposed"),
+				},
+				"required": []string{"path", "content"},
+			},
+		},
+	}
+}
+
+// Execute runs a tool call against a project. Read-only tools return their
+// output directly; propose tools record a pending proposal.
+func (t *AssistantTools) Execute(ctx context.Context, projectID, sessionID string, call models.ToolCall) (*models.ToolResult, error) {
+	project, err := t.projectService.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ToolResult{Name: call.Name}
+
+	switch call.Name {
+	case models.ToolListFiles:
+		var args listFilesArgs
+		if err := decodeToolArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		files, err := t.projectFiles(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		paths := []string{}
+		for _, file := range files {
+			if !file.IsDirectory && strings.HasPrefix(file.Path, args.Prefix) {
+				paths = append(paths, file.Path)
+			}
+		}
+		sort.Strings(paths)
+		result.Output = paths
+
+	case models.ToolReadFile:
+		var args readFileArgs
+		if err := decodeToolArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		file, err := t.findFile(ctx, project, args.Path)
+		if err != nil {
+			return nil, err
+		}
+		content := file.Content
+		truncated := len(content) > maxToolFileSize
+		if truncated {
+			content = content[:maxToolFileSize]
+		}
+		result.Output = map[string]interface{}{
+			"path":      file.Path,
+			"content":   content,
+			"truncated": truncated,
+		}
+
+	case models.ToolProposeOptionsChange:
+		var args proposeOptionsArgs
+		if err := decodeToolArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		options := project.Options
+		mergeOptions(&options, args.Options)
+		result.Proposal = t.propose(&models.ChangeProposal{
+			ProjectID: project.ID,
+			SessionID: sessionID,
+			Kind:      models.ProposalKindOptions,
+			Summary:   proposalSummary(describeOptionsChange(project.Options, options), args.Reason),
+			Options:   &options,
+		})
+
+	case models.ToolProposeFilePatch:
+		var args proposeFilePatchArgs
+		if err := decodeToolArgs(call.Arguments, &args); err != nil {
+			return nil, err
+		}
+		path, err := cleanFilePath(args.Path)
+		if err != nil {
+			return nil, err
+		}
+		patch := &models.FilePatch{Path: path, Content: args.Content}
+		if file, err := t.findFile(ctx, project, path); err == nil {
+			patch.BaseChecksum = checksum(file.Content)
+		}
+		result.Proposal = t.propose(&models.ChangeProposal{
+			ProjectID: project.ID,
+			SessionID: sessionID,
+			Kind:      models.ProposalKindPatch,
+			Summary:   proposalSummary("Update "+path, args.Reason),
+			Patch:     patch,
+		})
+
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTool, call.Name)
+	}
+
+	return result, nil
+}
+
+// ListProposals returns a project's change proposals, oldest first
+func (t *AssistantTools) ListProposals(ctx context.Context, projectID string) []models.ChangeProposal {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	now := time.Now()
+	proposals := []models.ChangeProposal{}
+	for _, proposal := range t.proposals {
+		if proposal.ProjectID == projectID && !t.expired(proposal, now) {
+			proposals = append(proposals, *proposal)
+		}
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].CreatedAt.Before(proposals[j].CreatedAt)
+	})
+
+	return proposals
+}
+
+// ConfirmProposal applies a pending proposal to its project
+func (t *AssistantTools) ConfirmProposal(ctx context.Context, projectID, proposalID string) (*models.ChangeProposal, *models.Project, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	proposal, err := t.pendingProposal(projectID, proposalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var project *models.Project
+	switch proposal.Kind {
+	case models.ProposalKindOptions:
+		project, err = t.projectService.UpdateProjectOptions(ctx, projectID, *proposal.Options)
+	case models.ProposalKindPatch:
+		project, err = t.projectService.GetProject(ctx, projectID)
+		if err == nil {
+			err = t.checkPatchBase(ctx, project, proposal.Patch)
+		}
+		if err == nil {
+			project, err = t.projectService.UpdateProjectFile(ctx, projectID, proposal.Patch.Path, proposal.Patch.Content)
+		}
+	default:
+		err = fmt.Errorf("unsupported proposal kind: %s", proposal.Kind)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resolveProposal(proposal, models.ProposalApplied)
+	confirmed := *proposal
+	return &confirmed, project, nil
+}
+
+// RejectProposal discards a pending proposal without changing the project
+func (t *AssistantTools) RejectProposal(ctx context.Context, projectID, proposalID string) (*models.ChangeProposal, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	proposal, err := t.pendingProposal(projectID, proposalID)
+	if err != nil {
+		return nil, err
+	}
+
+	resolveProposal(proposal, models.ProposalRejected)
+	rejected := *proposal
+	return &rejected, nil
+}
+
+// pendingProposal looks up a proposal that can still be resolved. The caller must hold the write lock.
+func (t *AssistantTools) pendingProposal(projectID, proposalID string) (*models.ChangeProposal, error) {
+	proposal, exists := t.proposals[proposalID]
+	if !exists || proposal.ProjectID != projectID || t.expired(proposal, time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrProposalNotFound, proposalID)
+	}
+	if proposal.Status != models.ProposalPending {
+		return nil, fmt.Errorf("%w: %s is %s", ErrProposalResolved, proposalID, proposal.Status)
+	}
+	return proposal, nil
+}
+
+func (t *AssistantTools) checkPatchBase(ctx context.Context, project *models.Project, patch *models.FilePatch) error {
+	file, err := t.findFile(ctx, project, patch.Path)
+	switch {
+	case err != nil && patch.BaseChecksum == "":
+		return nil // New file that still does not exist
+	case err != nil:
+		return fmt.Errorf("%w: %s was removed", ErrProposalConflict, patch.Path)
+	case checksum(file.Content) != patch.BaseChecksum:
+		return fmt.Errorf("%w: %s", ErrProposalConflict, patch.Path)
+	}
+	return nil
+}
+
+func (t *AssistantTools) propose(proposal *models.ChangeProposal) *models.ChangeProposal {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	proposal.ID = uuid.New().String()
+	proposal.Status = models.ProposalPending
+	proposal.CreatedAt = time.Now()
+	t.proposals[proposal.ID] = proposal
+	t.expireLocked(proposal.CreatedAt)
+	t.capLocked(proposal.ProjectID)
+
+	pending := *proposal
+	return &pending
+}
+
+// ExpireProposals drops proposals older than the retention TTL and returns
+// how many were dropped
+func (t *AssistantTools) ExpireProposals(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.expireLocked(now)
+}
+
+// expireLocked drops expired proposals. The caller must hold the write lock.
+func (t *AssistantTools) expireLocked(now time.Time) int {
+	expired := 0
+	for id, proposal := range t.proposals {
+		if t.expired(proposal, now) {
+			delete(t.proposals, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// capLocked drops a project's oldest proposals beyond the retention limit.
+// The caller must hold the write lock.
+func (t *AssistantTools) capLocked(projectID string) {
+	if t.retention.MaxPerProject <= 0 {
+		return
+	}
+
+	var proposals []*models.ChangeProposal
+	for _, proposal := range t.proposals {
+		if proposal.ProjectID == projectID {
+			proposals = append(proposals, proposal)
+		}
+	}
+	if len(proposals) <= t.retention.MaxPerProject {
+		return
+	}
+
+	sort.Slice(proposals, func(i, j int) bool {
+		return proposals[i].CreatedAt.Before(proposals[j].CreatedAt)
+	})
+	for _, proposal := range proposals[:len(proposals)-t.retention.MaxPerProject] {
+		delete(t.proposals, proposal.ID)
+	}
+}
+
+func (t *AssistantTools) expired(proposal *models.ChangeProposal, now time.Time) bool {
+	return t.retention.TTL > 0 && now.Sub(proposal.CreatedAt) > t.retention.TTL
+}
+
+// projectFiles returns a project's files, generating them on first use
+func (t *AssistantTools) projectFiles(ctx context.Context, project *models.Project) ([]models.ProjectFile, error) {
+	if len(project.Files) == 0 {
+		if _, err := t.projectService.GenerateProjectFiles(ctx, project); err != nil {
+			return nil, fmt.Errorf("failed to generate project files: %w", err)
+		}
+	}
+	return project.Files, nil
+}
+
+func (t *AssistantTools) findFile(ctx context.Context, project *models.Project, path string) (*models.ProjectFile, error) {
+	files, err := t.projectFiles(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if files[i].Path == path && !files[i].IsDirectory {
+			return &files[i], nil
+		}
+	}
+	return nil, apperror.NotFound("file_not_found", fmt.Sprintf("file not found: %s", path))
+}
+
+func decodeToolArgs(raw json.RawMessage, args interface{}) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw, args); err != nil {
+		return fmt.Errorf("%w: %v", apperror.InvalidField("arguments", "invalid tool arguments"), err)
+	}
+	return nil
+}
+
+func resolveProposal(proposal *models.ChangeProposal, status string) {
+	now := time.Now()
+	proposal.Status = status
+	proposal.ResolvedAt = &now
+}
+
+func describeOptionsChange(before, after models.ProjectOptions) string {
+	var changes []string
+	add := func(name, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %s → %s", name, from, to))
+		}
+	}
+	add("framework", before.Framework, after.Framework)
+	add("database", before.Database, after.Database)
+	add("authentication", before.Authentication, after.Authentication)
+	add("ci_version", before.CIVersion, after.CIVersion)
+	add("frontend", before.Frontend, after.Frontend)
+	add("utilities", strings.Join(before.Utilities, ","), strings.Join(after.Utilities, ","))
+	add("features", strings.Join(before.Features, ","), strings.Join(after.Features, ","))
+
+	if len(changes) == 0 {
+		return "No option changes"
+	}
+	return "Change " + strings.Join(changes, ", ")
+}
+
+func proposalSummary(summary, reason string) string {
+	if reason = strings.TrimSpace(reason); reason != "" {
+		return summary + ": " + reason
+	}
+	return summary
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,117 +1,372 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"sync"
 	"time"
 
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/logging"
 	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+	"boilerplate-blueprint/internal/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrIncompleteDraft is returned when a conversation has not gathered enough
+// configuration to create a project
+var ErrIncompleteDraft = apperror.Validation("incomplete_draft", "conversation has not settled on a project language")
+
 type ChatService struct {
-	conversations map[string]*models.ChatHistory
-	mu            sync.RWMutex
+	sessions        map[string]*models.ChatHistory // Keyed by session ID
+	projectSessions map[string]string              // Project ID to its linked session ID
+	retention       ChatRetention
+	summarizer      ChatSummarizer
+	tools           *AssistantTools
+	store           storage.Store // Optional; state is only kept in memory when nil
+	observer        Observer      // Optional
+	mu              sync.RWMutex
 }
 
 func NewChatService() *ChatService {
+	return NewChatServiceWithRetention(DefaultChatRetention())
+}
+
+func NewChatServiceWithRetention(retention ChatRetention) *ChatService {
 	return &ChatService{
-		conversations: make(map[string]*models.ChatHistory),
+		sessions:        make(map[string]*models.ChatHistory),
+		projectSessions: make(map[string]string),
+		retention:       retention,
+		summarizer:      ruleBasedSummarizer{},
 	}
 }
 
-func (s *ChatService) ProcessMessage(req *models.ChatRequest) (*models.ChatResponse, error) {
+func (s *ChatService) ProcessMessage(ctx context.Context, req *models.ChatRequest) (response *models.ChatResponse, err error) {
+	ctx, span := tracing.Start(ctx, "chat.process_message", attribute.String("project.id", req.ProjectID))
+	defer func() {
+		if response != nil {
+			span.SetAttributes(
+				attribute.String("chat.session_id", response.SessionID),
+				attribute.Int("chat.suggestions", len(response.Suggestions)),
+			)
+		}
+		tracing.End(span, err)
+	}()
+	defer func(start time.Time) {
+		duration := time.Since(start)
+		if observer := s.currentObserver(); observer != nil {
+			observer.MessageProcessed(response, duration, err)
+		}
+
+		logger := logging.FromContext(ctx).With(slog.String("project_id", req.ProjectID))
+		if err != nil {
+			logger.Error("chat message failed", "session_id", req.SessionID, "error", err)
+			return
+		}
+		logger.Info("chat message processed",
+			"session_id", response.SessionID,
+			"suggestions", len(response.Suggestions),
+			"tool_results", len(response.ToolResults),
+			"duration_ms", float64(duration.Microseconds())/1000,
+		)
+	}(time.Now())
+
+	return s.processMessage(ctx, req)
+}
+
+func (s *ChatService) processMessage(ctx context.Context, req *models.ChatRequest) (*models.ChatResponse, error) {
+	sessionID, projectID, err := s.resolveSession(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create user message
 	userMessage := &models.ChatMessage{
 		ID:        uuid.New().String(),
 		Role:      "user",
 		Content:   req.Message,
-		ProjectID: req.ProjectID,
+		SessionID: sessionID,
+		ProjectID: projectID,
 		CreatedAt: time.Now(),
 	}
 
 	// Store user message
-	s.storeMessage(req.ProjectID, userMessage)
+	if err := s.storeMessage(ctx, sessionID, userMessage); err != nil {
+		return nil, err
+	}
 
 	// Process the message and generate AI response
-	assistantMessage, suggestions, err := s.generateAIResponse(req, userMessage)
+	assistantMessage, suggestions, toolResults, err := s.generateAIResponse(ctx, req, userMessage)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate AI response: %w", err)
 	}
 
 	// Store assistant message
-	s.storeMessage(req.ProjectID, assistantMessage)
+	if err := s.storeMessage(ctx, sessionID, assistantMessage); err != nil {
+		return nil, err
+	}
+
+	// Fold applicable suggestions into the conversation's draft configuration
+	s.applySuggestions(ctx, sessionID, suggestions)
 
 	return &models.ChatResponse{
 		Success:     true,
+		SessionID:   sessionID,
 		Message:     assistantMessage,
 		Suggestions: suggestions,
+		ToolResults: toolResults,
 	}, nil
 }
 
-func (s *ChatService) GetChatHistory(projectID string) (*models.ChatHistory, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// GetChatHistory returns the conversation linked to a project. An empty,
+// unsaved history is returned when the project has no conversation yet.
+func (s *ChatService) GetChatHistory(ctx context.Context, projectID string) (*models.ChatHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if projectID == "" {
-		// Return all conversations or create a general one
-		projectID = "general"
+	if history, exists := s.projectSessionLocked(ctx, projectID); exists {
+		return history, nil
 	}
 
-	history, exists := s.conversations[projectID]
-	if !exists {
-		// Create new conversation
-		history = &models.ChatHistory{
-			ProjectID: projectID,
-			Messages:  []models.ChatMessage{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+	return &models.ChatHistory{
+		ProjectID: projectID,
+		Messages:  []models.ChatMessage{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// resolveSession finds the session a message belongs to. An explicit session
+// ID must exist; the caller is expected to have checked access to it.
+// Otherwise the session linked to the project is used when it is shared the
+// same way the new one would be — with the same team, or privately by the
+// same owner — and a new session is started when there is none.
+func (s *ChatService) resolveSession(ctx context.Context, req *models.ChatRequest) (string, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.SessionID != "" {
+		history, exists := s.sessionLocked(ctx, req.SessionID)
+		if !exists {
+			return "", "", fmt.Errorf("%w: %s", ErrSessionNotFound, req.SessionID)
+		}
+		if req.ProjectID != "" && history.ProjectID != req.ProjectID {
+			s.linkSessionLocked(history, req.ProjectID)
+			if err := s.persistLocked(ctx, history); err != nil {
+				return "", "", err
+			}
 		}
+		return history.SessionID, history.ProjectID, nil
 	}
 
-	return history, nil
+	if req.ProjectID != "" {
+		if history, exists := s.projectSessionLocked(ctx, req.ProjectID); exists && history.TeamID == req.TeamID &&
+			(req.TeamID != "" || history.OwnerID == req.OwnerID) {
+			return history.SessionID, history.ProjectID, nil
+		}
+	}
+
+	history := s.newSessionLocked("", req.ProjectID, req.OwnerID, req.TeamID)
+	if err := s.persistLocked(ctx, history); err != nil {
+		return "", "", err
+	}
+	s.sessionCreatedLocked()
+	return history.SessionID, history.ProjectID, nil
 }
 
-func (s *ChatService) storeMessage(projectID string, message *models.ChatMessage) {
+func (s *ChatService) storeMessage(ctx context.Context, sessionID string, message *models.ChatMessage) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if projectID == "" {
-		projectID = "general"
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	if history.Title == defaultSessionTitle && message.Role == "user" {
+		history.Title = titleFromMessage(message.Content)
 	}
 
-	history, exists := s.conversations[projectID]
+	history.Messages = append(history.Messages, *message)
+	history.UpdatedAt = time.Now()
+	s.trimLocked(ctx, history)
+	return s.persistLocked(ctx, history)
+}
+
+func (s *ChatService) applySuggestions(ctx context.Context, sessionID string, suggestions []models.ProjectSuggestion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
 	if !exists {
-		history = &models.ChatHistory{
-			ProjectID: projectID,
-			Messages:  []models.ChatMessage{},
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		return
+	}
+	if history.Draft == nil {
+		history.Draft = &models.ProjectRequest{}
+	}
+
+	for _, suggestion := range suggestions {
+		if !suggestion.Apply {
+			continue
 		}
-		s.conversations[projectID] = history
+		applySuggestion(history.Draft, suggestion)
 	}
 
-	history.Messages = append(history.Messages, *message)
+	if err := s.persistLocked(ctx, history); err != nil {
+		logging.FromContext(ctx).Warn("failed to save chat draft", "session_id", sessionID, "error", err)
+	}
+}
+
+func applySuggestion(draft *models.ProjectRequest, suggestion models.ProjectSuggestion) {
+	switch suggestion.Type {
+	case "language":
+		draft.Language = models.ProjectLanguage(suggestion.Value)
+	case "feature":
+		draft.Options.Features = appendUnique(draft.Options.Features, suggestion.Value)
+	case "utility":
+		draft.Options.Utilities = appendUnique(draft.Options.Utilities, suggestion.Value)
+	default:
+		applySuggestionToOptions(&draft.Options, suggestion)
+	}
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// DraftProjectRequest builds a project request from the configuration gathered
+// in a conversation, with any non-empty fields of req taking precedence
+func (s *ChatService) DraftProjectRequest(ctx context.Context, req *models.ChatProjectRequest) (*models.ProjectRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, req.SessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, req.SessionID)
+	}
+
+	draft := &models.ProjectRequest{}
+	if history.Draft != nil {
+		draft.Language = history.Draft.Language
+		draft.Options = history.Draft.Options
+		draft.Options.Utilities = append([]string(nil), history.Draft.Options.Utilities...)
+		draft.Options.Features = append([]string(nil), history.Draft.Options.Features...)
+	}
+
+	draft.Name = req.Name
+	draft.Description = req.Description
+	if req.Language != "" {
+		draft.Language = req.Language
+	}
+	mergeOptions(&draft.Options, req.Options)
+
+	if draft.Language == "" {
+		return nil, ErrIncompleteDraft
+	}
+
+	return draft, nil
+}
+
+func mergeOptions(dst *models.ProjectOptions, src models.ProjectOptions) {
+	if src.Framework != "" {
+		dst.Framework = src.Framework
+	}
+	if src.Database != "" {
+		dst.Database = src.Database
+	}
+	if src.Authentication != "" {
+		dst.Authentication = src.Authentication
+	}
+	if len(src.Utilities) > 0 {
+		dst.Utilities = src.Utilities
+	}
+	if src.CIVersion != "" {
+		dst.CIVersion = src.CIVersion
+	}
+	if src.Frontend != "" {
+		dst.Frontend = src.Frontend
+	}
+	if len(src.Features) > 0 {
+		dst.Features = src.Features
+	}
+}
+
+// AttachProject links a conversation to a project created from it and
+// records the creation as an assistant message
+func (s *ChatService) AttachProject(ctx context.Context, sessionID string, project *models.Project) (*models.ChatResponse, error) {
+	if project == nil || project.ID == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	s.linkSessionLocked(history, project.ID)
+	history.Draft = nil
+
+	assistantMessage := &models.ChatMessage{
+		ID:        uuid.New().String(),
+		Role:      "assistant",
+		Content:   fmt.Sprintf("I've created the project **%s** (%s) from our conversation. You can now generate its files or download it as a ZIP archive.", project.Name, project.Language),
+		SessionID: history.SessionID,
+		ProjectID: project.ID,
+		CreatedAt: time.Now(),
+	}
+	history.Messages = append(history.Messages, *assistantMessage)
 	history.UpdatedAt = time.Now()
+	s.trimLocked(ctx, history)
+	if err := s.persistLocked(ctx, history); err != nil {
+		return nil, err
+	}
+
+	return &models.ChatResponse{
+		Success:   true,
+		SessionID: history.SessionID,
+		Message:   assistantMessage,
+		Project:   project,
+	}, nil
 }
 
-func (s *ChatService) generateAIResponse(req *models.ChatRequest, userMessage *models.ChatMessage) (*models.ChatMessage, []models.ProjectSuggestion, error) {
+func (s *ChatService) generateAIResponse(ctx context.Context, req *models.ChatRequest, userMessage *models.ChatMessage) (*models.ChatMessage, []models.ProjectSuggestion, []models.ToolResult, error) {
 	// For now, we'll create a simple rule-based response system
 	// In a real implementation, this would integrate with OpenAI API
+	_, span := tracing.Start(ctx, "chat.generate_response", attribute.String("chat.responder", "rule_based"))
+	defer span.End()
 
 	response, suggestions := s.generateRuleBasedResponse(req.Message, req.Context)
 
+	// Answer questions about a linked project with the assistant tools
+	toolResults, toolResponse := s.runAssistantTools(ctx, userMessage, suggestions)
+	if toolResponse != "" {
+		response = toolResponse
+	}
+
 	assistantMessage := &models.ChatMessage{
-		ID:        uuid.New().String(),
-		Role:      "assistant",
-		Content:   response,
-		ProjectID: req.ProjectID,
-		CreatedAt: time.Now(),
+		ID:          uuid.New().String(),
+		Role:        "assistant",
+		Content:     response,
+		SessionID:   userMessage.SessionID,
+		ProjectID:   userMessage.ProjectID,
+		Suggestions: suggestions,
+		CreatedAt:   time.Now(),
 	}
 
-	return assistantMessage, suggestions, nil
+	return assistantMessage, suggestions, toolResults, nil
 }
 
 func (s *ChatService) generateRuleBasedResponse(message, context string) (string, []models.ProjectSuggestion) {
@@ -218,16 +473,23 @@ func (s *ChatService) generateRuleBasedResponse(message, context string) (string
 	return responseText, suggestions
 }
 
-// Mock function for future OpenAI integration
-func (s *ChatService) callOpenAI(messages []models.ChatMessage, context string) (string, error) {
+// Mock function for future OpenAI integration. LLM calls are traced as
+// "llm.chat" spans, so time spent waiting on the provider shows up in traces.
+func (s *ChatService) callOpenAI(ctx context.Context, messages []models.ChatMessage, chatContext string) (reply string, err error) {
+	_, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.provider", "openai"),
+		attribute.Int("llm.messages", len(messages)),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	// This would integrate with OpenAI API
 	// For now, return a placeholder
 	return "OpenAI integration not yet implemented", nil
 }
 
 // Helper function to format chat context for AI
-func (s *ChatService) formatContextForAI(projectID string) string {
-	history, _ := s.GetChatHistory(projectID)
+func (s *ChatService) formatContextForAI(ctx context.Context, sessionID string) string {
+	history, _ := s.GetSessionHistory(ctx, sessionID)
 	if history == nil || len(history.Messages) == 0 {
 		return "New conversation"
 	}
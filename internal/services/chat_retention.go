@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	summaryHeader     = "Summary of earlier conversation:"
+	maxSummaryTopics  = 20
+	maxSummaryExcerpt = 120
+)
+
+// ChatRetention controls how much chat history is kept in memory
+type ChatRetention struct {
+	MaxMessages   int           // Messages kept per session, including the summary; 0 disables trimming
+	IdleTimeout   time.Duration // Sessions idle for longer are expired; 0 disables expiry
+	SweepInterval time.Duration // How often the janitor looks for idle sessions
+}
+
+// DefaultChatRetention returns the retention used by NewChatService
+func DefaultChatRetention() ChatRetention {
+	return ChatRetention{
+		MaxMessages:   200,
+		IdleTimeout:   24 * time.Hour,
+		SweepInterval: 10 * time.Minute,
+	}
+}
+
+// ChatSummarizer condenses messages that are about to be trimmed from a
+// session. The first message may be the summary from a previous trim.
+type ChatSummarizer interface {
+	Summarize(messages []models.ChatMessage) (string, error)
+}
+
+// SetSummarizer replaces the summarizer used when trimming history
+func (s *ChatService) SetSummarizer(summarizer ChatSummarizer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summarizer = summarizer
+}
+
+// StartJanitor expires idle sessions every SweepInterval until ctx is done
+func (s *ChatService) StartJanitor(ctx context.Context) {
+	if s.retention.IdleTimeout <= 0 || s.retention.SweepInterval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.retention.SweepInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if expired := s.ExpireIdleSessions(ctx, now); expired > 0 {
+					logging.FromContext(ctx).Info("expired idle chat sessions", "sessions", expired)
+				}
+			}
+		}
+	}()
+}
+
+// ExpireIdleSessions removes sessions that have been idle for longer than the
+// configured timeout and returns how many were removed. With a store
+// configured only cached sessions are swept; the store expires its own copies.
+func (s *ChatService) ExpireIdleSessions(ctx context.Context, now time.Time) int {
+	if s.retention.IdleTimeout <= 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := 0
+	for sessionID, history := range s.sessions {
+		if now.Sub(history.UpdatedAt) <= s.retention.IdleTimeout {
+			continue
+		}
+		if s.projectSessions[history.ProjectID] == sessionID {
+			delete(s.projectSessions, history.ProjectID)
+		}
+		delete(s.sessions, sessionID)
+		expired++
+	}
+	if s.store == nil {
+		s.sessionsRemovedLocked(expired)
+	}
+
+	return expired
+}
+
+// trimLocked condenses the oldest messages of a session into a summary
+// message once it exceeds the message limit. The caller must hold the write lock.
+func (s *ChatService) trimLocked(ctx context.Context, history *models.ChatHistory) {
+	limit := s.retention.MaxMessages
+	if limit <= 0 || len(history.Messages) <= limit {
+		return
+	}
+	if limit == 1 {
+		limit = 2 // Always keep the summary and the latest message
+	}
+
+	// Keep the newest limit-1 messages; everything before becomes the summary
+	cut := len(history.Messages) - (limit - 1)
+	condensed := history.Messages[:cut]
+
+	content, err := s.summarizer.Summarize(condensed)
+	if err != nil {
+		logging.FromContext(ctx).Warn("failed to summarise chat session, keeping the previous summary", "session_id", history.SessionID, "project_id", history.ProjectID, "error", err)
+		content = previousSummary(condensed)
+	}
+
+	summary := models.ChatMessage{
+		ID:        uuid.New().String(),
+		Role:      "system",
+		Content:   content,
+		SessionID: history.SessionID,
+		ProjectID: history.ProjectID,
+		CreatedAt: condensed[len(condensed)-1].CreatedAt,
+	}
+
+	trimmed := len(condensed)
+	if isSummary(condensed[0]) {
+		trimmed-- // The previous summary was not an original message
+	}
+	history.Trimmed += trimmed
+
+	kept := make([]models.ChatMessage, 0, limit)
+	kept = append(kept, summary)
+	kept = append(kept, history.Messages[cut:]...)
+	history.Messages = kept
+}
+
+func isSummary(message models.ChatMessage) bool {
+	return message.Role == "system" && strings.HasPrefix(message.Content, summaryHeader)
+}
+
+func previousSummary(messages []models.ChatMessage) string {
+	if len(messages) > 0 && isSummary(messages[0]) {
+		return messages[0].Content
+	}
+	return summaryHeader
+}
+
+// ruleBasedSummarizer keeps an excerpt of each user message, folding in the
+// topics of any previous summary and dropping the oldest beyond a fixed count
+type ruleBasedSummarizer struct{}
+
+func (ruleBasedSummarizer) Summarize(messages []models.ChatMessage) (string, error) {
+	var topics []string
+	for _, message := range messages {
+		switch {
+		case isSummary(message):
+			for _, line := range strings.Split(message.Content, "\n")[1:] {
+				if line = strings.TrimSpace(line); line != "" {
+					topics = append(topics, line)
+				}
+			}
+		case message.Role == "user":
+			topics = append(topics, fmt.Sprintf("- User: %s", excerpt(message.Content, maxSummaryExcerpt)))
+		}
+	}
+
+	if len(topics) > maxSummaryTopics {
+		topics = topics[len(topics)-maxSummaryTopics:]
+	}
+
+	return strings.Join(append([]string{summaryHeader}, topics...), "\n"), nil
+}
+
+func excerpt(content string, limit int) string {
+	content = strings.Join(strings.Fields(content), " ")
+	runes := []rune(content)
+	if len(runes) <= limit {
+		return content
+	}
+	return strings.TrimSpace(string(runes[:limit-3])) + "..."
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSessionTitle = "New conversation"
+	maxSessionTitle     = 60
+
+	// DefaultMessagePageSize is used when a history page request has no limit
+	DefaultMessagePageSize = 50
+	// MaxMessagePageSize caps the number of messages returned in one page
+	MaxMessagePageSize = 200
+)
+
+// ErrSessionNotFound is returned when a chat session does not exist
+var ErrSessionNotFound = apperror.NotFound("chat_session_not_found", "chat session not found")
+
+// CreateSession starts a new, empty chat session, optionally linked to a project
+func (s *ChatService) CreateSession(ctx context.Context, req *models.ChatSessionRequest) (*models.ChatSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history := s.newSessionLocked(strings.TrimSpace(req.Title), req.ProjectID, req.OwnerID, req.TeamID)
+	if err := s.persistLocked(ctx, history); err != nil {
+		s.forgetLocked(history.SessionID)
+		return nil, err
+	}
+	s.sessionCreatedLocked()
+	session := sessionSummary(history)
+	return &session, nil
+}
+
+// GetSession returns the summary of a chat session
+func (s *ChatService) GetSession(ctx context.Context, sessionID string) (*models.ChatSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	session := sessionSummary(history)
+	return &session, nil
+}
+
+// GetSessionHistory returns the full history of a chat session
+func (s *ChatService) GetSessionHistory(ctx context.Context, sessionID string) (*models.ChatHistory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	return history, nil
+}
+
+// ListSessions returns all chat sessions, most recently active first
+func (s *ChatService) ListSessions(ctx context.Context) []models.ChatSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions, err := s.listSessionsLocked(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error("failed to list chat sessions", "error", err)
+		return []models.ChatSession{}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+
+	return sessions
+}
+
+// RenameSession changes the title of a chat session
+func (s *ChatService) RenameSession(ctx context.Context, sessionID, title string) (*models.ChatSession, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, apperror.InvalidField("title", "session title cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	history.Title = truncateTitle(title)
+	history.UpdatedAt = time.Now()
+	if err := s.persistLocked(ctx, history); err != nil {
+		return nil, err
+	}
+
+	session := sessionSummary(history)
+	return &session, nil
+}
+
+// LinkSession links a chat session to a project, replacing any previous link.
+// An empty project ID unlinks the session.
+func (s *ChatService) LinkSession(ctx context.Context, sessionID, projectID string) (*models.ChatSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	s.linkSessionLocked(history, projectID)
+	history.UpdatedAt = time.Now()
+	if err := s.persistLocked(ctx, history); err != nil {
+		return nil, err
+	}
+
+	session := sessionSummary(history)
+	return &session, nil
+}
+
+// DeleteSession removes a chat session and its messages
+func (s *ChatService) DeleteSession(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.sessionLocked(ctx, sessionID); !exists {
+		return fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	if err := s.removeLocked(ctx, sessionID); err != nil {
+		return err
+	}
+	s.sessionsRemovedLocked(1)
+	return nil
+}
+
+// GetSessionMessages returns a page of a session's messages, oldest first
+func (s *ChatService) GetSessionMessages(ctx context.Context, sessionID string, offset, limit int) (*models.ChatMessagePage, error) {
+	if offset < 0 {
+		return nil, apperror.InvalidField("offset", "offset cannot be negative")
+	}
+	if limit <= 0 {
+		limit = DefaultMessagePageSize
+	}
+	if limit > MaxMessagePageSize {
+		limit = MaxMessagePageSize
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	total := len(history.Messages)
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	messages := make([]models.ChatMessage, end-start)
+	copy(messages, history.Messages[start:end])
+
+	return &models.ChatMessagePage{
+		SessionID: sessionID,
+		Messages:  messages,
+		Total:     total,
+		Offset:    offset,
+		Limit:     limit,
+	}, nil
+}
+
+// newSessionLocked creates and stores a new session. The caller must hold the write lock.
+func (s *ChatService) newSessionLocked(title, projectID, ownerID, teamID string) *models.ChatHistory {
+	if title == "" {
+		title = defaultSessionTitle
+	}
+
+	history := &models.ChatHistory{
+		SessionID: uuid.New().String(),
+		Title:     truncateTitle(title),
+		OwnerID:   ownerID,
+		TeamID:    teamID,
+		Messages:  []models.ChatMessage{},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	s.sessions[history.SessionID] = history
+	s.linkSessionLocked(history, projectID)
+
+	return history
+}
+
+// linkSessionLocked points a session at projectID and re-tags its messages.
+// The caller must hold the write lock.
+func (s *ChatService) linkSessionLocked(history *models.ChatHistory, projectID string) {
+	if history.ProjectID != "" && s.projectSessions[history.ProjectID] == history.SessionID {
+		delete(s.projectSessions, history.ProjectID)
+	}
+
+	history.ProjectID = projectID
+	if projectID != "" {
+		s.projectSessions[projectID] = history.SessionID
+	}
+
+	for i := range history.Messages {
+		history.Messages[i].ProjectID = projectID
+	}
+}
+
+func sessionSummary(history *models.ChatHistory) models.ChatSession {
+	return models.ChatSession{
+		ID:           history.SessionID,
+		Title:        history.Title,
+		ProjectID:    history.ProjectID,
+		OwnerID:      history.OwnerID,
+		TeamID:       history.TeamID,
+		MessageCount: len(history.Messages),
+		CreatedAt:    history.CreatedAt,
+		UpdatedAt:    history.UpdatedAt,
+	}
+}
+
+func titleFromMessage(content string) string {
+	title := strings.Join(strings.Fields(content), " ")
+	if title == "" {
+		return defaultSessionTitle
+	}
+	return truncateTitle(title)
+}
+
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= maxSessionTitle {
+		return title
+	}
+	return strings.TrimSpace(string(runes[:maxSessionTitle-3])) + "..."
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+)
+
+// SetStore persists chat histories in store. Reads go to the store so that
+// every instance sees the same conversations; memory only caches.
+func (s *ChatService) SetStore(store storage.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// sessionLocked returns a session, reloading it from the store when one is
+// configured. The caller must hold the write lock.
+func (s *ChatService) sessionLocked(ctx context.Context, sessionID string) (*models.ChatHistory, bool) {
+	if s.store == nil {
+		history, exists := s.sessions[sessionID]
+		return history, exists
+	}
+
+	history, err := s.store.GetChatHistory(ctx, sessionID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logging.FromContext(ctx).Warn("failed to load chat session", "session_id", sessionID, "error", err)
+		}
+		s.forgetLocked(sessionID)
+		return nil, false
+	}
+
+	s.cacheLocked(history)
+	return history, true
+}
+
+// projectSessionLocked returns the session linked to a project. The caller
+// must hold the write lock.
+func (s *ChatService) projectSessionLocked(ctx context.Context, projectID string) (*models.ChatHistory, bool) {
+	if s.store == nil {
+		sessionID, exists := s.projectSessions[projectID]
+		if !exists {
+			return nil, false
+		}
+		history, exists := s.sessions[sessionID]
+		return history, exists
+	}
+
+	history, err := s.store.GetProjectChatHistory(ctx, projectID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			logging.FromContext(ctx).Warn("failed to load chat for project", "project_id", projectID, "error", err)
+		}
+		return nil, false
+	}
+
+	s.cacheLocked(history)
+	return history, true
+}
+
+// listSessionsLocked returns session summaries from the store, or from
+// memory when there is none. The caller must hold the lock.
+func (s *ChatService) listSessionsLocked(ctx context.Context) ([]models.ChatSession, error) {
+	if s.store != nil {
+		return s.store.ListChatSessions(ctx)
+	}
+
+	sessions := make([]models.ChatSession, 0, len(s.sessions))
+	for _, history := range s.sessions {
+		sessions = append(sessions, sessionSummary(history))
+	}
+	return sessions, nil
+}
+
+// persistLocked writes a session to the store, if any. The session has already
+// changed in memory, so it is saved even when ctx is cancelled. The caller must
+// hold the lock.
+func (s *ChatService) persistLocked(ctx context.Context, history *models.ChatHistory) error {
+	if s.store == nil {
+		return nil
+	}
+
+	var expiresAt time.Time
+	if s.retention.IdleTimeout > 0 {
+		expiresAt = history.UpdatedAt.Add(s.retention.IdleTimeout)
+	}
+
+	if err := s.store.SaveChatHistory(context.WithoutCancel(ctx), history, expiresAt); err != nil {
+		return fmt.Errorf("failed to save chat session: %w", err)
+	}
+	return nil
+}
+
+// removeLocked deletes a session from memory and the store, even when ctx is
+// cancelled. The caller must hold the write lock.
+func (s *ChatService) removeLocked(ctx context.Context, sessionID string) error {
+	s.forgetLocked(sessionID)
+
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.DeleteChatHistory(context.WithoutCancel(ctx), sessionID); err != nil {
+		return fmt.Errorf("failed to delete chat session: %w", err)
+	}
+	return nil
+}
+
+// cacheLocked replaces the cached copy of a session. The caller must hold the write lock.
+func (s *ChatService) cacheLocked(history *models.ChatHistory) {
+	s.forgetLocked(history.SessionID)
+	s.sessions[history.SessionID] = history
+	if history.ProjectID != "" {
+		s.projectSessions[history.ProjectID] = history.SessionID
+	}
+}
+
+// forgetLocked drops a session from memory only. The caller must hold the write lock.
+func (s *ChatService) forgetLocked(sessionID string) {
+	if cached, exists := s.sessions[sessionID]; exists {
+		if s.projectSessions[cached.ProjectID] == sessionID {
+			delete(s.projectSessions, cached.ProjectID)
+		}
+		delete(s.sessions, sessionID)
+	}
+}
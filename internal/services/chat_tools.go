@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// Maximum number of paths listed in a chat reply
+const maxListedFiles = 50
+
+// Words ignored when matching a request like "show me the auth middleware" to a file
+var fileQueryStopWords = map[string]bool{
+	"show": true, "me": true, "the": true, "open": true, "read": true, "file": true,
+	"please": true, "can": true, "you": true, "what": true, "is": true, "in": true, "a": true,
+}
+
+// SetAssistantTools enables tool use for sessions linked to a project
+func (s *ChatService) SetAssistantTools(tools *AssistantTools) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tools = tools
+}
+
+// AssistantTools returns the tools enabled with SetAssistantTools, or nil
+func (s *ChatService) AssistantTools() *AssistantTools {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tools
+}
+
+// runAssistantTools maps requests about the linked project onto tool calls.
+// It returns the results and a reply describing them, or no reply when the
+// message did not call for a tool.
+func (s *ChatService) runAssistantTools(ctx context.Context, message *models.ChatMessage, suggestions []models.ProjectSuggestion) ([]models.ToolResult, string) {
+	tools := s.AssistantTools()
+	if tools == nil || message.ProjectID == "" {
+		return nil, ""
+	}
+
+	text := strings.ToLower(message.Content)
+
+	switch {
+	case strings.Contains(text, "list files") || strings.Contains(text, "what files") || strings.Contains(text, "show files"):
+		result, err := tools.Execute(ctx, message.ProjectID, message.SessionID, models.ToolCall{Name: models.ToolListFiles})
+		if err != nil {
+			return nil, "I couldn't list the project files: " + err.Error()
+		}
+		return []models.ToolResult{*result}, describeFileList(result.Output.([]string))
+
+	case strings.Contains(text, "show me") || strings.HasPrefix(text, "open ") || strings.HasPrefix(text, "read "):
+		listing, err := tools.Execute(ctx, message.ProjectID, message.SessionID, models.ToolCall{Name: models.ToolListFiles})
+		if err != nil {
+			return nil, "I couldn't look through the project files: " + err.Error()
+		}
+		path := bestMatchingFile(text, listing.Output.([]string))
+		if path == "" {
+			return []models.ToolResult{*listing}, "I couldn't find a file matching that in the project. Ask me to list files to see what was generated."
+		}
+		args, _ := json.Marshal(readFileArgs{Path: path})
+		result, err := tools.Execute(ctx, message.ProjectID, message.SessionID, models.ToolCall{Name: models.ToolReadFile, Arguments: args})
+		if err != nil {
+			return nil, "I couldn't read " + path + ": " + err.Error()
+		}
+		output := result.Output.(map[string]interface{})
+		return []models.ToolResult{*result}, fmt.Sprintf("Here is `%s`:\n\n```\n%s\n```", path, output["content"])
+	}
+
+	// Configuration suggestions for an existing project become an options proposal
+	var options models.ProjectOptions
+	for _, suggestion := range suggestions {
+		if suggestion.Type != "language" {
+			applySuggestionToOptions(&options, suggestion)
+		}
+	}
+	if options.Framework == "" && options.Database == "" && options.Authentication == "" &&
+		options.CIVersion == "" && options.Frontend == "" {
+		return nil, ""
+	}
+
+	args, _ := json.Marshal(proposeOptionsArgs{Options: options, Reason: "Requested in chat"})
+	result, err := tools.Execute(ctx, message.ProjectID, message.SessionID, models.ToolCall{Name: models.ToolProposeOptionsChange, Arguments: args})
+	if err != nil {
+		return nil, "I couldn't prepare that change: " + err.Error()
+	}
+
+	return []models.ToolResult{*result}, fmt.Sprintf("I've prepared a change to your project: %s. Nothing changes until you confirm proposal `%s`.",
+		result.Proposal.Summary, result.Proposal.ID)
+}
+
+func applySuggestionToOptions(options *models.ProjectOptions, suggestion models.ProjectSuggestion) {
+	switch suggestion.Type {
+	case "framework":
+		options.Framework = suggestion.Value
+	case "database":
+		options.Database = suggestion.Value
+	case "authentication":
+		options.Authentication = suggestion.Value
+	case "ci_version":
+		options.CIVersion = suggestion.Value
+	case "frontend":
+		options.Frontend = suggestion.Value
+	}
+}
+
+func describeFileList(paths []string) string {
+	if len(paths) == 0 {
+		return "The project has no generated files yet."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "The project has %d files:\n", len(paths))
+	for i, path := range paths {
+		if i == maxListedFiles {
+			fmt.Fprintf(&b, "- ... and %d more\n", len(paths)-maxListedFiles)
+			break
+		}
+		fmt.Fprintf(&b, "- `%s`\n", path)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// bestMatchingFile picks the path matching the most words of the request
+func bestMatchingFile(text string, paths []string) string {
+	var keywords []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '.' || r == '_' || r == '-')
+	}) {
+		if len(word) > 1 && !fileQueryStopWords[word] {
+			keywords = append(keywords, word)
+		}
+	}
+
+	best, bestScore := "", 0
+	for _, path := range paths {
+		lowerPath := strings.ToLower(path)
+		score := 0
+		for _, keyword := range keywords {
+			if strings.Contains(lowerPath, keyword) {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = path, score
+		}
+	}
+
+	return best
+}
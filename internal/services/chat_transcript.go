@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// TranscriptVersion is the transcript format written by ExportTranscript
+const TranscriptVersion = 1
+
+// ExportTranscript captures a chat session, including suggestions and the
+// draft configuration, in a form that ImportTranscript can restore
+func (s *ChatService) ExportTranscript(ctx context.Context, sessionID string) (*models.ChatTranscript, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	history, exists := s.sessionLocked(ctx, sessionID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrSessionNotFound, sessionID)
+	}
+
+	// Copy so the transcript is unaffected by later messages
+	snapshot := *history
+	snapshot.Messages = make([]models.ChatMessage, len(history.Messages))
+	copy(snapshot.Messages, history.Messages)
+	if history.Draft != nil {
+		draft := *history.Draft
+		snapshot.Draft = &draft
+	}
+
+	return &models.ChatTranscript{
+		Version:    TranscriptVersion,
+		ExportedAt: time.Now(),
+		History:    snapshot,
+	}, nil
+}
+
+// ImportTranscript restores an exported chat session so the conversation can
+// be continued. The original session ID is kept unless it is already in use.
+func (s *ChatService) ImportTranscript(ctx context.Context, transcript *models.ChatTranscript) (*models.ChatSession, error) {
+	if err := validateTranscript(transcript); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	imported := transcript.History
+	if _, taken := s.sessionLocked(ctx, imported.SessionID); imported.SessionID == "" || taken {
+		imported.SessionID = uuid.New().String()
+	}
+	if strings.TrimSpace(imported.Title) == "" {
+		imported.Title = defaultSessionTitle
+	}
+	imported.Title = truncateTitle(imported.Title)
+
+	now := time.Now()
+	if imported.CreatedAt.IsZero() {
+		imported.CreatedAt = now
+	}
+	imported.UpdatedAt = now // Imported sessions start a fresh idle period
+
+	history := &imported
+	history.Messages = make([]models.ChatMessage, len(transcript.History.Messages))
+	for i, message := range transcript.History.Messages {
+		if message.ID == "" {
+			message.ID = uuid.New().String()
+		}
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = imported.CreatedAt
+		}
+		message.SessionID = history.SessionID
+		history.Messages[i] = message
+	}
+
+	projectID := history.ProjectID
+	history.ProjectID = ""
+	s.sessions[history.SessionID] = history
+	s.linkSessionLocked(history, projectID)
+	s.trimLocked(ctx, history)
+	if err := s.persistLocked(ctx, history); err != nil {
+		s.forgetLocked(history.SessionID)
+		return nil, err
+	}
+	s.sessionCreatedLocked()
+
+	session := sessionSummary(history)
+	return &session, nil
+}
+
+func validateTranscript(transcript *models.ChatTranscript) error {
+	if transcript == nil {
+		return apperror.Validation("invalid_transcript", "transcript is required")
+	}
+	if transcript.Version != TranscriptVersion {
+		return apperror.InvalidField("version", fmt.Sprintf("unsupported transcript version: %d", transcript.Version))
+	}
+
+	for i, message := range transcript.History.Messages {
+		switch message.Role {
+		case "user", "assistant", "system":
+		default:
+			return apperror.InvalidField(fmt.Sprintf("history.messages[%d].role", i), fmt.Sprintf("message %d has unsupported role: %q", i, message.Role))
+		}
+		if strings.TrimSpace(message.Content) == "" {
+			return apperror.InvalidField(fmt.Sprintf("history.messages[%d].content", i), fmt.Sprintf("message %d has no content", i))
+		}
+	}
+
+	return nil
+}
+
+// RenderTranscriptMarkdown formats a transcript as a Markdown document
+// suitable for attaching to a ticket
+func RenderTranscriptMarkdown(transcript *models.ChatTranscript) string {
+	history := transcript.History
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", history.Title)
+	fmt.Fprintf(&b, "- **Session:** `%s`\n", history.SessionID)
+	if history.ProjectID != "" {
+		fmt.Fprintf(&b, "- **Project:** `%s`\n", history.ProjectID)
+	}
+	fmt.Fprintf(&b, "- **Started:** %s\n", formatTranscriptTime(history.CreatedAt))
+	fmt.Fprintf(&b, "- **Last activity:** %s\n", formatTranscriptTime(history.UpdatedAt))
+	fmt.Fprintf(&b, "- **Exported:** %s\n", formatTranscriptTime(transcript.ExportedAt))
+	if history.Trimmed > 0 {
+		fmt.Fprintf(&b, "- **Summarised messages:** %d\n", history.Trimmed)
+	}
+
+	if history.Draft != nil {
+		draft, err := json.MarshalIndent(history.Draft, "", "  ")
+		if err == nil {
+			fmt.Fprintf(&b, "\n## Draft configuration\n\n```json\n%s\n```\n", draft)
+		}
+	}
+
+	b.WriteString("\n## Conversation\n")
+	for _, message := range history.Messages {
+		fmt.Fprintf(&b, "\n### %s · %s\n\n", transcriptRole(message.Role), formatTranscriptTime(message.CreatedAt))
+		b.WriteString(strings.TrimSpace(message.Content))
+		b.WriteString("\n")
+
+		if len(message.Suggestions) > 0 {
+			b.WriteString("\n**Suggestions:**\n\n")
+			for _, suggestion := range message.Suggestions {
+				applied := ""
+				if suggestion.Apply {
+					applied = ", auto-applied"
+				}
+				fmt.Fprintf(&b, "- `%s` = `%s` (%.0f%% confidence%s) — %s\n",
+					suggestion.Type, suggestion.Value, suggestion.Confidence*100, applied, suggestion.Reason)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+func transcriptRole(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "system":
+		return "Summary"
+	default:
+		return role
+	}
+}
+
+func formatTranscriptTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
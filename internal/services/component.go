@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+)
+
+// ErrComponentLanguage is returned when a component is added to a project
+// whose template does not offer components
+var ErrComponentLanguage = apperror.Validation("component_language", "components can only be added to Go projects")
+
+// entityNamePattern matches entity names such as order, OrderItem or order_item
+var entityNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*([_-][A-Za-z0-9]+)*$`)
+
+// ComponentPlan is what adding a component changes in a project
+type ComponentPlan struct {
+	Result   models.ComponentResult
+	Files    []models.ProjectFile  // Created and merged files, with their new content
+	Options  models.ProjectOptions // The project's options, with the component recorded where they track it
+	Revision int                   // The project's revision once the plan is applied
+}
+
+// anchorInsert adds lines before an anchor comment in a file
+type anchorInsert struct {
+	path   string
+	anchor string
+	lines  []string
+}
+
+// RenderComponent renders a component against the project's options and
+// works out how its files fit into the project's current ones. New files
+// must not exist yet, or already match; route registrations and settings
+// are merged into existing files at their anchors. When that would
+// overwrite edits, the plan lists the conflicts and a component_conflict
+// error is returned with them.
+func (s *TemplateService) RenderComponent(ctx context.Context, project *models.Project, req *models.ComponentRequest) (*ComponentPlan, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if project.Language != models.LanguageGo {
+		return nil, fmt.Errorf("%w: project %s is %s", ErrComponentLanguage, project.ID, project.Language)
+	}
+
+	component := *req
+	if err := normalizeComponent(&component); err != nil {
+		return nil, err
+	}
+	spec := component.Type + ":" + component.Name
+	options := project.Options
+	options.Utilities = append([]string(nil), project.Options.Utilities...)
+	options.Components = append([]string(nil), project.Options.Components...)
+
+	// Render against the defaults for options the project leaves unset
+	rendered := *project
+	rendered.Options = options
+	s.ApplyDefaults(&rendered)
+	data := goTemplateData(&rendered)
+
+	// Utilities are recorded with the ones the project was generated with,
+	// other components separately so that generation can add them again
+	var rendering goComponent
+	switch component.Type {
+	case models.ComponentUtility:
+		rendering.files = []models.ProjectFile{goUtilityFile(data, component.Name)}
+		if !containsValue(options.Utilities, component.Name) {
+			options.Utilities = append(options.Utilities, component.Name)
+		}
+	case models.ComponentOAuth:
+		rendering = goOAuthComponent(data, component.Name, oauthProviders[component.Name])
+	case models.ComponentEntity:
+		rendering = goEntityComponent(data, component.Name)
+	}
+	if component.Type != models.ComponentUtility && !containsValue(options.Components, spec) {
+		options.Components = append(options.Components, spec)
+	}
+
+	plan := &ComponentPlan{
+		Result:   models.ComponentResult{Component: spec, Files: []models.ComponentFile{}},
+		Options:  options,
+		Revision: project.Revision,
+	}
+	planComponentFiles(plan, project.Files, rendering)
+	if len(plan.Result.Conflicts) > 0 {
+		plan.Files = nil
+		return plan, componentConflict(plan.Result.Conflicts)
+	}
+	return plan, nil
+}
+
+// ParseComponent reads a component recorded as type:name, such as
+// entity:order, and returns it with its type and name in canonical form
+func ParseComponent(spec string) (models.ComponentRequest, error) {
+	componentType, name, _ := strings.Cut(spec, ":")
+	req := models.ComponentRequest{Type: componentType, Name: name}
+	if err := normalizeComponent(&req); err != nil {
+		return models.ComponentRequest{}, err
+	}
+	return req, nil
+}
+
+// normalizeComponent checks that req names a component the Go template
+// offers and puts its type and name in the form components are recorded in
+func normalizeComponent(req *models.ComponentRequest) error {
+	req.Type = strings.ToLower(strings.TrimSpace(req.Type))
+	req.Name = strings.TrimSpace(req.Name)
+
+	switch req.Type {
+	case models.ComponentUtility:
+		req.Name = strings.ToLower(req.Name)
+		if !containsValue(goUtilities, req.Name) {
+			return apperror.InvalidField("name", fmt.Sprintf("unknown utility %q: expected one of %s", req.Name, strings.Join(goUtilities, ", ")))
+		}
+	case models.ComponentOAuth:
+		req.Name = strings.ToLower(req.Name)
+		if _, ok := oauthProviders[req.Name]; !ok {
+			return apperror.InvalidField("name", fmt.Sprintf("unknown OAuth provider %q: expected one of %s", req.Name, strings.Join(oauthProviderNames(), ", ")))
+		}
+	case models.ComponentEntity:
+		if !entityNamePattern.MatchString(req.Name) {
+			return apperror.InvalidField("name", fmt.Sprintf("invalid entity name %q: use letters and digits, optionally separated by _ or -", req.Name))
+		}
+		req.Name = snakeCase(exportedName(req.Name))
+	default:
+		return apperror.InvalidField("type", fmt.Sprintf("unknown component type %q: expected utility, oauth or entity", req.Type))
+	}
+	return nil
+}
+
+// addRecordedComponents adds the components project's options record to its
+// freshly generated files, the way AddComponent added them
+func (s *TemplateService) addRecordedComponents(ctx context.Context, project *models.Project, files []models.ProjectFile) ([]models.ProjectFile, error) {
+	for _, spec := range project.Options.Components {
+		req, err := ParseComponent(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid component %q: %w", spec, err)
+		}
+
+		generated := *project
+		generated.Files = files
+		plan, err := s.RenderComponent(ctx, &generated, &req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add component %s: %w", spec, err)
+		}
+		files = applyFiles(files, plan.Files)
+	}
+	return files, nil
+}
+
+// applyFiles replaces the files changes has new content for and appends
+// the ones it adds
+func applyFiles(files, changes []models.ProjectFile) []models.ProjectFile {
+	index := make(map[string]int, len(files))
+	for i, file := range files {
+		index[file.Path] = i
+	}
+	for _, change := range changes {
+		if i, ok := index[change.Path]; ok {
+			files[i].Content = change.Content
+			continue
+		}
+		index[change.Path] = len(files)
+		files = append(files, change)
+	}
+	return files
+}
+
+// planComponentFiles compares the component's files with the project's and
+// fills in the plan's files, report and conflicts
+func planComponentFiles(plan *ComponentPlan, existing []models.ProjectFile, component goComponent) {
+	current := make(map[string]models.ProjectFile, len(existing))
+	for _, file := range existing {
+		current[file.Path] = file
+	}
+	seen := make(map[string]bool)
+
+	for _, file := range component.files {
+		if seen[file.Path] {
+			continue
+		}
+		seen[file.Path] = true
+
+		existingFile, exists := current[file.Path]
+		switch {
+		case !exists:
+			plan.Files = append(plan.Files, file)
+			plan.Result.Files = append(plan.Result.Files, models.ComponentFile{Path: file.Path, Action: models.ComponentFileCreated})
+		case existingFile.IsDirectory:
+			plan.conflict(file.Path, "a directory is in the way")
+		case existingFile.Content == file.Content:
+			plan.Result.Files = append(plan.Result.Files, models.ComponentFile{Path: file.Path, Action: models.ComponentFileUnchanged})
+		default:
+			plan.conflict(file.Path, "file already exists with different content")
+		}
+	}
+
+	// Inserts into the same file are applied together, in the order given
+	var paths []string
+	byPath := make(map[string][]anchorInsert)
+	for _, insert := range component.inserts {
+		if _, ok := byPath[insert.path]; !ok {
+			paths = append(paths, insert.path)
+		}
+		byPath[insert.path] = append(byPath[insert.path], insert)
+	}
+
+	for _, path := range paths {
+		existingFile, exists := current[path]
+		if !exists || existingFile.IsDirectory {
+			plan.conflict(path, "file to merge into not found")
+			continue
+		}
+
+		content, changed, reason := mergeAtAnchors(existingFile.Content, byPath[path])
+		switch {
+		case reason != "":
+			plan.conflict(path, reason)
+		case changed:
+			plan.Files = append(plan.Files, models.ProjectFile{Path: path, Content: content})
+			plan.Result.Files = append(plan.Result.Files, models.ComponentFile{Path: path, Action: models.ComponentFileMerged})
+		default:
+			plan.Result.Files = append(plan.Result.Files, models.ComponentFile{Path: path, Action: models.ComponentFileUnchanged})
+		}
+	}
+}
+
+func (p *ComponentPlan) conflict(path, reason string) {
+	p.Result.Conflicts = append(p.Result.Conflicts, models.ComponentConflict{Path: path, Reason: reason})
+}
+
+// mergeAtAnchors inserts each insert's lines before its anchor, indented like
+// the anchor. Lines the file already holds are skipped, so merging the same
+// component twice changes nothing. A missing or repeated anchor is reported
+// as the reason the file cannot be merged.
+func mergeAtAnchors(content string, inserts []anchorInsert) (string, bool, string) {
+	lines := strings.Split(content, "\n")
+	present := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	changed := false
+	for _, insert := range inserts {
+		at := -1
+		for i, line := range lines {
+			if strings.TrimSpace(line) != insert.anchor {
+				continue
+			}
+			if at >= 0 {
+				return "", false, fmt.Sprintf("anchor %q appears more than once", insert.anchor)
+			}
+			at = i
+		}
+		if at < 0 {
+			return "", false, fmt.Sprintf("anchor %q not found", insert.anchor)
+		}
+
+		indent := lines[at][:len(lines[at])-len(strings.TrimLeft(lines[at], " \t"))]
+		var added []string
+		for _, line := range insert.lines {
+			if present[line] {
+				continue
+			}
+			present[line] = true
+			added = append(added, indent+line)
+		}
+		if len(added) == 0 {
+			continue
+		}
+
+		lines = append(lines[:at], append(added, lines[at:]...)...)
+		changed = true
+	}
+	return strings.Join(lines, "\n"), changed, ""
+}
+
+// componentConflict reports the files a component cannot be added to
+func componentConflict(conflicts []models.ComponentConflict) error {
+	err := apperror.Conflict("component_conflict", fmt.Sprintf("component conflicts with %d existing file(s); nothing was changed", len(conflicts)))
+	for _, conflict := range conflicts {
+		err.Details = append(err.Details, apperror.FieldError{Field: conflict.Path, Message: conflict.Reason})
+	}
+	return err
+}
+
+func oauthProviderNames() []string {
+	names := make([]string, 0, len(oauthProviders))
+	for name := range oauthProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,619 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// Anchors are comments in generated files that components insert lines
+// before. Users may move them, but removing one stops components from
+// merging into that file.
+const (
+	anchorImports = "// blueprint:imports"
+	anchorRoutes  = "// blueprint:routes"
+	anchorEnv     = "# blueprint:env"
+)
+
+// goRouter describes how the routes file of one framework registers handlers.
+// Component handlers are plain http.HandlerFuncs, so that one controller
+// works with every framework; the router adapts them.
+type goRouter struct {
+	imports      []string // Imports of the routes file
+	routeImports []string // Further imports of the registered routes
+	parameter    string   // Parameter of Register
+	handle       string   // Format of a route registration, given the path and handler
+	itemSuffix   string   // Appended to a collection path to match one of its items
+}
+
+var goRouters = map[string]goRouter{
+	"gin": {
+		imports:    []string{"github.com/gin-gonic/gin"},
+		parameter:  "router *gin.Engine",
+		handle:     "router.Any(%q, gin.WrapF(%s))",
+		itemSuffix: "/:id",
+	},
+	"chi": {
+		imports:    []string{"github.com/go-chi/chi/v5"},
+		parameter:  "router chi.Router",
+		handle:     "router.HandleFunc(%q, %s)",
+		itemSuffix: "/{id}",
+	},
+	"echo": {
+		imports:      []string{"github.com/labstack/echo/v4"},
+		routeImports: []string{"net/http"},
+		parameter:    "router *echo.Echo",
+		handle:       "router.Any(%q, echo.WrapHandler(http.HandlerFunc(%s)))",
+		itemSuffix:   "/:id",
+	},
+	"standard": {
+		imports:    []string{"net/http"},
+		parameter:  "router *http.ServeMux",
+		handle:     "router.HandleFunc(%q, %s)",
+		itemSuffix: "/",
+	},
+}
+
+// goRouterFor returns the router of framework, falling back to gin
+func goRouterFor(framework string) goRouter {
+	if router, ok := goRouters[framework]; ok {
+		return router
+	}
+	return goRouters["gin"]
+}
+
+func goRouterPath(data map[string]interface{}) string {
+	return filepath.Join(data["ProjectName"].(string), "internal", "routes", "router.go")
+}
+
+func goEnvExamplePath(data map[string]interface{}) string {
+	return filepath.Join(data["ProjectName"].(string), ".env.example")
+}
+
+// goComponent is what a component adds to a Go project
+type goComponent struct {
+	files   []models.ProjectFile
+	inserts []anchorInsert
+}
+
+// route adds a route registration, and the imports it needs, to the router
+func (c *goComponent) route(data map[string]interface{}, path, handler string) {
+	router := goRouterFor(data["Framework"].(string))
+	imports := []string{fmt.Sprintf("%q", data["PackageName"].(string)+"/internal/controller")}
+	for _, imported := range router.routeImports {
+		imports = append(imports, fmt.Sprintf("%q", imported))
+	}
+
+	c.inserts = append(c.inserts,
+		anchorInsert{path: goRouterPath(data), anchor: anchorImports, lines: imports},
+		anchorInsert{path: goRouterPath(data), anchor: anchorRoutes, lines: []string{fmt.Sprintf(router.handle, path, handler)}},
+	)
+}
+
+// goUtilityFile renders the package of one utility
+func goUtilityFile(data map[string]interface{}, utility string) models.ProjectFile {
+	content := fmt.Sprintf("// Package %s holds the project's %s utilities\npackage %s\n", utility, utility, utility)
+	return models.ProjectFile{
+		Path:    filepath.Join(data["ProjectName"].(string), "internal", "util", utility, utility+".go"),
+		Content: content,
+	}
+}
+
+// oauthProvider is an OAuth provider the Go template can sign users in with
+type oauthProvider struct {
+	Title    string // Exported Go name
+	Env      string // Prefix of its environment variables
+	AuthURL  string
+	TokenURL string
+	Scopes   []string
+}
+
+var oauthProviders = map[string]oauthProvider{
+	"github": {
+		Title: "GitHub", Env: "GITHUB",
+		AuthURL: "https://github.com/login/oauth/authorize", TokenURL: "https://github.com/login/oauth/access_token",
+		Scopes: []string{"read:user", "user:email"},
+	},
+	"gitlab": {
+		Title: "GitLab", Env: "GITLAB",
+		AuthURL: "https://gitlab.com/oauth/authorize", TokenURL: "https://gitlab.com/oauth/token",
+		Scopes: []string{"read_user"},
+	},
+	"google": {
+		Title: "Google", Env: "GOOGLE",
+		AuthURL: "https://accounts.google.com/o/oauth2/v2/auth", TokenURL: "https://oauth2.googleapis.com/token",
+		Scopes: []string{"openid", "email", "profile"},
+	},
+	"microsoft": {
+		Title: "Microsoft", Env: "MICROSOFT",
+		AuthURL: "https://login.microsoftonline.com/common/oauth2/v2.0/authorize", TokenURL: "https://login.microsoftonline.com/common/oauth2/v2.0/token",
+		Scopes: []string{"openid", "email", "profile"},
+	},
+}
+
+// goOAuthComponent renders sign-in with one OAuth provider: the shared OAuth
+// client, the provider's settings, its login and callback handlers and routes
+func goOAuthComponent(data map[string]interface{}, name string, provider oauthProvider) goComponent {
+	root := data["ProjectName"].(string)
+	values := map[string]interface{}{
+		"Module":   data["PackageName"],
+		"Name":     name,
+		"Provider": provider,
+		"Scopes":   fmt.Sprintf("%#v", provider.Scopes),
+	}
+
+	component := goComponent{files: []models.ProjectFile{
+		{Path: filepath.Join(root, "internal", "app", "oauth", "oauth.go"), Content: renderSource(oauthClientSource, values)},
+		{Path: filepath.Join(root, "internal", "app", "oauth", name+".go"), Content: renderSource(oauthProviderSource, values)},
+		{Path: filepath.Join(root, "internal", "controller", "respond.go"), Content: renderSource(respondSource, values)},
+		{Path: filepath.Join(root, "internal", "controller", "oauth_"+name+"_controller.go"), Content: renderSource(oauthControllerSource, values)},
+	}}
+	component.route(data, "/auth/"+name+"/login", "controller."+provider.Title+"Login")
+	component.route(data, "/auth/"+name+"/callback", "controller."+provider.Title+"Callback")
+	component.inserts = append(component.inserts, anchorInsert{
+		path:   goEnvExamplePath(data),
+		anchor: anchorEnv,
+		lines: []string{
+			provider.Env + "_CLIENT_ID=",
+			provider.Env + "_CLIENT_SECRET=",
+			provider.Env + "_REDIRECT_URL=http://localhost:8080/auth/" + name + "/callback",
+		},
+	})
+	return component
+}
+
+// goEntityComponent renders a CRUD entity with its repository, service,
+// controller and routes
+func goEntityComponent(data map[string]interface{}, name string) goComponent {
+	root := data["ProjectName"].(string)
+	typeName := exportedName(name)
+	file := snakeCase(typeName)
+	route := "/api/" + pluralize(strings.ReplaceAll(file, "_", "-"))
+	values := map[string]interface{}{
+		"Module":   data["PackageName"],
+		"Type":     typeName,
+		"Var":      string(unicode.ToLower(rune(typeName[0]))) + typeName[1:],
+		"Plural":   pluralize(typeName),
+		"Label":    strings.ReplaceAll(file, "_", " "),
+		"Route":    route,
+		"ItemPath": route + "/",
+	}
+
+	component := goComponent{files: []models.ProjectFile{
+		{Path: filepath.Join(root, "internal", "entity", file+".go"), Content: renderSource(entitySource, values)},
+		{Path: filepath.Join(root, "internal", "repository", file+"_repository.go"), Content: renderSource(entityRepositorySource, values)},
+		{Path: filepath.Join(root, "internal", "service", file+"_service.go"), Content: renderSource(entityServiceSource, values)},
+		{Path: filepath.Join(root, "internal", "controller", "respond.go"), Content: renderSource(respondSource, values)},
+		{Path: filepath.Join(root, "internal", "controller", file+"_controller.go"), Content: renderSource(entityControllerSource, values)},
+	}}
+	router := goRouterFor(data["Framework"].(string))
+	component.route(data, route, "controller."+pluralize(typeName)+"Handler")
+	component.route(data, route+router.itemSuffix, "controller."+typeName+"Handler")
+	return component
+}
+
+// renderSource executes a Go source template. The templates are constants,
+// so a failure is a bug in them.
+func renderSource(source string, values map[string]interface{}) string {
+	var buf bytes.Buffer
+	if err := template.Must(template.New("source").Parse(source)).Execute(&buf, values); err != nil {
+		panic(fmt.Sprintf("component template: %v", err))
+	}
+	return buf.String()
+}
+
+// exportedName turns an entity name such as order_item or order-item into
+// OrderItem
+func exportedName(name string) string {
+	var result strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// snakeCase turns OrderItem into order_item
+func snakeCase(name string) string {
+	var result strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				result.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		result.WriteRune(r)
+	}
+	return result.String()
+}
+
+// pluralize forms the English plural of simple nouns
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case strings.HasSuffix(lower, "y") && !strings.HasSuffix(lower, "ay") && !strings.HasSuffix(lower, "ey") && !strings.HasSuffix(lower, "oy"):
+		return word[:len(word)-1] + "ies"
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	default:
+		return word + "s"
+	}
+}
+
+const entitySource = `package entity
+
+import "time"
+
+// {{.Type}} is a stored {{.Label}}
+type {{.Type}} struct {
+	ID        string    ` + "`json:\"id\"`" + `
+	CreatedAt time.Time ` + "`json:\"created_at\"`" + `
+	UpdatedAt time.Time ` + "`json:\"updated_at\"`" + `
+}
+`
+
+const entityRepositorySource = `package repository
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"{{.Module}}/internal/entity"
+)
+
+// Err{{.Type}}NotFound is returned when a {{.Label}} does not exist
+var Err{{.Type}}NotFound = errors.New("{{.Label}} not found")
+
+// {{.Type}}Repository keeps {{.Label}} records in memory
+type {{.Type}}Repository struct {
+	mu      sync.RWMutex
+	records map[string]entity.{{.Type}}
+}
+
+func New{{.Type}}Repository() *{{.Type}}Repository {
+	return &{{.Type}}Repository{records: make(map[string]entity.{{.Type}})}
+}
+
+// List returns every {{.Label}}, oldest first
+func (r *{{.Type}}Repository) List() []entity.{{.Type}} {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	records := make([]entity.{{.Type}}, 0, len(r.records))
+	for _, record := range r.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].CreatedAt.Before(records[j].CreatedAt) })
+	return records
+}
+
+// Get returns one {{.Label}}
+func (r *{{.Type}}Repository) Get(id string) (entity.{{.Type}}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, ok := r.records[id]
+	if !ok {
+		return entity.{{.Type}}{}, Err{{.Type}}NotFound
+	}
+	return record, nil
+}
+
+// Save creates or replaces a {{.Label}}
+func (r *{{.Type}}Repository) Save(record entity.{{.Type}}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[record.ID] = record
+}
+
+// Delete removes a {{.Label}}
+func (r *{{.Type}}Repository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.records[id]; !ok {
+		return Err{{.Type}}NotFound
+	}
+	delete(r.records, id)
+	return nil
+}
+`
+
+const entityServiceSource = `package service
+
+import (
+	"time"
+
+	"{{.Module}}/internal/entity"
+	"{{.Module}}/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// {{.Type}}Service holds the business rules for {{.Label}} records
+type {{.Type}}Service struct {
+	repository *repository.{{.Type}}Repository
+}
+
+func New{{.Type}}Service(repository *repository.{{.Type}}Repository) *{{.Type}}Service {
+	return &{{.Type}}Service{repository: repository}
+}
+
+// List returns every {{.Label}}
+func (s *{{.Type}}Service) List() []entity.{{.Type}} {
+	return s.repository.List()
+}
+
+// Get returns one {{.Label}}
+func (s *{{.Type}}Service) Get(id string) (entity.{{.Type}}, error) {
+	return s.repository.Get(id)
+}
+
+// Create stores a new {{.Label}}
+func (s *{{.Type}}Service) Create(record entity.{{.Type}}) entity.{{.Type}} {
+	now := time.Now()
+	record.ID = uuid.New().String()
+	record.CreatedAt = now
+	record.UpdatedAt = now
+	s.repository.Save(record)
+	return record
+}
+
+// Update replaces a {{.Label}}, keeping its ID and creation time
+func (s *{{.Type}}Service) Update(id string, record entity.{{.Type}}) (entity.{{.Type}}, error) {
+	current, err := s.repository.Get(id)
+	if err != nil {
+		return entity.{{.Type}}{}, err
+	}
+	record.ID = current.ID
+	record.CreatedAt = current.CreatedAt
+	record.UpdatedAt = time.Now()
+	s.repository.Save(record)
+	return record, nil
+}
+
+// Delete removes a {{.Label}}
+func (s *{{.Type}}Service) Delete(id string) error {
+	return s.repository.Delete(id)
+}
+`
+
+const respondSource = `package controller
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// respondJSON writes value as a JSON response
+func respondJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// respondError writes err as a JSON error response
+func respondError(w http.ResponseWriter, status int, err error) {
+	respondJSON(w, status, map[string]string{"error": err.Error()})
+}
+`
+
+const entityControllerSource = `package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+
+	"{{.Module}}/internal/entity"
+	"{{.Module}}/internal/repository"
+	"{{.Module}}/internal/service"
+)
+
+var {{.Var}}Service = service.New{{.Type}}Service(repository.New{{.Type}}Repository())
+
+// {{.Plural}}Handler lists and creates {{.Label}} records at {{.Route}}
+func {{.Plural}}Handler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		respondJSON(w, http.StatusOK, {{.Var}}Service.List())
+	case http.MethodPost:
+		var record entity.{{.Type}}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		respondJSON(w, http.StatusCreated, {{.Var}}Service.Create(record))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// {{.Type}}Handler reads, updates and deletes one {{.Label}} at {{.ItemPath}}{id}
+func {{.Type}}Handler(w http.ResponseWriter, r *http.Request) {
+	id := path.Base(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		record, err := {{.Var}}Service.Get(id)
+		if err != nil {
+			respondError(w, {{.Var}}Status(err), err)
+			return
+		}
+		respondJSON(w, http.StatusOK, record)
+	case http.MethodPut:
+		var record entity.{{.Type}}
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil {
+			respondError(w, http.StatusBadRequest, err)
+			return
+		}
+		updated, err := {{.Var}}Service.Update(id, record)
+		if err != nil {
+			respondError(w, {{.Var}}Status(err), err)
+			return
+		}
+		respondJSON(w, http.StatusOK, updated)
+	case http.MethodDelete:
+		if err := {{.Var}}Service.Delete(id); err != nil {
+			respondError(w, {{.Var}}Status(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func {{.Var}}Status(err error) int {
+	if errors.Is(err, repository.Err{{.Type}}NotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+`
+
+const oauthClientSource = `// Package oauth signs users in with OAuth 2.0 providers
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Provider is an OAuth 2.0 authorization server
+type Provider struct {
+	Name      string
+	AuthURL   string
+	TokenURL  string
+	Scopes    []string
+	EnvPrefix string // Client ID, secret and redirect URL are read from <prefix>_CLIENT_ID and so on
+}
+
+// Token is the response of a successful code exchange
+type Token struct {
+	AccessToken  string ` + "`json:\"access_token\"`" + `
+	TokenType    string ` + "`json:\"token_type\"`" + `
+	RefreshToken string ` + "`json:\"refresh_token,omitempty\"`" + `
+	ExpiresIn    int    ` + "`json:\"expires_in,omitempty\"`" + `
+}
+
+// LoginURL is where users are sent to sign in. state is returned to the
+// callback and must be checked there.
+func (p Provider) LoginURL(state string) string {
+	query := url.Values{
+		"client_id":     {os.Getenv(p.EnvPrefix + "_CLIENT_ID")},
+		"redirect_uri":  {os.Getenv(p.EnvPrefix + "_REDIRECT_URL")},
+		"response_type": {"code"},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+	return p.AuthURL + "?" + query.Encode()
+}
+
+// Exchange trades the code the provider sent to the callback for a token
+func (p Provider) Exchange(ctx context.Context, code string) (*Token, error) {
+	form := url.Values{
+		"client_id":     {os.Getenv(p.EnvPrefix + "_CLIENT_ID")},
+		"client_secret": {os.Getenv(p.EnvPrefix + "_CLIENT_SECRET")},
+		"redirect_uri":  {os.Getenv(p.EnvPrefix + "_REDIRECT_URL")},
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s token exchange failed: %w", p.Name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s token exchange failed with status %d", p.Name, resp.StatusCode)
+	}
+
+	var token Token
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%s token response unreadable: %w", p.Name, err)
+	}
+	return &token, nil
+}
+`
+
+const oauthProviderSource = `package oauth
+
+// {{.Provider.Title}} signs users in with {{.Provider.Title}}
+var {{.Provider.Title}} = Provider{
+	Name:      "{{.Name}}",
+	AuthURL:   "{{.Provider.AuthURL}}",
+	TokenURL:  "{{.Provider.TokenURL}}",
+	Scopes:    {{.Scopes}},
+	EnvPrefix: "{{.Provider.Env}}",
+}
+`
+
+const oauthControllerSource = `package controller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"{{.Module}}/internal/app/oauth"
+)
+
+const {{.Name}}StateCookie = "oauth_state_{{.Name}}"
+
+// {{.Provider.Title}}Login sends the user to {{.Provider.Title}} to sign in
+func {{.Provider.Title}}Login(w http.ResponseWriter, r *http.Request) {
+	state := make([]byte, 16)
+	if _, err := rand.Read(state); err != nil {
+		respondError(w, http.StatusInternalServerError, err)
+		return
+	}
+	value := hex.EncodeToString(state)
+	http.SetCookie(w, &http.Cookie{Name: {{.Name}}StateCookie, Value: value, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+	http.Redirect(w, r, oauth.{{.Provider.Title}}.LoginURL(value), http.StatusFound)
+}
+
+// {{.Provider.Title}}Callback completes a {{.Provider.Title}} sign-in
+func {{.Provider.Title}}Callback(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie({{.Name}}StateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != r.URL.Query().Get("state") {
+		respondError(w, http.StatusBadRequest, errors.New("invalid OAuth state"))
+		return
+	}
+
+	token, err := oauth.{{.Provider.Title}}.Exchange(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		respondError(w, http.StatusBadGateway, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, token)
+}
+`
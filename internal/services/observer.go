@@ -0,0 +1,71 @@
+package services
+
+import (
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// Observer is told about the work the services do, so that it can be
+// measured. Implementations must be safe for concurrent use and return
+// quickly; see internal/metrics.
+type Observer interface {
+	// ProjectGenerated reports a GenerateProjectFiles call
+	ProjectGenerated(project *models.Project, duration time.Duration, err error)
+	// ArchiveCreated reports the size of an archive built by CreateZIPArchive
+	ArchiveCreated(project *models.Project, size int)
+	// MessageProcessed reports a ProcessMessage call; response is nil when it failed
+	MessageProcessed(response *models.ChatResponse, duration time.Duration, err error)
+	// ProjectCreated reports a project stored by CreateProject
+	ProjectCreated(project *models.Project)
+	// SessionCreated reports a new chat session, started or imported
+	SessionCreated()
+	// SessionsRemoved reports chat sessions deleted or expired
+	SessionsRemoved(count int)
+}
+
+// SetObserver reports archive creation to observer. Call it before the
+// service is used.
+func (s *TemplateService) SetObserver(observer Observer) {
+	s.observer = observer
+}
+
+// SetObserver reports project generation to observer
+func (s *ProjectService) SetObserver(observer Observer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = observer
+}
+
+func (s *ProjectService) currentObserver() Observer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.observer
+}
+
+// SetObserver reports processed chat messages to observer
+func (s *ChatService) SetObserver(observer Observer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = observer
+}
+
+func (s *ChatService) currentObserver() Observer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.observer
+}
+
+// sessionCreatedLocked reports a new session. The caller must hold the lock.
+func (s *ChatService) sessionCreatedLocked() {
+	if s.observer != nil {
+		s.observer.SessionCreated()
+	}
+}
+
+// sessionsRemovedLocked reports removed sessions. The caller must hold the lock.
+func (s *ChatService) sessionsRemovedLocked(count int) {
+	if s.observer != nil && count > 0 {
+		s.observer.SessionsRemoved(count)
+	}
+}
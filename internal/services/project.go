@@ -1,35 +1,52 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"path"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/logging"
 	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+	"boilerplate-blueprint/internal/tracing"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ErrProjectNotFound is returned when a project does not exist
+var ErrProjectNotFound = apperror.NotFound("project_not_found", "project not found")
+
 type ProjectService struct {
 	projects        map[string]*models.Project
+	revisions       map[string][]models.ProjectRevision // Project ID to its revisions, oldest first; unused with a store
 	templateService *TemplateService
+	artifactStore   artifacts.Store
+	store           storage.Store // Optional; state is only kept in memory when nil
+	observer        Observer      // Optional
 	mu              sync.RWMutex
 }
 
 func NewProjectService(templateService *TemplateService) *ProjectService {
 	return &ProjectService{
 		projects:        make(map[string]*models.Project),
+		revisions:       make(map[string][]models.ProjectRevision),
 		templateService: templateService,
 	}
 }
 
-func (s *ProjectService) CreateProject(req *models.ProjectRequest) (*models.Project, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+func (s *ProjectService) CreateProject(ctx context.Context, req *models.ProjectRequest) (*models.Project, error) {
 	// Validate language
 	if req.Language != models.LanguageGo && req.Language != models.LanguagePHP {
-		return nil, fmt.Errorf("unsupported language: %s", req.Language)
+		return nil, apperror.InvalidField("language", fmt.Sprintf("unsupported language: %s", req.Language))
 	}
 
 	// Create new project
@@ -40,6 +57,9 @@ func (s *ProjectService) CreateProject(req *models.ProjectRequest) (*models.Proj
 		Description: req.Description,
 		Options:     req.Options,
 		Files:       []models.ProjectFile{},
+		Revision:    1,
+		OwnerID:     req.OwnerID,
+		TeamID:      req.TeamID,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
@@ -50,116 +70,382 @@ func (s *ProjectService) CreateProject(req *models.ProjectRequest) (*models.Proj
 	}
 
 	// Store project
+	s.mu.Lock()
 	s.projects[project.ID] = project
+	s.recordRevisionLocked(project)
+	s.mu.Unlock()
+
+	if err := s.persistProject(ctx, project, 0); err != nil {
+		projectLogger(ctx, project).Error("failed to save project", "error", err)
+		return nil, err
+	}
 
+	if observer := s.currentObserver(); observer != nil {
+		observer.ProjectCreated(project)
+	}
+	projectLogger(ctx, project).Info("project created")
 	return project, nil
 }
 
-func (s *ProjectService) GetProject(projectID string) (*models.Project, error) {
+func (s *ProjectService) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if store := s.Store(); store != nil {
+		return s.loadProject(ctx, store, projectID)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	project, exists := s.projects[projectID]
 	if !exists {
-		return nil, fmt.Errorf("project not found: %s", projectID)
+		return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectID)
 	}
 
 	return project, nil
 }
 
-func (s *ProjectService) GenerateProjectFiles(project *models.Project) ([]models.ProjectFile, error) {
-	var files []models.ProjectFile
-	var err error
+func (s *ProjectService) GenerateProjectFiles(ctx context.Context, project *models.Project) (files []models.ProjectFile, err error) {
+	ctx, span := tracing.Start(ctx, "project.generate", projectAttributes(project)...)
+	defer func() {
+		span.SetAttributes(attribute.Int("template.files", len(files)))
+		tracing.End(span, err)
+	}()
+	defer func(start time.Time) {
+		duration := time.Since(start)
+		if observer := s.currentObserver(); observer != nil {
+			observer.ProjectGenerated(project, duration, err)
+		}
 
-	switch project.Language {
-	case models.LanguageGo:
-		files, err = s.templateService.GenerateGoProject(project)
-	case models.LanguagePHP:
-		files, err = s.templateService.GeneratePHPProject(project)
-	default:
-		return nil, fmt.Errorf("unsupported language: %s", project.Language)
-	}
+		logger := projectLogger(ctx, project)
+		if err != nil {
+			logger.Error("project generation failed", "error", err)
+			return
+		}
+		logger.Info("project generated", "files", len(files), "duration_ms", float64(duration.Microseconds())/1000)
+	}(time.Now())
 
+	return s.generateProjectFiles(ctx, project)
+}
+
+func (s *ProjectService) generateProjectFiles(ctx context.Context, project *models.Project) ([]models.ProjectFile, error) {
+	files, err := s.templateService.GenerateProject(ctx, project)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update project with generated files
+	// Regenerating files that did not change keeps the revision, so archives
+	// stored for it stay valid
 	s.mu.Lock()
+	if reflect.DeepEqual(files, project.Files) {
+		s.mu.Unlock()
+		return files, nil
+	}
+	previous := project.Revision
 	project.Files = files
+	project.Revision++
 	project.UpdatedAt = time.Now()
+	s.recordRevisionLocked(project)
 	s.mu.Unlock()
 
+	if err := s.persistProject(ctx, project, previous); err != nil {
+		return nil, err
+	}
+
 	return files, nil
 }
 
-func (s *ProjectService) CreateProjectZIP(projectID string) ([]byte, string, error) {
-	project, err := s.GetProject(projectID)
+// SetArtifactStore makes generated archives persist in store, keyed by
+// project revision, instead of being rebuilt on every download
+func (s *ProjectService) SetArtifactStore(store artifacts.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.artifactStore = store
+}
+
+// ArtifactStore returns the configured artifact store, or nil
+func (s *ProjectService) ArtifactStore() artifacts.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.artifactStore
+}
+
+func (s *ProjectService) CreateProjectZIP(ctx context.Context, projectID string) ([]byte, string, error) {
+	project, err := s.GetProject(ctx, projectID)
 	if err != nil {
 		return nil, "", err
 	}
 
-	// Generate files if not already generated
-	if len(project.Files) == 0 {
-		_, err = s.GenerateProjectFiles(project)
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to generate project files: %w", err)
+	ctx, span := tracing.Start(ctx, "project.archive", projectAttributes(project)...)
+	defer span.End()
+
+	logger := projectLogger(ctx, project)
+	// Generating changes the revision, so it comes before the archive's key
+	if err := s.ensureGenerated(ctx, project); err != nil {
+		tracing.Fail(span, err)
+		logger.Error("failed to build archive", "error", err)
+		return nil, "", err
+	}
+
+	filename := archiveFilename(project)
+	store, key := s.archiveLocation(project)
+	if store != nil {
+		zipData, err := store.Get(ctx, key)
+		if err == nil {
+			span.SetAttributes(attribute.Bool("archive.cached", true))
+			logger.Debug("serving stored archive", "key", key)
+			return zipData, filename, nil
+		}
+		if !errors.Is(err, artifacts.ErrNotFound) {
+			logger.Warn("failed to read stored archive", "key", key, "error", err)
 		}
 	}
 
-	// Create ZIP file
-	zipData, err := s.templateService.CreateZIPArchive(project)
+	zipData, err := s.buildProjectZIP(ctx, project)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create ZIP archive: %w", err)
+		tracing.Fail(span, err)
+		logger.Error("failed to build archive", "error", err)
+		return nil, "", err
+	}
+
+	if store != nil {
+		// The archive was built, so a storage failure only costs a rebuild next time
+		if err := store.Put(ctx, key, zipData, "application/zip"); err != nil {
+			logger.Warn("failed to store archive", "key", key, "error", err)
+		}
 	}
 
-	filename := fmt.Sprintf("%s-%s.zip", project.Name, project.Language)
 	return zipData, filename, nil
 }
 
-func (s *ProjectService) setDefaultOptions(project *models.Project) error {
-	switch project.Language {
-	case models.LanguageGo:
-		// Set default Go options if not specified
-		if project.Options.Framework == "" {
-			project.Options.Framework = "gin"
-		}
-		if project.Options.Database == "" {
-			project.Options.Database = "postgresql"
+// ProjectArchiveURL stores the project's archive if needed and returns a
+// pre-signed URL for downloading it directly from the artifact store
+func (s *ProjectService) ProjectArchiveURL(ctx context.Context, projectID string, expires time.Duration) (string, string, error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if s.ArtifactStore() == nil {
+		return "", "", artifacts.ErrPresignUnsupported
+	}
+
+	logger := projectLogger(ctx, project)
+	if err := s.ensureGenerated(ctx, project); err != nil {
+		logger.Error("failed to build archive", "error", err)
+		return "", "", err
+	}
+	store, key := s.archiveLocation(project)
+	exists, err := store.Exists(ctx, key)
+	if err != nil {
+		logger.Error("failed to check stored archive", "key", key, "error", err)
+		return "", "", err
+	}
+	if !exists {
+		zipData, err := s.buildProjectZIP(ctx, project)
+		if err != nil {
+			logger.Error("failed to build archive", "error", err)
+			return "", "", err
 		}
-		if project.Options.Authentication == "" {
-			project.Options.Authentication = "jwt"
+		if err := store.Put(ctx, key, zipData, "application/zip"); err != nil {
+			logger.Error("failed to store archive", "key", key, "error", err)
+			return "", "", err
 		}
-		if len(project.Options.Utilities) == 0 {
-			// Default to all utility packages
-			project.Options.Utilities = []string{
-				"authentication", "cache", "common", "constants", "converter",
-				"date", "datatype", "encryption", "exception", "exceptioncode",
-				"helper", "httphelper", "json", "logger", "password",
-				"queryhelper", "sort", "template", "validator", "alert",
-			}
+	}
+
+	filename := archiveFilename(project)
+	url, err := store.PresignGet(ctx, key, filename, expires)
+	if err != nil {
+		logger.Error("failed to presign archive URL", "key", key, "error", err)
+		return "", "", err
+	}
+
+	return url, filename, nil
+}
+
+// ensureGenerated generates the project's files if there are none yet
+func (s *ProjectService) ensureGenerated(ctx context.Context, project *models.Project) error {
+	if len(project.Files) > 0 {
+		return nil
+	}
+	if _, err := s.GenerateProjectFiles(ctx, project); err != nil {
+		return fmt.Errorf("failed to generate project files: %w", err)
+	}
+	return nil
+}
+
+// buildProjectZIP archives the project's files
+func (s *ProjectService) buildProjectZIP(ctx context.Context, project *models.Project) ([]byte, error) {
+	zipData, err := s.templateService.CreateZIPArchive(ctx, project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ZIP archive: %w", err)
+	}
+
+	return zipData, nil
+}
+
+// archiveLocation returns the artifact store and the key for the project's current revision
+func (s *ProjectService) archiveLocation(project *models.Project) (artifacts.Store, string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.artifactStore, artifacts.ArchiveKey(project.ID, project.Revision)
+}
+
+// projectAttributes describes a project on trace spans
+func projectAttributes(project *models.Project) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("project.id", project.ID),
+		attribute.String("project.language", string(project.Language)),
+		attribute.String("project.framework", project.Options.Framework),
+	}
+}
+
+// projectLogger returns the request's logger tagged with the project's ID,
+// language and options
+func projectLogger(ctx context.Context, project *models.Project) *slog.Logger {
+	return logging.FromContext(ctx).With(
+		slog.String("project_id", project.ID),
+		slog.String("language", string(project.Language)),
+		slog.Any("options", project.Options),
+	)
+}
+
+func archiveFilename(project *models.Project) string {
+	return fmt.Sprintf("%s-%s.zip", project.Name, project.Language)
+}
+
+// UpdateProjectOptions replaces a project's options. Files that were already
+// generated are regenerated from the new options.
+func (s *ProjectService) UpdateProjectOptions(ctx context.Context, projectID string, options models.ProjectOptions) (*models.Project, error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	project.Options = options
+	if err := s.setDefaultOptions(project); err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to set default options: %w", err)
+	}
+	revision := project.Revision
+	regenerate := len(project.Files) > 0
+	s.mu.Unlock()
+
+	if regenerate {
+		if _, err := s.GenerateProjectFiles(ctx, project); err != nil {
+			return nil, fmt.Errorf("failed to regenerate project files: %w", err)
 		}
+	}
 
-	case models.LanguagePHP:
-		// Set default PHP options if not specified
-		if project.Options.CIVersion == "" {
-			project.Options.CIVersion = "3"
+	// The options changed even when the files did not; regenerating saved
+	// the project if they did
+	s.mu.Lock()
+	unchanged := project.Revision == revision
+	if unchanged {
+		project.Revision++
+		project.UpdatedAt = time.Now()
+		s.recordRevisionLocked(project)
+	}
+	s.mu.Unlock()
+
+	if unchanged {
+		if err := s.persistProject(ctx, project, revision); err != nil {
+			return nil, err
 		}
-		if project.Options.Database == "" {
-			project.Options.Database = "postgresql"
+	}
+
+	return project, nil
+}
+
+// UpdateProjectFile replaces the content of a generated file, adding the file
+// if it does not exist yet
+func (s *ProjectService) UpdateProjectFile(ctx context.Context, projectID, filePath, content string) (*models.Project, error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	cleaned, err := cleanFilePath(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Make sure there is something to patch
+	if len(project.Files) == 0 {
+		if _, err := s.GenerateProjectFiles(ctx, project); err != nil {
+			return nil, fmt.Errorf("failed to generate project files: %w", err)
 		}
-		if project.Options.Frontend == "" {
-			project.Options.Frontend = "bootstrap"
+	}
+
+	previous := project.Revision
+	if err := s.replaceFile(project, cleaned, content); err != nil {
+		return nil, err
+	}
+
+	if err := s.persistProject(ctx, project, previous); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// replaceFile sets the content of one file and records the new revision
+func (s *ProjectService) replaceFile(project *models.Project, filePath, content string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	replaced := false
+	for i, file := range project.Files {
+		if file.Path != filePath {
+			continue
 		}
-		if len(project.Options.Features) == 0 {
-			project.Options.Features = []string{"authentication", "user_management", "dashboard"}
+		if file.IsDirectory {
+			return apperror.InvalidField("path", fmt.Sprintf("path is a directory: %s", filePath))
 		}
+		project.Files[i].Content = content
+		replaced = true
+		break
 	}
 
+	if !replaced {
+		project.Files = append(project.Files, models.ProjectFile{
+			Path:        filePath,
+			Content:     content,
+			IsDirectory: false,
+		})
+	}
+	project.Revision++
+	project.UpdatedAt = time.Now()
+	s.recordRevisionLocked(project)
+
 	return nil
 }
 
-func (s *ProjectService) ListProjects() []*models.Project {
+// cleanFilePath normalises a project-relative path, rejecting paths that escape the project
+func cleanFilePath(filePath string) (string, error) {
+	cleaned := path.Clean(filePath)
+	if filePath == "" || path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", apperror.InvalidField("path", fmt.Sprintf("invalid file path: %s", filePath))
+	}
+	return cleaned, nil
+}
+
+func (s *ProjectService) setDefaultOptions(project *models.Project) error {
+	s.templateService.ApplyDefaults(project)
+	return nil
+}
+
+func (s *ProjectService) ListProjects(ctx context.Context) []*models.Project {
+	if store := s.Store(); store != nil {
+		projects, err := store.ListProjects(ctx)
+		if err == nil {
+			return projects
+		}
+		logging.FromContext(ctx).Warn("failed to list stored projects, using cached ones", "error", err)
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
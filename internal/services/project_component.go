@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// AddComponent renders a component into a project without regenerating it.
+// Projects without files are generated first. Nothing changes when the
+// component conflicts with the project's files or req is a dry run;
+// otherwise the created and merged files are stored as a new revision.
+func (s *ProjectService) AddComponent(ctx context.Context, projectID string, req *models.ComponentRequest) (*ComponentPlan, error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(project.Files) == 0 {
+		if _, err := s.GenerateProjectFiles(ctx, project); err != nil {
+			return nil, fmt.Errorf("failed to generate project files: %w", err)
+		}
+	}
+
+	// Rendering and applying under one lock keeps a concurrent add from
+	// merging into files this one is about to replace. With a store, other
+	// instances are caught by the conditional save instead.
+	s.mu.Lock()
+	logger := projectLogger(ctx, project)
+	plan, err := s.templateService.RenderComponent(ctx, project, req)
+	if err != nil {
+		s.mu.Unlock()
+		if plan != nil {
+			logger.Info("component conflicts with project files", "component", plan.Result.Component, "conflicts", len(plan.Result.Conflicts))
+		}
+		return plan, err
+	}
+	if req.DryRun || len(plan.Files) == 0 && reflect.DeepEqual(plan.Options, project.Options) {
+		s.mu.Unlock()
+		return plan, nil
+	}
+
+	previous := project.Revision
+	project.Files = applyFiles(project.Files, plan.Files)
+	project.Options = plan.Options
+	project.Revision++
+	project.UpdatedAt = time.Now()
+	plan.Revision = project.Revision
+	s.recordRevisionLocked(project)
+	s.mu.Unlock()
+
+	if err := s.persistProject(ctx, project, previous); err != nil {
+		return nil, err
+	}
+
+	plan.Result.Applied = true
+	logger.Info("component added", "component", plan.Result.Component, "files", len(plan.Files), "revision", plan.Revision)
+	return plan, nil
+}
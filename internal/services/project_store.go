@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+)
+
+// ErrRevisionNotFound is returned when a project revision does not exist
+var ErrRevisionNotFound = apperror.NotFound("revision_not_found", "project revision not found")
+
+// ErrProjectChanged is returned when another request changed a project
+// between reading and saving it
+var ErrProjectChanged = apperror.Conflict("project_changed", "project changed since it was read; retry the request")
+
+// SetStore persists projects and their revisions in store. Reads go to the
+// store so that every instance sees the same data; memory only caches.
+func (s *ProjectService) SetStore(store storage.Store) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// Store returns the configured storage backend, or nil
+func (s *ProjectService) Store() storage.Store {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.store
+}
+
+// GetRevision returns the snapshot of a project at one revision
+func (s *ProjectService) GetRevision(ctx context.Context, projectID string, revision int) (*models.ProjectRevision, error) {
+	if store := s.Store(); store != nil {
+		snapshot, err := store.GetRevision(ctx, projectID, revision)
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: %s@%d", ErrRevisionNotFound, projectID, revision)
+		}
+		return snapshot, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, snapshot := range s.revisions[projectID] {
+		if snapshot.Revision == revision {
+			copied := snapshot
+			return &copied, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s@%d", ErrRevisionNotFound, projectID, revision)
+}
+
+// ListRevisions returns a project's revisions, oldest first
+func (s *ProjectService) ListRevisions(ctx context.Context, projectID string) ([]models.ProjectRevision, error) {
+	if _, err := s.GetProject(ctx, projectID); err != nil {
+		return nil, err
+	}
+
+	if store := s.Store(); store != nil {
+		return store.ListRevisions(ctx, projectID)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	revisions := make([]models.ProjectRevision, len(s.revisions[projectID]))
+	copy(revisions, s.revisions[projectID])
+	return revisions, nil
+}
+
+// loadProject reads a project from the store and refreshes the cached copy
+func (s *ProjectService) loadProject(ctx context.Context, store storage.Store, projectID string) (*models.Project, error) {
+	project, err := store.GetProject(ctx, projectID)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load project: %w", err)
+	}
+
+	s.mu.Lock()
+	s.projects[project.ID] = project
+	s.mu.Unlock()
+
+	return project, nil
+}
+
+// persistProject writes the project and a snapshot of its current revision
+// to the store, if any. previousRevision is the revision the change was made
+// to, or 0 for a new project; if the stored project moved on since, nothing
+// is saved and ErrProjectChanged is returned. The change has already been
+// made in memory, so it is saved even when ctx is cancelled; otherwise the
+// store would fall behind the cache.
+func (s *ProjectService) persistProject(ctx context.Context, project *models.Project, previousRevision int) error {
+	s.mu.RLock()
+	store := s.store
+	snapshot := *project
+	snapshot.Files = append([]models.ProjectFile(nil), project.Files...)
+	s.mu.RUnlock()
+
+	if store == nil {
+		return nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	err := store.SaveProject(ctx, &snapshot, previousRevision)
+	if err == nil {
+		err = store.SaveRevision(ctx, revisionSnapshot(&snapshot))
+	}
+	if errors.Is(err, storage.ErrConflict) {
+		return fmt.Errorf("%w: %s@%d", ErrProjectChanged, project.ID, previousRevision)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+
+	return nil
+}
+
+// recordRevisionLocked snapshots the project's current revision in memory.
+// Snapshots are never replaced: archives and upgrades rely on a revision's
+// files staying what they were. With a store configured, persistProject
+// saves the snapshot there instead, so instances do not hold every revision.
+// The caller must hold the write lock.
+func (s *ProjectService) recordRevisionLocked(project *models.Project) {
+	if s.store != nil {
+		return
+	}
+
+	revisions := s.revisions[project.ID]
+	for _, snapshot := range revisions {
+		if snapshot.Revision == project.Revision {
+			return
+		}
+	}
+
+	revisions = append(revisions, *revisionSnapshot(project))
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+	s.revisions[project.ID] = revisions
+}
+
+// revisionSnapshot copies the project's options and files as its current revision
+func revisionSnapshot(project *models.Project) *models.ProjectRevision {
+	return &models.ProjectRevision{
+		ProjectID: project.ID,
+		Revision:  project.Revision,
+		Options:   project.Options,
+		Files:     append([]models.ProjectFile(nil), project.Files...),
+		CreatedAt: time.Now(),
+	}
+}
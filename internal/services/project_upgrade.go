@@ -0,0 +1,335 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/merge"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Limits on uploaded archives once extracted, so a small ZIP cannot expand
+// to exhaust memory
+const (
+	maxUpgradeArchiveBytes   = 256 << 20
+	maxUpgradeArchiveEntries = 20000
+)
+
+var (
+	// ErrInvalidArchive is returned when an uploaded archive cannot be read
+	ErrInvalidArchive = apperror.Validation("invalid_archive", "archive is not a readable ZIP file")
+	// ErrUpgradeBaseEmpty is returned when the base revision of an upgrade
+	// has no generated files to merge against
+	ErrUpgradeBaseEmpty = apperror.Validation("upgrade_base_empty", "base revision has no generated files")
+)
+
+// UpgradeResult is a project merged with the current templates
+type UpgradeResult struct {
+	Report   models.UpgradeReport
+	Files    []models.ProjectFile
+	Archive  []byte // ZIP of Files
+	Filename string
+}
+
+// UpgradeProject merges what the current templates change in a project into
+// a copy of it that may have been edited since it was generated. Each file
+// is merged three ways: the project's files at baseRevision are the common
+// base, the templates rendered with that revision's options are theirs, and
+// the copy in archive, a ZIP, is ours. baseRevision 0 means the project's
+// current revision. Conflicts are written into the merged files with
+// diff3-style markers and listed in the report. The project itself is not
+// changed.
+func (s *ProjectService) UpgradeProject(ctx context.Context, projectID string, baseRevision int, archive []byte) (result *UpgradeResult, err error) {
+	project, err := s.GetProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := tracing.Start(ctx, "project.upgrade", projectAttributes(project)...)
+	defer func() {
+		if result != nil {
+			span.SetAttributes(attribute.Int("upgrade.files", len(result.Files)), attribute.Int("upgrade.conflicts", result.Report.Conflicts))
+		}
+		tracing.End(span, err)
+	}()
+
+	if baseRevision == 0 {
+		baseRevision = project.Revision
+	}
+	base, err := s.GetRevision(ctx, projectID, baseRevision)
+	if err != nil {
+		return nil, err
+	}
+	if countProjectFiles(base.Files) == 0 {
+		return nil, fmt.Errorf("%w: revision %d of project %s", ErrUpgradeBaseEmpty, baseRevision, projectID)
+	}
+
+	ours, err := readUpgradeArchive(archive, project.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	generated := *project
+	generated.Options = base.Options
+	generated.Files = nil
+	theirs, err := s.templateService.GenerateProject(ctx, &generated)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render templates: %w", err)
+	}
+
+	labels := merge.Labels{Ours: "local", Base: fmt.Sprintf("revision %d", baseRevision), Theirs: "template"}
+	files, report := mergeProjectFiles(base.Files, ours, theirs, labels)
+	report.BaseRevision = baseRevision
+
+	merged := *project
+	merged.Files = files
+	zipData, err := s.templateService.CreateZIPArchive(ctx, &merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ZIP archive: %w", err)
+	}
+
+	projectLogger(ctx, project).Info("project upgrade merged", "base_revision", baseRevision, "files", len(files), "conflicts", report.Conflicts)
+	return &UpgradeResult{
+		Report:   report,
+		Files:    files,
+		Archive:  zipData,
+		Filename: fmt.Sprintf("%s-%s-upgrade.zip", project.Name, project.Language),
+	}, nil
+}
+
+// UpgradeArchiveURL stores the merged archive of an upgrade in the artifact
+// store and returns a pre-signed URL that downloads it until expires
+func (s *ProjectService) UpgradeArchiveURL(ctx context.Context, projectID string, result *UpgradeResult, expires time.Duration) (string, error) {
+	store := s.ArtifactStore()
+	if store == nil {
+		return "", artifacts.ErrPresignUnsupported
+	}
+
+	digest := sha256.Sum256(result.Archive)
+	key := artifacts.UpgradeKey(projectID, hex.EncodeToString(digest[:]))
+	logger := logging.FromContext(ctx).With("project_id", projectID, "key", key)
+	if err := store.Put(ctx, key, result.Archive, "application/zip"); err != nil {
+		logger.Error("failed to store upgrade archive", "error", err)
+		return "", err
+	}
+
+	url, err := store.PresignGet(ctx, key, result.Filename, expires)
+	if err != nil {
+		logger.Error("failed to presign upgrade archive URL", "error", err)
+		return "", err
+	}
+	return url, nil
+}
+
+// mergeProjectFiles merges every file of the three versions and reports
+// what happened to each
+func mergeProjectFiles(base, ours, theirs []models.ProjectFile, labels merge.Labels) ([]models.ProjectFile, models.UpgradeReport) {
+	baseFiles, ourFiles, theirFiles := fileContents(base), fileContents(ours), fileContents(theirs)
+	paths := make(map[string]bool)
+	for _, files := range []map[string]string{baseFiles, ourFiles, theirFiles} {
+		for path := range files {
+			paths[path] = true
+		}
+	}
+	sorted := make([]string, 0, len(paths))
+	for path := range paths {
+		sorted = append(sorted, path)
+	}
+	sort.Strings(sorted)
+
+	report := models.UpgradeReport{Files: []models.UpgradeFile{}, Summary: make(map[string]int)}
+	var merged []models.ProjectFile
+	for _, path := range sorted {
+		content, keep, file := mergeFile(path, baseFiles, ourFiles, theirFiles, labels)
+		if keep {
+			merged = append(merged, models.ProjectFile{Path: path, Content: content})
+		}
+		if file.Status == "" {
+			continue
+		}
+		report.Summary[file.Status]++
+		switch file.Status {
+		case models.UpgradeFileUnchanged, models.UpgradeFileKept:
+		case models.UpgradeFileConflict:
+			report.Conflicts++
+			report.Files = append(report.Files, file)
+		default:
+			report.Files = append(report.Files, file)
+		}
+	}
+	return overlayFiles(nil, merged), report
+}
+
+// mergeFile merges one path. It returns the merged content, whether the file
+// stays in the project, and its report entry; files only ours has, and
+// files ours deleted that the template did not change, have no status.
+func mergeFile(path string, base, ours, theirs map[string]string, labels merge.Labels) (string, bool, models.UpgradeFile) {
+	b, inBase := base[path]
+	o, inOurs := ours[path]
+	t, inTheirs := theirs[path]
+	file := models.UpgradeFile{Path: path}
+
+	switch {
+	case inOurs && inTheirs && o == t:
+		file.Status = models.UpgradeFileUnchanged
+		return o, true, file
+
+	case !inOurs:
+		switch {
+		case !inBase:
+			file.Status = models.UpgradeFileAdded
+			return t, true, file
+		case inTheirs && t != b:
+			file.Status, file.Reason = models.UpgradeFileConflict, "deleted locally but changed by the template"
+			return t, true, file
+		}
+		// Deleted locally, and the template did not change it or dropped it too
+		return "", false, file
+
+	case !inTheirs:
+		switch {
+		case !inBase:
+			return o, true, file
+		case o == b:
+			file.Status = models.UpgradeFileRemoved
+			return "", false, file
+		}
+		file.Status, file.Reason = models.UpgradeFileConflict, "changed locally but removed from the template"
+		return o, true, file
+
+	case inBase && o == b:
+		file.Status = models.UpgradeFileUpdated
+		return t, true, file
+
+	case inBase && t == b:
+		file.Status = models.UpgradeFileKept
+		return o, true, file
+
+	case isBinary(o) || isBinary(t) || isBinary(b):
+		file.Status, file.Reason = models.UpgradeFileConflict, "binary file changed locally and by the template; the local version was kept"
+		return o, true, file
+	}
+
+	// Changed on both sides, or added on both sides when there is no base
+	result := merge.Merge(b, o, t, labels)
+	if result.Clean() {
+		file.Status = models.UpgradeFileMerged
+		return result.Content, true, file
+	}
+	file.Status, file.Conflicts = models.UpgradeFileConflict, result.Conflicts
+	return result.Content, true, file
+}
+
+// readUpgradeArchive reads the files of an uploaded ZIP archive. Their paths
+// are rebased onto the project's directory: archives of a generated project
+// hold it under one top-level directory, which may have been renamed, and
+// archives of its contents hold none.
+func readUpgradeArchive(data []byte, projectName string) ([]models.ProjectFile, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	if len(reader.File) > maxUpgradeArchiveEntries {
+		return nil, fmt.Errorf("%w: more than %d entries", ErrInvalidArchive, maxUpgradeArchiveEntries)
+	}
+
+	var files []models.ProjectFile
+	var total int64
+	for _, entry := range reader.File {
+		name := path.Clean(strings.TrimPrefix(entry.Name, "./"))
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("%w: entry %s is outside the archive", ErrInvalidArchive, entry.Name)
+		}
+
+		content, err := readArchiveEntry(entry, maxUpgradeArchiveBytes-total)
+		if err != nil {
+			return nil, err
+		}
+		total += int64(len(content))
+		files = append(files, models.ProjectFile{Path: name, Content: string(content)})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: archive holds no files", ErrInvalidArchive)
+	}
+
+	root := ""
+	for i, file := range files {
+		top, _, nested := strings.Cut(file.Path, "/")
+		if !nested || (i > 0 && top != root) {
+			root = ""
+			break
+		}
+		root = top
+	}
+	for i := range files {
+		rel := files[i].Path
+		if root != "" {
+			rel = strings.TrimPrefix(rel, root+"/")
+		}
+		files[i].Path = filepath.Join(projectName, filepath.FromSlash(rel))
+	}
+	return files, nil
+}
+
+func readArchiveEntry(entry *zip.File, limit int64) ([]byte, error) {
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to open %s: %v", ErrInvalidArchive, entry.Name, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read %s: %v", ErrInvalidArchive, entry.Name, err)
+	}
+	if int64(len(content)) > limit {
+		return nil, fmt.Errorf("%w: archive expands to more than %d bytes", ErrInvalidArchive, maxUpgradeArchiveBytes)
+	}
+	return content, nil
+}
+
+// fileContents maps the paths of the regular files among files to their content
+func fileContents(files []models.ProjectFile) map[string]string {
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		if !file.IsDirectory {
+			contents[file.Path] = file.Content
+		}
+	}
+	return contents
+}
+
+func countProjectFiles(files []models.ProjectFile) int {
+	count := 0
+	for _, file := range files {
+		if !file.IsDirectory {
+			count++
+		}
+	}
+	return count
+}
+
+// isBinary reports whether content does not look like text
+func isBinary(content string) bool {
+	return strings.IndexByte(content, 0) >= 0 || !utf8.ValidString(content)
+}
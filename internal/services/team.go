@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTeamNotFound is returned when a team does not exist
+	ErrTeamNotFound = apperror.NotFound("team_not_found", "team not found")
+	// ErrTeamMemberNotFound is returned when a principal is not a member of a team
+	ErrTeamMemberNotFound = apperror.NotFound("team_member_not_found", "team member not found")
+	// ErrLastTeamOwner is returned when a change would leave a team without an owner
+	ErrLastTeamOwner = apperror.Conflict("last_team_owner", "a team must keep at least one owner")
+)
+
+// TeamService manages teams and their members. Role checks are left to the
+// API layer; the service only keeps every team with at least one owner.
+type TeamService struct {
+	teams map[string]*models.Team
+	store storage.TeamStore // Optional; teams are only kept in memory when nil
+	mu    sync.RWMutex
+}
+
+func NewTeamService() *TeamService {
+	return &TeamService{
+		teams: make(map[string]*models.Team),
+	}
+}
+
+// SetStore persists teams in store. Reads go to the store so that every
+// instance sees the same memberships; memory only caches.
+func (s *TeamService) SetStore(store storage.TeamStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// CreateTeam creates a team with ownerID as its only member and owner
+func (s *TeamService) CreateTeam(ctx context.Context, name, ownerID string) (*models.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperror.InvalidField("name", "team name cannot be empty")
+	}
+
+	now := time.Now()
+	team := &models.Team{
+		ID:   uuid.New().String(),
+		Name: name,
+		Members: []models.TeamMember{
+			{PrincipalID: ownerID, Role: models.RoleOwner, AddedAt: now},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.saveLocked(ctx, team); err != nil {
+		return nil, err
+	}
+	return copyTeam(team), nil
+}
+
+// GetTeam returns a copy of a team
+func (s *TeamService) GetTeam(ctx context.Context, teamID string) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, err := s.teamLocked(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return copyTeam(team), nil
+}
+
+// ListTeams returns the teams principalID is a member of, sorted by name
+func (s *TeamService) ListTeams(ctx context.Context, principalID string) []models.Team {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	teams := []models.Team{}
+	for _, team := range s.allTeamsLocked(ctx) {
+		if team.Role(principalID) != "" {
+			teams = append(teams, *copyTeam(team))
+		}
+	}
+
+	sort.Slice(teams, func(i, j int) bool {
+		if teams[i].Name != teams[j].Name {
+			return teams[i].Name < teams[j].Name
+		}
+		return teams[i].ID < teams[j].ID
+	})
+	return teams
+}
+
+// Roles returns principalID's role in each of their teams, keyed by team ID
+func (s *TeamService) Roles(ctx context.Context, principalID string) map[string]models.TeamRole {
+	roles := make(map[string]models.TeamRole)
+	for _, team := range s.ListTeams(ctx, principalID) {
+		roles[team.ID] = team.Role(principalID)
+	}
+	return roles
+}
+
+// RenameTeam changes a team's name
+func (s *TeamService) RenameTeam(ctx context.Context, teamID, name string) (*models.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apperror.InvalidField("name", "team name cannot be empty")
+	}
+
+	return s.update(ctx, teamID, func(team *models.Team) error {
+		team.Name = name
+		return nil
+	})
+}
+
+// SetMember adds principalID to a team with role, or changes their role
+func (s *TeamService) SetMember(ctx context.Context, teamID, principalID string, role models.TeamRole) (*models.Team, error) {
+	if !role.Valid() {
+		return nil, apperror.InvalidField("role", fmt.Sprintf("invalid team role: %s", role))
+	}
+
+	return s.update(ctx, teamID, func(team *models.Team) error {
+		for i := range team.Members {
+			if team.Members[i].PrincipalID != principalID {
+				continue
+			}
+			if team.Members[i].Role == models.RoleOwner && role != models.RoleOwner && countOwners(team) == 1 {
+				return ErrLastTeamOwner
+			}
+			team.Members[i].Role = role
+			return nil
+		}
+
+		team.Members = append(team.Members, models.TeamMember{
+			PrincipalID: principalID,
+			Role:        role,
+			AddedAt:     time.Now(),
+		})
+		return nil
+	})
+}
+
+// RemoveMember removes principalID from a team
+func (s *TeamService) RemoveMember(ctx context.Context, teamID, principalID string) (*models.Team, error) {
+	return s.update(ctx, teamID, func(team *models.Team) error {
+		for i, member := range team.Members {
+			if member.PrincipalID != principalID {
+				continue
+			}
+			if member.Role == models.RoleOwner && countOwners(team) == 1 {
+				return ErrLastTeamOwner
+			}
+			team.Members = append(team.Members[:i], team.Members[i+1:]...)
+			return nil
+		}
+		return fmt.Errorf("%w: %s", ErrTeamMemberNotFound, principalID)
+	})
+}
+
+// DeleteTeam removes a team. Projects, template packs and chat sessions
+// shared with it fall back to the principals who created them.
+func (s *TeamService) DeleteTeam(ctx context.Context, teamID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.teamLocked(ctx, teamID); err != nil {
+		return err
+	}
+
+	delete(s.teams, teamID)
+	if s.store != nil {
+		if err := s.store.DeleteTeam(ctx, teamID); err != nil {
+			return fmt.Errorf("failed to delete team: %w", err)
+		}
+	}
+	return nil
+}
+
+// update applies change to a copy of the team and saves it if change succeeds
+func (s *TeamService) update(ctx context.Context, teamID string, change func(team *models.Team) error) (*models.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.teamLocked(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	team := copyTeam(current)
+	if err := change(team); err != nil {
+		return nil, err
+	}
+	team.UpdatedAt = time.Now()
+
+	if err := s.saveLocked(ctx, team); err != nil {
+		return nil, err
+	}
+	return copyTeam(team), nil
+}
+
+// teamLocked returns a team, reloading it from the store when one is
+// configured. The caller must hold the write lock.
+func (s *TeamService) teamLocked(ctx context.Context, teamID string) (*models.Team, error) {
+	if s.store == nil {
+		team, exists := s.teams[teamID]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrTeamNotFound, teamID)
+		}
+		return team, nil
+	}
+
+	team, err := s.store.GetTeam(ctx, teamID)
+	if errors.Is(err, storage.ErrNotFound) {
+		delete(s.teams, teamID)
+		return nil, fmt.Errorf("%w: %s", ErrTeamNotFound, teamID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load team: %w", err)
+	}
+
+	s.teams[team.ID] = team
+	return team, nil
+}
+
+// allTeamsLocked returns every team from the store, or from memory when
+// there is none or it fails. The caller must hold the write lock.
+func (s *TeamService) allTeamsLocked(ctx context.Context) []*models.Team {
+	if s.store != nil {
+		teams, err := s.store.ListTeams(ctx)
+		if err == nil {
+			return teams
+		}
+		logging.FromContext(ctx).Warn("failed to list stored teams, using cached ones", "error", err)
+	}
+
+	teams := make([]*models.Team, 0, len(s.teams))
+	for _, team := range s.teams {
+		teams = append(teams, team)
+	}
+	return teams
+}
+
+// saveLocked writes a team to the store, if any, and caches it. The caller
+// must hold the write lock.
+func (s *TeamService) saveLocked(ctx context.Context, team *models.Team) error {
+	if s.store != nil {
+		if err := s.store.SaveTeam(ctx, team); err != nil {
+			return fmt.Errorf("failed to save team: %w", err)
+		}
+	}
+	s.teams[team.ID] = team
+	return nil
+}
+
+func copyTeam(team *models.Team) *models.Team {
+	copied := *team
+	copied.Members = append([]models.TeamMember(nil), team.Members...)
+	return &copied
+}
+
+func countOwners(team *models.Team) int {
+	owners := 0
+	for _, member := range team.Members {
+		if member.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	return owners
+}
@@ -3,17 +3,22 @@ package services
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"fmt"
 	"path/filepath"
 	"strings"
 	"text/template"
 
 	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type TemplateService struct {
-	goTemplates  map[string]*template.Template
+	goTemplates  map[string]*template.Template // Loaded from template directories, keyed by path
 	phpTemplates map[string]*template.Template
+	observer     Observer // Optional
 }
 
 func NewTemplateService() *TemplateService {
@@ -90,11 +95,67 @@ func (s *TemplateService) GetAvailableTemplates() []models.TemplateInfo {
 	}
 }
 
-func (s *TemplateService) GenerateGoProject(project *models.Project) ([]models.ProjectFile, error) {
-	var files []models.ProjectFile
-
+func (s *TemplateService) GenerateGoProject(ctx context.Context, project *models.Project) ([]models.ProjectFile, error) {
+	return s.render(ctx, project, goTemplateData(project), []generatorStep{
+		// Generate directory structure first
+		{"directories", s.createGoDirectoryStructure},
+
+		// Generate core files
+		{"go_mod", single(s.generateGoModFile)},
+		{"main", single(s.generateGoMainFile)},
+		{"makefile", single(s.generateGoMakefile)},
+		{"dockerfile", single(s.generateGoDockerfile)},
+		{"readme", single(s.generateGoReadme)},
+		{"gitignore", single(s.generateGoGitignore)},
+		{"env", s.generateGoEnvFiles},
+
+		// Generate application structure
+		{"config", s.generateGoConfigFiles},
+		{"middleware", s.generateGoMiddleware},
+		{"controllers", s.generateGoControllers},
+		{"services", s.generateGoServices},
+		{"repositories", s.generateGoRepositories},
+		{"models", s.generateGoModels},
+		{"utilities", s.generateGoUtilities},
+		{"routes", single(s.generateGoRoutes)},
+	})
+}
+
+func (s *TemplateService) GeneratePHPProject(ctx context.Context, project *models.Project) ([]models.ProjectFile, error) {
 	// Template data
 	data := map[string]interface{}{
+		"ProjectName": project.Name,
+		"Description": project.Description,
+		"CIVersion":   project.Options.CIVersion,
+		"Database":    project.Options.Database,
+		"Frontend":    project.Options.Frontend,
+		"Features":    project.Options.Features,
+	}
+
+	return s.render(ctx, project, data, []generatorStep{
+		// Generate directory structure first
+		{"directories", s.createPHPDirectoryStructure},
+
+		// Generate core files
+		{"index", single(s.generatePHPIndexFile)},
+		{"composer", single(s.generatePHPComposerFile)},
+		{"readme", single(s.generatePHPReadme)},
+		{"gitignore", single(s.generatePHPGitignore)},
+
+		// Generate application structure
+		{"config", s.generatePHPConfigFiles},
+		{"controllers", s.generatePHPControllers},
+		{"models", s.generatePHPModels},
+		{"views", s.generatePHPViews},
+		{"helpers", s.generatePHPHelpers},
+		{"libraries", s.generatePHPLibraries},
+		{"core", s.generatePHPCore},
+	})
+}
+
+// goTemplateData is the data Go templates are rendered with
+func goTemplateData(project *models.Project) map[string]interface{} {
+	return map[string]interface{}{
 		"ProjectName":    project.Name,
 		"Description":    project.Description,
 		"Framework":      project.Options.Framework,
@@ -103,71 +164,72 @@ func (s *TemplateService) GenerateGoProject(project *models.Project) ([]models.P
 		"Utilities":      project.Options.Utilities,
 		"PackageName":    strings.ToLower(strings.ReplaceAll(project.Name, " ", "-")),
 	}
+}
 
-	// Generate directory structure first
-	files = append(files, s.createGoDirectoryStructure(data)...)
-
-	// Generate core files
-	files = append(files, s.generateGoModFile(data))
-	files = append(files, s.generateGoMainFile(data))
-	files = append(files, s.generateGoMakefile(data))
-	files = append(files, s.generateGoDockerfile(data))
-	files = append(files, s.generateGoReadme(data))
-	files = append(files, s.generateGoGitignore(data))
-	files = append(files, s.generateGoEnvFiles(data)...)
-
-	// Generate application structure
-	files = append(files, s.generateGoConfigFiles(data)...)
-	files = append(files, s.generateGoMiddleware(data)...)
-	files = append(files, s.generateGoControllers(data)...)
-	files = append(files, s.generateGoServices(data)...)
-	files = append(files, s.generateGoRepositories(data)...)
-	files = append(files, s.generateGoModels(data)...)
-	files = append(files, s.generateGoUtilities(data)...)
-	files = append(files, s.generateGoRoutes(data))
+// generatorStep renders one part of a project from the template data
+type generatorStep struct {
+	name   string
+	render func(data map[string]interface{}) []models.ProjectFile
+}
 
-	return files, nil
+// single adapts a step that renders one file
+func single(render func(map[string]interface{}) models.ProjectFile) func(map[string]interface{}) []models.ProjectFile {
+	return func(data map[string]interface{}) []models.ProjectFile {
+		return []models.ProjectFile{render(data)}
+	}
 }
 
-func (s *TemplateService) GeneratePHPProject(project *models.Project) ([]models.ProjectFile, error) {
-	var files []models.ProjectFile
+// render runs the steps in order, tracing each one as its own span. It stops
+// with ctx's error when ctx is done before the last step.
+func (s *TemplateService) render(ctx context.Context, project *models.Project, data map[string]interface{}, steps []generatorStep) (files []models.ProjectFile, err error) {
+	ctx, span := tracing.Start(ctx, "template.render", projectAttributes(project)...)
+	defer func() { tracing.End(span, err) }()
 
-	// Template data
-	data := map[string]interface{}{
-		"ProjectName": project.Name,
-		"Description": project.Description,
-		"CIVersion":   project.Options.CIVersion,
-		"Database":    project.Options.Database,
-		"Frontend":    project.Options.Frontend,
-		"Features":    project.Options.Features,
-	}
+	for _, step := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("rendering stopped before %s: %w", step.name, err)
+		}
 
-	// Generate directory structure first
-	files = append(files, s.createPHPDirectoryStructure(data)...)
+		_, stepSpan := tracing.Start(ctx, "template.render."+step.name)
+		rendered := step.render(data)
+		stepSpan.SetAttributes(attribute.Int("template.files", len(rendered)))
+		stepSpan.End()
 
-	// Generate core files
-	files = append(files, s.generatePHPIndexFile(data))
-	files = append(files, s.generatePHPComposerFile(data))
-	files = append(files, s.generatePHPReadme(data))
-	files = append(files, s.generatePHPGitignore(data))
+		files = append(files, rendered...)
+	}
 
-	// Generate application structure
-	files = append(files, s.generatePHPConfigFiles(data)...)
-	files = append(files, s.generatePHPControllers(data)...)
-	files = append(files, s.generatePHPModels(data)...)
-	files = append(files, s.generatePHPViews(data)...)
-	files = append(files, s.generatePHPHelpers(data)...)
-	files = append(files, s.generatePHPLibraries(data)...)
-	files = append(files, s.generatePHPCore(data)...)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("rendering stopped before template directories: %w", err)
+	}
+	_, dirSpan := tracing.Start(ctx, "template.render.template_dirs")
+	overlays, err := s.renderDirTemplates(ctx, project, data)
+	dirSpan.SetAttributes(attribute.Int("template.files", len(overlays)))
+	tracing.End(dirSpan, err)
+	if err != nil {
+		return nil, err
+	}
+	files = overlayFiles(files, overlays)
 
+	span.SetAttributes(attribute.Int("template.files", len(files)))
+	projectLogger(ctx, project).Debug("rendered templates", "files", len(files))
 	return files, nil
 }
 
-func (s *TemplateService) CreateZIPArchive(project *models.Project) ([]byte, error) {
+func (s *TemplateService) CreateZIPArchive(ctx context.Context, project *models.Project) (zipData []byte, err error) {
+	ctx, span := tracing.Start(ctx, "template.create_zip", projectAttributes(project)...)
+	defer func() {
+		span.SetAttributes(attribute.Int("template.files", len(project.Files)), attribute.Int("archive.bytes", len(zipData)))
+		tracing.End(span, err)
+	}()
+
 	var buf bytes.Buffer
 	zipWriter := zip.NewWriter(&buf)
 
 	for _, file := range project.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("archiving stopped before %s: %w", file.Path, err)
+		}
+
 		if file.IsDirectory {
 			// Create directory entry
 			_, err := zipWriter.Create(file.Path + "/")
@@ -188,11 +250,15 @@ func (s *TemplateService) CreateZIPArchive(project *models.Project) ([]byte, err
 		}
 	}
 
-	err := zipWriter.Close()
+	err = zipWriter.Close()
 	if err != nil {
 		return nil, fmt.Errorf("failed to close ZIP writer: %w", err)
 	}
 
+	if s.observer != nil {
+		s.observer.ArchiveCreated(project, buf.Len())
+	}
+	projectLogger(ctx, project).Debug("archive created", "files", len(project.Files), "bytes", buf.Len())
 	return buf.Bytes(), nil
 }
 
@@ -319,7 +385,7 @@ func (s *TemplateService) generateGoGitignore(data map[string]interface{}) model
 func (s *TemplateService) generateGoEnvFiles(data map[string]interface{}) []models.ProjectFile {
 	return []models.ProjectFile{
 		{Path: filepath.Join(data["ProjectName"].(string), ".env"), Content: "# Environment variables", IsDirectory: false},
-		{Path: filepath.Join(data["ProjectName"].(string), ".env.example"), Content: "# Environment example", IsDirectory: false},
+		{Path: filepath.Join(data["ProjectName"].(string), ".env.example"), Content: "# Environment example\n" + anchorEnv + "\n", IsDirectory: false},
 	}
 }
 
@@ -348,11 +414,29 @@ func (s *TemplateService) generateGoModels(data map[string]interface{}) []models
 }
 
 func (s *TemplateService) generateGoUtilities(data map[string]interface{}) []models.ProjectFile {
-	return []models.ProjectFile{}
+	utilities, _ := data["Utilities"].([]string)
+	var files []models.ProjectFile
+	for _, utility := range utilities {
+		files = append(files, goUtilityFile(data, utility))
+	}
+	return files
 }
 
+// generateGoRoutes renders the router with the anchors components add
+// their routes and imports at
 func (s *TemplateService) generateGoRoutes(data map[string]interface{}) models.ProjectFile {
-	return models.ProjectFile{Path: filepath.Join(data["ProjectName"].(string), "internal", "routes", "router.go"), Content: "// Router placeholder", IsDirectory: false}
+	router := goRouterFor(data["Framework"].(string))
+
+	var content strings.Builder
+	content.WriteString("package routes\n\nimport (\n")
+	for _, path := range router.imports {
+		fmt.Fprintf(&content, "\t%q\n", path)
+	}
+	fmt.Fprintf(&content, "\t%s\n)\n\n", anchorImports)
+	content.WriteString("// Register adds the application's routes to router\n")
+	fmt.Fprintf(&content, "func Register(%s) {\n\t%s\n}\n", router.parameter, anchorRoutes)
+
+	return models.ProjectFile{Path: goRouterPath(data), Content: content.String(), IsDirectory: false}
 }
 
 // PHP file generation functions (placeholders for now)
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// templateSuffix is dropped from the paths of files loaded from template
+// directories, so templates of Go files need not be Go files themselves
+const templateSuffix = ".tmpl"
+
+// LoadTemplateDirs adds the file templates in dirs to generated projects.
+// Each directory holds one subdirectory per language, go and php. Every file
+// in one is a text/template, rendered with the same data as the built-in
+// templates and written to the same relative path under the project's
+// directory, where it replaces a generated file. A .tmpl suffix is dropped
+// from the path. Later directories take precedence over earlier ones. Call it
+// before generating projects.
+func (s *TemplateService) LoadTemplateDirs(dirs []string) error {
+	for _, dir := range dirs {
+		for language, templates := range map[models.ProjectLanguage]map[string]*template.Template{
+			models.LanguageGo:  s.goTemplates,
+			models.LanguagePHP: s.phpTemplates,
+		} {
+			if err := loadTemplateDir(filepath.Join(dir, string(language)), templates); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadTemplateDir parses every file under root into templates, keyed by its
+// path relative to root. A missing root holds no templates.
+func loadTemplateDir(root string, templates map[string]*template.Template) error {
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		tmpl, err := template.New(rel).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			return fmt.Errorf("failed to parse template %s: %w", path, err)
+		}
+		templates[strings.TrimSuffix(rel, templateSuffix)] = tmpl
+		return nil
+	})
+}
+
+// renderDirTemplates renders the project language's templates loaded from
+// template directories. It stops with ctx's error when ctx is done before the
+// last file.
+func (s *TemplateService) renderDirTemplates(ctx context.Context, project *models.Project, data map[string]interface{}) ([]models.ProjectFile, error) {
+	templates := s.goTemplates
+	if project.Language == models.LanguagePHP {
+		templates = s.phpTemplates
+	}
+
+	paths := make([]string, 0, len(templates))
+	for path := range templates {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	files := make([]models.ProjectFile, 0, len(paths))
+	for _, path := range paths {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("rendering stopped before template %s: %w", path, err)
+		}
+
+		var content bytes.Buffer
+		if err := templates[path].Execute(&content, data); err != nil {
+			return nil, fmt.Errorf("failed to render template %s: %w", path, err)
+		}
+		files = append(files, models.ProjectFile{Path: filepath.Join(project.Name, path), Content: content.String()})
+	}
+	return files, nil
+}
+
+// overlayFiles replaces generated files with overlays at the same path and
+// adds the others, with entries for directories that were not generated
+func overlayFiles(files, overlays []models.ProjectFile) []models.ProjectFile {
+	existing := make(map[string]bool, len(files))
+	for _, file := range files {
+		existing[file.Path] = true
+	}
+
+	for _, overlay := range overlays {
+		var parents []string
+		for dir := filepath.Dir(overlay.Path); dir != "." && dir != string(filepath.Separator) && !existing[dir]; dir = filepath.Dir(dir) {
+			existing[dir] = true
+			parents = append(parents, dir)
+		}
+		for i := len(parents) - 1; i >= 0; i-- {
+			files = append(files, models.ProjectFile{Path: parents[i], IsDirectory: true})
+		}
+	}
+	return applyFiles(files, overlays)
+}
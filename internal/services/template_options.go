@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+)
+
+// goUtilities are the utility packages the Go template offers
+var goUtilities = []string{
+	"authentication", "cache", "common", "constants", "converter",
+	"date", "datatype", "encryption", "exception", "exceptioncode",
+	"helper", "httphelper", "json", "logger", "password",
+	"queryhelper", "sort", "template", "validator", "alert",
+}
+
+// listOptions are the project options that hold several values, written
+// comma-separated wherever options are given as text
+var listOptions = map[string]bool{"utilities": true, "components": true, "features": true}
+
+// GenerateProject renders the files of project with the template for its
+// language, with the components its options record added
+func (s *TemplateService) GenerateProject(ctx context.Context, project *models.Project) ([]models.ProjectFile, error) {
+	switch project.Language {
+	case models.LanguageGo:
+		files, err := s.GenerateGoProject(ctx, project)
+		if err != nil {
+			return nil, err
+		}
+		return s.addRecordedComponents(ctx, project, files)
+	case models.LanguagePHP:
+		return s.GeneratePHPProject(ctx, project)
+	default:
+		return nil, fmt.Errorf("unsupported language: %s", project.Language)
+	}
+}
+
+// ApplyDefaults fills the options project leaves unset with the defaults of
+// its language's template
+func (s *TemplateService) ApplyDefaults(project *models.Project) {
+	switch project.Language {
+	case models.LanguageGo:
+		// Set default Go options if not specified
+		if project.Options.Framework == "" {
+			project.Options.Framework = "gin"
+		}
+		if project.Options.Database == "" {
+			project.Options.Database = "postgresql"
+		}
+		if project.Options.Authentication == "" {
+			project.Options.Authentication = "jwt"
+		}
+		if len(project.Options.Utilities) == 0 {
+			// Default to all utility packages
+			project.Options.Utilities = append([]string(nil), goUtilities...)
+		}
+
+	case models.LanguagePHP:
+		// Set default PHP options if not specified
+		if project.Options.CIVersion == "" {
+			project.Options.CIVersion = "3"
+		}
+		if project.Options.Database == "" {
+			project.Options.Database = "postgresql"
+		}
+		if project.Options.Frontend == "" {
+			project.Options.Frontend = "bootstrap"
+		}
+		if len(project.Options.Features) == 0 {
+			project.Options.Features = []string{"authentication", "user_management", "dashboard"}
+		}
+	}
+}
+
+// ValidateOptions checks that language has a template and that every select
+// option set in options is one the template offers
+func (s *TemplateService) ValidateOptions(language models.ProjectLanguage, options models.ProjectOptions) error {
+	template, ok := s.Template(language)
+	if !ok {
+		return apperror.InvalidField("language", fmt.Sprintf("unsupported language: %s", language))
+	}
+
+	for _, option := range template.Options {
+		value := OptionValue(options, option.Key)
+		if value == "" || len(option.Options) == 0 || containsValue(option.Options, value) {
+			continue
+		}
+		return apperror.InvalidField("options."+option.Key, fmt.Sprintf("invalid %s %q for %s: expected one of %s", option.Key, value, language, strings.Join(option.Options, ", ")))
+	}
+
+	for _, spec := range options.Components {
+		if language != models.LanguageGo {
+			return apperror.InvalidField("options.components", fmt.Sprintf("components can only be added to Go projects, not %s", language))
+		}
+		if _, err := ParseComponent(spec); err != nil {
+			return apperror.InvalidField("options.components", apperror.From(err).Message)
+		}
+	}
+	return nil
+}
+
+// Template returns the template for language
+func (s *TemplateService) Template(language models.ProjectLanguage) (models.TemplateInfo, bool) {
+	for _, template := range s.GetAvailableTemplates() {
+		if template.Language == language {
+			return template, true
+		}
+	}
+	return models.TemplateInfo{}, false
+}
+
+// OptionValue returns the option named by a TemplateOption key, with list
+// options joined by commas
+func OptionValue(options models.ProjectOptions, key string) string {
+	switch key {
+	case "framework":
+		return options.Framework
+	case "database":
+		return options.Database
+	case "authentication":
+		return options.Authentication
+	case "ci_version":
+		return options.CIVersion
+	case "frontend":
+		return options.Frontend
+	case "utilities":
+		return strings.Join(options.Utilities, ",")
+	case "components":
+		return strings.Join(options.Components, ",")
+	case "features":
+		return strings.Join(options.Features, ",")
+	}
+	return ""
+}
+
+// SetOption sets the option named by a TemplateOption key. List options take
+// comma-separated values.
+func SetOption(options *models.ProjectOptions, key, value string) error {
+	var list []string
+	if listOptions[key] {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+
+	switch key {
+	case "framework":
+		options.Framework = value
+	case "database":
+		options.Database = value
+	case "authentication":
+		options.Authentication = value
+	case "ci_version":
+		options.CIVersion = value
+	case "frontend":
+		options.Frontend = value
+	case "utilities":
+		options.Utilities = list
+	case "components":
+		options.Components = list
+	case "features":
+		options.Features = list
+	default:
+		return apperror.InvalidField("options", fmt.Sprintf("unknown option: %s", key))
+	}
+	return nil
+}
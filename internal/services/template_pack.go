@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrTemplatePackNotFound is returned when a template pack does not exist
+	ErrTemplatePackNotFound = apperror.NotFound("template_pack_not_found", "template pack not found")
+	// ErrTemplatePackLanguage is returned when a pack is applied to a project in another language
+	ErrTemplatePackLanguage = apperror.Validation("template_pack_language", "template pack is for a different language")
+)
+
+// TemplatePackService manages template packs: curated, shareable presets of
+// template options that projects can be created from
+type TemplatePackService struct {
+	packs           map[string]*models.TemplatePack
+	templateService *TemplateService
+	store           storage.TeamStore // Optional; packs are only kept in memory when nil
+	mu              sync.RWMutex
+}
+
+func NewTemplatePackService(templateService *TemplateService) *TemplatePackService {
+	return &TemplatePackService{
+		packs:           make(map[string]*models.TemplatePack),
+		templateService: templateService,
+	}
+}
+
+// SetStore persists template packs in store. Reads go to the store so that
+// every instance sees the same packs; memory only caches.
+func (s *TemplatePackService) SetStore(store storage.TeamStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.store = store
+}
+
+// CreatePack validates and stores a new template pack
+func (s *TemplatePackService) CreatePack(ctx context.Context, req *models.TemplatePackRequest) (*models.TemplatePack, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pack := &models.TemplatePack{
+		ID:          uuid.New().String(),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Language:    req.Language,
+		Options:     req.Options,
+		TeamID:      req.TeamID,
+		OwnerID:     req.OwnerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.saveLocked(ctx, pack); err != nil {
+		return nil, err
+	}
+	copied := *pack
+	return &copied, nil
+}
+
+// GetPack returns a copy of a template pack
+func (s *TemplatePackService) GetPack(ctx context.Context, packID string) (*models.TemplatePack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pack, err := s.packLocked(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	copied := *pack
+	return &copied, nil
+}
+
+// ListPacks returns every template pack, sorted by name. When the store
+// cannot be read the cached packs are returned instead.
+func (s *TemplatePackService) ListPacks(ctx context.Context) []models.TemplatePack {
+	packs, err := s.LoadPacks(ctx)
+	if err == nil {
+		return packs
+	}
+	logging.FromContext(ctx).Warn("failed to list stored template packs, using cached ones", "error", err)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedPacks(s.cachedPacksLocked())
+}
+
+// LoadPacks returns every template pack, sorted by name, or the error that
+// kept them from being read from the store
+func (s *TemplatePackService) LoadPacks(ctx context.Context) ([]models.TemplatePack, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store == nil {
+		return sortedPacks(s.cachedPacksLocked()), nil
+	}
+
+	stored, err := s.store.ListTemplatePacks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template packs: %w", err)
+	}
+	return sortedPacks(stored), nil
+}
+
+// cachedPacksLocked returns the packs held in memory. The caller must hold the lock.
+func (s *TemplatePackService) cachedPacksLocked() []*models.TemplatePack {
+	packs := make([]*models.TemplatePack, 0, len(s.packs))
+	for _, pack := range s.packs {
+		packs = append(packs, pack)
+	}
+	return packs
+}
+
+func sortedPacks(stored []*models.TemplatePack) []models.TemplatePack {
+	packs := make([]models.TemplatePack, 0, len(stored))
+	for _, pack := range stored {
+		packs = append(packs, *pack)
+	}
+	sort.Slice(packs, func(i, j int) bool {
+		if packs[i].Name != packs[j].Name {
+			return packs[i].Name < packs[j].Name
+		}
+		return packs[i].ID < packs[j].ID
+	})
+	return packs
+}
+
+// UpdatePack replaces a template pack's name, description, language,
+// options and team. Its owner and creation time are kept.
+func (s *TemplatePackService) UpdatePack(ctx context.Context, packID string, req *models.TemplatePackRequest) (*models.TemplatePack, error) {
+	if err := s.validate(req); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.packLocked(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+
+	pack := *current
+	pack.Name = strings.TrimSpace(req.Name)
+	pack.Description = req.Description
+	pack.Language = req.Language
+	pack.Options = req.Options
+	pack.TeamID = req.TeamID
+	pack.UpdatedAt = time.Now()
+
+	if err := s.saveLocked(ctx, &pack); err != nil {
+		return nil, err
+	}
+	copied := pack
+	return &copied, nil
+}
+
+// DeletePack removes a template pack. Projects created from it keep their options.
+func (s *TemplatePackService) DeletePack(ctx context.Context, packID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.packLocked(ctx, packID); err != nil {
+		return err
+	}
+
+	delete(s.packs, packID)
+	if s.store != nil {
+		if err := s.store.DeleteTemplatePack(ctx, packID); err != nil {
+			return fmt.Errorf("failed to delete template pack: %w", err)
+		}
+	}
+	return nil
+}
+
+// ApplyTemplatePack fills the options req leaves unset from pack. The
+// request's language must match the pack's.
+func ApplyTemplatePack(pack *models.TemplatePack, req *models.ProjectRequest) error {
+	if req.Language != pack.Language {
+		return fmt.Errorf("%w: pack %s is for %s, not %s", ErrTemplatePackLanguage, pack.Name, pack.Language, req.Language)
+	}
+
+	options := pack.Options
+	options.Utilities = append([]string(nil), pack.Options.Utilities...)
+	options.Features = append([]string(nil), pack.Options.Features...)
+	mergeOptions(&options, req.Options)
+	req.Options = options
+	return nil
+}
+
+// validate checks the pack's language and that every select option it sets
+// is one the language's template offers
+func (s *TemplatePackService) validate(req *models.TemplatePackRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return apperror.InvalidField("name", "template pack name cannot be empty")
+	}
+
+	return s.templateService.ValidateOptions(req.Language, req.Options)
+}
+
+// packLocked returns a pack, reloading it from the store when one is
+// configured. The caller must hold the write lock.
+func (s *TemplatePackService) packLocked(ctx context.Context, packID string) (*models.TemplatePack, error) {
+	if s.store == nil {
+		pack, exists := s.packs[packID]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrTemplatePackNotFound, packID)
+		}
+		return pack, nil
+	}
+
+	pack, err := s.store.GetTemplatePack(ctx, packID)
+	if errors.Is(err, storage.ErrNotFound) {
+		delete(s.packs, packID)
+		return nil, fmt.Errorf("%w: %s", ErrTemplatePackNotFound, packID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template pack: %w", err)
+	}
+
+	s.packs[pack.ID] = pack
+	return pack, nil
+}
+
+// saveLocked writes a pack to the store, if any, and caches it. The caller
+// must hold the write lock.
+func (s *TemplatePackService) saveLocked(ctx context.Context, pack *models.TemplatePack) error {
+	if s.store != nil {
+		if err := s.store.SaveTemplatePack(ctx, pack); err != nil {
+			return fmt.Errorf("failed to save template pack: %w", err)
+		}
+	}
+	s.packs[pack.ID] = pack
+	return nil
+}
+
+func containsValue(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,640 @@
+package storage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"boilerplate-blueprint/internal/awsauth"
+	"boilerplate-blueprint/internal/models"
+)
+
+// DynamoDBStore keeps all state in a single DynamoDB table.
+//
+// Table design: every item has a string partition key "pk" and sort key "sk".
+// Listings go through one global secondary index, "gsi1", keyed by "gsi1pk"
+// and "gsi1sk" and projecting all attributes.
+//
+//	item                pk                  sk                 gsi1pk    gsi1sk
+//	project             PROJECT#<id>        PROJECT            PROJECTS  <created at>
+//	project revision    PROJECT#<id>        REVISION#<n>       -         -
+//	project chat link   PROJECT#<id>        CHAT               -         -
+//	chat history        CHAT#<session id>   CHAT               CHATS     <updated at>
+//	team                TEAM#<id>           TEAM               TEAMS     <created at>
+//	template pack       PACK#<id>           PACK               PACKS     <created at>
+//
+// Projects, revisions, chat histories, teams and template packs are stored as gzip-compressed JSON in
+// the binary "data" attribute, keeping large histories well inside the 400 KB
+// item limit. Chat history items also carry their summary fields (session_id,
+// title, project_id, owner_id, team_id, message_count, created_at, updated_at) so
+// listings can skip "data", and an "expires_at" epoch-seconds attribute meant
+// to be the table's TTL attribute, so idle sessions are dropped by DynamoDB
+// itself.
+//
+// A project's chat link is only a pointer; it is ignored when the session it
+// names has since been deleted or linked elsewhere. Revision numbers are zero
+// padded so they sort numerically, and timestamps in sort keys use a fixed
+// width UTC layout so they sort chronologically.
+type DynamoDBStore struct {
+	table  string
+	client *dynamoClient
+}
+
+const (
+	dynamoIndexName   = "gsi1"
+	projectsPartition = "PROJECTS"
+	chatsPartition    = "CHATS"
+	teamsPartition    = "TEAMS"
+	packsPartition    = "PACKS"
+	projectSortKey    = "PROJECT"
+	chatSortKey       = "CHAT"
+	teamSortKey       = "TEAM"
+	packSortKey       = "PACK"
+	revisionPrefix    = "REVISION#"
+
+	// ChatTTLAttribute is the attribute to enable as the table's TTL
+	ChatTTLAttribute = "expires_at"
+
+	sortableTime = "2006-01-02T15:04:05.000000000Z"
+)
+
+// DynamoDBConfig configures a DynamoDBStore
+type DynamoDBConfig struct {
+	Table  string
+	Region string
+
+	// Endpoint overrides the AWS endpoint, e.g. http://localhost:8000 for DynamoDB Local
+	Endpoint string
+
+	Credentials awsauth.Credentials
+	HTTPClient  *http.Client
+}
+
+// NewDynamoDBStore validates the configuration and returns a store for the table
+func NewDynamoDBStore(cfg DynamoDBConfig) (*DynamoDBStore, error) {
+	if cfg.Table == "" {
+		return nil, fmt.Errorf("DynamoDB table name is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if !cfg.Credentials.Valid() {
+		return nil, fmt.Errorf("DynamoDB credentials are required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://dynamodb.%s.amazonaws.com", cfg.Region)
+	}
+	if !validEndpoint(endpoint) {
+		return nil, fmt.Errorf("invalid DynamoDB endpoint: %s", endpoint)
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	return &DynamoDBStore{
+		table: cfg.Table,
+		client: &dynamoClient{
+			endpoint: endpoint,
+			signer: awsauth.Signer{
+				Credentials: cfg.Credentials,
+				Region:      cfg.Region,
+				Service:     "dynamodb",
+			},
+			client: client,
+		},
+	}, nil
+}
+
+// EnsureTable creates the table and its index if they do not exist and waits
+// until the table is active. Deployments normally create the table up front;
+// this is for DynamoDB Local and development.
+func (s *DynamoDBStore) EnsureTable(ctx context.Context) error {
+	attribute := func(name string) map[string]string {
+		return map[string]string{"AttributeName": name, "AttributeType": "S"}
+	}
+	key := func(name, keyType string) map[string]string {
+		return map[string]string{"AttributeName": name, "KeyType": keyType}
+	}
+
+	err := s.client.call(ctx, "CreateTable", map[string]interface{}{
+		"TableName": s.table,
+		"AttributeDefinitions": []map[string]string{
+			attribute("pk"), attribute("sk"), attribute("gsi1pk"), attribute("gsi1sk"),
+		},
+		"KeySchema": []map[string]string{key("pk", "HASH"), key("sk", "RANGE")},
+		"GlobalSecondaryIndexes": []map[string]interface{}{{
+			"IndexName":  dynamoIndexName,
+			"KeySchema":  []map[string]string{key("gsi1pk", "HASH"), key("gsi1sk", "RANGE")},
+			"Projection": map[string]string{"ProjectionType": "ALL"},
+		}},
+		"BillingMode": "PAY_PER_REQUEST",
+	}, nil)
+
+	var apiErr *DynamoAPIError
+	if err != nil && !(errors.As(err, &apiErr) && apiErr.Type == "ResourceInUseException") {
+		return fmt.Errorf("failed to create table %s: %w", s.table, err)
+	}
+
+	for {
+		var described struct {
+			Table struct {
+				TableStatus string `json:"TableStatus"`
+			} `json:"Table"`
+		}
+		if err := s.client.call(ctx, "DescribeTable", map[string]string{"TableName": s.table}, &described); err != nil {
+			return fmt.Errorf("failed to describe table %s: %w", s.table, err)
+		}
+		if described.Table.TableStatus == "ACTIVE" {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Ping checks that the table is reachable
+func (s *DynamoDBStore) Ping(ctx context.Context) error {
+	return s.client.call(ctx, "DescribeTable", map[string]string{"TableName": s.table}, nil)
+}
+
+func (s *DynamoDBStore) SaveProject(ctx context.Context, project *models.Project, previousRevision int) error {
+	data, err := encodeData(project)
+	if err != nil {
+		return err
+	}
+
+	item := dynamoItem{
+		"pk":       stringValue(projectPartition(project.ID)),
+		"sk":       stringValue(projectSortKey),
+		"gsi1pk":   stringValue(projectsPartition),
+		"gsi1sk":   stringValue(project.CreatedAt.UTC().Format(sortableTime) + "#" + project.ID),
+		"revision": numberValue(int64(project.Revision)),
+		"data":     binaryValue(data),
+	}
+	if previousRevision == 0 {
+		return s.putItemIf(ctx, item, "attribute_not_exists(pk)", nil)
+	}
+	// Items saved before revisions were stored as an attribute have none
+	return s.putItemIf(ctx, item, "attribute_exists(pk) AND (#revision = :expected OR attribute_not_exists(#revision))", map[string]interface{}{
+		"ExpressionAttributeNames":  map[string]string{"#revision": "revision"},
+		"ExpressionAttributeValues": dynamoItem{":expected": numberValue(int64(previousRevision))},
+	})
+}
+
+func (s *DynamoDBStore) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	item, err := s.getItem(ctx, projectPartition(projectID), projectSortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var project models.Project
+	if err := decodeData(item, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *DynamoDBStore) ListProjects(ctx context.Context) ([]*models.Project, error) {
+	items, err := s.queryIndex(ctx, projectsPartition)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make([]*models.Project, 0, len(items))
+	for _, item := range items {
+		var project models.Project
+		if err := decodeData(item, &project); err != nil {
+			return nil, err
+		}
+		projects = append(projects, &project)
+	}
+
+	return projects, nil
+}
+
+func (s *DynamoDBStore) SaveRevision(ctx context.Context, revision *models.ProjectRevision) error {
+	data, err := encodeData(revision)
+	if err != nil {
+		return err
+	}
+
+	return s.putItemIf(ctx, dynamoItem{
+		"pk":   stringValue(projectPartition(revision.ProjectID)),
+		"sk":   stringValue(revisionSortKey(revision.Revision)),
+		"data": binaryValue(data),
+	}, "attribute_not_exists(pk)", nil)
+}
+
+func (s *DynamoDBStore) GetRevision(ctx context.Context, projectID string, revision int) (*models.ProjectRevision, error) {
+	item, err := s.getItem(ctx, projectPartition(projectID), revisionSortKey(revision))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot models.ProjectRevision
+	if err := decodeData(item, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (s *DynamoDBStore) ListRevisions(ctx context.Context, projectID string) ([]models.ProjectRevision, error) {
+	items, err := s.query(ctx, map[string]interface{}{
+		"TableName":                 s.table,
+		"KeyConditionExpression":    "pk = :pk AND begins_with(sk, :prefix)",
+		"ExpressionAttributeValues": dynamoItem{":pk": stringValue(projectPartition(projectID)), ":prefix": stringValue(revisionPrefix)},
+		"ConsistentRead":            true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := make([]models.ProjectRevision, 0, len(items))
+	for _, item := range items {
+		var snapshot models.ProjectRevision
+		if err := decodeData(item, &snapshot); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, snapshot)
+	}
+
+	return revisions, nil
+}
+
+func (s *DynamoDBStore) SaveChatHistory(ctx context.Context, history *models.ChatHistory, expiresAt time.Time) error {
+	data, err := encodeData(history)
+	if err != nil {
+		return err
+	}
+
+	item := dynamoItem{
+		"pk":            stringValue(chatPartition(history.SessionID)),
+		"sk":            stringValue(chatSortKey),
+		"gsi1pk":        stringValue(chatsPartition),
+		"gsi1sk":        stringValue(history.UpdatedAt.UTC().Format(sortableTime) + "#" + history.SessionID),
+		"session_id":    stringValue(history.SessionID),
+		"title":         stringValue(history.Title),
+		"project_id":    stringValue(history.ProjectID),
+		"owner_id":      stringValue(history.OwnerID),
+		"team_id":       stringValue(history.TeamID),
+		"message_count": numberValue(int64(len(history.Messages))),
+		"created_at":    stringValue(history.CreatedAt.UTC().Format(time.RFC3339Nano)),
+		"updated_at":    stringValue(history.UpdatedAt.UTC().Format(time.RFC3339Nano)),
+		"data":          binaryValue(data),
+	}
+	if !expiresAt.IsZero() {
+		item[ChatTTLAttribute] = numberValue(expiresAt.Unix())
+	}
+
+	if err := s.putItem(ctx, item); err != nil {
+		return err
+	}
+
+	if history.ProjectID == "" {
+		return nil
+	}
+	return s.putItem(ctx, dynamoItem{
+		"pk":         stringValue(projectPartition(history.ProjectID)),
+		"sk":         stringValue(chatSortKey),
+		"session_id": stringValue(history.SessionID),
+	})
+}
+
+func (s *DynamoDBStore) GetChatHistory(ctx context.Context, sessionID string) (*models.ChatHistory, error) {
+	item, err := s.getItem(ctx, chatPartition(sessionID), chatSortKey)
+	if err != nil {
+		return nil, err
+	}
+	if expired(item) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, sessionID)
+	}
+
+	var history models.ChatHistory
+	if err := decodeData(item, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func (s *DynamoDBStore) GetProjectChatHistory(ctx context.Context, projectID string) (*models.ChatHistory, error) {
+	link, err := s.getItem(ctx, projectPartition(projectID), chatSortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.GetChatHistory(ctx, link.str("session_id"))
+	if err != nil {
+		return nil, err
+	}
+	if history.ProjectID != projectID {
+		return nil, fmt.Errorf("%w: chat for project %s", ErrNotFound, projectID)
+	}
+
+	return history, nil
+}
+
+func (s *DynamoDBStore) ListChatSessions(ctx context.Context) ([]models.ChatSession, error) {
+	items, err := s.queryIndex(ctx, chatsPartition,
+		"session_id", "title", "project_id", "owner_id", "team_id", "message_count", "created_at", "updated_at", ChatTTLAttribute)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.ChatSession, 0, len(items))
+	for _, item := range items {
+		if expired(item) {
+			continue
+		}
+		createdAt, _ := time.Parse(time.RFC3339Nano, item.str("created_at"))
+		updatedAt, _ := time.Parse(time.RFC3339Nano, item.str("updated_at"))
+		sessions = append(sessions, models.ChatSession{
+			ID:           item.str("session_id"),
+			Title:        item.str("title"),
+			ProjectID:    item.str("project_id"),
+			OwnerID:      item.str("owner_id"),
+			TeamID:       item.str("team_id"),
+			MessageCount: int(item.num("message_count")),
+			CreatedAt:    createdAt,
+			UpdatedAt:    updatedAt,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (s *DynamoDBStore) DeleteChatHistory(ctx context.Context, sessionID string) error {
+	return s.deleteItem(ctx, chatPartition(sessionID), chatSortKey)
+}
+
+func (s *DynamoDBStore) SaveTeam(ctx context.Context, team *models.Team) error {
+	return s.putListed(ctx, teamPartition(team.ID), teamSortKey, teamsPartition, team.CreatedAt, team.ID, team)
+}
+
+func (s *DynamoDBStore) GetTeam(ctx context.Context, teamID string) (*models.Team, error) {
+	item, err := s.getItem(ctx, teamPartition(teamID), teamSortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var team models.Team
+	if err := decodeData(item, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *DynamoDBStore) ListTeams(ctx context.Context) ([]*models.Team, error) {
+	items, err := s.queryIndex(ctx, teamsPartition)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make([]*models.Team, 0, len(items))
+	for _, item := range items {
+		var team models.Team
+		if err := decodeData(item, &team); err != nil {
+			return nil, err
+		}
+		teams = append(teams, &team)
+	}
+
+	return teams, nil
+}
+
+func (s *DynamoDBStore) DeleteTeam(ctx context.Context, teamID string) error {
+	return s.deleteItem(ctx, teamPartition(teamID), teamSortKey)
+}
+
+func (s *DynamoDBStore) SaveTemplatePack(ctx context.Context, pack *models.TemplatePack) error {
+	return s.putListed(ctx, packPartition(pack.ID), packSortKey, packsPartition, pack.CreatedAt, pack.ID, pack)
+}
+
+func (s *DynamoDBStore) GetTemplatePack(ctx context.Context, packID string) (*models.TemplatePack, error) {
+	item, err := s.getItem(ctx, packPartition(packID), packSortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var pack models.TemplatePack
+	if err := decodeData(item, &pack); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+func (s *DynamoDBStore) ListTemplatePacks(ctx context.Context) ([]*models.TemplatePack, error) {
+	items, err := s.queryIndex(ctx, packsPartition)
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]*models.TemplatePack, 0, len(items))
+	for _, item := range items {
+		var pack models.TemplatePack
+		if err := decodeData(item, &pack); err != nil {
+			return nil, err
+		}
+		packs = append(packs, &pack)
+	}
+
+	return packs, nil
+}
+
+func (s *DynamoDBStore) DeleteTemplatePack(ctx context.Context, packID string) error {
+	return s.deleteItem(ctx, packPartition(packID), packSortKey)
+}
+
+// putListed stores value as the data of an item that is also listed in one
+// partition of the index, ordered by creation time
+func (s *DynamoDBStore) putListed(ctx context.Context, pk, sk, partition string, createdAt time.Time, id string, value interface{}) error {
+	data, err := encodeData(value)
+	if err != nil {
+		return err
+	}
+
+	return s.putItem(ctx, dynamoItem{
+		"pk":     stringValue(pk),
+		"sk":     stringValue(sk),
+		"gsi1pk": stringValue(partition),
+		"gsi1sk": stringValue(createdAt.UTC().Format(sortableTime) + "#" + id),
+		"data":   binaryValue(data),
+	})
+}
+
+func (s *DynamoDBStore) deleteItem(ctx context.Context, pk, sk string) error {
+	return s.client.call(ctx, "DeleteItem", map[string]interface{}{
+		"TableName": s.table,
+		"Key":       dynamoItem{"pk": stringValue(pk), "sk": stringValue(sk)},
+	}, nil)
+}
+
+func (s *DynamoDBStore) putItem(ctx context.Context, item dynamoItem) error {
+	return s.client.call(ctx, "PutItem", map[string]interface{}{
+		"TableName": s.table,
+		"Item":      item,
+	}, nil)
+}
+
+// putItemIf stores one item if condition holds for the stored one, returning
+// ErrConflict when it does not. expression adds the condition's attribute
+// names and values to the request.
+func (s *DynamoDBStore) putItemIf(ctx context.Context, item dynamoItem, condition string, expression map[string]interface{}) error {
+	input := map[string]interface{}{
+		"TableName":           s.table,
+		"Item":                item,
+		"ConditionExpression": condition,
+	}
+	for key, value := range expression {
+		input[key] = value
+	}
+
+	err := s.client.call(ctx, "PutItem", input, nil)
+	var apiErr *DynamoAPIError
+	if errors.As(err, &apiErr) && apiErr.Type == "ConditionalCheckFailedException" {
+		return fmt.Errorf("%w: %s/%s", ErrConflict, item.str("pk"), item.str("sk"))
+	}
+	return err
+}
+
+// getItem does a consistent read of one item, returning ErrNotFound when it is missing
+func (s *DynamoDBStore) getItem(ctx context.Context, pk, sk string) (dynamoItem, error) {
+	var out struct {
+		Item dynamoItem `json:"Item"`
+	}
+	err := s.client.call(ctx, "GetItem", map[string]interface{}{
+		"TableName":      s.table,
+		"Key":            dynamoItem{"pk": stringValue(pk), "sk": stringValue(sk)},
+		"ConsistentRead": true,
+	}, &out)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Item) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNotFound, pk, sk)
+	}
+
+	return out.Item, nil
+}
+
+// queryIndex lists one partition of the index, newest first, reading only
+// the given attributes when any are named
+func (s *DynamoDBStore) queryIndex(ctx context.Context, partition string, attributes ...string) ([]dynamoItem, error) {
+	input := map[string]interface{}{
+		"TableName":                 s.table,
+		"IndexName":                 dynamoIndexName,
+		"KeyConditionExpression":    "gsi1pk = :pk",
+		"ExpressionAttributeValues": dynamoItem{":pk": stringValue(partition)},
+		"ScanIndexForward":          false,
+	}
+	if len(attributes) > 0 {
+		// Placeholders avoid clashes with DynamoDB's reserved words
+		names := make(map[string]string, len(attributes))
+		placeholders := make([]string, len(attributes))
+		for i, attribute := range attributes {
+			placeholders[i] = fmt.Sprintf("#a%d", i)
+			names[placeholders[i]] = attribute
+		}
+		input["ProjectionExpression"] = strings.Join(placeholders, ", ")
+		input["ExpressionAttributeNames"] = names
+	}
+
+	return s.query(ctx, input)
+}
+
+// query runs a Query, following pagination until all items are read
+func (s *DynamoDBStore) query(ctx context.Context, input map[string]interface{}) ([]dynamoItem, error) {
+	var items []dynamoItem
+	for {
+		var out struct {
+			Items            []dynamoItem `json:"Items"`
+			LastEvaluatedKey dynamoItem   `json:"LastEvaluatedKey"`
+		}
+		if err := s.client.call(ctx, "Query", input, &out); err != nil {
+			return nil, err
+		}
+
+		items = append(items, out.Items...)
+		if len(out.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+		input["ExclusiveStartKey"] = out.LastEvaluatedKey
+	}
+}
+
+func projectPartition(projectID string) string {
+	return "PROJECT#" + projectID
+}
+
+func chatPartition(sessionID string) string {
+	return "CHAT#" + sessionID
+}
+
+func teamPartition(teamID string) string {
+	return "TEAM#" + teamID
+}
+
+func packPartition(packID string) string {
+	return "PACK#" + packID
+}
+
+func revisionSortKey(revision int) string {
+	return fmt.Sprintf("%s%010d", revisionPrefix, revision)
+}
+
+// expired reports whether an item's TTL has passed; DynamoDB deletes expired
+// items lazily, so they can still be returned for a while
+func expired(item dynamoItem) bool {
+	expiresAt := item.num(ChatTTLAttribute)
+	return expiresAt > 0 && time.Now().Unix() >= expiresAt
+}
+
+func encodeData(value interface{}) ([]byte, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode item: %w", err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(raw); err != nil {
+		return nil, fmt.Errorf("failed to compress item: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress item: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeData(item dynamoItem, out interface{}) error {
+	reader, err := gzip.NewReader(bytes.NewReader(item["data"].B))
+	if err != nil {
+		return fmt.Errorf("failed to decompress item: %w", err)
+	}
+	defer reader.Close()
+
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("failed to decompress item: %w", err)
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode item: %w", err)
+	}
+
+	return nil
+}
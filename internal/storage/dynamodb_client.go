@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"boilerplate-blueprint/internal/awsauth"
+)
+
+const dynamoTargetPrefix = "DynamoDB_20120810."
+
+// attributeValue is a DynamoDB attribute in its JSON wire form. Only the
+// string, number and binary types are used by this package.
+type attributeValue struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+type dynamoItem map[string]attributeValue
+
+func stringValue(value string) attributeValue {
+	return attributeValue{S: &value}
+}
+
+func numberValue(value int64) attributeValue {
+	n := fmt.Sprintf("%d", value)
+	return attributeValue{N: &n}
+}
+
+func binaryValue(value []byte) attributeValue {
+	return attributeValue{B: value}
+}
+
+// str returns the string attribute name, or "" when it is missing
+func (item dynamoItem) str(name string) string {
+	if value, ok := item[name]; ok && value.S != nil {
+		return *value.S
+	}
+	return ""
+}
+
+// num returns the number attribute name, or 0 when it is missing
+func (item dynamoItem) num(name string) int64 {
+	var n int64
+	if value, ok := item[name]; ok && value.N != nil {
+		fmt.Sscan(*value.N, &n)
+	}
+	return n
+}
+
+// DynamoAPIError is an error returned by the DynamoDB API
+type DynamoAPIError struct {
+	StatusCode int
+	Type       string // e.g. ResourceNotFoundException
+	Message    string
+}
+
+func (e *DynamoAPIError) Error() string {
+	return fmt.Sprintf("dynamodb returned %d %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// dynamoClient calls the DynamoDB JSON API
+type dynamoClient struct {
+	endpoint string
+	signer   awsauth.Signer
+	client   *http.Client
+}
+
+// call invokes an operation, decoding the response into out when it is not nil
+func (c *dynamoClient) call(ctx context.Context, operation string, in, out interface{}) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", operation, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid %s request: %w", operation, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.0")
+	req.Header.Set("X-Amz-Target", dynamoTargetPrefix+operation)
+	c.signer.Sign(req, awsauth.HashPayload(body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("dynamodb %s failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("dynamodb %s failed: %w", operation, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type         string `json:"__type"`
+			Message      string `json:"message"`
+			MessageUpper string `json:"Message"`
+		}
+		json.Unmarshal(data, &apiErr)
+
+		errorType := apiErr.Type
+		if i := strings.LastIndex(errorType, "#"); i >= 0 {
+			errorType = errorType[i+1:]
+		}
+		message := apiErr.Message
+		if message == "" {
+			message = apiErr.MessageUpper
+		}
+
+		return &DynamoAPIError{StatusCode: resp.StatusCode, Type: errorType, Message: message}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to decode %s response: %w", operation, err)
+		}
+	}
+
+	return nil
+}
+
+func validEndpoint(raw string) bool {
+	endpoint, err := url.Parse(raw)
+	return err == nil && endpoint.Scheme != "" && endpoint.Host != ""
+}
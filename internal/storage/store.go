@@ -0,0 +1,70 @@
+// Package storage persists projects, project revisions, chat histories, teams
+// and template packs outside process memory, so that stateless deployments
+// such as Lambda see the same data on every invocation.
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+)
+
+// ErrNotFound is returned when an item does not exist
+var ErrNotFound = errors.New("item not found")
+
+// ErrConflict is returned when a write would overwrite a change made since
+// the item was read
+var ErrConflict = errors.New("item changed")
+
+// Store is a key-value backend for the services' state
+type Store interface {
+	// SaveProject stores the project if the stored copy is still at
+	// previousRevision, or does not exist when it is 0, and returns
+	// ErrConflict otherwise
+	SaveProject(ctx context.Context, project *models.Project, previousRevision int) error
+	// GetProject returns the project, or ErrNotFound
+	GetProject(ctx context.Context, projectID string) (*models.Project, error)
+	// ListProjects returns all projects, newest first
+	ListProjects(ctx context.Context) ([]*models.Project, error)
+
+	// SaveRevision stores a new revision; revisions are never replaced, so
+	// saving one that exists returns ErrConflict
+	SaveRevision(ctx context.Context, revision *models.ProjectRevision) error
+	// GetRevision returns one revision of a project, or ErrNotFound
+	GetRevision(ctx context.Context, projectID string, revision int) (*models.ProjectRevision, error)
+	// ListRevisions returns a project's revisions, oldest first
+	ListRevisions(ctx context.Context, projectID string) ([]models.ProjectRevision, error)
+
+	// SaveChatHistory stores a session; a non-zero expiresAt lets the backend drop it once idle
+	SaveChatHistory(ctx context.Context, history *models.ChatHistory, expiresAt time.Time) error
+	// GetChatHistory returns a session, or ErrNotFound
+	GetChatHistory(ctx context.Context, sessionID string) (*models.ChatHistory, error)
+	// GetProjectChatHistory returns the session linked to a project, or ErrNotFound
+	GetProjectChatHistory(ctx context.Context, projectID string) (*models.ChatHistory, error)
+	// ListChatSessions returns session summaries, most recently active first
+	ListChatSessions(ctx context.Context) ([]models.ChatSession, error)
+	// DeleteChatHistory removes a session; missing sessions are not an error
+	DeleteChatHistory(ctx context.Context, sessionID string) error
+}
+
+// TeamStore is implemented by stores that also keep teams and template packs.
+// Services keep them in process memory when the configured Store does not.
+type TeamStore interface {
+	SaveTeam(ctx context.Context, team *models.Team) error
+	// GetTeam returns the team, or ErrNotFound
+	GetTeam(ctx context.Context, teamID string) (*models.Team, error)
+	// ListTeams returns all teams
+	ListTeams(ctx context.Context) ([]*models.Team, error)
+	// DeleteTeam removes a team; missing teams are not an error
+	DeleteTeam(ctx context.Context, teamID string) error
+
+	SaveTemplatePack(ctx context.Context, pack *models.TemplatePack) error
+	// GetTemplatePack returns the template pack, or ErrNotFound
+	GetTemplatePack(ctx context.Context, packID string) (*models.TemplatePack, error)
+	// ListTemplatePacks returns all template packs
+	ListTemplatePacks(ctx context.Context) ([]*models.TemplatePack, error)
+	// DeleteTemplatePack removes a template pack; missing packs are not an error
+	DeleteTemplatePack(ctx context.Context, packID string) error
+}
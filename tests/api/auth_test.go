@@ -0,0 +1,234 @@
+package api_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	aliceKey = "alice-key-0123456789"
+	bobKey   = "bob-key-0123456789"
+)
+
+func setupAuthRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "alice", Key: aliceKey},
+		{Name: "bob", Key: bobKey},
+	}})
+	require.NoError(t, err)
+
+	router := gin.New()
+	api.SetupRoutes(router, setupTestHandlers(), auth.Middleware(authenticator))
+	return router
+}
+
+func performAs(router *gin.Engine, key, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+const jwtSecret = "0123456789abcdef0123456789abcdef"
+
+// signToken returns an HS256 JWT for subject from issuer, signed with jwtSecret
+func signToken(t *testing.T, issuer, subject string) string {
+	segment := func(v interface{}) string {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+	input := segment(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." +
+		segment(map[string]interface{}{"iss": issuer, "sub": subject, "exp": time.Now().Add(time.Hour).Unix()})
+	mac := hmac.New(sha256.New, []byte(jwtSecret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func performWithToken(router *gin.Engine, token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestAuth_RequiresCredentials(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	w := performAs(router, "", "GET", "/api/health", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "the health check stays public")
+
+	w = performAs(router, "", "GET", "/api/templates", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = performAs(router, "not-a-valid-key-at-all", "POST", "/api/projects", models.ProjectRequest{Name: "x", Language: models.LanguageGo})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = performAs(router, aliceKey, "GET", "/api/templates", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAuth_ProjectsAreOwned(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	w := performAs(router, aliceKey, "POST", "/api/projects", models.ProjectRequest{
+		Name:     "alice-api",
+		Language: models.LanguageGo,
+		OwnerID:  "bob", // Ignored: the owner comes from the credentials
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "apikey:alice", created.Project.OwnerID)
+	projectPath := "/api/projects/" + created.Project.ID
+
+	assert.Equal(t, http.StatusOK, performAs(router, aliceKey, "GET", projectPath, nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, aliceKey, "POST", projectPath+"/generate", nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, aliceKey, "GET", projectPath+"/download", nil).Code)
+
+	for _, request := range []struct{ method, path string }{
+		{"GET", projectPath},
+		{"POST", projectPath + "/generate"},
+		{"GET", projectPath + "/download"},
+		{"GET", projectPath + "/download?delivery=url"},
+		{"GET", "/api/chat/history?project_id=" + created.Project.ID},
+	} {
+		w := performAs(router, bobKey, request.method, request.path, nil)
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", request.method, request.path)
+		assert.NotContains(t, w.Body.String(), "alice-api")
+	}
+
+	w = performAs(router, bobKey, "POST", "/api/chat/message", models.ChatRequest{Message: "hi", ProjectID: created.Project.ID})
+	assert.Equal(t, http.StatusNotFound, w.Code, "bob cannot attach a chat to alice's project")
+}
+
+func TestAuth_PrincipalsDoNotCollide(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator, err := auth.New(auth.Config{
+		APIKeys: []auth.APIKey{{Name: "alice", Key: aliceKey}},
+		JWT:     auth.JWTConfig{Secret: jwtSecret},
+	})
+	require.NoError(t, err)
+	router := gin.New()
+	api.SetupRoutes(router, setupTestHandlers(), auth.Middleware(authenticator))
+
+	w := performAs(router, aliceKey, "POST", "/api/projects", models.ProjectRequest{Name: "keyed", Language: models.LanguageGo})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	projectPath := "/api/projects/" + created.Project.ID
+
+	// A token whose subject is the key's name, or its whole principal ID, is someone else
+	for _, token := range []string{
+		signToken(t, "", "alice"),
+		signToken(t, "", "apikey:alice"),
+		signToken(t, "https://id.example.com", "alice"),
+	} {
+		assert.Equal(t, http.StatusNotFound, performWithToken(router, token, "GET", projectPath, nil).Code)
+	}
+
+	// Tokens from different issuers own their projects separately
+	w = performWithToken(router, signToken(t, "https://id.example.com", "alice"), "POST", "/api/projects", models.ProjectRequest{Name: "token", Language: models.LanguageGo})
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jwt:https://id.example.com:alice", created.Project.OwnerID)
+	projectPath = "/api/projects/" + created.Project.ID
+
+	assert.Equal(t, http.StatusOK, performWithToken(router, signToken(t, "https://id.example.com", "alice"), "GET", projectPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performWithToken(router, signToken(t, "https://other.example.com", "alice"), "GET", projectPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, aliceKey, "GET", projectPath, nil).Code)
+}
+
+func TestAuth_ChatSessionsAreOwned(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	w := performAs(router, aliceKey, "POST", "/api/chat/message", models.ChatRequest{Message: "I want a Go API"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var chat models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chat))
+	sessionPath := "/api/chat/sessions/" + chat.SessionID
+
+	w = performAs(router, aliceKey, "GET", "/api/chat/sessions", nil)
+	var listing models.ChatSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	require.Len(t, listing.Sessions, 1)
+	assert.Equal(t, "apikey:alice", listing.Sessions[0].OwnerID)
+
+	w = performAs(router, bobKey, "GET", "/api/chat/sessions", nil)
+	listing = models.ChatSessionResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	assert.Empty(t, listing.Sessions)
+
+	for _, request := range []struct {
+		method, path string
+		body         interface{}
+	}{
+		{"GET", sessionPath, nil},
+		{"GET", sessionPath + "/messages", nil},
+		{"PATCH", sessionPath, map[string]string{"title": "mine now"}},
+		{"DELETE", sessionPath, nil},
+		{"GET", "/api/chat/history?session_id=" + chat.SessionID, nil},
+		{"GET", "/api/chat/history/export?session_id=" + chat.SessionID, nil},
+		{"POST", "/api/chat/message", models.ChatRequest{Message: "hello", SessionID: chat.SessionID}},
+		{"POST", "/api/chat/projects", models.ChatProjectRequest{SessionID: chat.SessionID, Name: "stolen"}},
+	} {
+		w := performAs(router, bobKey, request.method, request.path, request.body)
+		assert.Equal(t, http.StatusNotFound, w.Code, "%s %s", request.method, request.path)
+	}
+
+	w = performAs(router, aliceKey, "GET", sessionPath+"/messages", nil)
+	assert.Equal(t, http.StatusOK, w.Code, "alice's session is untouched")
+	assert.Contains(t, w.Body.String(), "I want a Go API")
+}
+
+func TestAuth_ImportedTranscriptsBelongToTheImporter(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	w := performAs(router, aliceKey, "POST", "/api/chat/message", models.ChatRequest{Message: "hello"})
+	var chat models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chat))
+
+	w = performAs(router, aliceKey, "GET", "/api/chat/history/export?session_id="+chat.SessionID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var transcript models.ChatTranscript
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &transcript))
+	assert.Equal(t, "apikey:alice", transcript.History.OwnerID)
+
+	w = performAs(router, bobKey, "POST", "/api/chat/history/import", transcript)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var imported models.ChatSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &imported))
+	assert.Equal(t, "apikey:bob", imported.Session.OwnerID)
+	assert.NotEqual(t, chat.SessionID, imported.Session.ID, "alice's session ID is not taken over")
+}
@@ -0,0 +1,49 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlers_AddComponent(t *testing.T) {
+	router, projectService := setupDownloadRouter(t, nil)
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "shop", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	w := performJSON(router, "POST", "/api/projects/"+project.ID+"/components", models.ComponentRequest{Type: "entity", Name: "order"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.ComponentResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.True(t, response.Result.Applied)
+	assert.Equal(t, "entity:order", response.Result.Component)
+	assert.NotEmpty(t, response.Files)
+	assert.Equal(t, project.Revision, response.Revision)
+
+	// An edited controller is reported instead of overwritten
+	path := filepath.Join("shop", "internal", "controller", "respond.go")
+	_, err = projectService.UpdateProjectFile(context.Background(), project.ID, path, "package controller\n")
+	require.NoError(t, err)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/components", models.ComponentRequest{Type: "oauth", Name: "github"})
+	require.Equal(t, http.StatusConflict, w.Code)
+	var failure apperror.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure))
+	assert.Equal(t, "component_conflict", failure.Code)
+	require.Len(t, failure.Details, 1)
+	assert.Equal(t, path, failure.Details[0].Field)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/components", map[string]string{"type": "entity"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performJSON(router, "POST", "/api/projects/missing/components", models.ComponentRequest{Type: "entity", Name: "order"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,85 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// signingStore is a local store that pretends to hand out pre-signed URLs
+type signingStore struct {
+	*artifacts.LocalStore
+}
+
+func (s signingStore) PresignGet(ctx context.Context, key, filename string, expires time.Duration) (string, error) {
+	return "https://artifacts.example.com/" + key, nil
+}
+
+func setupDownloadRouter(t *testing.T, store artifacts.Store) (*gin.Engine, *services.ProjectService) {
+	gin.SetMode(gin.TestMode)
+	templateService := services.NewTemplateService()
+	projectService := services.NewProjectService(templateService)
+	if store != nil {
+		projectService.SetArtifactStore(store)
+	}
+
+	router := gin.New()
+	api.SetupRoutes(router, api.NewHandlers(projectService, templateService, services.NewChatService()))
+	return router, projectService
+}
+
+func TestHandlers_DownloadProject_PresignedURL(t *testing.T) {
+	local, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	router, projectService := setupDownloadRouter(t, signingStore{local})
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "signed", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	w := performJSON(router, "GET", "/api/projects/"+project.ID+"/download?delivery=url", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Success   bool      `json:"success"`
+		URL       string    `json:"url"`
+		Filename  string    `json:"filename"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, "https://artifacts.example.com/"+artifacts.ArchiveKey(project.ID, 2), response.URL)
+	assert.Equal(t, "signed-go.zip", response.Filename)
+	assert.True(t, response.ExpiresAt.After(time.Now()))
+
+	w = performJSON(router, "GET", "/api/projects/"+project.ID+"/download?delivery=redirect", nil)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	assert.Equal(t, response.URL, w.Header().Get("Location"))
+
+	w = performJSON(router, "GET", "/api/projects/missing/download?delivery=url", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlers_DownloadProject_DeliveryModes(t *testing.T) {
+	router, projectService := setupDownloadRouter(t, nil)
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "plain", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	w := performJSON(router, "GET", "/api/projects/"+project.ID+"/download", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+	w = performJSON(router, "GET", "/api/projects/"+project.ID+"/download?delivery=url", nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	w = performJSON(router, "GET", "/api/projects/"+project.ID+"/download?delivery=carrier-pigeon", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/requestid"
+	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// brokenStore fails every project read with an error that must not reach clients
+type brokenStore struct {
+	storage.Store
+}
+
+func (brokenStore) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	return nil, errors.New("dial tcp 10.0.0.5:5432: password authentication failed for user admin")
+}
+
+func setupErrorRouter(handlers *api.Handlers) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.Middleware())
+	api.SetupRoutes(router, handlers)
+	return router
+}
+
+func doErrorRequest(t *testing.T, router *gin.Engine, method, path, body string) (int, apperror.Response) {
+	t.Helper()
+	req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(requestid.Header, "req-test")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response apperror.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), w.Body.String())
+	assert.Equal(t, "req-test", w.Header().Get(requestid.Header))
+	return w.Code, response
+}
+
+func TestErrors_MissingFieldsAreListed(t *testing.T) {
+	router := setupErrorRouter(setupTestHandlers())
+
+	status, response := doErrorRequest(t, router, http.MethodPost, "/api/projects", `{"description":"no name"}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.False(t, response.Success)
+	assert.Equal(t, "invalid_request", response.Code)
+	assert.Equal(t, "req-test", response.RequestID)
+	assert.Contains(t, response.Details, apperror.FieldError{Field: "name", Message: "is required"})
+	assert.Contains(t, response.Details, apperror.FieldError{Field: "language", Message: "is required"})
+}
+
+func TestErrors_WrongFieldType(t *testing.T) {
+	router := setupErrorRouter(setupTestHandlers())
+
+	status, response := doErrorRequest(t, router, http.MethodPost, "/api/projects", `{"name":5,"language":"go"}`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_request", response.Code)
+	assert.Equal(t, []apperror.FieldError{{Field: "name", Message: "must be a string"}}, response.Details)
+}
+
+func TestErrors_MalformedJSON(t *testing.T) {
+	router := setupErrorRouter(setupTestHandlers())
+
+	status, response := doErrorRequest(t, router, http.MethodPost, "/api/projects", `{"name":`)
+
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_json", response.Code)
+}
+
+func TestErrors_NotFound(t *testing.T) {
+	router := setupErrorRouter(setupTestHandlers())
+
+	status, response := doErrorRequest(t, router, http.MethodGet, "/api/projects/missing", "")
+
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "project_not_found", response.Code)
+	assert.Equal(t, "Project not found", response.Error)
+}
+
+func TestErrors_InternalDetailsAreHidden(t *testing.T) {
+	templateService := services.NewTemplateService()
+	projectService := services.NewProjectService(templateService)
+	projectService.SetStore(brokenStore{})
+	router := setupErrorRouter(api.NewHandlers(projectService, templateService, services.NewChatService()))
+
+	status, response := doErrorRequest(t, router, http.MethodGet, "/api/projects/some-id", "")
+
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "internal_error", response.Code)
+	assert.Equal(t, "Internal server error", response.Error)
+	assert.NotContains(t, response.Error, "password")
+	assert.Empty(t, response.Details)
+}
@@ -2,6 +2,7 @@ package api_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -36,6 +37,7 @@ func TestNewHandlers(t *testing.T) {
 
 func TestHandlers_Health(t *testing.T) {
 	handlers := setupTestHandlers()
+	handlers.SetBuildInfo(models.BuildInfo{Version: "1.0.0", Commit: "3f2c1ab"})
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
 	router.GET("/health", handlers.Health)
@@ -55,6 +57,7 @@ func TestHandlers_Health(t *testing.T) {
 	assert.Equal(t, "healthy", response["status"])
 	assert.Equal(t, "boilerplate-blueprint", response["service"])
 	assert.Equal(t, "1.0.0", response["version"])
+	assert.Equal(t, "3f2c1ab", response["commit"])
 }
 
 func TestHandlers_GetTemplates(t *testing.T) {
@@ -376,3 +379,257 @@ func TestHandlers_GetChatHistory_ValidProjectID(t *testing.T) {
 	assert.Equal(t, "test-project", history["project_id"])
 	assert.NotNil(t, history["messages"])
 }
+
+func performJSON(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var reader *bytes.Buffer
+	if body != nil {
+		jsonData, _ := json.Marshal(body)
+		reader = bytes.NewBuffer(jsonData)
+	} else {
+		reader = bytes.NewBuffer(nil)
+	}
+
+	req, _ := http.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlers_CreateProjectFromChat(t *testing.T) {
+	handlers := setupTestHandlers()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/chat/message", handlers.ChatMessage)
+	router.POST("/chat/projects", handlers.CreateProjectFromChat)
+	router.GET("/chat/history", handlers.GetChatHistory)
+
+	w := performJSON(router, "POST", "/chat/message", models.ChatRequest{Message: "I want to build a Go web application"})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var chatResponse models.ChatResponse
+	err := json.Unmarshal(w.Body.Bytes(), &chatResponse)
+	require.NoError(t, err)
+	require.NotEmpty(t, chatResponse.SessionID)
+
+	w = performJSON(router, "POST", "/chat/projects", models.ChatProjectRequest{SessionID: chatResponse.SessionID, Name: "chat-project"})
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var response models.ChatResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+
+	assert.True(t, response.Success)
+	require.NotNil(t, response.Project)
+	assert.Equal(t, "chat-project", response.Project.Name)
+	assert.Equal(t, models.LanguageGo, response.Project.Language)
+	assert.Equal(t, "gin", response.Project.Options.Framework)
+
+	w = performJSON(router, "GET", "/chat/history?project_id="+response.Project.ID, nil)
+
+	var historyResponse map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &historyResponse)
+	require.NoError(t, err)
+	history := historyResponse["history"].(map[string]interface{})
+	assert.Equal(t, chatResponse.SessionID, history["session_id"])
+	assert.Len(t, history["messages"].([]interface{}), 3)
+}
+
+func TestHandlers_CreateProjectFromChat_IncompleteDraft(t *testing.T) {
+	handlers := setupTestHandlers()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/chat/sessions", handlers.CreateChatSession)
+	router.POST("/chat/projects", handlers.CreateProjectFromChat)
+
+	w := performJSON(router, "POST", "/chat/sessions", nil)
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var sessionResponse models.ChatSessionResponse
+	err := json.Unmarshal(w.Body.Bytes(), &sessionResponse)
+	require.NoError(t, err)
+
+	w = performJSON(router, "POST", "/chat/projects", models.ChatProjectRequest{SessionID: sessionResponse.Session.ID, Name: "chat-project"})
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response models.ChatResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	require.NoError(t, err)
+	assert.False(t, response.Success)
+	assert.NotEmpty(t, response.Error)
+
+	w = performJSON(router, "POST", "/chat/projects", models.ChatProjectRequest{SessionID: "missing", Name: "chat-project"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlers_ChatSessions_Lifecycle(t *testing.T) {
+	handlers := setupTestHandlers()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, handlers)
+
+	w := performJSON(router, "POST", "/api/chat/sessions", models.ChatSessionRequest{Title: "Design chat"})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.ChatSessionResponse
+	err := json.Unmarshal(w.Body.Bytes(), &created)
+	require.NoError(t, err)
+	sessionID := created.Session.ID
+	assert.Equal(t, "Design chat", created.Session.Title)
+
+	w = performJSON(router, "POST", "/api/chat/message", models.ChatRequest{Message: "Hello", SessionID: sessionID})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = performJSON(router, "GET", "/api/chat/sessions", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed models.ChatSessionResponse
+	err = json.Unmarshal(w.Body.Bytes(), &listed)
+	require.NoError(t, err)
+	require.Len(t, listed.Sessions, 1)
+	assert.Equal(t, 2, listed.Sessions[0].MessageCount)
+
+	title := "Renamed chat"
+	w = performJSON(router, "PATCH", "/api/chat/sessions/"+sessionID, models.ChatSessionUpdateRequest{Title: &title})
+	require.Equal(t, http.StatusOK, w.Code)
+	var updated models.ChatSessionResponse
+	err = json.Unmarshal(w.Body.Bytes(), &updated)
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed chat", updated.Session.Title)
+
+	projectID := "missing-project"
+	w = performJSON(router, "PATCH", "/api/chat/sessions/"+sessionID, models.ChatSessionUpdateRequest{ProjectID: &projectID})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performJSON(router, "GET", "/api/chat/sessions/"+sessionID+"/messages?offset=1&limit=1", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var pageResponse struct {
+		Success bool                   `json:"success"`
+		Page    models.ChatMessagePage `json:"page"`
+	}
+	err = json.Unmarshal(w.Body.Bytes(), &pageResponse)
+	require.NoError(t, err)
+	assert.Equal(t, 2, pageResponse.Page.Total)
+	require.Len(t, pageResponse.Page.Messages, 1)
+	assert.Equal(t, "assistant", pageResponse.Page.Messages[0].Role)
+
+	w = performJSON(router, "GET", "/api/chat/sessions/"+sessionID+"/messages?limit=abc", nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performJSON(router, "GET", "/api/chat/history?session_id="+sessionID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performJSON(router, "DELETE", "/api/chat/sessions/"+sessionID, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = performJSON(router, "GET", "/api/chat/sessions/"+sessionID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performJSON(router, "GET", "/api/chat/history?session_id="+sessionID, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlers_ExportImportChatHistory(t *testing.T) {
+	source := setupTestHandlers()
+	gin.SetMode(gin.TestMode)
+	sourceRouter := gin.New()
+	api.SetupRoutes(sourceRouter, source)
+
+	w := performJSON(sourceRouter, "POST", "/api/chat/message", models.ChatRequest{Message: "I want to build a Go web application"})
+	require.Equal(t, http.StatusOK, w.Code)
+	var chatResponse models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chatResponse))
+
+	w = performJSON(sourceRouter, "GET", "/api/chat/history/export?format=markdown&session_id="+chatResponse.SessionID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/markdown")
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".md")
+	assert.Contains(t, w.Body.String(), "**Suggestions:**")
+
+	w = performJSON(sourceRouter, "GET", "/api/chat/history/export?format=xml&session_id="+chatResponse.SessionID, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performJSON(sourceRouter, "GET", "/api/chat/history/export?session_id="+chatResponse.SessionID, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var transcript models.ChatTranscript
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &transcript))
+	assert.Len(t, transcript.History.Messages, 2)
+
+	// Restore on another instance
+	target := setupTestHandlers()
+	targetRouter := gin.New()
+	api.SetupRoutes(targetRouter, target)
+
+	w = performJSON(targetRouter, "POST", "/api/chat/history/import", transcript)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var imported models.ChatSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &imported))
+	assert.Equal(t, chatResponse.SessionID, imported.Session.ID)
+	assert.Equal(t, 2, imported.Session.MessageCount)
+
+	w = performJSON(targetRouter, "POST", "/api/chat/history/import", models.ChatTranscript{Version: 42})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performJSON(targetRouter, "GET", "/api/chat/history/export?session_id=missing", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandlers_AssistantToolsAndProposals(t *testing.T) {
+	templateService := services.NewTemplateService()
+	projectService := services.NewProjectService(templateService)
+	chatService := services.NewChatService()
+	handlers := api.NewHandlers(projectService, templateService, chatService)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, handlers)
+
+	w := performJSON(router, "GET", "/api/assistant/tools", nil)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	chatService.SetAssistantTools(services.NewAssistantTools(projectService))
+
+	w = performJSON(router, "GET", "/api/assistant/tools", nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "api-tools", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/assistant/tools", map[string]interface{}{
+		"name":      models.ToolProposeOptionsChange,
+		"arguments": map[string]interface{}{"options": map[string]string{"framework": "echo"}},
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var toolResponse struct {
+		Success bool              `json:"success"`
+		Result  models.ToolResult `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &toolResponse))
+	require.NotNil(t, toolResponse.Result.Proposal)
+	proposalID := toolResponse.Result.Proposal.ID
+
+	w = performJSON(router, "GET", "/api/projects/"+project.ID+"/proposals", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed models.ProposalResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(t, listed.Proposals, 1)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/proposals/"+proposalID+"/confirm", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var confirmed models.ProposalResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &confirmed))
+	assert.Equal(t, "echo", confirmed.Project.Options.Framework)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/proposals/"+proposalID+"/reject", nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/proposals/missing/confirm", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = performJSON(router, "POST", "/api/projects/"+project.ID+"/assistant/tools", map[string]interface{}{"name": "unknown"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = performJSON(router, "POST", "/api/projects/missing/assistant/tools", map[string]interface{}{"name": models.ToolListFiles})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,206 @@
+package api_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func apiGatewayEvent(method, rawPath, rawQuery string) events.APIGatewayV2HTTPRequest {
+	return events.APIGatewayV2HTTPRequest{
+		Version:        "2.0",
+		RouteKey:       "$default",
+		RawPath:        rawPath,
+		RawQueryString: rawQuery,
+		Headers: map[string]string{
+			"host":         "abc123.execute-api.us-east-1.amazonaws.com",
+			"content-type": "application/json",
+		},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			Stage:      "$default",
+			DomainName: "abc123.execute-api.us-east-1.amazonaws.com",
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{
+				Method:   method,
+				Path:     rawPath,
+				SourceIP: "203.0.113.10",
+			},
+		},
+	}
+}
+
+func setupLambdaHandler() api.LambdaHandlerFunc {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, setupTestHandlers())
+	return api.NewLambdaHandler(router)
+}
+
+func TestLambdaHandler_Health(t *testing.T) {
+	handler := setupLambdaHandler()
+
+	response, err := handler(context.Background(), apiGatewayEvent("GET", "/api/health", ""))
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.False(t, response.IsBase64Encoded)
+	assert.Contains(t, response.Headers["Content-Type"], "application/json")
+	assert.Contains(t, response.Body, "healthy")
+}
+
+func TestLambdaHandler_Base64BodyAndBinaryDownload(t *testing.T) {
+	handler := setupLambdaHandler()
+
+	body, err := json.Marshal(models.ProjectRequest{Name: "lambda-project", Language: models.LanguageGo})
+	require.NoError(t, err)
+	event := apiGatewayEvent("POST", "/api/projects", "")
+	event.Body = base64.StdEncoding.EncodeToString(body)
+	event.IsBase64Encoded = true
+
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &created))
+	require.NotNil(t, created.Project)
+
+	response, err = handler(context.Background(), apiGatewayEvent("GET", "/api/projects/"+created.Project.ID+"/download", ""))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	assert.True(t, response.IsBase64Encoded)
+	assert.Equal(t, "application/zip", response.Headers["Content-Type"])
+
+	archive, err := base64.StdEncoding.DecodeString(response.Body)
+	require.NoError(t, err)
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+	assert.NotEmpty(t, reader.File)
+}
+
+func TestLambdaHandler_QueryStringAndStagePrefix(t *testing.T) {
+	handler := setupLambdaHandler()
+
+	event := apiGatewayEvent("GET", "/dev/api/chat/history/export", "")
+	event.RequestContext.Stage = "dev"
+	event.QueryStringParameters = map[string]string{"session_id": "missing", "format": "json"}
+
+	response, err := handler(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, response.StatusCode) // Routed and parsed, but the session is unknown
+	assert.Contains(t, response.Body, "Chat session not found")
+}
+
+func TestLambdaHandler_HeadersCookiesAndRequestDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/echo/:name", func(c *gin.Context) {
+		session, _ := c.Cookie("session")
+		body, _ := io.ReadAll(c.Request.Body)
+		c.SetCookie("seen", "yes", 60, "/", "", true, true)
+		c.Header("X-Multi", "a")
+		c.Writer.Header().Add("X-Multi", "b")
+		c.JSON(http.StatusAccepted, gin.H{
+			"name":    c.Param("name"),
+			"tags":    c.QueryArray("tag"),
+			"session": session,
+			"trace":   c.GetHeader("X-Trace-Id"),
+			"ip":      c.ClientIP(),
+			"host":    c.Request.Host,
+			"body":    string(body),
+		})
+	})
+	handler := api.NewLambdaHandler(router)
+
+	event := apiGatewayEvent("POST", "/echo/widget", "tag=a&tag=b")
+	event.Headers["x-trace-id"] = "trace-123"
+	event.Cookies = []string{"session=abc", "theme=dark"}
+	event.Body = `{"hello":"world"}`
+
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, "a,b", response.Headers["X-Multi"])
+	require.Len(t, response.Cookies, 1)
+	assert.Contains(t, response.Cookies[0], "seen=yes")
+
+	var echoed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &echoed))
+	assert.Equal(t, "widget", echoed["name"])
+	assert.Equal(t, []interface{}{"a", "b"}, echoed["tags"])
+	assert.Equal(t, "abc", echoed["session"])
+	assert.Equal(t, "trace-123", echoed["trace"])
+	assert.Equal(t, "203.0.113.10", echoed["ip"])
+	assert.Equal(t, "abc123.execute-api.us-east-1.amazonaws.com", echoed["host"])
+	assert.Equal(t, `{"hello":"world"}`, echoed["body"])
+}
+
+func TestLambdaHandler_EncodedPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/files/*path", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"param":   c.Param("path"),
+			"escaped": c.Request.URL.EscapedPath(),
+			"uri":     c.Request.RequestURI,
+		})
+	})
+	handler := api.NewLambdaHandler(router)
+
+	response, err := handler(context.Background(), apiGatewayEvent("GET", "/files/docs/a%20b.md", "v=1"))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, response.StatusCode, response.Body)
+
+	var echoed map[string]string
+	require.NoError(t, json.Unmarshal([]byte(response.Body), &echoed))
+	assert.Equal(t, "/docs/a b.md", echoed["param"])
+	assert.Equal(t, "/files/docs/a%20b.md", echoed["escaped"])
+	assert.Equal(t, "/files/docs/a%20b.md?v=1", echoed["uri"])
+
+	response, err = handler(context.Background(), apiGatewayEvent("GET", "/files/%zz", ""))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLambdaHandler_InvalidBase64Body(t *testing.T) {
+	handler := setupLambdaHandler()
+
+	event := apiGatewayEvent("POST", "/api/projects", "")
+	event.Body = "not base64!"
+	event.IsBase64Encoded = true
+
+	response, err := handler(context.Background(), event)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+}
+
+func TestLambdaHandler_FlushAfter(t *testing.T) {
+	flushes := 0
+	handler := api.FlushAfter(setupLambdaHandler(), func(context.Context) error {
+		flushes++
+		return errors.New("collector unreachable")
+	})
+
+	for i := 1; i <= 2; i++ {
+		response, err := handler(context.Background(), apiGatewayEvent("GET", "/api/health", ""))
+		require.NoError(t, err, "a failed flush does not fail the invocation")
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, i, flushes)
+	}
+}
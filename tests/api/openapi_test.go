@@ -0,0 +1,133 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupDocumentedRouter(t *testing.T) (*gin.Engine, openapi.Document) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, setupTestHandlers())
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, api.BasePath+"/openapi.json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	return router, doc
+}
+
+// TestOpenAPI_DocumentsEveryRoute fails when a route is registered without
+// appearing in the OpenAPI document, or documented without being served
+func TestOpenAPI_DocumentsEveryRoute(t *testing.T) {
+	router, doc := setupDocumentedRouter(t)
+
+	served := make(map[string]bool)
+	for _, route := range router.Routes() {
+		if !strings.HasPrefix(route.Path, api.BasePath+"/") {
+			continue
+		}
+		path, _ := openapi.Path(strings.TrimPrefix(route.Path, api.BasePath))
+		key := route.Method + " " + path
+		served[key] = true
+		assert.NotNil(t, doc.Operation(route.Method, path), "%s is served but missing from the OpenAPI document", key)
+	}
+
+	for path, item := range doc.Paths {
+		for method := range item {
+			key := strings.ToUpper(method) + " " + path
+			assert.True(t, served[key], "%s is documented but not served", key)
+		}
+	}
+	assert.NotEmpty(t, served)
+}
+
+func TestOpenAPI_LegacyPrefixAliasesEveryRoute(t *testing.T) {
+	router, _ := setupDocumentedRouter(t)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range router.Routes() {
+		if strings.HasPrefix(route.Path, api.BasePath+"/") {
+			legacy := api.LegacyBasePath + strings.TrimPrefix(route.Path, api.BasePath)
+			assert.True(t, registered[route.Method+" "+legacy], "%s %s has no alias", route.Method, legacy)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/templates", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("Deprecation"))
+	assert.Equal(t, `</api/v1/templates>; rel="successor-version"`, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/templates", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Deprecation"))
+}
+
+func TestOpenAPI_DescribesModels(t *testing.T) {
+	_, doc := setupDocumentedRouter(t)
+
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	require.Len(t, doc.Servers, 1)
+	assert.Equal(t, api.BasePath, doc.Servers[0].URL)
+
+	create := doc.Operation(http.MethodPost, "/projects")
+	require.NotNil(t, create)
+	require.NotNil(t, create.RequestBody)
+	assert.Equal(t, "#/components/schemas/ProjectRequest", create.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, create.Responses, "201")
+	assert.Equal(t, "#/components/schemas/ErrorResponse", create.Responses["default"].Content["application/json"].Schema.Ref)
+	assert.NotEmpty(t, create.Security)
+
+	request := doc.Components.Schemas["ProjectRequest"]
+	require.NotNil(t, request)
+	assert.ElementsMatch(t, []string{"name", "language"}, request.Required)
+	assert.NotContains(t, request.Properties, "OwnerID")
+	assert.Contains(t, request.Properties, "template_pack_id")
+
+	get := doc.Operation(http.MethodGet, "/projects/{id}")
+	require.NotNil(t, get)
+	require.Len(t, get.Parameters, 1)
+	assert.Equal(t, "id", get.Parameters[0].Name)
+	assert.True(t, get.Parameters[0].Required)
+
+	health := doc.Operation(http.MethodGet, "/health")
+	require.NotNil(t, health)
+	assert.Empty(t, health.Security)
+
+	// Every reference resolves to a component
+	encoded, err := json.Marshal(doc)
+	require.NoError(t, err)
+	for _, match := range regexp.MustCompile(`#/components/schemas/(\w+)`).FindAllStringSubmatch(string(encoded), -1) {
+		assert.Contains(t, doc.Components.Schemas, match[1])
+	}
+}
+
+func TestOpenAPI_ServesDocsPage(t *testing.T) {
+	router, _ := setupDocumentedRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, api.BasePath+"/docs", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "openapi.json")
+}
@@ -0,0 +1,280 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	carolKey = "carol-key-0123456789"
+	daveKey  = "dave-key-0123456789"
+)
+
+func setupTeamRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "alice", Key: aliceKey},
+		{Name: "bob", Key: bobKey},
+		{Name: "carol", Key: carolKey},
+		{Name: "dave", Key: daveKey},
+	}})
+	require.NoError(t, err)
+
+	templateService := services.NewTemplateService()
+	projectService := services.NewProjectService(templateService)
+	chatService := services.NewChatService()
+	chatService.SetAssistantTools(services.NewAssistantTools(projectService))
+	handlers := api.NewHandlers(projectService, templateService, chatService)
+	handlers.SetTeamService(services.NewTeamService())
+	handlers.SetTemplatePackService(services.NewTemplatePackService(templateService))
+
+	router := gin.New()
+	api.SetupRoutes(router, handlers, auth.Middleware(authenticator))
+	return router
+}
+
+// setupPlatformTeam creates a team owned by alice, with bob as a viewer and
+// carol as an editor. Dave is not a member.
+func setupPlatformTeam(t *testing.T, router *gin.Engine) string {
+	w := performAs(router, aliceKey, "POST", "/api/teams", models.TeamRequest{Name: "Platform"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.TeamResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	teamID := created.Team.ID
+
+	w = performAs(router, aliceKey, "PUT", "/api/teams/"+teamID+"/members/apikey:bob", models.TeamMemberRequest{Role: models.RoleViewer})
+	require.Equal(t, http.StatusOK, w.Code)
+	w = performAs(router, aliceKey, "PUT", "/api/teams/"+teamID+"/members/apikey:carol", models.TeamMemberRequest{Role: models.RoleEditor})
+	require.Equal(t, http.StatusOK, w.Code)
+	return teamID
+}
+
+func decodeProject(t *testing.T, body []byte) *models.Project {
+	var response models.ProjectResponse
+	require.NoError(t, json.Unmarshal(body, &response))
+	require.NotNil(t, response.Project)
+	return response.Project
+}
+
+func TestTeams_ProjectRoles(t *testing.T) {
+	router := setupTeamRouter(t)
+	teamID := setupPlatformTeam(t, router)
+
+	w := performAs(router, bobKey, "POST", "/api/projects", models.ProjectRequest{Name: "x", Language: models.LanguageGo, TeamID: teamID})
+	assert.Equal(t, http.StatusForbidden, w.Code, "viewers cannot add projects to the team")
+	w = performAs(router, daveKey, "POST", "/api/projects", models.ProjectRequest{Name: "x", Language: models.LanguageGo, TeamID: teamID})
+	assert.Equal(t, http.StatusNotFound, w.Code, "non-members do not see the team")
+
+	w = performAs(router, carolKey, "POST", "/api/projects", models.ProjectRequest{Name: "shared-api", Language: models.LanguageGo, TeamID: teamID})
+	require.Equal(t, http.StatusCreated, w.Code)
+	project := decodeProject(t, w.Body.Bytes())
+	assert.Equal(t, teamID, project.TeamID)
+	projectPath := "/api/projects/" + project.ID
+
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "GET", projectPath, nil).Code)
+	assert.Equal(t, http.StatusForbidden, performAs(router, bobKey, "POST", projectPath+"/generate", nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, aliceKey, "POST", projectPath+"/generate", nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "GET", projectPath+"/download", nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, daveKey, "GET", projectPath, nil).Code)
+
+	// Viewers may use the assistant's read tools; proposing changes takes an editor
+	readFile := map[string]interface{}{"name": models.ToolReadFile, "arguments": map[string]string{"path": "shared-api/go.mod"}}
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "POST", projectPath+"/assistant/tools", map[string]interface{}{"name": models.ToolListFiles}).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "POST", projectPath+"/assistant/tools", readFile).Code)
+	propose := map[string]interface{}{"name": models.ToolProposeFilePatch, "arguments": map[string]string{"path": "shared-api/NOTES.md", "content": "notes"}}
+	assert.Equal(t, http.StatusForbidden, performAs(router, bobKey, "POST", projectPath+"/assistant/tools", propose).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, carolKey, "POST", projectPath+"/assistant/tools", propose).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, daveKey, "POST", projectPath+"/assistant/tools", map[string]interface{}{"name": models.ToolListFiles}).Code)
+
+	// Listings cover the caller's own projects and their teams' projects
+	performAs(router, bobKey, "POST", "/api/projects", models.ProjectRequest{Name: "bob-private", Language: models.LanguageGo})
+	listProjects := func(key, query string) []string {
+		w := performAs(router, key, "GET", "/api/projects"+query, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response models.ProjectResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		names := []string{}
+		for _, project := range response.Projects {
+			assert.Empty(t, project.Files, "listings omit files")
+			names = append(names, project.Name)
+		}
+		return names
+	}
+	assert.ElementsMatch(t, []string{"shared-api", "bob-private"}, listProjects(bobKey, ""))
+	assert.Equal(t, []string{"shared-api"}, listProjects(bobKey, "?team_id="+teamID))
+	assert.Equal(t, []string{"shared-api"}, listProjects(aliceKey, ""))
+	assert.Empty(t, listProjects(daveKey, ""))
+}
+
+func TestTeams_ChatSessionRoles(t *testing.T) {
+	router := setupTeamRouter(t)
+	teamID := setupPlatformTeam(t, router)
+
+	w := performAs(router, carolKey, "POST", "/api/chat/sessions", models.ChatSessionRequest{Title: "Design", TeamID: teamID})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ChatSessionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	sessionID := created.Session.ID
+	assert.Equal(t, teamID, created.Session.TeamID)
+
+	w = performAs(router, aliceKey, "POST", "/api/chat/message", models.ChatRequest{Message: "Use Go", SessionID: sessionID})
+	assert.Equal(t, http.StatusOK, w.Code, "team editors can continue the conversation")
+
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "GET", "/api/chat/sessions/"+sessionID+"/messages", nil).Code)
+	w = performAs(router, bobKey, "POST", "/api/chat/message", models.ChatRequest{Message: "hi", SessionID: sessionID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, http.StatusForbidden, performAs(router, bobKey, "DELETE", "/api/chat/sessions/"+sessionID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, daveKey, "GET", "/api/chat/sessions/"+sessionID, nil).Code)
+
+	listSessions := func(key string) int {
+		w := performAs(router, key, "GET", "/api/chat/sessions", nil)
+		var response models.ChatSessionResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return len(response.Sessions)
+	}
+	assert.Equal(t, 1, listSessions(bobKey))
+	assert.Equal(t, 0, listSessions(daveKey))
+
+	// Projects created from a shared conversation are shared with the same team
+	w = performAs(router, carolKey, "POST", "/api/chat/projects", models.ChatProjectRequest{SessionID: sessionID, Name: "from-chat"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var chatResponse models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chatResponse))
+	assert.Equal(t, teamID, chatResponse.Project.TeamID)
+}
+
+func TestTeams_TemplatePacks(t *testing.T) {
+	router := setupTeamRouter(t)
+	teamID := setupPlatformTeam(t, router)
+
+	w := performAs(router, bobKey, "POST", "/api/template-packs", models.TemplatePackRequest{Name: "x", Language: models.LanguageGo, TeamID: teamID})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = performAs(router, carolKey, "POST", "/api/template-packs", models.TemplatePackRequest{
+		Name:     "Chi + MySQL",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "chi", Database: "mysql"},
+		TeamID:   teamID,
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.TemplatePackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	packID := created.Pack.ID
+
+	w = performAs(router, bobKey, "GET", "/api/template-packs?language=go", nil)
+	var listing models.TemplatePackResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	require.Len(t, listing.Packs, 1)
+	assert.Equal(t, "Chi + MySQL", listing.Packs[0].Name)
+
+	// Viewers can create their own projects from the team's presets
+	w = performAs(router, bobKey, "POST", "/api/projects", models.ProjectRequest{Name: "preset", Language: models.LanguageGo, PackID: packID})
+	require.Equal(t, http.StatusCreated, w.Code)
+	project := decodeProject(t, w.Body.Bytes())
+	assert.Equal(t, "chi", project.Options.Framework)
+	assert.Equal(t, "mysql", project.Options.Database)
+
+	w = performAs(router, bobKey, "POST", "/api/projects", models.ProjectRequest{Name: "preset", Language: models.LanguagePHP, PackID: packID})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "the pack's language must match")
+	w = performAs(router, daveKey, "POST", "/api/projects", models.ProjectRequest{Name: "preset", Language: models.LanguageGo, PackID: packID})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	assert.Equal(t, http.StatusForbidden, performAs(router, bobKey, "DELETE", "/api/template-packs/"+packID, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, daveKey, "GET", "/api/template-packs/"+packID, nil).Code)
+
+	// Editors may change a pack but only owners may move it out of the team
+	update := models.TemplatePackRequest{Name: "Chi + SQLite", Language: models.LanguageGo, Options: models.ProjectOptions{Framework: "chi", Database: "sqlite"}, TeamID: teamID}
+	assert.Equal(t, http.StatusOK, performAs(router, carolKey, "PUT", "/api/template-packs/"+packID, update).Code)
+	update.TeamID = ""
+	assert.Equal(t, http.StatusForbidden, performAs(router, carolKey, "PUT", "/api/template-packs/"+packID, update).Code)
+
+	assert.Equal(t, http.StatusOK, performAs(router, carolKey, "DELETE", "/api/template-packs/"+packID, nil).Code)
+}
+
+func TestTeams_Management(t *testing.T) {
+	router := setupTeamRouter(t)
+	teamID := setupPlatformTeam(t, router)
+	teamPath := "/api/teams/" + teamID
+
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "GET", teamPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, daveKey, "GET", teamPath, nil).Code)
+	w := performAs(router, carolKey, "PUT", teamPath+"/members/apikey:dave", models.TeamMemberRequest{Role: models.RoleViewer})
+	assert.Equal(t, http.StatusForbidden, w.Code, "only owners manage members")
+	w = performAs(router, aliceKey, "PUT", teamPath+"/members/apikey:dave", models.TeamMemberRequest{Role: "admin"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = performAs(router, aliceKey, "PUT", teamPath+"/members/apikey:alice", models.TeamMemberRequest{Role: models.RoleViewer})
+	assert.Equal(t, http.StatusConflict, w.Code, "the last owner cannot step down")
+
+	w = performAs(router, daveKey, "GET", "/api/teams", nil)
+	var listing models.TeamResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listing))
+	assert.Empty(t, listing.Teams)
+
+	w = performAs(router, carolKey, "POST", "/api/projects", models.ProjectRequest{Name: "shared", Language: models.LanguageGo, TeamID: teamID})
+	require.Equal(t, http.StatusCreated, w.Code)
+	projectPath := "/api/projects/" + decodeProject(t, w.Body.Bytes()).ID
+
+	// Members may leave; they then lose access to the team's resources
+	assert.Equal(t, http.StatusOK, performAs(router, bobKey, "DELETE", teamPath+"/members/apikey:bob", nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, bobKey, "GET", projectPath, nil).Code)
+
+	// Deleting the team hands its resources back to their creators
+	assert.Equal(t, http.StatusForbidden, performAs(router, carolKey, "DELETE", teamPath, nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, aliceKey, "DELETE", teamPath, nil).Code)
+	assert.Equal(t, http.StatusOK, performAs(router, carolKey, "GET", projectPath, nil).Code)
+	assert.Equal(t, http.StatusNotFound, performAs(router, aliceKey, "GET", projectPath, nil).Code)
+}
+
+func TestTeams_TokenMembers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	authenticator, err := auth.New(auth.Config{
+		APIKeys: []auth.APIKey{{Name: "alice", Key: aliceKey}},
+		JWT:     auth.JWTConfig{Secret: jwtSecret},
+	})
+	require.NoError(t, err)
+	templateService := services.NewTemplateService()
+	handlers := api.NewHandlers(services.NewProjectService(templateService), templateService, services.NewChatService())
+	handlers.SetTeamService(services.NewTeamService())
+	router := gin.New()
+	api.SetupRoutes(router, handlers, auth.Middleware(authenticator))
+
+	w := performAs(router, aliceKey, "POST", "/api/teams", models.TeamRequest{Name: "Platform"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.TeamResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	teamPath := "/api/teams/" + created.Team.ID
+
+	// Principal IDs of tokens hold their issuer, slashes included
+	w = performAs(router, aliceKey, "PUT", teamPath+"/members/jwt:https://id.example.com/:bob", models.TeamMemberRequest{Role: models.RoleViewer})
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "jwt:https://id.example.com/:bob", created.Team.Members[1].PrincipalID)
+
+	token := signToken(t, "https://id.example.com/", "bob")
+	assert.Equal(t, http.StatusOK, performWithToken(router, token, "GET", teamPath, nil).Code)
+	assert.Equal(t, http.StatusOK, performWithToken(router, token, "DELETE", teamPath+"/members/jwt:https://id.example.com/:bob", nil).Code)
+	assert.Equal(t, http.StatusNotFound, performWithToken(router, token, "GET", teamPath, nil).Code)
+
+	w = performAs(router, aliceKey, "PUT", teamPath+"/members/", models.TeamMemberRequest{Role: models.RoleViewer})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTeams_NotEnabled(t *testing.T) {
+	router := setupAuthRouter(t)
+
+	assert.Equal(t, http.StatusNotImplemented, performAs(router, aliceKey, "GET", "/api/teams", nil).Code)
+	assert.Equal(t, http.StatusNotImplemented, performAs(router, aliceKey, "GET", "/api/template-packs", nil).Code)
+	w := performAs(router, aliceKey, "POST", "/api/projects", models.ProjectRequest{Name: "x", Language: models.LanguageGo, TeamID: "team"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
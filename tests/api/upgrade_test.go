@@ -0,0 +1,118 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performUpload(router *gin.Engine, path, contentType string, body []byte) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlers_UpgradeProject(t *testing.T) {
+	router, projectService := setupDownloadRouter(t, nil)
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "shop", Language: models.LanguageGo})
+	require.NoError(t, err)
+	_, err = projectService.GenerateProjectFiles(context.Background(), project)
+	require.NoError(t, err)
+	archive, _, err := projectService.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+	path := "/api/projects/" + project.ID + "/upgrade"
+
+	w := performUpload(router, path, "application/zip", archive)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.UpgradeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, "Project upgraded without conflicts", response.Message)
+	assert.Equal(t, project.Revision, response.Report.BaseRevision)
+	assert.Empty(t, response.Report.Files)
+	assert.Equal(t, "shop-go-upgrade.zip", response.Filename)
+	assert.NotEmpty(t, response.Archive)
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("archive", "shop.zip")
+	require.NoError(t, err)
+	_, err = part.Write(archive)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	w = performUpload(router, path+"?base_revision=2", writer.FormDataContentType(), form.Bytes())
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Report.BaseRevision)
+
+	// Revision 1 was recorded before the project was generated
+	var failure apperror.Response
+	w = performUpload(router, path+"?base_revision=1", "application/zip", archive)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure))
+	assert.Equal(t, "upgrade_base_empty", failure.Code)
+
+	w = performUpload(router, path, "application/zip", []byte("not a zip"))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &failure))
+	assert.Equal(t, "invalid_archive", failure.Code)
+
+	assert.Equal(t, http.StatusBadRequest, performUpload(router, path, "application/zip", nil).Code)
+	assert.Equal(t, http.StatusBadRequest, performUpload(router, path+"?base_revision=-1", "application/zip", archive).Code)
+	assert.Equal(t, http.StatusNotFound, performUpload(router, path+"?base_revision=9", "application/zip", archive).Code)
+	assert.Equal(t, http.StatusNotFound, performUpload(router, "/api/projects/missing/upgrade", "application/zip", archive).Code)
+}
+
+func TestHandlers_UpgradeProject_PresignedURL(t *testing.T) {
+	local, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	router, projectService := setupDownloadRouter(t, signingStore{local})
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "signed", Language: models.LanguageGo})
+	require.NoError(t, err)
+	archive, _, err := projectService.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+	path := "/api/projects/" + project.ID + "/upgrade"
+
+	w := performUpload(router, path+"?delivery=url", "application/zip", archive)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response models.UpgradeResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Archive, "the archive is downloaded from the URL instead")
+	require.NotNil(t, response.ExpiresAt)
+	assert.True(t, strings.HasPrefix(response.URL, "https://artifacts.example.com/upgrades/"+project.ID+"/"), response.URL)
+
+	// The URL serves the merged archive
+	stored, err := local.Get(context.Background(), strings.TrimPrefix(response.URL, "https://artifacts.example.com/"))
+	require.NoError(t, err)
+	w = performUpload(router, path, "application/zip", archive)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, response.Archive, stored)
+
+	assert.Equal(t, http.StatusBadRequest, performUpload(router, path+"?delivery=carrier-pigeon", "application/zip", archive).Code)
+}
+
+func TestHandlers_UpgradeProject_URLNeedsArtifactStore(t *testing.T) {
+	router, projectService := setupDownloadRouter(t, nil)
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "plain", Language: models.LanguageGo})
+	require.NoError(t, err)
+	archive, _, err := projectService.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+
+	w := performUpload(router, "/api/projects/"+project.ID+"/upgrade?delivery=url", "application/zip", archive)
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
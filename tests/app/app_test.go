@@ -0,0 +1,259 @@
+package app_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/api"
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/limits"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig(t *testing.T) app.Config {
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.StaticDir, "index.html"), []byte("<html>blueprint</html>"), 0o644))
+	return cfg
+}
+
+func preflight(handler http.Handler, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("OPTIONS", "/api/projects", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", "PATCH")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestNew_DefaultConfig(t *testing.T) {
+	application, err := app.New(testConfig(t))
+	require.NoError(t, err)
+
+	assert.NotNil(t, application.Handlers)
+	assert.NotNil(t, application.ProjectService)
+	assert.NotNil(t, application.ChatService)
+	assert.Nil(t, application.ProjectService.ArtifactStore())
+	assert.Nil(t, application.ProjectService.Store())
+
+	w := httptest.NewRecorder()
+	application.ServeHTTP(w, httptest.NewRequest("GET", "/api/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestNew_Probes(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Build = models.BuildInfo{Version: "v1.4.0", Commit: "3f2c1ab", BuildTime: "2026-10-19T09:12:44Z"}
+	cfg.Artifacts.Backend = "local"
+	cfg.Artifacts.Dir = t.TempDir()
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		application.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	var live models.HealthResponse
+	w := get("/livez")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &live))
+	assert.Equal(t, "alive", live.Status)
+	assert.Equal(t, "v1.4.0", live.Version)
+	assert.Equal(t, "3f2c1ab", live.Commit)
+	assert.Contains(t, get("/api/v1/health").Body.String(), `"version":"v1.4.0"`)
+
+	var ready models.ReadinessResponse
+	w = get("/readyz")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ready))
+	assert.Equal(t, "ready", ready.Status)
+	assert.Equal(t, cfg.Build, ready.Build)
+	var names []string
+	for _, check := range ready.Checks {
+		names = append(names, check.Name)
+		assert.Equal(t, models.CheckUp, check.Status, check.Name)
+	}
+	assert.Equal(t, []string{"artifacts", "templates"}, names, "only configured dependencies are checked")
+
+	application.Readiness.Register("llm", func(context.Context) error { return fmt.Errorf("provider down") })
+	w = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"not_ready"`)
+
+	cfg = testConfig(t)
+	cfg.LLM = app.LLMConfig{Provider: "cohere"}
+	_, err = app.New(cfg)
+	assert.Error(t, err)
+}
+
+func TestNew_CORSPolicy(t *testing.T) {
+	application, err := app.New(testConfig(t))
+	require.NoError(t, err)
+
+	w := preflight(application, "http://localhost:5173")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "http://localhost:5173", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), "PATCH")
+
+	w = preflight(application, "https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+	cfg := testConfig(t)
+	cfg.CORS.AllowOrigins = []string{"*"}
+	application, err = app.New(cfg)
+	require.NoError(t, err)
+
+	w = preflight(application, "https://anywhere.example.com")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestNew_StaticFiles(t *testing.T) {
+	cfg := testConfig(t)
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	application.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "blueprint")
+
+	cfg.StaticDir = ""
+	application, err = app.New(cfg)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	application.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestNew_Backends(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Artifacts.Backend = "local"
+	cfg.Artifacts.Dir = t.TempDir()
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	assert.NotNil(t, application.ProjectService.ArtifactStore())
+
+	cfg = testConfig(t)
+	cfg.Artifacts.Backend = "ftp"
+	_, err = app.New(cfg)
+	assert.Error(t, err)
+
+	cfg = testConfig(t)
+	cfg.Storage.Backend = "dynamodb" // No table configured
+	_, err = app.New(cfg)
+	assert.Error(t, err)
+
+	cfg = testConfig(t)
+	cfg.Storage.Backend = "postgres"
+	_, err = app.New(cfg)
+	assert.Error(t, err)
+}
+
+func TestNew_TemplateDirs(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.TemplateDirs = []string{t.TempDir()}
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.TemplateDirs[0], "go"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.TemplateDirs[0], "go", "OWNERS"), []byte("team-{{.ProjectName}}\n"), 0o644))
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	project, err := application.ProjectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "shop", Language: models.LanguageGo})
+	require.NoError(t, err)
+	files, err := application.ProjectService.GenerateProjectFiles(context.Background(), project)
+	require.NoError(t, err)
+	var owners string
+	for _, file := range files {
+		if file.Path == filepath.Join("shop", "OWNERS") {
+			owners = file.Content
+		}
+	}
+	assert.Equal(t, "team-shop\n", owners)
+
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.TemplateDirs[0], "go", "OWNERS"), []byte("{{end}}"), 0o644))
+	_, err = app.New(cfg)
+	assert.ErrorContains(t, err, "template")
+}
+
+func TestNew_RateLimitsAndBodyCaps(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Default = limits.Rate{RequestsPerMinute: 60, Burst: 1}
+	cfg.RateLimit.Generate = limits.Rate{RequestsPerMinute: 60, Burst: 1}
+	cfg.MaxBodyBytes = 64
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	requests := 0
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		requests++
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", requests))
+		w := httptest.NewRecorder()
+		application.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("POST", "/api/projects", `{"name":"`+strings.Repeat("x", 64)+`","language":"go"}`)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+	w = serve("POST", "/api/projects/missing/generate", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "1;w=1", w.Header().Get("RateLimit-Policy"))
+	w = serve("GET", "/api/projects/missing/download", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "generation and downloads share a budget")
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/templates", "").Code, "other routes have their own budget")
+	assert.Equal(t, http.StatusTooManyRequests, serve("GET", "/api/templates", "").Code,
+		"X-Forwarded-For from an untrusted peer does not give a fresh budget")
+	assert.Equal(t, http.StatusOK, serve("GET", "/api/health", "").Code, "the health check is never limited")
+}
+
+// The Lambda adapter serves the very same application as the HTTP server
+func TestNew_SameBehaviourInLambda(t *testing.T) {
+	application, err := app.New(testConfig(t))
+	require.NoError(t, err)
+	handler := api.NewLambdaHandler(application)
+
+	event := events.APIGatewayV2HTTPRequest{
+		RawPath: "/api/projects",
+		Headers: map[string]string{
+			"origin":                        "http://localhost:3000",
+			"access-control-request-method": "POST",
+		},
+		RequestContext: events.APIGatewayV2HTTPRequestContext{
+			HTTP: events.APIGatewayV2HTTPRequestContextHTTPDescription{Method: "OPTIONS"},
+		},
+	}
+	response, err := handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, response.StatusCode)
+	assert.Equal(t, "http://localhost:3000", response.Headers["Access-Control-Allow-Origin"])
+
+	event.RawPath = "/"
+	event.Headers = nil
+	event.RequestContext.HTTP.Method = "GET"
+	response, err = handler(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Body, "blueprint")
+}
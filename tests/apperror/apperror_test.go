@@ -0,0 +1,133 @@
+package apperror_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/requestid"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errWidgetNotFound = apperror.NotFound("widget_not_found", "widget not found")
+
+func TestKind_Status(t *testing.T) {
+	for kind, status := range map[apperror.Kind]int{
+		apperror.KindValidation:   http.StatusBadRequest,
+		apperror.KindUnauthorized: http.StatusUnauthorized,
+		apperror.KindForbidden:    http.StatusForbidden,
+		apperror.KindNotFound:     http.StatusNotFound,
+		apperror.KindConflict:     http.StatusConflict,
+		apperror.KindTooLarge:     http.StatusRequestEntityTooLarge,
+		apperror.KindRateLimited:  http.StatusTooManyRequests,
+		apperror.KindUnsupported:  http.StatusNotImplemented,
+		apperror.KindTimeout:      http.StatusGatewayTimeout,
+		apperror.KindInternal:     http.StatusInternalServerError,
+		apperror.Kind("unknown"):  http.StatusInternalServerError,
+	} {
+		assert.Equal(t, status, kind.Status(), kind)
+	}
+}
+
+func TestFrom(t *testing.T) {
+	wrapped := fmt.Errorf("failed to load widget: %w", fmt.Errorf("%w: w-1", errWidgetNotFound))
+	assert.Same(t, errWidgetNotFound, apperror.From(wrapped))
+	assert.True(t, errors.Is(wrapped, errWidgetNotFound))
+	assert.True(t, apperror.Is(wrapped, apperror.KindNotFound))
+	assert.False(t, apperror.Is(wrapped, apperror.KindConflict))
+
+	cause := errors.New("dial tcp 10.0.0.5:8000: connection refused")
+	internal := apperror.From(fmt.Errorf("failed to save widget: %w", cause))
+	assert.Equal(t, apperror.KindInternal, internal.Kind)
+	assert.Equal(t, "internal_error", internal.Code)
+	assert.Equal(t, "internal server error", internal.Message)
+	assert.ErrorIs(t, internal, cause, "the cause is kept for logs")
+
+	assert.Same(t, apperror.ErrDeadlineExceeded, apperror.From(fmt.Errorf("rendering stopped: %w", context.DeadlineExceeded)))
+	assert.Same(t, apperror.ErrRequestCancelled, apperror.From(context.Canceled))
+
+	invalid := apperror.InvalidField("name", "name cannot be empty")
+	assert.Equal(t, apperror.KindValidation, invalid.Kind)
+	assert.Equal(t, []apperror.FieldError{{Field: "name", Message: "name cannot be empty"}}, invalid.Details)
+}
+
+func serve(handler gin.HandlerFunc) (*httptest.ResponseRecorder, apperror.Response) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.Middleware(), apperror.Middleware())
+	router.GET("/widgets", handler)
+
+	req := httptest.NewRequest("GET", "/widgets", nil)
+	req.Header.Set(requestid.Header, "req-42")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response apperror.Response
+	_ = json.Unmarshal(w.Body.Bytes(), &response)
+	return w, response
+}
+
+func TestMiddleware_WritesEnvelope(t *testing.T) {
+	w, response := serve(func(c *gin.Context) {
+		_ = c.Error(fmt.Errorf("%w: w-1", errWidgetNotFound))
+	})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, apperror.Response{
+		Success:   false,
+		Error:     "Widget not found",
+		Code:      "widget_not_found",
+		RequestID: "req-42",
+	}, response)
+	assert.NotContains(t, w.Body.String(), "w-1", "context added for logs is not shown")
+
+	w, response = serve(func(c *gin.Context) {
+		_ = c.Error(errors.New("pq: password authentication failed for user admin"))
+	})
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "internal_error", response.Code)
+	assert.Equal(t, "Internal server error", response.Error)
+	assert.NotContains(t, w.Body.String(), "password")
+
+	w, _ = serve(func(c *gin.Context) {
+		_ = c.Error(errWidgetNotFound)
+		c.JSON(http.StatusAccepted, gin.H{"success": true})
+	})
+	assert.Equal(t, http.StatusAccepted, w.Code, "responses already written are left alone")
+}
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestid.Middleware())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, requestid.FromContext(c.Request.Context()))
+	})
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if id != "" {
+			req.Header.Set(requestid.Header, id)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("edge-7f3a")
+	assert.Equal(t, "edge-7f3a", w.Body.String())
+	assert.Equal(t, "edge-7f3a", w.Header().Get(requestid.Header))
+
+	for _, id := range []string{"", "has spaces", "line\nbreak"} {
+		w = get(id)
+		require.Len(t, w.Body.String(), 36, "a UUID replaces %q", id)
+		assert.Equal(t, w.Body.String(), w.Header().Get(requestid.Header))
+	}
+}
@@ -0,0 +1,63 @@
+package artifacts_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/artifacts"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	store, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+	key := artifacts.ArchiveKey("project-1", 1)
+
+	_, err = store.Get(ctx, key)
+	assert.True(t, errors.Is(err, artifacts.ErrNotFound))
+
+	require.NoError(t, store.Put(ctx, key, []byte("first"), "application/zip"))
+	require.NoError(t, store.Put(ctx, key, []byte("second"), "application/zip"))
+
+	data, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("second"), data)
+
+	exists, err := store.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	require.NoError(t, store.Delete(ctx, key))
+	require.NoError(t, store.Delete(ctx, key), "deleting a missing artifact is not an error")
+
+	exists, err = store.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestLocalStore_RejectsEscapingKeys(t *testing.T) {
+	store, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../outside.zip", "archives/../../outside.zip", "/etc/passwd"} {
+		assert.Error(t, store.Put(context.Background(), key, []byte("x"), ""), key)
+	}
+}
+
+func TestLocalStore_PresignUnsupported(t *testing.T) {
+	store, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	_, err = store.PresignGet(context.Background(), "archives/p/r1.zip", "p.zip", time.Minute)
+
+	assert.True(t, errors.Is(err, artifacts.ErrPresignUnsupported))
+}
+
+func TestArchiveKey(t *testing.T) {
+	assert.Equal(t, "archives/abc/r3.zip", artifacts.ArchiveKey("abc", 3))
+}
@@ -0,0 +1,131 @@
+package artifacts_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/awsauth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestS3Store(t *testing.T, endpoint, secret string) *artifacts.S3Store {
+	store, err := artifacts.NewS3Store(artifacts.S3Config{
+		Bucket:       stubBucket,
+		Endpoint:     endpoint,
+		UsePathStyle: true,
+		Credentials:  awsauth.Credentials{AccessKeyID: stubAccessKey, SecretAccessKey: secret},
+	})
+	require.NoError(t, err)
+	return store
+}
+
+func TestNewS3Store_Validation(t *testing.T) {
+	credentials := awsauth.Credentials{AccessKeyID: "a", SecretAccessKey: "b"}
+
+	_, err := artifacts.NewS3Store(artifacts.S3Config{Credentials: credentials})
+	assert.Error(t, err, "bucket is required")
+
+	_, err = artifacts.NewS3Store(artifacts.S3Config{Bucket: "b"})
+	assert.Error(t, err, "credentials are required")
+
+	_, err = artifacts.NewS3Store(artifacts.S3Config{Bucket: "b", Endpoint: "localhost", Credentials: credentials})
+	assert.Error(t, err, "endpoint needs a scheme")
+}
+
+func TestS3Store_RoundTrip(t *testing.T) {
+	stub := newMinioStub(t)
+	store := newTestS3Store(t, stub.URL, stubSecretKey)
+	ctx := context.Background()
+	key := artifacts.ArchiveKey("project-1", 2)
+
+	exists, err := store.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = store.Get(ctx, key)
+	assert.True(t, errors.Is(err, artifacts.ErrNotFound))
+
+	require.NoError(t, store.Put(ctx, key, []byte("zip-bytes"), "application/zip"))
+	assert.Equal(t, "application/zip", stub.contentTypes["archives/project-1/r2.zip"])
+
+	exists, err = store.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	data, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("zip-bytes"), data)
+
+	require.NoError(t, store.Delete(ctx, key))
+	exists, err = store.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestS3Store_KeysNeedingEscaping(t *testing.T) {
+	stub := newMinioStub(t)
+	store := newTestS3Store(t, stub.URL, stubSecretKey)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "archives/my project+1/r1.zip", []byte("data"), "application/zip"))
+
+	data, err := store.Get(ctx, "archives/my project+1/r1.zip")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+}
+
+func TestS3Store_RejectsBadCredentials(t *testing.T) {
+	stub := newMinioStub(t)
+	store := newTestS3Store(t, stub.URL, "wrong-secret")
+
+	err := store.Put(context.Background(), "archives/p/r1.zip", []byte("data"), "application/zip")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SignatureDoesNotMatch")
+}
+
+func TestS3Store_PresignGet(t *testing.T) {
+	stub := newMinioStub(t)
+	store := newTestS3Store(t, stub.URL, stubSecretKey)
+	ctx := context.Background()
+	key := artifacts.ArchiveKey("project-1", 1)
+	require.NoError(t, store.Put(ctx, key, []byte("zip-bytes"), "application/zip"))
+
+	url, err := store.PresignGet(ctx, key, "demo-go.zip", 10*time.Minute)
+	require.NoError(t, err)
+
+	// The URL works without any credentials
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "zip-bytes", string(body))
+	assert.Equal(t, "attachment; filename=demo-go.zip", resp.Header.Get("Content-Disposition"))
+}
+
+func TestS3Store_PresignGet_TamperedURL(t *testing.T) {
+	stub := newMinioStub(t)
+	store := newTestS3Store(t, stub.URL, stubSecretKey)
+	ctx := context.Background()
+	require.NoError(t, store.Put(ctx, "archives/a/r1.zip", []byte("a"), "application/zip"))
+	require.NoError(t, store.Put(ctx, "archives/b/r1.zip", []byte("b"), "application/zip"))
+
+	url, err := store.PresignGet(ctx, "archives/a/r1.zip", "", time.Minute)
+	require.NoError(t, err)
+
+	resp, err := http.Get(stub.URL + "/" + stubBucket + "/archives/b/r1.zip?" + url[len(stub.URL+"/"+stubBucket+"/archives/a/r1.zip?"):])
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
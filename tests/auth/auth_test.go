@@ -0,0 +1,252 @@
+package auth_test
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func segment(t *testing.T, v interface{}) string {
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(t *testing.T, secret string, claims map[string]interface{}) string {
+	input := segment(t, map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + segment(t, claims)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(input))
+	return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "alice",
+		"name": "Alice",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func request(header, value string) *http.Request {
+	req := httptest.NewRequest("GET", "/api/projects/1", nil)
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+	return req
+}
+
+func writePublicKey(t *testing.T, publicKey interface{}) string {
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+func TestNew_Validation(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{})
+	require.NoError(t, err)
+	assert.False(t, authenticator.Enabled())
+
+	_, err = auth.New(auth.Config{APIKeys: []auth.APIKey{{Name: "ci", Key: "short"}}})
+	assert.ErrorContains(t, err, "at least 16 characters")
+
+	_, err = auth.New(auth.Config{APIKeys: []auth.APIKey{
+		{Name: "ci", Key: "ci-key-0123456789"},
+		{Name: "deploy", Key: "ci-key-0123456789"},
+	}})
+	assert.ErrorContains(t, err, "configured twice")
+
+	_, err = auth.New(auth.Config{JWT: auth.JWTConfig{Secret: "too-short"}})
+	assert.ErrorContains(t, err, "at least 32 bytes")
+
+	_, err = auth.New(auth.Config{JWT: auth.JWTConfig{PublicKeyFile: filepath.Join(t.TempDir(), "missing.pem")}})
+	assert.Error(t, err)
+}
+
+func TestAuthenticate_APIKeys(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{{Name: "ci", Key: "ci-key-0123456789"}}})
+	require.NoError(t, err)
+	assert.True(t, authenticator.Enabled())
+
+	for _, req := range []*http.Request{
+		request("X-API-Key", "ci-key-0123456789"),
+		request("Authorization", "Bearer ci-key-0123456789"),
+	} {
+		principal, err := authenticator.Authenticate(req)
+		require.NoError(t, err)
+		assert.Equal(t, auth.Principal{ID: "apikey:ci", Name: "ci", Method: auth.MethodAPIKey}, principal)
+	}
+
+	_, err = authenticator.Authenticate(request("", ""))
+	assert.ErrorIs(t, err, auth.ErrMissingCredentials)
+
+	_, err = authenticator.Authenticate(request("X-API-Key", "ci-key-wrong"))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authenticator.Authenticate(request("Authorization", "Basic Y2k6a2V5"))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+
+	_, err = authenticator.Authenticate(request("Authorization", "Bearer "+signHS256(t, testSecret, validClaims())))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials, "tokens are rejected when no JWT key is configured")
+}
+
+func TestAuthenticate_HS256(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{JWT: auth.JWTConfig{
+		Secret:   testSecret,
+		Issuer:   "https://id.example.com",
+		Audience: "blueprint",
+	}})
+	require.NoError(t, err)
+
+	claims := validClaims()
+	claims["iss"] = "https://id.example.com"
+	claims["aud"] = []string{"other", "blueprint"}
+
+	principal, err := authenticator.Authenticate(request("Authorization", "Bearer "+signHS256(t, testSecret, claims)))
+	require.NoError(t, err)
+	assert.Equal(t, auth.Principal{ID: "jwt:https://id.example.com:alice", Name: "Alice", Method: auth.MethodJWT}, principal)
+
+	tests := []struct {
+		name   string
+		mutate func(map[string]interface{})
+		secret string
+	}{
+		{"wrong secret", func(map[string]interface{}) {}, "another-secret-0123456789abcdefgh"},
+		{"expired", func(c map[string]interface{}) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() }, testSecret},
+		{"not yet valid", func(c map[string]interface{}) { c["nbf"] = time.Now().Add(10 * time.Minute).Unix() }, testSecret},
+		{"no expiry", func(c map[string]interface{}) { delete(c, "exp") }, testSecret},
+		{"no subject", func(c map[string]interface{}) { delete(c, "sub") }, testSecret},
+		{"wrong issuer", func(c map[string]interface{}) { c["iss"] = "https://evil.example.com" }, testSecret},
+		{"wrong audience", func(c map[string]interface{}) { c["aud"] = "other" }, testSecret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := validClaims()
+			claims["iss"] = "https://id.example.com"
+			claims["aud"] = "blueprint"
+			tt.mutate(claims)
+
+			_, err := authenticator.Authenticate(request("Authorization", "Bearer "+signHS256(t, tt.secret, claims)))
+			assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+		})
+	}
+}
+
+func TestAuthenticate_RejectsUnsignedTokens(t *testing.T) {
+	authenticator, err := auth.New(auth.Config{JWT: auth.JWTConfig{Secret: testSecret}})
+	require.NoError(t, err)
+
+	token := segment(t, map[string]string{"alg": "none"}) + "." + segment(t, validClaims()) + "."
+	_, err = authenticator.Authenticate(request("Authorization", "Bearer "+token))
+	assert.ErrorContains(t, err, "unexpected token algorithm")
+}
+
+func TestAuthenticate_RS256(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	authenticator, err := auth.New(auth.Config{JWT: auth.JWTConfig{PublicKeyFile: writePublicKey(t, &key.PublicKey)}})
+	require.NoError(t, err)
+
+	input := segment(t, map[string]string{"alg": "RS256"}) + "." + segment(t, validClaims())
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	principal, err := authenticator.Authenticate(request("Authorization", "Bearer "+input+"."+base64.RawURLEncoding.EncodeToString(signature)))
+	require.NoError(t, err)
+	assert.Equal(t, "jwt::alice", principal.ID, "tokens without an issuer keep an empty one")
+
+	// An HMAC token "signed" with the public key must not pass as RS256
+	pemKey, _ := os.ReadFile(writePublicKey(t, &key.PublicKey))
+	_, err = authenticator.Authenticate(request("Authorization", "Bearer "+signHS256(t, string(pemKey), validClaims())))
+	assert.ErrorIs(t, err, auth.ErrInvalidCredentials)
+}
+
+func TestAuthenticate_ES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	authenticator, err := auth.New(auth.Config{JWT: auth.JWTConfig{PublicKeyFile: writePublicKey(t, &key.PublicKey)}})
+	require.NoError(t, err)
+
+	input := segment(t, map[string]string{"alg": "ES256"}) + "." + segment(t, validClaims())
+	digest := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+
+	principal, err := authenticator.Authenticate(request("Authorization", "Bearer "+input+"."+base64.RawURLEncoding.EncodeToString(signature)))
+	require.NoError(t, err)
+	assert.Equal(t, "jwt::alice", principal.ID, "tokens without an issuer keep an empty one")
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(authenticator *auth.Authenticator) *gin.Engine {
+		router := gin.New()
+		router.Use(auth.Middleware(authenticator))
+		router.GET("/whoami", func(c *gin.Context) {
+			c.JSON(http.StatusOK, auth.FromContext(c.Request.Context()))
+		})
+		return router
+	}
+
+	authenticator, err := auth.New(auth.Config{APIKeys: []auth.APIKey{{Name: "ci", Key: "ci-key-0123456789"}}})
+	require.NoError(t, err)
+	router := newRouter(authenticator)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/whoami", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="blueprint"`, w.Header().Get("WWW-Authenticate"))
+	assert.Contains(t, w.Body.String(), "Authentication required")
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("X-API-Key", "wrong-key-0123456789")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="invalid_token"`)
+	assert.NotContains(t, w.Body.String(), "unknown API key", "the reason is not disclosed")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/whoami", nil)
+	req.Header.Set("X-API-Key", "ci-key-0123456789")
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"apikey:ci","name":"ci","method":"api_key"}`, w.Body.String())
+
+	disabled, err := auth.New(auth.Config{})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	newRouter(disabled).ServeHTTP(w, httptest.NewRequest("GET", "/whoami", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"","method":""}`, w.Body.String())
+}
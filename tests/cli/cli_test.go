@@ -0,0 +1,255 @@
+package cli_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/cli"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func run(t *testing.T, input string, interactive bool, args ...string) (string, string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	command := cli.New(strings.NewReader(input), &out, &errOut)
+	command.SetInteractive(interactive)
+	err := command.Run(context.Background(), args)
+	return out.String(), errOut.String(), err
+}
+
+func TestRun_TemplatesList(t *testing.T) {
+	out, _, err := run(t, "", false, "templates", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "LANGUAGE")
+	assert.Contains(t, out, "Go Clean Architecture")
+	assert.Contains(t, out, "PHP CodeIgniter MVC")
+
+	out, _, err = run(t, "", false, "templates", "list", "-json")
+	require.NoError(t, err)
+	var templates []models.TemplateInfo
+	require.NoError(t, json.Unmarshal([]byte(out), &templates))
+	assert.Len(t, templates, 2)
+}
+
+func TestRun_TemplatesShow(t *testing.T) {
+	out, _, err := run(t, "", false, "templates", "show", "go")
+	require.NoError(t, err)
+	assert.Contains(t, out, "framework")
+	assert.Contains(t, out, "gin, chi, echo, standard")
+
+	out, _, err = run(t, "", false, "templates", "show", "-json", "php codeigniter mvc")
+	require.NoError(t, err)
+	var template models.TemplateInfo
+	require.NoError(t, json.Unmarshal([]byte(out), &template))
+	assert.Equal(t, models.LanguagePHP, template.Language)
+
+	_, _, err = run(t, "", false, "templates", "show", "rust")
+	assert.Error(t, err)
+	assert.Equal(t, 1, cli.ExitCode(err))
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"bogus"},
+		{"templates"},
+		{"templates", "show"},
+		{"new", "-language", "go"},
+		{"new", "-name", "x", "-language", "go", "-option", "nonsense"},
+		{"new", "-name", "x", "-language", "go", "-format", "rar"},
+	} {
+		_, _, err := run(t, "", false, args...)
+		assert.Equal(t, 2, cli.ExitCode(err), "args %v: %v", args, err)
+	}
+}
+
+func TestRun_NewWritesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	out, _, err := run(t, "", false, "new", "-name", "shop", "-language", "go", "-option", "framework=echo", "-output", dir)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Generated shop")
+
+	goMod, err := os.ReadFile(filepath.Join(dir, "shop", "go.mod"))
+	require.NoError(t, err)
+	assert.Contains(t, string(goMod), "module shop")
+	assert.DirExists(t, filepath.Join(dir, "shop", "internal", "controller"))
+
+	// A second run refuses to overwrite, and -force allows it
+	_, _, err = run(t, "", false, "new", "-name", "shop", "-language", "go", "-output", dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exist")
+	_, _, err = run(t, "", false, "new", "-name", "shop", "-language", "go", "-output", dir, "-force")
+	require.NoError(t, err)
+}
+
+func TestRun_NewRefusesSymlinksAndDirectories(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shop"), 0o755))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "shop", "internal")))
+
+	// Even with -force nothing is written through the link
+	_, _, err := run(t, "", false, "new", "-name", "shop", "-language", "go", "-output", dir, "-force")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "shop", "internal")+" is a symlink")
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+	assert.NoFileExists(t, filepath.Join(dir, "shop", "go.mod"), "nothing is written before the check")
+
+	// A directory where a file goes is reported up front too
+	dir = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "shop", "go.mod"), 0o755))
+	_, _, err = run(t, "", false, "new", "-name", "shop", "-language", "go", "-output", dir, "-force")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "shop", "go.mod")+" is a directory")
+	assert.NoDirExists(t, filepath.Join(dir, "shop", "internal"))
+}
+
+func TestRun_NewRejectsInvalidOptions(t *testing.T) {
+	_, _, err := run(t, "", false, "new", "-name", "shop", "-language", "go", "-option", "framework=rails", "-output", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rails")
+
+	_, _, err = run(t, "", false, "new", "-name", "shop", "-language", "rust", "-output", t.TempDir())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported language")
+}
+
+func TestRun_NewFromAnswersFile(t *testing.T) {
+	dir := t.TempDir()
+	answers := filepath.Join(dir, "answers.yaml")
+	require.NoError(t, os.WriteFile(answers, []byte(`name: portal
+language: php
+description: Customer portal
+options:
+  ci_version: 4
+  features: [authentication, dashboard]
+`), 0o644))
+
+	archive := filepath.Join(dir, "portal.zip")
+	_, _, err := run(t, "", false, "new", "-answers", answers, "-name", "renamed", "-output", archive)
+	require.NoError(t, err)
+
+	reader, err := zip.OpenReader(archive)
+	require.NoError(t, err)
+	defer reader.Close()
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.Contains(t, names, "renamed/composer.json", "flags override the answers file")
+
+	require.NoError(t, os.WriteFile(answers, []byte("name: x\nlanguage: go\ncolour: blue\n"), 0o644))
+	_, _, err = run(t, "", false, "new", "-answers", answers, "-output", dir)
+	assert.Error(t, err, "unknown fields are rejected")
+}
+
+func TestRun_NewWritesTarGz(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "api.tar.gz")
+	_, _, err := run(t, "", false, "new", "-name", "api", "-language", "go", "-output", archive)
+	require.NoError(t, err)
+
+	file, err := os.Open(archive)
+	require.NoError(t, err)
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	require.NoError(t, err)
+
+	found := false
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if header.Name == "api/go.mod" {
+			found = true
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestRun_NewPrompts(t *testing.T) {
+	dir := t.TempDir()
+	// Name, language, description, then an invalid and a valid framework;
+	// database and authentication take their defaults
+	input := "prompted\ngo\n\nrails\nchi\n\n\n"
+	_, prompts, err := run(t, input, true, "new", "-output", dir)
+	require.NoError(t, err)
+	assert.Contains(t, prompts, "HTTP Framework [gin/chi/echo/standard] (gin): ")
+	assert.Contains(t, prompts, "Choose one of")
+	assert.FileExists(t, filepath.Join(dir, "prompted", "go.mod"))
+
+	// -yes skips prompting, so the missing name is an error
+	_, _, err = run(t, input, true, "new", "-yes", "-language", "go", "-output", dir)
+	assert.Equal(t, 2, cli.ExitCode(err))
+
+	// Running out of input stops instead of looping
+	_, _, err = run(t, "half\n", true, "new", "-output", dir)
+	assert.Error(t, err)
+}
+
+func TestRun_Version(t *testing.T) {
+	var out bytes.Buffer
+	command := cli.New(strings.NewReader(""), &out, io.Discard)
+	command.SetBuildInfo(models.BuildInfo{Version: "1.2.3", Commit: "abc123"})
+	require.NoError(t, command.Run(context.Background(), []string{"version"}))
+	assert.Equal(t, "blueprint 1.2.3 (commit abc123)\n", out.String())
+}
+
+func TestRun_AddComponent(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := run(t, "", false, "new", "-name", "shop", "-language", "go", "-option", "framework=chi", "-output", dir)
+	require.NoError(t, err)
+	root := filepath.Join(dir, "shop")
+
+	out, _, err := run(t, "", false, "add", "-dir", root, "-option", "framework=chi", "-dry-run", "entity:order")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Dry run")
+	assert.NoFileExists(t, filepath.Join(root, "internal", "entity", "order.go"))
+
+	out, _, err = run(t, "", false, "add", "-dir", root, "-option", "framework=chi", "entity:order")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added entity:order")
+	assert.FileExists(t, filepath.Join(root, "internal", "entity", "order.go"))
+	router, err := os.ReadFile(filepath.Join(root, "internal", "routes", "router.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(router), `router.HandleFunc("/api/orders", controller.OrdersHandler)`)
+
+	out, _, err = run(t, "", false, "add", "-dir", root, "-json", "entity:order")
+	require.NoError(t, err)
+	var result models.ComponentResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.False(t, result.Applied, "adding it again changes nothing")
+
+	// Edited files are reported, not overwritten
+	edited := filepath.Join(root, "internal", "controller", "respond.go")
+	require.NoError(t, os.WriteFile(edited, []byte("package controller\n"), 0o644))
+	_, errOut, err := run(t, "", false, "add", "-dir", root, "entity:customer")
+	require.Error(t, err)
+	assert.Equal(t, 1, cli.ExitCode(err))
+	assert.Contains(t, errOut, "respond.go")
+	assert.NoFileExists(t, filepath.Join(root, "internal", "entity", "customer.go"))
+
+	for _, args := range [][]string{
+		{"add"},
+		{"add", "entity"},
+		{"add", "-option", "colour=blue", "entity:order"},
+	} {
+		_, _, err := run(t, "", false, args...)
+		assert.Equal(t, 2, cli.ExitCode(err), "args %v: %v", args, err)
+	}
+}
@@ -0,0 +1,211 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/cli"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = t.TempDir()
+
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	server := httptest.NewServer(application)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// runRemote runs a command with the server set the way the environment would
+func runRemote(t *testing.T, serverURL, input string, args ...string) (string, string, error) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	command := cli.New(strings.NewReader(input), &out, &errOut)
+	command.SetServer(serverURL, "")
+	err := command.Run(context.Background(), args)
+	return out.String(), errOut.String(), err
+}
+
+func TestRun_RemoteNeedsServer(t *testing.T) {
+	_, _, err := runRemote(t, "", "", "projects", "list")
+	assert.Equal(t, 2, cli.ExitCode(err))
+	assert.Contains(t, err.Error(), "BLUEPRINT_SERVER")
+
+	_, _, err = runRemote(t, "", "", "projects", "list", "-server", "not a url")
+	assert.Equal(t, 2, cli.ExitCode(err))
+}
+
+func TestRun_RemoteProjects(t *testing.T) {
+	server := newServer(t)
+
+	out, _, err := runRemote(t, server.URL, "", "projects", "create", "-json", "-name", "remote", "-language", "go", "-option", "framework=chi", "-generate")
+	require.NoError(t, err)
+	var project models.Project
+	require.NoError(t, json.Unmarshal([]byte(out), &project))
+	assert.Equal(t, "chi", project.Options.Framework)
+	assert.NotEmpty(t, project.Files)
+
+	out, _, err = runRemote(t, server.URL, "", "projects", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "REVISION")
+	assert.Contains(t, out, project.ID)
+
+	out, _, err = runRemote(t, server.URL, "", "projects", "show", project.ID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "framework:")
+	assert.Contains(t, out, "remote/go.mod")
+
+	_, _, err = runRemote(t, server.URL, "", "projects", "show", "missing")
+	require.Error(t, err)
+	assert.Equal(t, 1, cli.ExitCode(err))
+	assert.Contains(t, err.Error(), "404")
+}
+
+func TestRun_RemoteDownloadExtracts(t *testing.T) {
+	server := newServer(t)
+	out, _, err := runRemote(t, server.URL, "", "projects", "create", "-json", "-name", "fetched", "-language", "php", "-generate")
+	require.NoError(t, err)
+	var project models.Project
+	require.NoError(t, json.Unmarshal([]byte(out), &project))
+
+	dir := t.TempDir()
+	out, _, err = runRemote(t, server.URL, "", "projects", "download", "-output", dir, project.ID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "fetched-php.zip")
+	assert.FileExists(t, filepath.Join(dir, "fetched", "composer.json"))
+
+	// Local edits are not overwritten without -force
+	edited := filepath.Join(dir, "fetched", "composer.json")
+	require.NoError(t, os.WriteFile(edited, []byte("local"), 0o644))
+	_, _, err = runRemote(t, server.URL, "", "projects", "download", "-output", dir, project.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exist")
+	content, err := os.ReadFile(edited)
+	require.NoError(t, err)
+	assert.Equal(t, "local", string(content))
+
+	_, _, err = runRemote(t, server.URL, "", "projects", "download", "-output", dir, "-force", project.ID)
+	require.NoError(t, err)
+	content, err = os.ReadFile(edited)
+	require.NoError(t, err)
+	assert.NotEqual(t, "local", string(content))
+}
+
+func TestRun_RemoteChat(t *testing.T) {
+	server := newServer(t)
+
+	out, errOut, err := runRemote(t, server.URL, "", "chat", "What", "database", "should", "I", "use?")
+	require.NoError(t, err)
+	assert.NotEmpty(t, strings.TrimSpace(out))
+	assert.Contains(t, errOut, "Session: ")
+
+	// Without arguments the message is read from the input
+	out, _, err = runRemote(t, server.URL, "Suggest a framework\n", "chat", "-json")
+	require.NoError(t, err)
+	var response models.ChatResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &response))
+	assert.True(t, response.Success)
+	assert.NotEmpty(t, response.SessionID)
+
+	_, _, err = runRemote(t, server.URL, "", "chat")
+	assert.Equal(t, 2, cli.ExitCode(err))
+}
+
+func TestRun_RemoteChatConversation(t *testing.T) {
+	server := newServer(t)
+	var out, errOut bytes.Buffer
+	command := cli.New(strings.NewReader("Suggest a framework\n\nAnd a database?\n"), &out, &errOut)
+	command.SetServer(server.URL, "")
+	command.SetInteractive(true)
+
+	require.NoError(t, command.Run(context.Background(), []string{"chat"}))
+	assert.Equal(t, 4, strings.Count(errOut.String(), "> "), "one prompt per line, and a last one that meets the end of the input")
+	assert.Equal(t, 1, strings.Count(errOut.String(), "Session: "), "later messages continue the session")
+}
+
+func TestRun_RemoteAddComponent(t *testing.T) {
+	server := newServer(t)
+	out, _, err := runRemote(t, server.URL, "", "projects", "create", "-json", "-name", "shop", "-language", "go", "-generate")
+	require.NoError(t, err)
+	var project models.Project
+	require.NoError(t, json.Unmarshal([]byte(out), &project))
+
+	out, _, err = runRemote(t, server.URL, "", "projects", "add", project.ID, "oauth:github")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Added oauth:github")
+	assert.Contains(t, out, "merged")
+	assert.Contains(t, out, "revision 3")
+
+	out, _, err = runRemote(t, server.URL, "", "projects", "add", "-json", project.ID, "oauth:github")
+	require.NoError(t, err)
+	var response models.ComponentResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &response))
+	assert.False(t, response.Result.Applied)
+
+	_, _, err = runRemote(t, server.URL, "", "projects", "add", project.ID, "oauth:myspace")
+	require.Error(t, err)
+	assert.Equal(t, 1, cli.ExitCode(err))
+	assert.Contains(t, err.Error(), "400")
+}
+
+func TestRun_RemoteUpgrade(t *testing.T) {
+	server := newServer(t)
+	out, _, err := runRemote(t, server.URL, "", "projects", "create", "-json", "-name", "shop", "-language", "go", "-generate")
+	require.NoError(t, err)
+	var project models.Project
+	require.NoError(t, json.Unmarshal([]byte(out), &project))
+
+	dir := t.TempDir()
+	_, _, err = runRemote(t, server.URL, "", "projects", "download", "-output", dir, project.ID)
+	require.NoError(t, err)
+	root := filepath.Join(dir, "shop")
+	notes := filepath.Join(root, "NOTES.md")
+	require.NoError(t, os.WriteFile(notes, []byte("local notes\n"), 0o644))
+
+	out, _, err = runRemote(t, server.URL, "", "projects", "upgrade", "-dir", root, project.ID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "FILE")
+	assert.Contains(t, out, "Upgraded from revision 2: ")
+	content, err := os.ReadFile(notes)
+	require.NoError(t, err)
+	assert.Equal(t, "local notes\n", string(content), "local files are kept")
+
+	merged := t.TempDir()
+	out, _, err = runRemote(t, server.URL, "", "projects", "upgrade", "-json", "-dir", root, "-output", merged, project.ID)
+	require.NoError(t, err)
+	var report models.UpgradeReport
+	require.NoError(t, json.Unmarshal([]byte(out), &report))
+	assert.Equal(t, 2, report.BaseRevision)
+	assert.Zero(t, report.Conflicts)
+	assert.FileExists(t, filepath.Join(merged, "shop", "go.mod"))
+	assert.FileExists(t, filepath.Join(merged, "shop", "NOTES.md"))
+
+	empty := t.TempDir()
+	out, _, err = runRemote(t, server.URL, "", "projects", "upgrade", "-dry-run", "-dir", root, "-output", empty, project.ID)
+	require.NoError(t, err)
+	assert.Contains(t, out, "Dry run: upgrade from revision 2")
+	entries, err := os.ReadDir(empty)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "dry runs write nothing")
+
+	_, _, err = runRemote(t, server.URL, "", "projects", "upgrade", "-dir", root)
+	assert.Equal(t, 2, cli.ExitCode(err))
+	_, _, err = runRemote(t, server.URL, "", "projects", "upgrade", "-dir", root, "-base", "7", project.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/client"
+	"boilerplate-blueprint/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "cli-test-key-0123456789"
+
+func newServer(t *testing.T) *httptest.Server {
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = t.TempDir()
+	cfg.Auth = auth.Config{APIKeys: []auth.APIKey{{Name: "cli", Key: testKey}}}
+
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	server := httptest.NewServer(application)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNew_RejectsInvalidURLs(t *testing.T) {
+	for _, serverURL := range []string{"", "localhost:8080", "ftp://example.com", "http://"} {
+		_, err := client.New(serverURL, "")
+		assert.Error(t, err, serverURL)
+	}
+}
+
+func TestClient_ProjectLifecycle(t *testing.T) {
+	server := newServer(t)
+	api, err := client.New(server.URL+"/", testKey)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	templates, err := api.Templates(ctx)
+	require.NoError(t, err)
+	assert.Len(t, templates, 2)
+
+	project, err := api.CreateProject(ctx, &models.ProjectRequest{Name: "remote", Language: models.LanguageGo})
+	require.NoError(t, err)
+	assert.Equal(t, "gin", project.Options.Framework)
+
+	files, err := api.GenerateProject(ctx, project.ID)
+	require.NoError(t, err)
+	assert.NotEmpty(t, files)
+
+	projects, err := api.ListProjects(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, project.ID, projects[0].ID)
+
+	fetched, err := api.GetProject(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Len(t, fetched.Files, len(files))
+
+	data, filename, err := api.DownloadProject(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "remote-go.zip", filename)
+	_, err = zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	assert.NoError(t, err)
+
+	upgrade, err := api.UpgradeProject(ctx, project.ID, 0, data)
+	require.NoError(t, err)
+	assert.True(t, upgrade.Success)
+	assert.Zero(t, upgrade.Report.Conflicts)
+	assert.Equal(t, "remote-go-upgrade.zip", upgrade.Filename)
+	assert.NotEmpty(t, upgrade.Archive)
+
+	response, err := api.Chat(ctx, &models.ChatRequest{Message: "Which database should I use?", ProjectID: project.ID})
+	require.NoError(t, err)
+	assert.NotEmpty(t, response.SessionID)
+	require.NotNil(t, response.Message)
+	assert.NotEmpty(t, response.Message.Content)
+}
+
+func TestClient_Errors(t *testing.T) {
+	server := newServer(t)
+	ctx := context.Background()
+
+	anonymous, err := client.New(server.URL, "")
+	require.NoError(t, err)
+	_, err = anonymous.ListProjects(ctx, "")
+	var apiErr *client.Error
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnauthorized, apiErr.Status)
+
+	api, err := client.New(server.URL+"/api/v1", testKey)
+	require.NoError(t, err)
+	_, err = api.GetProject(ctx, "missing")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.Status)
+	assert.Equal(t, "project_not_found", apiErr.Code)
+	assert.Contains(t, err.Error(), "server returned 404")
+}
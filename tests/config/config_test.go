@@ -0,0 +1,375 @@
+package config_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/config"
+	"boilerplate-blueprint/internal/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, args ...string) (config.Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(&bytes.Buffer{})
+	return config.Load(fs, args)
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, "debug", cfg.Server.GinMode)
+	assert.Contains(t, cfg.CORS.AllowOrigins, "http://localhost:5173")
+	assert.Equal(t, 200, cfg.Chat.MaxMessages)
+	assert.Equal(t, config.Duration(24*time.Hour), cfg.Chat.IdleTimeout)
+	assert.Equal(t, "none", cfg.LLM.Provider)
+	assert.Equal(t, config.TLSConfig{}, cfg.TLS)
+}
+
+func TestLoad_YAMLFile(t *testing.T) {
+	templates := t.TempDir()
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: 127.0.0.1:9000
+  gin_mode: release
+cors:
+  allow_origins: ["https://app.example.com"]
+chat:
+  max_messages: 50
+  session_idle_timeout: 2h
+rate_limit:
+  enabled: true
+  requests_per_minute: 60
+templates:
+  dirs: [`+templates+`]
+`)
+
+	cfg, err := load(t, "-config", path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "127.0.0.1:9000", cfg.Server.Addr)
+	assert.Equal(t, "release", cfg.Server.GinMode)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORS.AllowOrigins)
+	assert.Equal(t, 50, cfg.Chat.MaxMessages)
+	assert.Equal(t, config.Duration(2*time.Hour), cfg.Chat.IdleTimeout)
+	assert.Equal(t, config.Duration(10*time.Minute), cfg.Chat.SweepInterval, "unset keys keep their defaults")
+	assert.True(t, cfg.RateLimit.Enabled)
+	assert.Equal(t, 60, cfg.RateLimit.RequestsPerMinute)
+	assert.Equal(t, []string{templates}, cfg.Templates.Dirs)
+	assert.Equal(t, []string{templates}, cfg.App().TemplateDirs)
+}
+
+func TestLoad_TOMLFile(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[server]
+addr = ":9100"
+
+[llm]
+provider = "anthropic"
+model = "claude"
+api_key = "secret-key"
+timeout = "30s"
+`)
+
+	t.Setenv("CONFIG_FILE", path)
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9100", cfg.Server.Addr)
+	assert.Equal(t, "anthropic", cfg.LLM.Provider)
+	assert.Equal(t, "secret-key", cfg.LLM.APIKey)
+	assert.Equal(t, config.Duration(30*time.Second), cfg.LLM.Timeout)
+}
+
+func TestLoad_RejectsUnknownKeysAndFormats(t *testing.T) {
+	_, err := load(t, "-config", writeFile(t, "config.yaml", "server:\n  adress: :9000\n"))
+	assert.ErrorContains(t, err, "adress")
+
+	_, err = load(t, "-config", writeFile(t, "config.json", "{}"))
+	assert.ErrorContains(t, err, "unsupported config file format")
+
+	_, err = load(t, "-config", filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: :7000
+  gin_mode: release
+storage:
+  backend: memory
+chat:
+  max_messages: 10
+`)
+
+	t.Setenv("GIN_MODE", "test")
+	t.Setenv("CHAT_MAX_MESSAGES", "20")
+	t.Setenv("CORS_ALLOW_ORIGINS", "https://a.example.com, https://b.example.com")
+
+	cfg, err := load(t, "--config="+path, "-gin-mode", "debug", "-addr", ":7100")
+	require.NoError(t, err)
+
+	assert.Equal(t, ":7100", cfg.Server.Addr, "flag overrides file")
+	assert.Equal(t, "debug", cfg.Server.GinMode, "flag overrides env and file")
+	assert.Equal(t, 20, cfg.Chat.MaxMessages, "env overrides file")
+	assert.Equal(t, "memory", cfg.Storage.Backend, "file overrides default")
+	assert.Equal(t, []string{"https://a.example.com", "https://b.example.com"}, cfg.CORS.AllowOrigins)
+}
+
+func TestLoad_EnvShorthands(t *testing.T) {
+	t.Setenv("PORT", "3001")
+	t.Setenv("STATIC_DIR", "")
+	t.Setenv("ARTIFACT_STORE", "")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, ":3001", cfg.Server.Addr)
+	assert.Empty(t, cfg.StaticDir, "an empty STATIC_DIR disables static files")
+	assert.Empty(t, cfg.Artifacts.Backend)
+
+	t.Setenv("SERVER_ADDR", "0.0.0.0:4000")
+	cfg, err = load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:4000", cfg.Server.Addr, "SERVER_ADDR wins over PORT")
+}
+
+func TestLoad_InvalidEnvValue(t *testing.T) {
+	t.Setenv("CHAT_SESSION_IDLE_TIMEOUT", "forever")
+	_, err := load(t)
+	assert.ErrorContains(t, err, "CHAT_SESSION_IDLE_TIMEOUT")
+}
+
+func TestLoad_LambdaDefaults(t *testing.T) {
+	t.Setenv("LAMBDA_TASK_ROOT", "/var/task")
+	t.Setenv("DYNAMODB_TABLE", "blueprint-dev")
+	t.Setenv("AWS_REGION", "eu-west-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	assert.Equal(t, "release", cfg.Server.GinMode)
+	assert.Equal(t, "dynamodb", cfg.Storage.Backend)
+	assert.Equal(t, "eu-west-1", cfg.Storage.DynamoDB.Region)
+
+	appConfig := cfg.App()
+	assert.Equal(t, "blueprint-dev", appConfig.Storage.DynamoDB.Table)
+	assert.Equal(t, "SECRET", appConfig.Storage.DynamoDB.Credentials.SecretAccessKey)
+}
+
+func TestLoad_AuthFromEnv(t *testing.T) {
+	t.Setenv("AUTH_API_KEYS", "ci:ci-key-0123456789, alice : alice-key-0123456789")
+	t.Setenv("AUTH_JWT_SECRET", "hs256-secret-that-is-long-enough-0123")
+	t.Setenv("AUTH_JWT_AUDIENCE", "blueprint")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	authConfig := cfg.App().Auth
+	require.Len(t, authConfig.APIKeys, 2)
+	assert.Equal(t, "ci", authConfig.APIKeys[0].Name)
+	assert.Equal(t, "alice", authConfig.APIKeys[1].Name)
+	assert.Equal(t, "alice-key-0123456789", authConfig.APIKeys[1].Key)
+	assert.Equal(t, "blueprint", authConfig.JWT.Audience)
+	assert.Equal(t, time.Minute, authConfig.JWT.Leeway)
+}
+
+func TestLoad_RateLimitsFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ENABLED", "true")
+	t.Setenv("RATE_LIMIT_GENERATE_REQUESTS_PER_MINUTE", "6")
+	t.Setenv("RATE_LIMIT_CHAT_BURST", "4")
+	t.Setenv("SERVER_MAX_BODY_BYTES", "2048")
+	t.Setenv("SERVER_TRUSTED_PROXIES", "10.0.0.0/8,192.0.2.10")
+
+	cfg, err := load(t)
+	require.NoError(t, err)
+
+	appConfig := cfg.App()
+	assert.True(t, appConfig.RateLimit.Enabled)
+	assert.Equal(t, limits.Rate{RequestsPerMinute: 120, Burst: 30}, appConfig.RateLimit.Default)
+	assert.Equal(t, 6, appConfig.RateLimit.Generate.RequestsPerMinute)
+	assert.Equal(t, 4, appConfig.RateLimit.Chat.Burst)
+	assert.Equal(t, int64(2048), appConfig.MaxBodyBytes)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.10"}, appConfig.TrustedProxies)
+
+	cfg.Server.TrustedProxies = []string{"proxy.internal"}
+	cfg.RateLimit.ChatRequestsPerMinute = 0
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.trusted_proxies")
+	assert.Contains(t, err.Error(), "rate_limit.chat_requests_per_minute")
+}
+
+func TestLoad_RequestTimeouts(t *testing.T) {
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.App().Timeouts.Default)
+	assert.Equal(t, 2*time.Minute, cfg.App().Timeouts.Generate)
+	assert.Equal(t, time.Minute, cfg.App().Timeouts.Chat)
+
+	t.Setenv("SERVER_CHAT_REQUEST_TIMEOUT", "90s")
+	cfg, err = load(t, "-generate-request-timeout", "5m", "-request-timeout", "0s")
+	require.NoError(t, err)
+	assert.Equal(t, time.Duration(0), cfg.App().Timeouts.Default)
+	assert.Equal(t, 5*time.Minute, cfg.App().Timeouts.Generate)
+	assert.Equal(t, 90*time.Second, cfg.App().Timeouts.Chat)
+
+	t.Setenv("SERVER_CHAT_REQUEST_TIMEOUT", "-1s")
+	_, err = load(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.chat_request_timeout")
+}
+
+func TestLoad_MetricsFromEnv(t *testing.T) {
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.True(t, cfg.App().Metrics.Enabled)
+	assert.Equal(t, "/metrics", cfg.App().Metrics.Path)
+
+	t.Setenv("METRICS_ENABLED", "false")
+	t.Setenv("METRICS_PATH", "/internal/metrics")
+	cfg, err = load(t)
+	require.NoError(t, err)
+	assert.False(t, cfg.App().Metrics.Enabled)
+	assert.Equal(t, "/internal/metrics", cfg.App().Metrics.Path)
+}
+
+func TestLoad_LogSettings(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "debug")
+	cfg, err := load(t, "-log-format", "text")
+	require.NoError(t, err)
+	assert.Equal(t, "text", cfg.Log.Format)
+	assert.Equal(t, "debug", cfg.Log.Level)
+
+	logger, err := cfg.Logger()
+	require.NoError(t, err)
+	assert.True(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	t.Setenv("LOG_LEVEL", "verbose")
+	_, err = load(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "log.level")
+}
+
+func TestLoad_TracingFromEnv(t *testing.T) {
+	cfg, err := load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "none", cfg.TracingSetup().Exporter)
+	assert.Equal(t, 1.0, cfg.TracingSetup().SampleRatio)
+
+	t.Setenv("TRACING_EXPORTER", "otlp")
+	t.Setenv("TRACING_OTLP_ENDPOINT", "collector:4318")
+	t.Setenv("TRACING_SAMPLE_RATIO", "0.25")
+	cfg, err = load(t)
+	require.NoError(t, err)
+	assert.Equal(t, "otlp", cfg.TracingSetup().Exporter)
+	assert.Equal(t, "collector:4318", cfg.TracingSetup().Endpoint)
+	assert.Equal(t, 0.25, cfg.TracingSetup().SampleRatio)
+
+	t.Setenv("TRACING_SAMPLE_RATIO", "2")
+	_, err = load(t)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing.sample_ratio")
+}
+
+func TestValidate_AuthProblems(t *testing.T) {
+	cfg := config.Default()
+	cfg.Auth.APIKeys = []string{"ci:ci-key-0123456789", "no-separator"}
+	cfg.Auth.JWTSecret = "hs256-secret-that-is-long-enough-0123"
+	cfg.Auth.JWTPublicKeyFile = filepath.Join(t.TempDir(), "missing.pem")
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "auth.api_keys entry 2")
+	assert.NotContains(t, err.Error(), "no-separator", "malformed entries may hold a key")
+	assert.Contains(t, err.Error(), "cannot both be set")
+	assert.Contains(t, err.Error(), "auth.jwt_public_key_file")
+
+	cfg = config.Default()
+	cfg.Auth.APIKeys = []string{"ci:short"}
+	err = cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at least 16 characters")
+}
+
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	cfg := config.Default()
+	cfg.Server.Addr = "8080"
+	cfg.Server.GinMode = "verbose"
+	cfg.TLS.CertFile = "cert.pem"
+	cfg.CORS.AllowOrigins = []string{"example.com"}
+	cfg.Storage.Backend = "dynamodb"
+	cfg.Artifacts.Backend = "ftp"
+	cfg.LLM.Provider = "openai"
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Burst = 0
+	cfg.Templates.Dirs = []string{filepath.Join(t.TempDir(), "missing")}
+	cfg.Metrics.Path = "/api/metrics"
+	cfg.Log.Format = "xml"
+	cfg.Tracing.Exporter = "zipkin"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, message := range []string{
+		"server.addr",
+		"server.gin_mode",
+		"tls.cert_file and tls.key_file",
+		"cors.allow_origins",
+		"storage.dynamodb.table",
+		"artifacts.backend",
+		"aws.access_key_id",
+		"llm.model",
+		"llm.api_key",
+		"rate_limit.burst",
+		"templates.dirs",
+		"metrics.path",
+		"log.format",
+		"tracing.exporter",
+	} {
+		assert.Contains(t, err.Error(), message)
+	}
+
+	assert.NoError(t, config.Default().Validate())
+}
+
+func TestDump_RedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.AWS.AccessKeyID = "AKIDEXAMPLE"
+	cfg.AWS.SecretAccessKey = "wJalrXUtnFEMI"
+	cfg.LLM.APIKey = "sk-live-123"
+	cfg.Auth.APIKeys = []string{"ci:ci-key-0123456789"}
+	cfg.Auth.JWTSecret = "hs256-secret-that-is-long-enough-0123"
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Dump(&out))
+
+	assert.Contains(t, out.String(), "AKIDEXAMPLE")
+	assert.Contains(t, out.String(), "[REDACTED]")
+	assert.Contains(t, out.String(), "session_idle_timeout: 24h0m0s")
+	assert.NotContains(t, out.String(), "wJalrXUtnFEMI")
+	assert.NotContains(t, out.String(), "sk-live-123")
+	assert.NotContains(t, out.String(), "ci-key-0123456789")
+	assert.NotContains(t, out.String(), "hs256-secret")
+	assert.Equal(t, "sk-live-123", cfg.LLM.APIKey, "redaction does not modify the original")
+	assert.Equal(t, []string{"ci:ci-key-0123456789"}, cfg.Auth.APIKeys)
+}
@@ -0,0 +1,106 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/health"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// unreachableStore fails every lookup the way an unreachable backend would
+type unreachableStore struct {
+	storage.Store
+}
+
+func (unreachableStore) GetProject(context.Context, string) (*models.Project, error) {
+	return nil, errors.New("dial tcp 10.0.0.5:8000: connection refused")
+}
+
+// emptyStore finds nothing, like a healthy backend asked for the probe key
+type emptyStore struct {
+	storage.Store
+}
+
+func (emptyStore) GetProject(context.Context, string) (*models.Project, error) {
+	return nil, storage.ErrNotFound
+}
+
+func TestChecker_Run(t *testing.T) {
+	checker := health.NewChecker(20 * time.Millisecond)
+	checker.Register("storage", health.StorageCheck(emptyStore{}))
+	checker.Register("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	checker.Register("llm", func(context.Context) error { return errors.New("connection refused") })
+
+	results, ready := checker.Run(context.Background())
+	assert.False(t, ready)
+	require.Len(t, results, 3)
+	assert.Equal(t, "storage", results[0].Name, "results keep registration order")
+	assert.Equal(t, models.CheckUp, results[0].Status)
+	assert.Empty(t, results[0].Error)
+	assert.Equal(t, models.CheckDown, results[1].Status)
+	assert.Equal(t, "check timed out", results[1].Error)
+	assert.Equal(t, "check failed", results[2].Error, "causes are logged, not reported")
+
+	checker.Register("slow", func(context.Context) error { return nil })
+	checker.Register("llm", func(context.Context) error { return nil })
+	results, ready = checker.Run(context.Background())
+	assert.True(t, ready)
+	assert.Len(t, results, 3, "registering a name again replaces its check")
+}
+
+func TestStorageAndArtifactChecks(t *testing.T) {
+	assert.NoError(t, health.StorageCheck(emptyStore{})(context.Background()))
+	assert.Error(t, health.StorageCheck(unreachableStore{})(context.Background()))
+
+	store, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	assert.NoError(t, health.ArtifactCheck(store)(context.Background()))
+}
+
+func TestLLMCheck(t *testing.T) {
+	var seen *http.Request
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	check, err := health.LLMCheck(health.ProviderOpenAI, server.URL+"/v1/", "sk-test", nil)
+	require.NoError(t, err)
+	require.NoError(t, check(context.Background()))
+	assert.Equal(t, "/v1/models", seen.URL.Path)
+	assert.Equal(t, "Bearer sk-test", seen.Header.Get("Authorization"))
+
+	check, err = health.LLMCheck(health.ProviderAnthropic, server.URL+"/v1", "key", nil)
+	require.NoError(t, err)
+	require.NoError(t, check(context.Background()))
+	assert.Equal(t, "key", seen.Header.Get("X-Api-Key"))
+	assert.NotEmpty(t, seen.Header.Get("Anthropic-Version"))
+
+	check, err = health.LLMCheck(health.ProviderOllama, server.URL, "", nil)
+	require.NoError(t, err)
+	require.NoError(t, check(context.Background()))
+	assert.Equal(t, "/api/tags", seen.URL.Path)
+
+	status = http.StatusUnauthorized
+	assert.ErrorContains(t, check(context.Background()), "rejected the API key")
+	status = http.StatusBadGateway
+	assert.ErrorContains(t, check(context.Background()), "502")
+
+	_, err = health.LLMCheck("cohere", "", "", nil)
+	assert.Error(t, err)
+}
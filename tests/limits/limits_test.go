@@ -0,0 +1,190 @@
+package limits_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/auth"
+	"boilerplate-blueprint/internal/limits"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct{ now time.Time }
+
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+func newClock() *clock                   { return &clock{now: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)} }
+func allow(l limits.Limiter, key string, rate limits.Rate) limits.Decision {
+	decision, _ := l.Allow(context.Background(), key, rate)
+	return decision
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, limits.Rate) (limits.Decision, error) {
+	return limits.Decision{}, errors.New("redis unavailable")
+}
+
+func setupRouter(limiter limits.Limiter, rate limits.Rate, principal string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if principal != "" {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), auth.Principal{ID: principal}))
+		}
+	})
+	router.GET("/generate", limits.RateLimit(limiter, "generate", rate), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	router.GET("/chat", limits.RateLimit(limiter, "chat", rate), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"success": true})
+	})
+	return router
+}
+
+func get(router *gin.Engine, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	req.RemoteAddr = remoteAddr
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMemoryLimiter_TokenBucket(t *testing.T) {
+	clock := newClock()
+	limiter := limits.NewMemoryLimiter()
+	limiter.SetClock(clock.Now)
+	rate := limits.Rate{RequestsPerMinute: 60, Burst: 3}
+
+	for want := 2; want >= 0; want-- {
+		decision := allow(limiter, "alice", rate)
+		assert.True(t, decision.Allowed)
+		assert.Equal(t, 3, decision.Limit)
+		assert.Equal(t, want, decision.Remaining)
+	}
+
+	denied := allow(limiter, "alice", rate)
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, time.Second, denied.RetryAfter)
+	assert.Equal(t, 3*time.Second, denied.Reset)
+	assert.True(t, allow(limiter, "bob", rate).Allowed, "buckets are per key")
+
+	clock.Advance(time.Second)
+	assert.True(t, allow(limiter, "alice", rate).Allowed, "one token refills per second")
+	assert.False(t, allow(limiter, "alice", rate).Allowed)
+
+	clock.Advance(time.Hour)
+	assert.Equal(t, 2, allow(limiter, "alice", rate).Remaining, "refills stop at the burst size")
+}
+
+func TestRateLimit_HeadersAndRetryAfter(t *testing.T) {
+	router := setupRouter(limits.NewMemoryLimiter(), limits.Rate{RequestsPerMinute: 30, Burst: 2}, "")
+
+	w := get(router, "/generate", "192.0.2.1:1234")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2;w=4", w.Header().Get("RateLimit-Policy"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, get(router, "/generate", "192.0.2.1:1234").Code)
+
+	w = get(router, "/generate", "192.0.2.1:5678")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"success":false`)
+
+	assert.Equal(t, http.StatusOK, get(router, "/chat", "192.0.2.1:1234").Code, "route groups have separate budgets")
+	assert.Equal(t, http.StatusOK, get(router, "/generate", "192.0.2.2:1234").Code, "clients are keyed by IP")
+}
+
+func TestRateLimit_KeysOnPrincipal(t *testing.T) {
+	limiter := limits.NewMemoryLimiter()
+	rate := limits.Rate{RequestsPerMinute: 1, Burst: 1}
+	alice := setupRouter(limiter, rate, "alice")
+	bob := setupRouter(limiter, rate, "bob")
+
+	assert.Equal(t, http.StatusOK, get(alice, "/generate", "192.0.2.1:1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, get(alice, "/generate", "192.0.2.2:1").Code, "a principal's budget follows them across addresses")
+	assert.Equal(t, http.StatusOK, get(bob, "/generate", "192.0.2.1:1").Code, "principals sharing an address have their own budgets")
+}
+
+func TestRateLimit_FailsOpen(t *testing.T) {
+	router := setupRouter(failingLimiter{}, limits.Rate{RequestsPerMinute: 1, Burst: 1}, "")
+
+	w := get(router, "/generate", "192.0.2.1:1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+}
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/echo", limits.BodyLimit(16), func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	})
+
+	post := func(body io.Reader, chunked bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/echo", body)
+		if chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(strings.NewReader(`{"name":"api"}`), false)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"api"}`, w.Body.String())
+
+	w = post(strings.NewReader(`{"name":"a much longer name"}`), false)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), "exceeds 16 bytes")
+
+	w = post(strings.NewReader(`{"name":"api"}`), true)
+	assert.Equal(t, http.StatusOK, w.Code, "bodies without a length are replayed to the handler")
+	assert.Equal(t, `{"name":"api"}`, w.Body.String())
+
+	w = post(strings.NewReader(`{"name":"a much longer name"}`), true)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/slow", limits.Timeout(20*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	router.GET("/fast", limits.Timeout(time.Second), func(c *gin.Context) {
+		deadline, ok := c.Request.Context().Deadline()
+		assert.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/unlimited", limits.Timeout(0), func(c *gin.Context) {
+		_, ok := c.Request.Context().Deadline()
+		assert.False(t, ok)
+		c.Status(http.StatusNoContent)
+	})
+
+	w := get(router, "/slow", "192.0.2.1:1")
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"deadline_exceeded"`)
+
+	assert.Equal(t, http.StatusNoContent, get(router, "/fast", "192.0.2.1:1").Code)
+	assert.Equal(t, http.StatusNoContent, get(router, "/unlimited", "192.0.2.1:1").Code)
+}
@@ -0,0 +1,177 @@
+package logging_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/requestid"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// records decodes the JSON lines written to buf
+func records(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(buf)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line), scanner.Text())
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func find(lines []map[string]interface{}, message string) map[string]interface{} {
+	for _, line := range lines {
+		if line["msg"] == message {
+			return line
+		}
+	}
+	return nil
+}
+
+func send(handler http.Handler, method, path, requestID string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if requestID != "" {
+		req.Header.Set(requestid.Header, requestID)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestNew_Formats(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(logging.Config{}, &buf)
+	require.NoError(t, err)
+	logger.Debug("hidden")
+	logger.Info("shown", "key", "value")
+	lines := records(t, &buf)
+	require.Len(t, lines, 1)
+	assert.Equal(t, "value", lines[0]["key"])
+
+	buf.Reset()
+	logger, err = logging.New(logging.Config{Format: "text", Level: "debug"}, &buf)
+	require.NoError(t, err)
+	logger.Debug("shown")
+	assert.Contains(t, buf.String(), "level=DEBUG msg=shown")
+
+	_, err = logging.New(logging.Config{Format: "xml"}, &buf)
+	assert.Error(t, err)
+	_, err = logging.New(logging.Config{Level: "loud"}, &buf)
+	assert.Error(t, err)
+}
+
+func TestFromContext_DefaultsToDefaultLogger(t *testing.T) {
+	assert.Same(t, slog.Default(), logging.FromContext(context.Background()))
+
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, nil))
+	assert.Same(t, logger, logging.FromContext(logging.WithLogger(context.Background(), logger)))
+}
+
+func TestMiddleware_LogsRequestsWithTheirID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	router := gin.New()
+	router.Use(requestid.Middleware(), logging.Middleware(slog.New(slog.NewJSONHandler(&buf, nil))))
+	router.GET("/items/:id", func(c *gin.Context) {
+		logging.FromContext(c.Request.Context()).Info("handling")
+		c.Status(http.StatusNoContent)
+	})
+
+	w := send(router, http.MethodGet, "/items/7", "proxy-assigned-1", nil)
+	assert.Equal(t, "proxy-assigned-1", w.Header().Get(requestid.Header))
+	send(router, http.MethodGet, "/missing", "", nil)
+
+	lines := records(t, &buf)
+	require.Len(t, lines, 3)
+
+	handling := lines[0]
+	assert.Equal(t, "handling", handling["msg"])
+	assert.Equal(t, "proxy-assigned-1", handling["request_id"])
+	assert.Equal(t, "/items/:id", handling["route"])
+
+	served := lines[1]
+	assert.Equal(t, "request served", served["msg"])
+	assert.Equal(t, "proxy-assigned-1", served["request_id"])
+	assert.Equal(t, "/items/7", served["path"])
+	assert.EqualValues(t, http.StatusNoContent, served["status"])
+	assert.Equal(t, "INFO", served["level"])
+
+	notFound := lines[2]
+	assert.Equal(t, "WARN", notFound["level"])
+	assert.NotEmpty(t, notFound["request_id"], "an ID is generated when the client sends none")
+}
+
+// TestApp_CorrelatesServiceLogsWithRequests follows a project from creation to
+// download through the logs, by request ID and project ID
+func TestApp_CorrelatesServiceLogsWithRequests(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = ""
+	cfg.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	w := send(application, http.MethodPost, "/api/v1/projects", "create-1", models.ProjectRequest{
+		Name: "shop", Language: models.LanguageGo, Options: models.ProjectOptions{Framework: "gin"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	projectID := created.Project.ID
+
+	require.Equal(t, http.StatusOK, send(application, http.MethodGet, "/api/v1/projects/"+projectID+"/download", "download-1", nil).Code)
+
+	lines := records(t, &buf)
+	createdLine := find(lines, "project created")
+	require.NotNil(t, createdLine)
+	assert.Equal(t, "create-1", createdLine["request_id"])
+	assert.Equal(t, projectID, createdLine["project_id"])
+	assert.Equal(t, "go", createdLine["language"])
+	assert.Equal(t, "gin", createdLine["options"].(map[string]interface{})["framework"])
+
+	generated := find(lines, "project generated")
+	require.NotNil(t, generated)
+	assert.Equal(t, "download-1", generated["request_id"], "generation on download is logged under the download request")
+	assert.Equal(t, projectID, generated["project_id"])
+}
+
+func TestProjectService_LogsFailuresWithProjectDetails(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&buf, nil)).With("request_id", "req-9"))
+	service := services.NewProjectService(services.NewTemplateService())
+
+	project := &models.Project{ID: "p-1", Language: "cobol", Options: models.ProjectOptions{Database: "db2"}}
+	_, err := service.GenerateProjectFiles(ctx, project)
+	require.Error(t, err)
+
+	failed := find(records(t, &buf), "project generation failed")
+	require.NotNil(t, failed)
+	assert.Equal(t, "ERROR", failed["level"])
+	assert.Equal(t, "req-9", failed["request_id"])
+	assert.Equal(t, "p-1", failed["project_id"])
+	assert.Equal(t, "cobol", failed["language"])
+	assert.Equal(t, "db2", failed["options"].(map[string]interface{})["database"])
+	assert.True(t, strings.Contains(failed["error"].(string), "unsupported language"))
+}
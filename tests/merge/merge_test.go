@@ -0,0 +1,115 @@
+package merge_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/merge"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var labels = merge.Labels{Ours: "local", Base: "revision 1", Theirs: "template"}
+
+func lines(values ...string) string {
+	return strings.Join(values, "\n") + "\n"
+}
+
+func TestMerge_TakesOneSidedChanges(t *testing.T) {
+	base := lines("a", "b", "c", "d", "e")
+	ours := lines("a", "B", "c", "d", "e")
+	theirs := lines("a", "b", "c", "d", "E", "f")
+
+	result := merge.Merge(base, ours, theirs, labels)
+	assert.True(t, result.Clean())
+	assert.Equal(t, lines("a", "B", "c", "d", "E", "f"), result.Content)
+}
+
+func TestMerge_IdenticalChangesDoNotConflict(t *testing.T) {
+	base := lines("a", "b", "c")
+	changed := lines("a", "x", "c")
+
+	result := merge.Merge(base, changed, changed, labels)
+	assert.True(t, result.Clean())
+	assert.Equal(t, changed, result.Content)
+}
+
+func TestMerge_OverlappingChangesConflict(t *testing.T) {
+	base := lines("package main", "", "const port = 8080", "", "func main() {}")
+	ours := lines("package main", "", "const port = 9000", "", "func main() {}")
+	theirs := lines("package main", "", "const port = 8081", "", "func main() {}")
+
+	result := merge.Merge(base, ours, theirs, labels)
+	require.Len(t, result.Conflicts, 1)
+	conflict := lines(
+		"<<<<<<< local",
+		"const port = 9000",
+		"||||||| revision 1",
+		"const port = 8080",
+		"=======",
+		"const port = 8081",
+		">>>>>>> template",
+	)
+	assert.Equal(t, conflict, result.Conflicts[0])
+	assert.Equal(t, lines("package main", "")+conflict+lines("", "func main() {}"), result.Content)
+}
+
+func TestMerge_WithoutBase(t *testing.T) {
+	// Files both sides added since the base are merged against an empty one
+	result := merge.Merge("", lines("same"), lines("same"), labels)
+	assert.True(t, result.Clean())
+
+	result = merge.Merge("", lines("mine"), lines("theirs"), labels)
+	assert.Len(t, result.Conflicts, 1)
+}
+
+func TestMerge_MissingFinalNewline(t *testing.T) {
+	result := merge.Merge("a\nb", "a\nB", "a\nb2", labels)
+	require.Len(t, result.Conflicts, 1)
+	assert.Equal(t, "a\n<<<<<<< local\nB\n||||||| revision 1\nb\n=======\nb2\n>>>>>>> template\n", result.Content)
+
+	result = merge.Merge("a\nb", "a\nb", "a\nb\nc", labels)
+	assert.True(t, result.Clean())
+	assert.Equal(t, "a\nb\nc", result.Content)
+}
+
+func TestMerge_SeparateHunksInLargeFiles(t *testing.T) {
+	var base []string
+	for i := 0; i < 500; i++ {
+		base = append(base, fmt.Sprintf("line %d", i))
+	}
+	ours := append([]string(nil), base...)
+	theirs := append([]string(nil), base...)
+	ours[10] = "ours 10"
+	theirs[400] = "theirs 400"
+	theirs = append(theirs[:200], theirs[210:]...)
+	ours[300], theirs[290] = "ours 300", "theirs 300"
+
+	result := merge.Merge(lines(base...), lines(ours...), lines(theirs...), labels)
+	require.Len(t, result.Conflicts, 1)
+	assert.Contains(t, result.Conflicts[0], "ours 300")
+	assert.Contains(t, result.Conflicts[0], "theirs 300")
+	assert.Contains(t, result.Content, "ours 10\n")
+	assert.Contains(t, result.Content, "theirs 400\n")
+	assert.NotContains(t, result.Content, "line 205\n")
+}
+
+func TestMerge_OneSidedChangesRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	text := func() string {
+		var values []string
+		for i := random.Intn(40); i > 0; i-- {
+			values = append(values, string(rune('a'+random.Intn(5))))
+		}
+		return lines(values...)
+	}
+
+	for i := 0; i < 500; i++ {
+		base, changed := text(), text()
+		assert.Equal(t, changed, merge.Merge(base, changed, base, labels).Content, "ours only: %q -> %q", base, changed)
+		assert.Equal(t, changed, merge.Merge(base, base, changed, labels).Content, "theirs only: %q -> %q", base, changed)
+	}
+}
@@ -0,0 +1,131 @@
+package metrics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/metrics"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(t *testing.T) *app.App {
+	t.Helper()
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = ""
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	require.NotNil(t, application.Metrics)
+	return application
+}
+
+func request(t *testing.T, handler http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reader).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &reader)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func scrape(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	w := request(t, handler, http.MethodGet, "/metrics", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	return w.Body.String()
+}
+
+func TestMetrics_RecordsServiceWork(t *testing.T) {
+	application := newApp(t)
+
+	w := request(t, application, http.MethodPost, "/api/v1/projects", models.ProjectRequest{
+		Name:     "shop",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "gin"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	projectID := created.Project.ID
+
+	require.Equal(t, http.StatusOK, request(t, application, http.MethodPost, "/api/v1/projects/"+projectID+"/generate", nil).Code)
+	require.Equal(t, http.StatusOK, request(t, application, http.MethodGet, "/api/v1/projects/"+projectID+"/download", nil).Code)
+	w = request(t, application, http.MethodPost, "/api/v1/chat/message", models.ChatRequest{
+		Message: "I want a Go API with PostgreSQL",
+	})
+	require.Equal(t, http.StatusOK, w.Code)
+	var chat models.ChatResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &chat))
+	request(t, application, http.MethodGet, "/api/v1/projects/missing", nil)
+
+	body := scrape(t, application)
+	for _, series := range []string{
+		`blueprint_http_request_duration_seconds_count{method="POST",route="/api/v1/projects",status="201"} 1`,
+		`blueprint_http_request_duration_seconds_count{method="GET",route="/api/v1/projects/:id",status="404"} 1`,
+		`blueprint_generations_total{framework="gin",language="go",result="success"} 1`,
+		`blueprint_generation_duration_seconds_count{framework="gin",language="go"} 1`,
+		`blueprint_archive_size_bytes_count{language="go"} 1`,
+		`blueprint_chat_messages_total{result="success"} 1`,
+		`blueprint_chat_suggestions_total{type="language"} 1`,
+		`blueprint_projects 1`,
+		`blueprint_conversations 1`,
+	} {
+		assert.Contains(t, body, series)
+	}
+	assert.NotContains(t, body, projectID, "paths must be labelled by route template")
+
+	require.Equal(t, http.StatusOK, request(t, application, http.MethodDelete, "/api/v1/chat/sessions/"+chat.SessionID, nil).Code)
+	assert.Contains(t, scrape(t, application), "blueprint_conversations 0")
+}
+
+func TestMetrics_BoundsLabelValues(t *testing.T) {
+	m := metrics.New(services.NewTemplateService().GetAvailableTemplates())
+	var _ services.Observer = m
+
+	m.ProjectGenerated(&models.Project{
+		Language: "cobol",
+		Options:  models.ProjectOptions{Framework: "client-chosen-value"},
+	}, time.Millisecond, errors.New("unsupported language"))
+	m.ProjectGenerated(&models.Project{Language: models.LanguagePHP}, time.Millisecond, nil)
+	m.MessageProcessed(&models.ChatResponse{Suggestions: []models.ProjectSuggestion{{Type: "made-up"}}}, time.Millisecond, nil)
+
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/metrics", gin.WrapH(m.Handler()))
+	request(t, router, "PROPFIND", "/nowhere", nil)
+
+	body := scrape(t, router)
+	assert.Contains(t, body, `blueprint_generations_total{framework="other",language="other",result="error"} 1`)
+	assert.Contains(t, body, `blueprint_generations_total{framework="none",language="php",result="success"} 1`)
+	assert.Contains(t, body, `blueprint_chat_suggestions_total{type="other"} 1`)
+	assert.Contains(t, body, `blueprint_http_request_duration_seconds_count{method="other",route="unmatched",status="404"} 1`)
+	assert.False(t, strings.Contains(body, "client-chosen-value"))
+}
+
+func TestMetrics_Disabled(t *testing.T) {
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = ""
+	cfg.Metrics.Enabled = false
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+
+	assert.Nil(t, application.Metrics)
+	assert.Equal(t, http.StatusNotFound, request(t, application, http.MethodGet, "/metrics", nil).Code)
+}
@@ -0,0 +1,226 @@
+package server_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type runningServer struct {
+	url    string
+	cancel context.CancelFunc
+	done   chan error
+}
+
+func start(t *testing.T, cfg server.Config, handler http.Handler, hooks ...server.ShutdownFunc) *runningServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := server.New(cfg, handler)
+	for _, hook := range hooks {
+		srv.OnShutdown(hook)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, listener) }()
+	t.Cleanup(cancel)
+
+	scheme := "http"
+	if cfg.TLS.Enabled() {
+		scheme = "https"
+	}
+	return &runningServer{url: scheme + "://" + listener.Addr().String(), cancel: cancel, done: done}
+}
+
+func (s *runningServer) stop(t *testing.T) error {
+	t.Helper()
+	s.cancel()
+	select {
+	case err := <-s.done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop")
+		return nil
+	}
+}
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	})
+}
+
+func TestServer_ServesAndRunsShutdownHooks(t *testing.T) {
+	var flushed bool
+	s := start(t, server.DefaultConfig(), okHandler(), func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		flushed = true
+		return nil
+	})
+
+	resp, err := http.Get(s.url)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, "ok", string(body))
+
+	assert.NoError(t, s.stop(t))
+	assert.True(t, flushed)
+
+	_, err = http.Get(s.url)
+	assert.Error(t, err, "the listener is closed after shutdown")
+}
+
+func TestServer_DrainsInFlightRequests(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	})
+	s := start(t, server.DefaultConfig(), handler)
+
+	result := make(chan string, 1)
+	go func() {
+		resp, err := http.Get(s.url)
+		if err != nil {
+			result <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		result <- string(body)
+	}()
+
+	<-started
+	s.cancel()
+
+	select {
+	case err := <-s.done:
+		t.Fatalf("server stopped before the request finished: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Equal(t, "finished", <-result)
+	assert.NoError(t, <-s.done)
+}
+
+func TestServer_ShutdownTimeoutAndHookErrors(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	cfg := server.DefaultConfig()
+	cfg.ShutdownTimeout = 50 * time.Millisecond
+	s := start(t, cfg, handler, func(ctx context.Context) error {
+		assert.NoError(t, ctx.Err(), "hooks get a fresh window after a slow drain")
+		return errors.New("flush failed")
+	})
+
+	go http.Get(s.url)
+	<-started
+
+	err := s.stop(t)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "flush failed")
+}
+
+func TestServer_MaxHeaderBytes(t *testing.T) {
+	cfg := server.DefaultConfig()
+	cfg.MaxHeaderBytes = 1024
+	s := start(t, cfg, okHandler())
+
+	req, err := http.NewRequest("GET", s.url, nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 8192))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+}
+
+func TestServer_SelfSignedTLS(t *testing.T) {
+	cfg := server.DefaultConfig()
+	cfg.TLS.SelfSigned = true
+	s := start(t, cfg, okHandler())
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	resp, err := client.Get(s.url)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+	assert.Contains(t, resp.TLS.PeerCertificates[0].DNSNames, "localhost")
+
+	_, err = http.Get(s.url)
+	assert.Error(t, err, "the certificate is not trusted by default clients")
+}
+
+func TestServer_TLSFromFiles(t *testing.T) {
+	certificate, err := server.SelfSignedCertificate()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	keyDER, err := x509.MarshalECPrivateKey(certificate.PrivateKey.(*ecdsa.PrivateKey))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Certificate[0]}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+
+	cfg := server.DefaultConfig()
+	cfg.TLS.CertFile = certFile
+	cfg.TLS.KeyFile = keyFile
+	s := start(t, cfg, okHandler())
+
+	roots := x509.NewCertPool()
+	roots.AddCert(certificate.Leaf)
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+	resp, err := client.Get(s.url)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServer_InvalidTLSFiles(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	cfg := server.DefaultConfig()
+	cfg.TLS.CertFile = filepath.Join(t.TempDir(), "missing.pem")
+	cfg.TLS.KeyFile = cfg.TLS.CertFile
+
+	err = server.New(cfg, okHandler()).Serve(context.Background(), listener)
+	assert.ErrorContains(t, err, "failed to load TLS certificate")
+}
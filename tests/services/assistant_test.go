@@ -0,0 +1,236 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAssistantTools(t *testing.T) (*services.AssistantTools, *services.ProjectService, *models.Project) {
+	templateService := services.NewTemplateService()
+	projectService := services.NewProjectService(templateService)
+
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{
+		Name:     "tools-project",
+		Language: models.LanguageGo,
+	})
+	require.NoError(t, err)
+
+	return services.NewAssistantTools(projectService), projectService, project
+}
+
+func toolCall(t *testing.T, name string, args interface{}) models.ToolCall {
+	raw, err := json.Marshal(args)
+	require.NoError(t, err)
+	return models.ToolCall{Name: name, Arguments: raw}
+}
+
+func TestAssistantTools_Definitions(t *testing.T) {
+	tools, _, _ := setupAssistantTools(t)
+
+	definitions := tools.Definitions()
+
+	names := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+		assert.NotEmpty(t, definition.Description)
+		assert.Equal(t, "object", definition.Parameters["type"])
+	}
+	assert.ElementsMatch(t, []string{
+		models.ToolListFiles, models.ToolReadFile, models.ToolProposeOptionsChange, models.ToolProposeFilePatch,
+	}, names)
+}
+
+func TestAssistantTools_ListAndReadFiles(t *testing.T) {
+	tools, _, project := setupAssistantTools(t)
+
+	result, err := tools.Execute(context.Background(), project.ID, "", models.ToolCall{Name: models.ToolListFiles})
+	require.NoError(t, err)
+	paths := result.Output.([]string)
+	assert.Contains(t, paths, "tools-project/go.mod")
+	assert.NotContains(t, paths, "tools-project/cmd") // Directories are not listed
+
+	result, err = tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolListFiles, map[string]string{"prefix": "tools-project/internal"}))
+	require.NoError(t, err)
+	for _, path := range result.Output.([]string) {
+		assert.True(t, strings.HasPrefix(path, "tools-project/internal"))
+	}
+
+	result, err = tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolReadFile, map[string]string{"path": "tools-project/go.mod"}))
+	require.NoError(t, err)
+	output := result.Output.(map[string]interface{})
+	assert.Contains(t, output["content"], "module tools-project")
+
+	_, err = tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolReadFile, map[string]string{"path": "missing.go"}))
+	assert.Error(t, err)
+
+	_, err = tools.Execute(context.Background(), project.ID, "", models.ToolCall{Name: "delete_everything"})
+	assert.ErrorIs(t, err, services.ErrUnknownTool)
+
+	_, err = tools.Execute(context.Background(), "missing", "", models.ToolCall{Name: models.ToolListFiles})
+	assert.ErrorIs(t, err, services.ErrProjectNotFound)
+}
+
+func TestAssistantTools_OptionsProposalRequiresConfirmation(t *testing.T) {
+	tools, projectService, project := setupAssistantTools(t)
+
+	result, err := tools.Execute(context.Background(), project.ID, "session-1", toolCall(t, models.ToolProposeOptionsChange, map[string]interface{}{
+		"options": map[string]string{"database": "mysql"},
+		"reason":  "Team standard",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result.Proposal)
+	assert.Equal(t, models.ProposalPending, result.Proposal.Status)
+	assert.Equal(t, "session-1", result.Proposal.SessionID)
+	assert.Contains(t, result.Proposal.Summary, "database postgresql → mysql")
+
+	// Nothing changes before confirmation
+	unchanged, err := projectService.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "postgresql", unchanged.Options.Database)
+
+	confirmed, updated, err := tools.ConfirmProposal(context.Background(), project.ID, result.Proposal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ProposalApplied, confirmed.Status)
+	assert.NotNil(t, confirmed.ResolvedAt)
+	assert.Equal(t, "mysql", updated.Options.Database)
+	assert.Equal(t, "gin", updated.Options.Framework)
+
+	_, _, err = tools.ConfirmProposal(context.Background(), project.ID, result.Proposal.ID)
+	assert.ErrorIs(t, err, services.ErrProposalResolved)
+}
+
+func TestAssistantTools_FilePatchProposal(t *testing.T) {
+	tools, projectService, project := setupAssistantTools(t)
+
+	result, err := tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolProposeFilePatch, map[string]string{
+		"path":    "tools-project/internal/routes/router.go",
+		"content": "// Router with /metrics",
+	}))
+	require.NoError(t, err)
+	require.NotNil(t, result.Proposal.Patch)
+	assert.NotEmpty(t, result.Proposal.Patch.BaseChecksum)
+
+	_, updated, err := tools.ConfirmProposal(context.Background(), project.ID, result.Proposal.ID)
+	require.NoError(t, err)
+
+	var content string
+	for _, file := range updated.Files {
+		if file.Path == "tools-project/internal/routes/router.go" {
+			content = file.Content
+		}
+	}
+	assert.Equal(t, "// Router with /metrics", content)
+
+	// A patch based on content that has since changed is a conflict
+	stale, err := tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolProposeFilePatch, map[string]string{
+		"path":    "tools-project/go.mod",
+		"content": "module stale",
+	}))
+	require.NoError(t, err)
+	_, err = projectService.UpdateProjectFile(context.Background(), project.ID, "tools-project/go.mod", "module edited")
+	require.NoError(t, err)
+
+	_, _, err = tools.ConfirmProposal(context.Background(), project.ID, stale.Proposal.ID)
+	assert.ErrorIs(t, err, services.ErrProposalConflict)
+
+	_, err = tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolProposeFilePatch, map[string]string{
+		"path":    "../outside.go",
+		"content": "package outside",
+	}))
+	assert.Error(t, err)
+}
+
+func TestAssistantTools_RejectProposal(t *testing.T) {
+	tools, _, project := setupAssistantTools(t)
+
+	result, err := tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolProposeFilePatch, map[string]string{
+		"path":    "tools-project/NEW.md",
+		"content": "# New",
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, result.Proposal.Patch.BaseChecksum)
+
+	rejected, err := tools.RejectProposal(context.Background(), project.ID, result.Proposal.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ProposalRejected, rejected.Status)
+
+	proposals := tools.ListProposals(context.Background(), project.ID)
+	require.Len(t, proposals, 1)
+	assert.Equal(t, models.ProposalRejected, proposals[0].Status)
+
+	_, err = tools.RejectProposal(context.Background(), project.ID, "missing")
+	assert.ErrorIs(t, err, services.ErrProposalNotFound)
+	_, err = tools.RejectProposal(context.Background(), "other-project", result.Proposal.ID)
+	assert.ErrorIs(t, err, services.ErrProposalNotFound)
+}
+
+func TestChatService_AssistantTools_InChat(t *testing.T) {
+	tools, projectService, project := setupAssistantTools(t)
+	chatService := services.NewChatService()
+	chatService.SetAssistantTools(tools)
+
+	response, err := chatService.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Please list files", ProjectID: project.ID})
+	require.NoError(t, err)
+	require.Len(t, response.ToolResults, 1)
+	assert.Equal(t, models.ToolListFiles, response.ToolResults[0].Name)
+	assert.Contains(t, response.Message.Content, "tools-project/go.mod")
+
+	response, err = chatService.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Show me the router", ProjectID: project.ID})
+	require.NoError(t, err)
+	require.Len(t, response.ToolResults, 1)
+	assert.Equal(t, models.ToolReadFile, response.ToolResults[0].Name)
+	assert.Contains(t, response.Message.Content, "internal/routes/router.go")
+
+	response, err = chatService.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Switch the database to MySQL", ProjectID: project.ID})
+	require.NoError(t, err)
+	require.Len(t, response.ToolResults, 1)
+	proposal := response.ToolResults[0].Proposal
+	require.NotNil(t, proposal)
+	assert.Equal(t, response.SessionID, proposal.SessionID)
+	assert.Contains(t, response.Message.Content, proposal.ID)
+
+	// The project only changes once the proposal is confirmed
+	current, err := projectService.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "postgresql", current.Options.Database)
+
+	// Sessions without a project do not use tools
+	response, err = chatService.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Please list files"})
+	require.NoError(t, err)
+	assert.Empty(t, response.ToolResults)
+}
+
+func TestAssistantTools_ProposalRetention(t *testing.T) {
+	projectService := services.NewProjectService(services.NewTemplateService())
+	project, err := projectService.CreateProject(context.Background(), &models.ProjectRequest{Name: "retained", Language: models.LanguageGo})
+	require.NoError(t, err)
+	tools := services.NewAssistantToolsWithRetention(projectService, services.ProposalRetention{TTL: time.Hour, MaxPerProject: 2})
+
+	var ids []string
+	for _, path := range []string{"retained/A.md", "retained/B.md", "retained/C.md"} {
+		result, err := tools.Execute(context.Background(), project.ID, "", toolCall(t, models.ToolProposeFilePatch, map[string]string{"path": path, "content": "x"}))
+		require.NoError(t, err)
+		ids = append(ids, result.Proposal.ID)
+	}
+
+	// Only the newest proposals are kept
+	proposals := tools.ListProposals(context.Background(), project.ID)
+	require.Len(t, proposals, 2)
+	assert.Equal(t, ids[1:], []string{proposals[0].ID, proposals[1].ID})
+	_, err = tools.RejectProposal(context.Background(), project.ID, ids[0])
+	assert.True(t, errors.Is(err, services.ErrProposalNotFound))
+
+	assert.Equal(t, 0, tools.ExpireProposals(time.Now()))
+	assert.Equal(t, 2, tools.ExpireProposals(time.Now().Add(2*time.Hour)))
+	assert.Empty(t, tools.ListProposals(context.Background(), project.ID))
+}
@@ -0,0 +1,160 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/logging"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingSummarizer struct{}
+
+func (failingSummarizer) Summarize(messages []models.ChatMessage) (string, error) {
+	return "", errors.New("summarizer unavailable")
+}
+
+func TestChatService_Retention_TrimsWithSummary(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{MaxMessages: 5})
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		_, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: fmt.Sprintf("Message %d", i), SessionID: session.ID})
+		require.NoError(t, err)
+	}
+
+	history, err := service.GetSessionHistory(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	require.Len(t, history.Messages, 5)
+	assert.Equal(t, 4, history.Trimmed)
+
+	summary := history.Messages[0]
+	assert.Equal(t, "system", summary.Role)
+	assert.Contains(t, summary.Content, "Message 0")
+	assert.Contains(t, summary.Content, "Message 1")
+	assert.NotContains(t, summary.Content, "Message 2")
+
+	assert.Equal(t, "Message 2", history.Messages[1].Content)
+	assert.Equal(t, "assistant", history.Messages[4].Role)
+}
+
+func TestChatService_Retention_SummaryCarriesOver(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{MaxMessages: 3})
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: fmt.Sprintf("Topic %d", i), SessionID: session.ID})
+		require.NoError(t, err)
+	}
+
+	history, err := service.GetSessionHistory(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	require.Len(t, history.Messages, 3)
+	assert.Equal(t, 8, history.Trimmed)
+	for i := 0; i < 4; i++ {
+		assert.Contains(t, history.Messages[0].Content, fmt.Sprintf("Topic %d", i))
+	}
+	assert.Equal(t, 1, strings.Count(history.Messages[0].Content, "Summary of earlier conversation"))
+}
+
+func TestChatService_Retention_SummarizerFailure(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{MaxMessages: 3})
+	service.SetSummarizer(failingSummarizer{})
+	var logs bytes.Buffer
+	ctx := logging.WithLogger(context.Background(), slog.New(slog.NewJSONHandler(&logs, nil)).With("request_id", "req-1"))
+
+	session, err := service.CreateSession(ctx, &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := service.ProcessMessage(ctx, &models.ChatRequest{Message: fmt.Sprintf("Message %d", i), SessionID: session.ID})
+		require.NoError(t, err)
+	}
+
+	history, err := service.GetSessionHistory(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Len(t, history.Messages, 3)
+	assert.Equal(t, "system", history.Messages[0].Role)
+
+	// The failure is logged with the request's logger
+	var entry map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "failed to summarise chat session, keeping the previous summary" {
+			break
+		}
+	}
+	assert.Equal(t, "failed to summarise chat session, keeping the previous summary", entry["msg"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, session.ID, entry["session_id"])
+	assert.Equal(t, "summarizer unavailable", entry["error"])
+}
+
+func TestChatService_Retention_Disabled(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{})
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Hello", SessionID: session.ID})
+		require.NoError(t, err)
+	}
+
+	history, err := service.GetSessionHistory(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.Len(t, history.Messages, 20)
+	assert.Zero(t, history.Trimmed)
+	assert.Zero(t, service.ExpireIdleSessions(context.Background(), time.Now().Add(24*time.Hour)))
+}
+
+func TestChatService_ExpireIdleSessions(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{IdleTimeout: time.Hour})
+
+	linked, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{ProjectID: "project-1"})
+	require.NoError(t, err)
+	_, err = service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+
+	assert.Zero(t, service.ExpireIdleSessions(context.Background(), time.Now()))
+	assert.Equal(t, 2, service.ExpireIdleSessions(context.Background(), time.Now().Add(2*time.Hour)))
+	assert.Empty(t, service.ListSessions(context.Background()))
+
+	_, err = service.GetSession(context.Background(), linked.ID)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+
+	history, err := service.GetChatHistory(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Empty(t, history.SessionID)
+}
+
+func TestChatService_StartJanitor(t *testing.T) {
+	service := services.NewChatServiceWithRetention(services.ChatRetention{
+		IdleTimeout:   time.Millisecond,
+		SweepInterval: 5 * time.Millisecond,
+	})
+
+	_, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	service.StartJanitor(ctx)
+
+	assert.Eventually(t, func() bool {
+		return len(service.ListSessions(context.Background())) == 0
+	}, time.Second, 5*time.Millisecond)
+}
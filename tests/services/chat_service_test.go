@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -26,7 +27,7 @@ func TestChatService_ProcessMessage_GoLanguage(t *testing.T) {
 		Context:   "project setup",
 	}
 
-	response, err := service.ProcessMessage(req)
+	response, err := service.ProcessMessage(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -59,7 +60,7 @@ func TestChatService_ProcessMessage_PHPLanguage(t *testing.T) {
 		Context:   "project setup",
 	}
 
-	response, err := service.ProcessMessage(req)
+	response, err := service.ProcessMessage(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -91,7 +92,7 @@ func TestChatService_ProcessMessage_DatabaseDetection(t *testing.T) {
 		Context:   "database setup",
 	}
 
-	response, err := service.ProcessMessage(req)
+	response, err := service.ProcessMessage(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -120,7 +121,7 @@ func TestChatService_ProcessMessage_DefaultResponse(t *testing.T) {
 		Context:   "greeting",
 	}
 
-	response, err := service.ProcessMessage(req)
+	response, err := service.ProcessMessage(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, response)
@@ -140,11 +141,11 @@ func TestChatService_GetChatHistory_ExistingProject(t *testing.T) {
 		ProjectID: "test-project",
 	}
 
-	_, err := service.ProcessMessage(req)
+	_, err := service.ProcessMessage(context.Background(), req)
 	require.NoError(t, err)
 
 	// Get chat history
-	history, err := service.GetChatHistory("test-project")
+	history, err := service.GetChatHistory(context.Background(), "test-project")
 
 	require.NoError(t, err)
 	assert.NotNil(t, history)
@@ -162,7 +163,7 @@ func TestChatService_GetChatHistory_NewProject(t *testing.T) {
 	service := services.NewChatService()
 
 	// Get chat history for non-existent project
-	history, err := service.GetChatHistory("new-project")
+	history, err := service.GetChatHistory(context.Background(), "new-project")
 
 	require.NoError(t, err)
 	assert.NotNil(t, history)
@@ -175,11 +176,12 @@ func TestChatService_GetChatHistory_EmptyProjectID(t *testing.T) {
 	service := services.NewChatService()
 
 	// Get chat history with empty project ID
-	history, err := service.GetChatHistory("")
+	history, err := service.GetChatHistory(context.Background(), "")
 
 	require.NoError(t, err)
 	assert.NotNil(t, history)
-	assert.Equal(t, "general", history.ProjectID)
+	assert.Empty(t, history.ProjectID)
+	assert.Empty(t, history.SessionID)
 	assert.Empty(t, history.Messages)
 }
 
@@ -194,7 +196,7 @@ func TestChatService_ConcurrentAccess(t *testing.T) {
 				Message:   fmt.Sprintf("Concurrent message %d", i),
 				ProjectID: "concurrent-project",
 			}
-			_, err := service.ProcessMessage(req)
+			_, err := service.ProcessMessage(context.Background(), req)
 			assert.NoError(t, err)
 			done <- true
 		}(i)
@@ -206,7 +208,7 @@ func TestChatService_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all messages were processed
-	history, err := service.GetChatHistory("concurrent-project")
+	history, err := service.GetChatHistory(context.Background(), "concurrent-project")
 	require.NoError(t, err)
 	assert.Len(t, history.Messages, 10) // 5 user messages + 5 assistant responses
 }
@@ -224,12 +226,12 @@ func TestChatService_MessageOrdering(t *testing.T) {
 		ProjectID: "order-test",
 	}
 
-	_, err := service.ProcessMessage(req1)
+	_, err := service.ProcessMessage(context.Background(), req1)
 	require.NoError(t, err)
-	_, err = service.ProcessMessage(req2)
+	_, err = service.ProcessMessage(context.Background(), req2)
 	require.NoError(t, err)
 
-	history, err := service.GetChatHistory("order-test")
+	history, err := service.GetChatHistory(context.Background(), "order-test")
 	require.NoError(t, err)
 
 	// Verify message ordering
@@ -241,3 +243,102 @@ func TestChatService_MessageOrdering(t *testing.T) {
 	assert.Equal(t, "First message", history.Messages[0].Content)
 	assert.Equal(t, "Second message", history.Messages[2].Content)
 }
+
+func TestChatService_DraftProjectRequest_FromSuggestions(t *testing.T) {
+	service := services.NewChatService()
+
+	first, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go web api"})
+	require.NoError(t, err)
+	_, err = service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "It should use MySQL", SessionID: first.SessionID})
+	require.NoError(t, err)
+
+	draft, err := service.DraftProjectRequest(context.Background(), &models.ChatProjectRequest{SessionID: first.SessionID, Name: "chat-project"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "chat-project", draft.Name)
+	assert.Equal(t, models.LanguageGo, draft.Language)
+	assert.Equal(t, "gin", draft.Options.Framework)
+	assert.Equal(t, "mysql", draft.Options.Database)
+	assert.Empty(t, draft.Options.Authentication) // Not auto-applied
+}
+
+func TestChatService_DraftProjectRequest_Overrides(t *testing.T) {
+	service := services.NewChatService()
+
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go web api"})
+	require.NoError(t, err)
+
+	draft, err := service.DraftProjectRequest(context.Background(), &models.ChatProjectRequest{
+		SessionID: response.SessionID,
+		Name:      "chat-project",
+		Options:   models.ProjectOptions{Framework: "echo"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.LanguageGo, draft.Language)
+	assert.Equal(t, "echo", draft.Options.Framework)
+}
+
+func TestChatService_DraftProjectRequest_NoLanguage(t *testing.T) {
+	service := services.NewChatService()
+
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Hello there"})
+	require.NoError(t, err)
+
+	draft, err := service.DraftProjectRequest(context.Background(), &models.ChatProjectRequest{SessionID: response.SessionID, Name: "chat-project"})
+
+	assert.ErrorIs(t, err, services.ErrIncompleteDraft)
+	assert.Nil(t, draft)
+}
+
+func TestChatService_AttachProject_LinksHistory(t *testing.T) {
+	service := services.NewChatService()
+
+	first, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I need a PHP CodeIgniter project"})
+	require.NoError(t, err)
+
+	project := &models.Project{ID: "new-project-id", Name: "chat-project", Language: models.LanguagePHP}
+	response, err := service.AttachProject(context.Background(), first.SessionID, project)
+
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, first.SessionID, response.SessionID)
+	assert.Equal(t, project, response.Project)
+	assert.Equal(t, "assistant", response.Message.Role)
+	assert.Contains(t, response.Message.Content, "chat-project")
+
+	history, err := service.GetChatHistory(context.Background(), "new-project-id")
+	require.NoError(t, err)
+	assert.Equal(t, first.SessionID, history.SessionID)
+	assert.Len(t, history.Messages, 3) // User message, assistant response, creation notice
+	assert.Nil(t, history.Draft)
+	for _, message := range history.Messages {
+		assert.Equal(t, "new-project-id", message.ProjectID)
+	}
+}
+
+func TestChatService_ProcessMessage_SeparateAnonymousSessions(t *testing.T) {
+	service := services.NewChatService()
+
+	first, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "First visitor"})
+	require.NoError(t, err)
+	second, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Second visitor"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, first.SessionID)
+	assert.NotEqual(t, first.SessionID, second.SessionID)
+
+	history, err := service.GetSessionHistory(context.Background(), first.SessionID)
+	require.NoError(t, err)
+	assert.Len(t, history.Messages, 2)
+	assert.Equal(t, "First visitor", history.Title)
+}
+
+func TestChatService_ProcessMessage_UnknownSession(t *testing.T) {
+	service := services.NewChatService()
+
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Hello", SessionID: "missing"})
+
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+	assert.Nil(t, response)
+}
@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatService_CreateSession(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{Title: "Payments API"})
+
+	require.NoError(t, err)
+	assert.NotEmpty(t, session.ID)
+	assert.Equal(t, "Payments API", session.Title)
+	assert.Empty(t, session.ProjectID)
+	assert.Zero(t, session.MessageCount)
+	assert.False(t, session.CreatedAt.IsZero())
+}
+
+func TestChatService_CreateSession_DefaultTitle(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "New conversation", session.Title)
+
+	_, err = service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Build me an inventory service", SessionID: session.ID})
+	require.NoError(t, err)
+
+	session, err = service.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Build me an inventory service", session.Title)
+	assert.Equal(t, 2, session.MessageCount)
+}
+
+func TestChatService_ListSessions(t *testing.T) {
+	service := services.NewChatService()
+
+	for i := 0; i < 3; i++ {
+		_, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{Title: fmt.Sprintf("Session %d", i)})
+		require.NoError(t, err)
+	}
+
+	sessions := service.ListSessions(context.Background())
+
+	assert.Len(t, sessions, 3)
+	for i := 1; i < len(sessions); i++ {
+		assert.False(t, sessions[i].UpdatedAt.After(sessions[i-1].UpdatedAt))
+	}
+}
+
+func TestChatService_RenameSession(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+
+	renamed, err := service.RenameSession(context.Background(), session.ID, "  Renamed  ")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", renamed.Title)
+
+	_, err = service.RenameSession(context.Background(), session.ID, " ")
+	assert.Error(t, err)
+
+	_, err = service.RenameSession(context.Background(), "missing", "Title")
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+}
+
+func TestChatService_LinkSession(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	_, err = service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Hello", SessionID: session.ID})
+	require.NoError(t, err)
+
+	linked, err := service.LinkSession(context.Background(), session.ID, "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, "project-1", linked.ProjectID)
+
+	history, err := service.GetChatHistory(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, history.SessionID)
+	for _, message := range history.Messages {
+		assert.Equal(t, "project-1", message.ProjectID)
+	}
+
+	// Messages sent with only the project ID continue the linked session
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Again", ProjectID: "project-1"})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, response.SessionID)
+
+	// Unlinking detaches the project
+	_, err = service.LinkSession(context.Background(), session.ID, "")
+	require.NoError(t, err)
+	history, err = service.GetChatHistory(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Empty(t, history.Messages)
+}
+
+func TestChatService_DeleteSession(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{ProjectID: "project-1"})
+	require.NoError(t, err)
+
+	err = service.DeleteSession(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	_, err = service.GetSession(context.Background(), session.ID)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+
+	history, err := service.GetChatHistory(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Empty(t, history.SessionID)
+
+	err = service.DeleteSession(context.Background(), session.ID)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+}
+
+func TestChatService_GetSessionMessages_Pagination(t *testing.T) {
+	service := services.NewChatService()
+
+	session, err := service.CreateSession(context.Background(), &models.ChatSessionRequest{})
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: fmt.Sprintf("Message %d", i), SessionID: session.ID})
+		require.NoError(t, err)
+	}
+
+	page, err := service.GetSessionMessages(context.Background(), session.ID, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 10, page.Total)
+	assert.Equal(t, 2, page.Offset)
+	assert.Equal(t, 3, page.Limit)
+	require.Len(t, page.Messages, 3)
+	assert.Equal(t, "Message 1", page.Messages[0].Content)
+
+	page, err = service.GetSessionMessages(context.Background(), session.ID, 8, 0)
+	require.NoError(t, err)
+	assert.Len(t, page.Messages, 2)
+	assert.Equal(t, services.DefaultMessagePageSize, page.Limit)
+
+	page, err = service.GetSessionMessages(context.Background(), session.ID, 20, 10)
+	require.NoError(t, err)
+	assert.Empty(t, page.Messages)
+
+	_, err = service.GetSessionMessages(context.Background(), session.ID, -1, 10)
+	assert.Error(t, err)
+
+	_, err = service.GetSessionMessages(context.Background(), "missing", 0, 10)
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+}
@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChatService_ExportTranscript(t *testing.T) {
+	service := services.NewChatService()
+
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go web api"})
+	require.NoError(t, err)
+
+	transcript, err := service.ExportTranscript(context.Background(), response.SessionID)
+
+	require.NoError(t, err)
+	assert.Equal(t, services.TranscriptVersion, transcript.Version)
+	assert.False(t, transcript.ExportedAt.IsZero())
+	assert.Equal(t, response.SessionID, transcript.History.SessionID)
+	require.Len(t, transcript.History.Messages, 2)
+	assert.NotEmpty(t, transcript.History.Messages[1].Suggestions)
+	require.NotNil(t, transcript.History.Draft)
+	assert.Equal(t, models.LanguageGo, transcript.History.Draft.Language)
+
+	// Later messages do not change an exported transcript
+	_, err = service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "More", SessionID: response.SessionID})
+	require.NoError(t, err)
+	assert.Len(t, transcript.History.Messages, 2)
+
+	_, err = service.ExportTranscript(context.Background(), "missing")
+	assert.ErrorIs(t, err, services.ErrSessionNotFound)
+}
+
+func TestChatService_ImportTranscript_RoundTrip(t *testing.T) {
+	source := services.NewChatService()
+	response, err := source.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go web api"})
+	require.NoError(t, err)
+	transcript, err := source.ExportTranscript(context.Background(), response.SessionID)
+	require.NoError(t, err)
+
+	target := services.NewChatService()
+	session, err := target.ImportTranscript(context.Background(), transcript)
+
+	require.NoError(t, err)
+	assert.Equal(t, response.SessionID, session.ID)
+	assert.Equal(t, 2, session.MessageCount)
+
+	// The draft survives, so the conversation can continue into a project
+	draft, err := target.DraftProjectRequest(context.Background(), &models.ChatProjectRequest{SessionID: session.ID, Name: "imported"})
+	require.NoError(t, err)
+	assert.Equal(t, models.LanguageGo, draft.Language)
+
+	next, err := target.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Continue", SessionID: session.ID})
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, next.SessionID)
+
+	// Importing again while the ID is in use assigns a new session ID
+	again, err := target.ImportTranscript(context.Background(), transcript)
+	require.NoError(t, err)
+	assert.NotEqual(t, session.ID, again.ID)
+}
+
+func TestChatService_ImportTranscript_Invalid(t *testing.T) {
+	service := services.NewChatService()
+
+	_, err := service.ImportTranscript(context.Background(), &models.ChatTranscript{Version: 99})
+	assert.Error(t, err)
+
+	_, err = service.ImportTranscript(context.Background(), &models.ChatTranscript{
+		Version: services.TranscriptVersion,
+		History: models.ChatHistory{Messages: []models.ChatMessage{{Role: "robot", Content: "Hi"}}},
+	})
+	assert.Error(t, err)
+
+	_, err = service.ImportTranscript(context.Background(), &models.ChatTranscript{
+		Version: services.TranscriptVersion,
+		History: models.ChatHistory{Messages: []models.ChatMessage{{Role: "user", Content: " "}}},
+	})
+	assert.Error(t, err)
+}
+
+func TestRenderTranscriptMarkdown(t *testing.T) {
+	service := services.NewChatService()
+	response, err := service.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go web api"})
+	require.NoError(t, err)
+	transcript, err := service.ExportTranscript(context.Background(), response.SessionID)
+	require.NoError(t, err)
+
+	markdown := services.RenderTranscriptMarkdown(transcript)
+
+	assert.Contains(t, markdown, "# I want a Go web api")
+	assert.Contains(t, markdown, response.SessionID)
+	assert.Contains(t, markdown, "### User · ")
+	assert.Contains(t, markdown, "### Assistant · ")
+	assert.Contains(t, markdown, "**Suggestions:**")
+	assert.Contains(t, markdown, "`language` = `go`")
+	assert.Contains(t, markdown, "## Draft configuration")
+}
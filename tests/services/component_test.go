@@ -0,0 +1,278 @@
+package services_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"boilerplate-blueprint/internal/apperror"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGoProject(t *testing.T, service *services.ProjectService, framework string) *models.Project {
+	t.Helper()
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{
+		Name:     "shop",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: framework, Utilities: []string{"logger"}},
+	})
+	require.NoError(t, err)
+	_, err = service.GenerateProjectFiles(context.Background(), project)
+	require.NoError(t, err)
+	return project
+}
+
+func projectFile(project *models.Project, path string) (models.ProjectFile, bool) {
+	for _, file := range project.Files {
+		if file.Path == path {
+			return file, true
+		}
+	}
+	return models.ProjectFile{}, false
+}
+
+func TestProjectService_AddComponent_Entity(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+	revision := project.Revision
+
+	plan, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: "OrderItem"})
+	require.NoError(t, err)
+	assert.True(t, plan.Result.Applied)
+	assert.Equal(t, "entity:order_item", plan.Result.Component)
+	assert.Equal(t, revision+1, plan.Revision)
+	assert.Empty(t, plan.Result.Conflicts)
+
+	actions := make(map[string]string)
+	for _, file := range plan.Result.Files {
+		actions[file.Path] = file.Action
+	}
+	assert.Equal(t, models.ComponentFileCreated, actions[filepath.Join("shop", "internal", "entity", "order_item.go")])
+	assert.Equal(t, models.ComponentFileCreated, actions[filepath.Join("shop", "internal", "controller", "order_item_controller.go")])
+	assert.Equal(t, models.ComponentFileMerged, actions[filepath.Join("shop", "internal", "routes", "router.go")])
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	router, ok := projectFile(stored, filepath.Join("shop", "internal", "routes", "router.go"))
+	require.True(t, ok)
+	assert.Contains(t, router.Content, `"shop/internal/controller"`)
+	assert.Contains(t, router.Content, `router.Any("/api/order-items", gin.WrapF(controller.OrderItemsHandler))`)
+	assert.Contains(t, router.Content, `router.Any("/api/order-items/:id", gin.WrapF(controller.OrderItemHandler))`)
+	assert.Contains(t, router.Content, "// blueprint:routes", "anchors stay for the next component")
+}
+
+func TestProjectService_AddComponent_Concurrent(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+	revision := project.Revision
+
+	names := []string{"order", "invoice", "customer", "shipment", "refund", "coupon", "voucher", "payment", "review", "basket", "supplier", "product"}
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			_, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: name})
+			assert.NoError(t, err)
+		}(name)
+	}
+	wg.Wait()
+
+	// Every add merged into the router the previous one left
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revision+len(names), stored.Revision)
+	router, ok := projectFile(stored, filepath.Join("shop", "internal", "routes", "router.go"))
+	require.True(t, ok)
+	for _, name := range names {
+		assert.Contains(t, router.Content, "/api/"+name+"s\"")
+	}
+}
+
+func TestProjectService_AddComponent_IsIdempotent(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "chi")
+
+	req := &models.ComponentRequest{Type: "oauth", Name: "GitHub"}
+	_, err := service.AddComponent(context.Background(), project.ID, req)
+	require.NoError(t, err)
+	revision := project.Revision
+
+	plan, err := service.AddComponent(context.Background(), project.ID, req)
+	require.NoError(t, err)
+	assert.False(t, plan.Result.Applied)
+	assert.Equal(t, revision, plan.Revision)
+	assert.Empty(t, plan.Files)
+	for _, file := range plan.Result.Files {
+		assert.Equal(t, models.ComponentFileUnchanged, file.Action, file.Path)
+	}
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	env, ok := projectFile(stored, filepath.Join("shop", ".env.example"))
+	require.True(t, ok)
+	assert.Equal(t, 1, strings.Count(env.Content, "GITHUB_CLIENT_ID="))
+}
+
+func TestProjectService_AddComponent_Utility(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+
+	plan, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "utility", Name: "cache"})
+	require.NoError(t, err)
+	assert.True(t, plan.Result.Applied)
+	require.Len(t, plan.Files, 1)
+	assert.Equal(t, filepath.Join("shop", "internal", "util", "cache", "cache.go"), plan.Files[0].Path)
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"logger", "cache"}, stored.Options.Utilities)
+
+	// Utilities the project was generated with are already there
+	plan, err = service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "utility", Name: "logger"})
+	require.NoError(t, err)
+	assert.False(t, plan.Result.Applied)
+}
+
+func TestProjectService_AddComponent_ConflictsWithEdits(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+
+	path := filepath.Join("shop", "internal", "entity", "order.go")
+	_, err := service.UpdateProjectFile(context.Background(), project.ID, path, "package entity\n\n// Order is mine\ntype Order struct{}\n")
+	require.NoError(t, err)
+	revision := project.Revision
+
+	plan, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: "order"})
+	require.Error(t, err)
+	appErr := apperror.From(err)
+	assert.Equal(t, apperror.KindConflict, appErr.Kind)
+	assert.Equal(t, "component_conflict", appErr.Code)
+	require.Len(t, appErr.Details, 1)
+	assert.Equal(t, path, appErr.Details[0].Field)
+
+	require.NotNil(t, plan)
+	assert.False(t, plan.Result.Applied)
+	assert.Len(t, plan.Result.Conflicts, 1)
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revision, stored.Revision)
+	_, created := projectFile(stored, filepath.Join("shop", "internal", "controller", "order_controller.go"))
+	assert.False(t, created, "nothing is written when a file conflicts")
+}
+
+func TestProjectService_AddComponent_MissingAnchor(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+
+	path := filepath.Join("shop", "internal", "routes", "router.go")
+	_, err := service.UpdateProjectFile(context.Background(), project.ID, path, "package routes\n\nfunc Register() {}\n")
+	require.NoError(t, err)
+
+	plan, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: "order"})
+	require.Error(t, err)
+	require.Len(t, plan.Result.Conflicts, 1)
+	assert.Equal(t, path, plan.Result.Conflicts[0].Path)
+	assert.Contains(t, plan.Result.Conflicts[0].Reason, "not found")
+}
+
+func TestProjectService_AddComponent_DryRun(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+	revision := project.Revision
+	files := len(project.Files)
+
+	plan, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: "order", DryRun: true})
+	require.NoError(t, err)
+	assert.False(t, plan.Result.Applied)
+	assert.NotEmpty(t, plan.Files)
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, revision, stored.Revision)
+	assert.Len(t, stored.Files, files)
+}
+
+func TestProjectService_AddComponent_Invalid(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+
+	for _, req := range []models.ComponentRequest{
+		{Type: "widget", Name: "x"},
+		{Type: "utility", Name: "teleport"},
+		{Type: "oauth", Name: "myspace"},
+		{Type: "entity", Name: "../order"},
+	} {
+		_, err := service.AddComponent(context.Background(), project.ID, &req)
+		assert.True(t, apperror.Is(err, apperror.KindValidation), "%+v: %v", req, err)
+	}
+
+	php, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "site", Language: models.LanguagePHP})
+	require.NoError(t, err)
+	_, err = service.AddComponent(context.Background(), php.ID, &models.ComponentRequest{Type: "entity", Name: "order"})
+	assert.ErrorIs(t, err, services.ErrComponentLanguage)
+}
+
+func TestTemplateService_RenderComponent_ParsesForEveryFramework(t *testing.T) {
+	for _, framework := range []string{"gin", "chi", "echo", "standard"} {
+		t.Run(framework, func(t *testing.T) {
+			service := services.NewProjectService(services.NewTemplateService())
+			project := newGoProject(t, service, framework)
+
+			for _, req := range []models.ComponentRequest{
+				{Type: "entity", Name: "order"},
+				{Type: "oauth", Name: "google"},
+				{Type: "utility", Name: "cache"},
+			} {
+				_, err := service.AddComponent(context.Background(), project.ID, &req)
+				require.NoError(t, err, "%+v", req)
+			}
+
+			stored, err := service.GetProject(context.Background(), project.ID)
+			require.NoError(t, err)
+			for _, file := range stored.Files {
+				if strings.HasSuffix(file.Path, ".go") && (strings.Contains(file.Path, "routes") || strings.Contains(file.Path, "controller") || strings.Contains(file.Path, "oauth") || strings.Contains(file.Path, filepath.Join("util", "cache"))) {
+					_, err := parser.ParseFile(token.NewFileSet(), file.Path, file.Content, parser.AllErrors)
+					assert.NoError(t, err, file.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectService_GenerateProject_RecordedComponents(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{
+		Name:     "shop",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "echo", Components: []string{"entity:order", "oauth:gitlab"}},
+	})
+	require.NoError(t, err)
+	_, err = service.GenerateProjectFiles(context.Background(), project)
+	require.NoError(t, err)
+
+	_, ok := projectFile(project, filepath.Join("shop", "internal", "controller", "order_controller.go"))
+	assert.True(t, ok)
+	router, ok := projectFile(project, filepath.Join("shop", "internal", "routes", "router.go"))
+	require.True(t, ok)
+	assert.Contains(t, router.Content, "controller.GitLabLogin")
+
+	templates := services.NewTemplateService()
+	assert.NoError(t, templates.ValidateOptions(models.LanguageGo, models.ProjectOptions{Components: []string{"entity:order", "oauth:gitlab"}}))
+	for language, components := range map[models.ProjectLanguage][]string{
+		models.LanguageGo:  {"widget:x"},
+		models.LanguagePHP: {"entity:order"},
+	} {
+		err := templates.ValidateOptions(language, models.ProjectOptions{Components: components})
+		assert.True(t, apperror.Is(err, apperror.KindValidation), "%s %v: %v", language, components, err)
+	}
+}
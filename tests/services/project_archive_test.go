@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/artifacts"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// presigningStore wraps a local store and hands out fake download URLs
+type presigningStore struct {
+	*artifacts.LocalStore
+}
+
+func (s presigningStore) PresignGet(ctx context.Context, key, filename string, expires time.Duration) (string, error) {
+	return "https://artifacts.example.com/" + key + "?filename=" + filename, nil
+}
+
+func newArchiveTestService(t *testing.T) (*services.ProjectService, *artifacts.LocalStore) {
+	store, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	service := services.NewProjectService(services.NewTemplateService())
+	service.SetArtifactStore(store)
+	return service, store
+}
+
+func TestProjectService_CreateProjectZIP_StoresArchiveByRevision(t *testing.T) {
+	service, store := newArchiveTestService(t)
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "stored", Language: models.LanguageGo})
+	require.NoError(t, err)
+	assert.Equal(t, 1, project.Revision)
+
+	zipData, _, err := service.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, project.Revision, "generating the files for the download is a new revision")
+
+	stored, err := store.Get(context.Background(), artifacts.ArchiveKey(project.ID, 2))
+	require.NoError(t, err)
+	assert.Equal(t, zipData, stored)
+
+	// Later downloads of the same revision are served from the store
+	require.NoError(t, store.Put(context.Background(), artifacts.ArchiveKey(project.ID, 2), []byte("cached"), "application/zip"))
+	zipData, _, err = service.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cached"), zipData)
+}
+
+func TestProjectService_Changes_BumpRevision(t *testing.T) {
+	service, store := newArchiveTestService(t)
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "revised", Language: models.LanguageGo})
+	require.NoError(t, err)
+	_, _, err = service.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+
+	project, err = service.UpdateProjectFile(context.Background(), project.ID, "revised/NOTES.md", "notes")
+	require.NoError(t, err)
+	assert.Equal(t, 3, project.Revision)
+
+	project, err = service.UpdateProjectOptions(context.Background(), project.ID, models.ProjectOptions{Framework: "echo"})
+	require.NoError(t, err)
+	assert.Equal(t, 4, project.Revision)
+
+	_, _, err = service.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+
+	exists, err := store.Exists(context.Background(), artifacts.ArchiveKey(project.ID, 4))
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestProjectService_Regenerate_KeepsStoredRevisions(t *testing.T) {
+	service, _ := newArchiveTestService(t)
+	ctx := context.Background()
+	project, err := service.CreateProject(ctx, &models.ProjectRequest{Name: "demo", Language: models.LanguageGo})
+	require.NoError(t, err)
+	_, err = service.GenerateProjectFiles(ctx, project)
+	require.NoError(t, err)
+
+	goMod := filepath.Join("demo", "go.mod")
+	_, err = service.UpdateProjectFile(ctx, project.ID, goMod, "module demo\n\ngo 1.19\n")
+	require.NoError(t, err)
+	patched := project.Revision
+	patchedZIP, _, err := service.CreateProjectZIP(ctx, project.ID)
+	require.NoError(t, err)
+
+	_, err = service.GenerateProjectFiles(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, patched+1, project.Revision, "regenerating over a patch is a new revision")
+
+	regeneratedZIP, _, err := service.CreateProjectZIP(ctx, project.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, patchedZIP, regeneratedZIP, "the archive of the patched revision is not served")
+
+	snapshot, err := service.GetRevision(ctx, project.ID, patched)
+	require.NoError(t, err)
+	var patchedGoMod string
+	for _, file := range snapshot.Files {
+		if file.Path == goMod {
+			patchedGoMod = file.Content
+		}
+	}
+	assert.Equal(t, "module demo\n\ngo 1.19\n", patchedGoMod, "the patched revision keeps its files")
+
+	// Regenerating unchanged files keeps the revision and its archive
+	_, err = service.GenerateProjectFiles(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, patched+1, project.Revision)
+}
+
+func TestProjectService_ProjectArchiveURL(t *testing.T) {
+	local, err := artifacts.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	service := services.NewProjectService(services.NewTemplateService())
+	service.SetArtifactStore(presigningStore{local})
+
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "signed", Language: models.LanguagePHP})
+	require.NoError(t, err)
+
+	url, filename, err := service.ProjectArchiveURL(context.Background(), project.ID, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "signed-php.zip", filename)
+	assert.Equal(t, 2, project.Revision)
+	assert.Contains(t, url, artifacts.ArchiveKey(project.ID, 2))
+
+	// The archive is uploaded before the URL is handed out
+	exists, err := local.Exists(context.Background(), artifacts.ArchiveKey(project.ID, 2))
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	_, _, err = service.ProjectArchiveURL(context.Background(), "missing", time.Minute)
+	assert.True(t, errors.Is(err, services.ErrProjectNotFound))
+}
+
+func TestProjectService_ProjectArchiveURL_Unsupported(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "memory", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	_, _, err = service.ProjectArchiveURL(context.Background(), project.ID, time.Minute)
+	assert.True(t, errors.Is(err, artifacts.ErrPresignUnsupported))
+
+	service, _ = newArchiveTestService(t)
+	project, err = service.CreateProject(context.Background(), &models.ProjectRequest{Name: "local", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	_, _, err = service.ProjectArchiveURL(context.Background(), project.ID, time.Minute)
+	assert.True(t, errors.Is(err, artifacts.ErrPresignUnsupported))
+}
+
+func TestProjectService_StopsWhenContextIsDone(t *testing.T) {
+	service, store := newArchiveTestService(t)
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "cancelled", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = service.GenerateProjectFiles(ctx, project)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, project.Files, "files are not replaced by a partial render")
+
+	_, _, err = service.CreateProjectZIP(ctx, project.ID)
+	assert.ErrorIs(t, err, context.Canceled)
+	exists, err := store.Exists(context.Background(), artifacts.ArchiveKey(project.ID, project.Revision))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	// Archiving checks between files, so a project generated earlier still stops
+	_, err = service.GenerateProjectFiles(context.Background(), project)
+	require.NoError(t, err)
+	_, err = services.NewTemplateService().CreateZIPArchive(ctx, project)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,6 +1,7 @@
 package services_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -34,7 +35,7 @@ func TestProjectService_CreateProject_Go(t *testing.T) {
 		},
 	}
 
-	project, err := service.CreateProject(req)
+	project, err := service.CreateProject(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, project)
@@ -66,7 +67,7 @@ func TestProjectService_CreateProject_PHP(t *testing.T) {
 		},
 	}
 
-	project, err := service.CreateProject(req)
+	project, err := service.CreateProject(context.Background(), req)
 
 	require.NoError(t, err)
 	assert.NotNil(t, project)
@@ -89,7 +90,7 @@ func TestProjectService_CreateProject_InvalidLanguage(t *testing.T) {
 		Description: "A test project",
 	}
 
-	project, err := service.CreateProject(req)
+	project, err := service.CreateProject(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Nil(t, project)
@@ -107,11 +108,11 @@ func TestProjectService_GetProject(t *testing.T) {
 		Description: "A test project",
 	}
 
-	createdProject, err := service.CreateProject(req)
+	createdProject, err := service.CreateProject(context.Background(), req)
 	require.NoError(t, err)
 
 	// Get the project
-	retrievedProject, err := service.GetProject(createdProject.ID)
+	retrievedProject, err := service.GetProject(context.Background(), createdProject.ID)
 
 	require.NoError(t, err)
 	assert.Equal(t, createdProject.ID, retrievedProject.ID)
@@ -123,7 +124,7 @@ func TestProjectService_GetProject_NotFound(t *testing.T) {
 	templateService := services.NewTemplateService()
 	service := services.NewProjectService(templateService)
 
-	project, err := service.GetProject("non-existent-id")
+	project, err := service.GetProject(context.Background(), "non-existent-id")
 
 	assert.Error(t, err)
 	assert.Nil(t, project)
@@ -145,11 +146,11 @@ func TestProjectService_GenerateProjectFiles_Go(t *testing.T) {
 		},
 	}
 
-	project, err := service.CreateProject(req)
+	project, err := service.CreateProject(context.Background(), req)
 	require.NoError(t, err)
 
 	// Generate files
-	files, err := service.GenerateProjectFiles(project)
+	files, err := service.GenerateProjectFiles(context.Background(), project)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, files)
@@ -181,11 +182,11 @@ func TestProjectService_CreateProjectZIP(t *testing.T) {
 		Description: "A test project for ZIP",
 	}
 
-	project, err := service.CreateProject(req)
+	project, err := service.CreateProject(context.Background(), req)
 	require.NoError(t, err)
 
 	// Create ZIP
-	zipData, filename, err := service.CreateProjectZIP(project.ID)
+	zipData, filename, err := service.CreateProjectZIP(context.Background(), project.ID)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, zipData)
@@ -198,7 +199,7 @@ func TestProjectService_ListProjects(t *testing.T) {
 	service := services.NewProjectService(templateService)
 
 	// Initially should be empty
-	projects := service.ListProjects()
+	projects := service.ListProjects(context.Background())
 	assert.Empty(t, projects)
 
 	// Create some projects
@@ -211,13 +212,13 @@ func TestProjectService_ListProjects(t *testing.T) {
 		Language: models.LanguagePHP,
 	}
 
-	_, err := service.CreateProject(req1)
+	_, err := service.CreateProject(context.Background(), req1)
 	require.NoError(t, err)
-	_, err = service.CreateProject(req2)
+	_, err = service.CreateProject(context.Background(), req2)
 	require.NoError(t, err)
 
 	// List projects
-	projects = service.ListProjects()
+	projects = service.ListProjects(context.Background())
 	assert.Len(t, projects, 2)
 
 	// Verify projects exist
@@ -241,7 +242,7 @@ func TestProjectService_ConcurrentAccess(t *testing.T) {
 				Name:     fmt.Sprintf("concurrent-project-%d", i),
 				Language: models.LanguageGo,
 			}
-			_, err := service.CreateProject(req)
+			_, err := service.CreateProject(context.Background(), req)
 			assert.NoError(t, err)
 			done <- true
 		}(i)
@@ -253,6 +254,6 @@ func TestProjectService_ConcurrentAccess(t *testing.T) {
 	}
 
 	// Verify all projects were created
-	projects := service.ListProjects()
+	projects := service.ListProjects(context.Background())
 	assert.Len(t, projects, 10)
 }
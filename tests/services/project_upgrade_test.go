@@ -0,0 +1,206 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zipFiles archives files, keyed by path
+func zipFiles(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for path, content := range files {
+		entry, err := writer.Create(path)
+		require.NoError(t, err)
+		_, err = io.WriteString(entry, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+// unzipFiles reads the regular files of an archive, keyed by path
+func unzipFiles(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	files := make(map[string]string)
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := entry.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[entry.Name] = string(content)
+	}
+	return files
+}
+
+// localCopy returns the project's files as a local copy would hold them
+func localCopy(project *models.Project) map[string]string {
+	files := make(map[string]string)
+	for _, file := range project.Files {
+		if !file.IsDirectory {
+			files[filepath.ToSlash(file.Path)] = file.Content
+		}
+	}
+	return files
+}
+
+// replaceLine swaps the line of content that starts with prefix
+func replaceLine(t *testing.T, content, prefix, line string) string {
+	t.Helper()
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		if strings.HasPrefix(lines[i], prefix) {
+			lines[i] = line
+			return strings.Join(lines, "\n")
+		}
+	}
+	t.Fatalf("no line starts with %q", prefix)
+	return ""
+}
+
+// outdatedProject generates a project and then stores an older version of
+// go.mod, as if the template had changed its go directive since
+func outdatedProject(t *testing.T, service *services.ProjectService) (*models.Project, string) {
+	t.Helper()
+	project := newGoProject(t, service, "gin")
+	goMod := filepath.Join("shop", "go.mod")
+	current, ok := projectFile(project, goMod)
+	require.True(t, ok)
+
+	old := replaceLine(t, current.Content, "go 1.21", "go 1.19")
+	_, err := service.UpdateProjectFile(context.Background(), project.ID, goMod, old)
+	require.NoError(t, err)
+	return project, old
+}
+
+func TestProjectService_UpgradeProject_MergesTemplateChanges(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, old := outdatedProject(t, service)
+	local := localCopy(project)
+
+	// The local copy added a dependency far from the line the template changed
+	local["shop/go.mod"] = strings.Replace(old, "require (\n", "require (\n\tgithub.com/acme/widgets v1.0.0\n", 1)
+	local["shop/NOTES.md"] = "local notes\n"
+
+	result, err := service.UpgradeProject(context.Background(), project.ID, 0, zipFiles(t, local))
+	require.NoError(t, err)
+	assert.Equal(t, project.Revision, result.Report.BaseRevision)
+	assert.Zero(t, result.Report.Conflicts)
+	require.Len(t, result.Report.Files, 1)
+	assert.Equal(t, models.UpgradeFile{Path: filepath.Join("shop", "go.mod"), Status: models.UpgradeFileMerged}, result.Report.Files[0])
+	assert.Equal(t, 1, result.Report.Summary[models.UpgradeFileMerged])
+
+	merged := unzipFiles(t, result.Archive)
+	assert.Contains(t, merged["shop/go.mod"], "go 1.21\n")
+	assert.Contains(t, merged["shop/go.mod"], "github.com/acme/widgets v1.0.0")
+	assert.Equal(t, "local notes\n", merged["shop/NOTES.md"], "files only the local copy has are kept")
+	assert.Equal(t, "shop-go-upgrade.zip", result.Filename)
+
+	stored, err := service.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project.Revision, stored.Revision, "upgrading does not change the project")
+}
+
+func TestProjectService_UpgradeProject_ReportsConflicts(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, old := outdatedProject(t, service)
+	local := localCopy(project)
+	local["shop/go.mod"] = replaceLine(t, old, "go 1.19", "go 1.20")
+
+	result, err := service.UpgradeProject(context.Background(), project.ID, 0, zipFiles(t, local))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Report.Conflicts)
+	require.Len(t, result.Report.Files, 1)
+	file := result.Report.Files[0]
+	assert.Equal(t, models.UpgradeFileConflict, file.Status)
+	require.Len(t, file.Conflicts, 1)
+	assert.Equal(t, "<<<<<<< local\ngo 1.20\n||||||| revision 3\ngo 1.19\n=======\ngo 1.21\n>>>>>>> template\n", file.Conflicts[0])
+
+	merged := unzipFiles(t, result.Archive)
+	assert.Contains(t, merged["shop/go.mod"], file.Conflicts[0])
+}
+
+func TestProjectService_UpgradeProject_TakesUneditedFiles(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, _ := outdatedProject(t, service)
+	extra := filepath.Join("shop", "docs", "OLD.md")
+	_, err := service.UpdateProjectFile(context.Background(), project.ID, extra, "dropped by the template\n")
+	require.NoError(t, err)
+
+	// A GitHub-style archive, whose top-level directory is not the project's name
+	local := make(map[string]string)
+	for path, content := range localCopy(project) {
+		local["shop-main/"+strings.TrimPrefix(path, "shop/")] = content
+	}
+
+	result, err := service.UpgradeProject(context.Background(), project.ID, 0, zipFiles(t, local))
+	require.NoError(t, err)
+	statuses := make(map[string]string)
+	for _, file := range result.Report.Files {
+		statuses[file.Path] = file.Status
+	}
+	assert.Equal(t, map[string]string{
+		filepath.Join("shop", "go.mod"): models.UpgradeFileUpdated,
+		extra:                           models.UpgradeFileRemoved,
+	}, statuses)
+
+	merged := unzipFiles(t, result.Archive)
+	assert.Contains(t, merged["shop/go.mod"], "go 1.21\n")
+	assert.NotContains(t, merged, "shop/docs/OLD.md")
+}
+
+func TestProjectService_UpgradeProject_UnchangedCopy(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "chi")
+	_, err := service.AddComponent(context.Background(), project.ID, &models.ComponentRequest{Type: "entity", Name: "order"})
+	require.NoError(t, err)
+
+	archive, _, err := service.CreateProjectZIP(context.Background(), project.ID)
+	require.NoError(t, err)
+	result, err := service.UpgradeProject(context.Background(), project.ID, 0, archive)
+	require.NoError(t, err)
+	assert.Empty(t, result.Report.Files, "components are rendered again, so nothing is removed")
+	assert.Zero(t, result.Report.Conflicts)
+	assert.Equal(t, localCopy(project), unzipFiles(t, result.Archive))
+}
+
+func TestProjectService_UpgradeProject_Invalid(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project := newGoProject(t, service, "gin")
+	archive := zipFiles(t, localCopy(project))
+
+	_, err := service.UpgradeProject(context.Background(), project.ID, 0, []byte("not a zip"))
+	assert.ErrorIs(t, err, services.ErrInvalidArchive)
+
+	_, err = service.UpgradeProject(context.Background(), project.ID, 0, zipFiles(t, map[string]string{"../escape.go": "package x\n"}))
+	assert.ErrorIs(t, err, services.ErrInvalidArchive)
+
+	_, err = service.UpgradeProject(context.Background(), project.ID, 99, archive)
+	assert.ErrorIs(t, err, services.ErrRevisionNotFound)
+
+	_, err = service.UpgradeProject(context.Background(), "missing", 0, archive)
+	assert.ErrorIs(t, err, services.ErrProjectNotFound)
+
+	ungenerated, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "empty", Language: models.LanguageGo})
+	require.NoError(t, err)
+	_, err = service.UpgradeProject(context.Background(), ungenerated.ID, 0, archive)
+	assert.ErrorIs(t, err, services.ErrUpgradeBaseEmpty)
+}
@@ -0,0 +1,323 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedStore is an in-memory storage.Store that hands out copies, like a
+// remote backend shared by several instances would
+type sharedStore struct {
+	projects  map[string][]byte
+	revisions map[string][]byte
+	chats     map[string][]byte
+	expiries  map[string]time.Time
+	mu        sync.Mutex
+}
+
+func newSharedStore() *sharedStore {
+	return &sharedStore{
+		projects:  map[string][]byte{},
+		revisions: map[string][]byte{},
+		chats:     map[string][]byte{},
+		expiries:  map[string]time.Time{},
+	}
+}
+
+func (s *sharedStore) put(bucket map[string][]byte, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bucket[key] = data
+	return nil
+}
+
+func (s *sharedStore) get(bucket map[string][]byte, key string, out interface{}) error {
+	s.mu.Lock()
+	data, ok := bucket[key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (s *sharedStore) SaveProject(ctx context.Context, project *models.Project, previousRevision int) error {
+	s.mu.Lock()
+	data, exists := s.projects[project.ID]
+	s.mu.Unlock()
+
+	var stored models.Project
+	if exists {
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return err
+		}
+	}
+	if exists != (previousRevision > 0) || stored.Revision != previousRevision {
+		return fmt.Errorf("%w: %s", storage.ErrConflict, project.ID)
+	}
+	return s.put(s.projects, project.ID, project)
+}
+
+func (s *sharedStore) GetProject(ctx context.Context, projectID string) (*models.Project, error) {
+	var project models.Project
+	if err := s.get(s.projects, projectID, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (s *sharedStore) ListProjects(ctx context.Context) ([]*models.Project, error) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.projects))
+	for id := range s.projects {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	projects := make([]*models.Project, 0, len(ids))
+	for _, id := range ids {
+		project, err := s.GetProject(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+func (s *sharedStore) SaveRevision(ctx context.Context, revision *models.ProjectRevision) error {
+	key := fmt.Sprintf("%s@%d", revision.ProjectID, revision.Revision)
+	s.mu.Lock()
+	_, exists := s.revisions[key]
+	s.mu.Unlock()
+	if exists {
+		return fmt.Errorf("%w: %s", storage.ErrConflict, key)
+	}
+	return s.put(s.revisions, key, revision)
+}
+
+func (s *sharedStore) GetRevision(ctx context.Context, projectID string, revision int) (*models.ProjectRevision, error) {
+	var snapshot models.ProjectRevision
+	if err := s.get(s.revisions, fmt.Sprintf("%s@%d", projectID, revision), &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+func (s *sharedStore) ListRevisions(ctx context.Context, projectID string) ([]models.ProjectRevision, error) {
+	var revisions []models.ProjectRevision
+	for revision := 1; ; revision++ {
+		snapshot, err := s.GetRevision(ctx, projectID, revision)
+		if errors.Is(err, storage.ErrNotFound) {
+			return revisions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, *snapshot)
+	}
+}
+
+func (s *sharedStore) SaveChatHistory(ctx context.Context, history *models.ChatHistory, expiresAt time.Time) error {
+	s.mu.Lock()
+	s.expiries[history.SessionID] = expiresAt
+	s.mu.Unlock()
+	return s.put(s.chats, history.SessionID, history)
+}
+
+func (s *sharedStore) GetChatHistory(ctx context.Context, sessionID string) (*models.ChatHistory, error) {
+	var history models.ChatHistory
+	if err := s.get(s.chats, sessionID, &history); err != nil {
+		return nil, err
+	}
+	return &history, nil
+}
+
+func (s *sharedStore) GetProjectChatHistory(ctx context.Context, projectID string) (*models.ChatHistory, error) {
+	sessions, _ := s.ListChatSessions(ctx)
+	for _, session := range sessions {
+		if session.ProjectID == projectID {
+			return s.GetChatHistory(ctx, session.ID)
+		}
+	}
+	return nil, fmt.Errorf("%w: chat for %s", storage.ErrNotFound, projectID)
+}
+
+func (s *sharedStore) ListChatSessions(ctx context.Context) ([]models.ChatSession, error) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.chats))
+	for id := range s.chats {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	sort.Strings(ids)
+
+	sessions := make([]models.ChatSession, 0, len(ids))
+	for _, id := range ids {
+		history, err := s.GetChatHistory(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, models.ChatSession{ID: history.SessionID, Title: history.Title, ProjectID: history.ProjectID, MessageCount: len(history.Messages), UpdatedAt: history.UpdatedAt})
+	}
+	return sessions, nil
+}
+
+func (s *sharedStore) DeleteChatHistory(ctx context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.chats, sessionID)
+	return nil
+}
+
+// newInstance builds services the way one Lambda invocation would
+func newInstance(store storage.Store) (*services.ProjectService, *services.ChatService) {
+	projectService := services.NewProjectService(services.NewTemplateService())
+	projectService.SetStore(store)
+	chatService := services.NewChatService()
+	chatService.SetStore(store)
+	return projectService, chatService
+}
+
+func TestProjectService_Store_SharedAcrossInstances(t *testing.T) {
+	store := newSharedStore()
+	first, _ := newInstance(store)
+	second, _ := newInstance(store)
+
+	project, err := first.CreateProject(context.Background(), &models.ProjectRequest{Name: "shared", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	loaded, err := second.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "shared", loaded.Name)
+
+	_, err = second.GenerateProjectFiles(context.Background(), loaded)
+	require.NoError(t, err)
+	_, err = second.UpdateProjectFile(context.Background(), project.ID, "shared/NOTES.md", "notes")
+	require.NoError(t, err)
+
+	// The first instance sees the second instance's changes
+	reloaded, err := first.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reloaded.Revision)
+	assert.NotEmpty(t, reloaded.Files)
+
+	revisions, err := first.ListRevisions(context.Background(), project.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Empty(t, revisions[0].Files, "revision 1 keeps the files it was created with")
+	assert.NotEmpty(t, revisions[1].Files, "generating records a new revision")
+
+	assert.Len(t, first.ListProjects(context.Background()), 1)
+
+	_, err = first.GetProject(context.Background(), "missing")
+	assert.True(t, errors.Is(err, services.ErrProjectNotFound))
+}
+
+func TestProjectService_Store_RejectsStaleWrites(t *testing.T) {
+	store := newSharedStore()
+	first, _ := newInstance(store)
+	second, _ := newInstance(store)
+
+	project, err := first.CreateProject(context.Background(), &models.ProjectRequest{Name: "stale", Language: models.LanguageGo})
+	require.NoError(t, err)
+	stale, err := first.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+
+	_, err = second.UpdateProjectFile(context.Background(), project.ID, "stale/NOTES.md", "notes")
+	require.NoError(t, err)
+
+	// The first instance's copy is behind the store; saving it would drop the notes
+	_, err = first.GenerateProjectFiles(context.Background(), stale)
+	assert.True(t, errors.Is(err, services.ErrProjectChanged))
+
+	reloaded, err := first.GetProject(context.Background(), project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, reloaded.Revision)
+	notes, ok := projectFile(reloaded, "stale/NOTES.md")
+	require.True(t, ok)
+	assert.Equal(t, "notes", notes.Content)
+}
+
+func TestProjectService_Revisions_InMemory(t *testing.T) {
+	service := services.NewProjectService(services.NewTemplateService())
+	project, err := service.CreateProject(context.Background(), &models.ProjectRequest{Name: "memory", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	_, err = service.UpdateProjectOptions(context.Background(), project.ID, models.ProjectOptions{Framework: "echo"})
+	require.NoError(t, err)
+
+	revisions, err := service.ListRevisions(context.Background(), project.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "gin", revisions[0].Options.Framework)
+	assert.Equal(t, "echo", revisions[1].Options.Framework)
+
+	revision, err := service.GetRevision(context.Background(), project.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, revision.Revision)
+
+	_, err = service.GetRevision(context.Background(), project.ID, 5)
+	assert.True(t, errors.Is(err, services.ErrRevisionNotFound))
+}
+
+func TestChatService_Store_SharedAcrossInstances(t *testing.T) {
+	store := newSharedStore()
+	_, first := newInstance(store)
+	_, second := newInstance(store)
+
+	response, err := first.ProcessMessage(context.Background(), &models.ChatRequest{Message: "I want a Go API with PostgreSQL"})
+	require.NoError(t, err)
+
+	// A follow-up handled by another instance continues the same conversation
+	_, err = second.ProcessMessage(context.Background(), &models.ChatRequest{Message: "Add Redis caching", SessionID: response.SessionID})
+	require.NoError(t, err)
+
+	history, err := first.GetSessionHistory(context.Background(), response.SessionID)
+	require.NoError(t, err)
+	assert.Len(t, history.Messages, 4)
+	require.NotNil(t, history.Draft)
+	assert.Equal(t, models.LanguageGo, history.Draft.Language)
+
+	sessions := second.ListSessions(context.Background())
+	require.Len(t, sessions, 1)
+	assert.Equal(t, response.SessionID, sessions[0].ID)
+
+	_, err = second.LinkSession(context.Background(), response.SessionID, "project-1")
+	require.NoError(t, err)
+	linked, err := first.GetChatHistory(context.Background(), "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, response.SessionID, linked.SessionID)
+
+	require.NoError(t, first.DeleteSession(context.Background(), response.SessionID))
+	_, err = second.GetSession(context.Background(), response.SessionID)
+	assert.True(t, errors.Is(err, services.ErrSessionNotFound))
+}
+
+func TestChatService_Store_ExpiryFollowsIdleTimeout(t *testing.T) {
+	store := newSharedStore()
+	chatService := services.NewChatServiceWithRetention(services.ChatRetention{MaxMessages: 10, IdleTimeout: time.Hour})
+	chatService.SetStore(store)
+
+	session, err := chatService.CreateSession(context.Background(), &models.ChatSessionRequest{Title: "expiring"})
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, session.UpdatedAt.Add(time.Hour), store.expiries[session.ID], time.Second)
+}
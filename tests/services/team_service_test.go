@@ -0,0 +1,302 @@
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/services"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// teamStore is an in-memory storage.TeamStore that hands out copies
+type teamStore struct {
+	teams map[string][]byte
+	packs map[string][]byte
+	mu    sync.Mutex
+}
+
+func newTeamStore() *teamStore {
+	return &teamStore{teams: map[string][]byte{}, packs: map[string][]byte{}}
+}
+
+func (s *teamStore) save(bucket map[string][]byte, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bucket[key] = data
+	return nil
+}
+
+func (s *teamStore) load(bucket map[string][]byte, key string, out interface{}) error {
+	s.mu.Lock()
+	data, ok := bucket[key]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", storage.ErrNotFound, key)
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (s *teamStore) keys(bucket map[string][]byte) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(bucket))
+	for key := range bucket {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *teamStore) remove(bucket map[string][]byte, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(bucket, key)
+	return nil
+}
+
+func (s *teamStore) SaveTeam(ctx context.Context, team *models.Team) error {
+	return s.save(s.teams, team.ID, team)
+}
+
+func (s *teamStore) GetTeam(ctx context.Context, teamID string) (*models.Team, error) {
+	var team models.Team
+	if err := s.load(s.teams, teamID, &team); err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (s *teamStore) ListTeams(ctx context.Context) ([]*models.Team, error) {
+	var teams []*models.Team
+	for _, id := range s.keys(s.teams) {
+		team, err := s.GetTeam(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, nil
+}
+
+func (s *teamStore) DeleteTeam(ctx context.Context, teamID string) error {
+	return s.remove(s.teams, teamID)
+}
+
+func (s *teamStore) SaveTemplatePack(ctx context.Context, pack *models.TemplatePack) error {
+	return s.save(s.packs, pack.ID, pack)
+}
+
+func (s *teamStore) GetTemplatePack(ctx context.Context, packID string) (*models.TemplatePack, error) {
+	var pack models.TemplatePack
+	if err := s.load(s.packs, packID, &pack); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+func (s *teamStore) ListTemplatePacks(ctx context.Context) ([]*models.TemplatePack, error) {
+	var packs []*models.TemplatePack
+	for _, id := range s.keys(s.packs) {
+		pack, err := s.GetTemplatePack(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+func (s *teamStore) DeleteTemplatePack(ctx context.Context, packID string) error {
+	return s.remove(s.packs, packID)
+}
+
+func TestTeamRole_Allows(t *testing.T) {
+	assert.True(t, models.RoleOwner.Allows(models.RoleEditor))
+	assert.True(t, models.RoleEditor.Allows(models.RoleEditor))
+	assert.False(t, models.RoleViewer.Allows(models.RoleEditor))
+	assert.False(t, models.TeamRole("").Allows(models.RoleViewer))
+	assert.False(t, models.TeamRole("admin").Valid())
+}
+
+func TestTeamService_Membership(t *testing.T) {
+	service := services.NewTeamService()
+
+	_, err := service.CreateTeam(context.Background(), "  ", "alice")
+	assert.Error(t, err)
+
+	team, err := service.CreateTeam(context.Background(), "Platform", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleOwner, team.Role("alice"))
+
+	team, err = service.SetMember(context.Background(), team.ID, "bob", models.RoleViewer)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, team.Role("bob"))
+
+	team, err = service.SetMember(context.Background(), team.ID, "bob", models.RoleEditor)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleEditor, team.Role("bob"))
+	assert.Len(t, team.Members, 2, "changing a role does not add a member")
+
+	_, err = service.SetMember(context.Background(), team.ID, "carol", models.TeamRole("admin"))
+	assert.Error(t, err)
+
+	assert.Len(t, service.ListTeams(context.Background(), "bob"), 1)
+	assert.Empty(t, service.ListTeams(context.Background(), "carol"))
+	assert.Equal(t, map[string]models.TeamRole{team.ID: models.RoleEditor}, service.Roles(context.Background(), "bob"))
+
+	team, err = service.RemoveMember(context.Background(), team.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, models.TeamRole(""), team.Role("bob"))
+
+	_, err = service.RemoveMember(context.Background(), team.ID, "bob")
+	assert.True(t, errors.Is(err, services.ErrTeamMemberNotFound))
+	_, err = service.SetMember(context.Background(), "missing", "bob", models.RoleViewer)
+	assert.True(t, errors.Is(err, services.ErrTeamNotFound))
+}
+
+func TestTeamService_KeepsAnOwner(t *testing.T) {
+	service := services.NewTeamService()
+	team, err := service.CreateTeam(context.Background(), "Platform", "alice")
+	require.NoError(t, err)
+
+	_, err = service.SetMember(context.Background(), team.ID, "alice", models.RoleEditor)
+	assert.True(t, errors.Is(err, services.ErrLastTeamOwner))
+	_, err = service.RemoveMember(context.Background(), team.ID, "alice")
+	assert.True(t, errors.Is(err, services.ErrLastTeamOwner))
+
+	_, err = service.SetMember(context.Background(), team.ID, "bob", models.RoleOwner)
+	require.NoError(t, err)
+	team, err = service.RemoveMember(context.Background(), team.ID, "alice")
+	require.NoError(t, err, "another owner remains")
+	assert.Equal(t, models.RoleOwner, team.Role("bob"))
+}
+
+func TestTeamService_ReturnsCopies(t *testing.T) {
+	service := services.NewTeamService()
+	team, err := service.CreateTeam(context.Background(), "Platform", "alice")
+	require.NoError(t, err)
+
+	team.Members[0].Role = models.RoleViewer
+	team.Name = "changed"
+
+	loaded, err := service.GetTeam(context.Background(), team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Platform", loaded.Name)
+	assert.Equal(t, models.RoleOwner, loaded.Role("alice"))
+}
+
+func TestTeamService_Store_SharedAcrossInstances(t *testing.T) {
+	store := newTeamStore()
+	first := services.NewTeamService()
+	first.SetStore(store)
+	second := services.NewTeamService()
+	second.SetStore(store)
+
+	team, err := first.CreateTeam(context.Background(), "Platform", "alice")
+	require.NoError(t, err)
+	_, err = second.SetMember(context.Background(), team.ID, "bob", models.RoleViewer)
+	require.NoError(t, err)
+
+	loaded, err := first.GetTeam(context.Background(), team.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, loaded.Role("bob"))
+
+	require.NoError(t, second.DeleteTeam(context.Background(), team.ID))
+	_, err = first.GetTeam(context.Background(), team.ID)
+	assert.True(t, errors.Is(err, services.ErrTeamNotFound))
+	assert.Empty(t, first.ListTeams(context.Background(), "alice"))
+}
+
+func TestTemplatePackService_Lifecycle(t *testing.T) {
+	service := services.NewTemplatePackService(services.NewTemplateService())
+
+	_, err := service.CreatePack(context.Background(), &models.TemplatePackRequest{Name: "Rust", Language: "rust"})
+	assert.Error(t, err, "unsupported language")
+	_, err = service.CreatePack(context.Background(), &models.TemplatePackRequest{
+		Name:     "Bad framework",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "rails"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "gin, chi, echo, standard")
+
+	pack, err := service.CreatePack(context.Background(), &models.TemplatePackRequest{
+		Name:     "Chi + MySQL",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "chi", Database: "mysql"},
+		TeamID:   "team-1",
+		OwnerID:  "alice",
+	})
+	require.NoError(t, err)
+	_, err = service.CreatePack(context.Background(), &models.TemplatePackRequest{Name: "CodeIgniter 4", Language: models.LanguagePHP, Options: models.ProjectOptions{CIVersion: "4"}})
+	require.NoError(t, err)
+
+	packs := service.ListPacks(context.Background())
+	require.Len(t, packs, 2)
+	assert.Equal(t, "Chi + MySQL", packs[0].Name, "sorted by name")
+
+	updated, err := service.UpdatePack(context.Background(), pack.ID, &models.TemplatePackRequest{
+		Name:     "Echo + MySQL",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "echo", Database: "mysql"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", updated.OwnerID, "the owner is kept")
+	assert.Empty(t, updated.TeamID)
+	assert.Equal(t, pack.CreatedAt, updated.CreatedAt)
+
+	require.NoError(t, service.DeletePack(context.Background(), pack.ID))
+	_, err = service.GetPack(context.Background(), pack.ID)
+	assert.True(t, errors.Is(err, services.ErrTemplatePackNotFound))
+}
+
+func TestTemplatePackService_Store(t *testing.T) {
+	store := newTeamStore()
+	first := services.NewTemplatePackService(services.NewTemplateService())
+	first.SetStore(store)
+	second := services.NewTemplatePackService(services.NewTemplateService())
+	second.SetStore(store)
+
+	pack, err := first.CreatePack(context.Background(), &models.TemplatePackRequest{Name: "Gin", Language: models.LanguageGo})
+	require.NoError(t, err)
+
+	loaded, err := second.GetPack(context.Background(), pack.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gin", loaded.Name)
+	assert.Len(t, second.ListPacks(context.Background()), 1)
+}
+
+func TestApplyTemplatePack(t *testing.T) {
+	pack := &models.TemplatePack{
+		Name:     "Chi + MySQL",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "chi", Database: "mysql", Utilities: []string{"logger"}},
+	}
+
+	req := &models.ProjectRequest{
+		Name:     "api",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Database: "sqlite"},
+	}
+	require.NoError(t, services.ApplyTemplatePack(pack, req))
+	assert.Equal(t, "chi", req.Options.Framework, "unset options come from the pack")
+	assert.Equal(t, "sqlite", req.Options.Database, "the request's own options win")
+	assert.Equal(t, []string{"logger"}, req.Options.Utilities)
+
+	req.Options.Utilities[0] = "cache"
+	assert.Equal(t, []string{"logger"}, pack.Options.Utilities, "the pack is not modified")
+
+	err := services.ApplyTemplatePack(pack, &models.ProjectRequest{Name: "web", Language: models.LanguagePHP})
+	assert.True(t, errors.Is(err, services.ErrTemplatePackLanguage))
+}
@@ -1,6 +1,10 @@
 package services_test
 
 import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"boilerplate-blueprint/internal/models"
@@ -113,7 +117,7 @@ func TestTemplateService_GenerateGoProject(t *testing.T) {
 		},
 	}
 
-	files, err := service.GenerateGoProject(project)
+	files, err := service.GenerateGoProject(context.Background(), project)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, files)
@@ -168,7 +172,7 @@ func TestTemplateService_GenerateGoProject_goModContent(t *testing.T) {
 		},
 	}
 
-	files, err := service.GenerateGoProject(project)
+	files, err := service.GenerateGoProject(context.Background(), project)
 	require.NoError(t, err)
 
 	// Find go.mod file
@@ -204,7 +208,7 @@ func TestTemplateService_GeneratePHPProject(t *testing.T) {
 		},
 	}
 
-	files, err := service.GeneratePHPProject(project)
+	files, err := service.GeneratePHPProject(context.Background(), project)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, files)
@@ -272,7 +276,7 @@ func TestTemplateService_CreateZIPArchive(t *testing.T) {
 		},
 	}
 
-	zipData, err := service.CreateZIPArchive(project)
+	zipData, err := service.CreateZIPArchive(context.Background(), project)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, zipData)
@@ -288,8 +292,128 @@ func TestTemplateService_CreateZIPArchive_EmptyProject(t *testing.T) {
 		Files:    []models.ProjectFile{},
 	}
 
-	zipData, err := service.CreateZIPArchive(project)
+	zipData, err := service.CreateZIPArchive(context.Background(), project)
 
 	require.NoError(t, err)
 	assert.NotEmpty(t, zipData)
 }
+
+func TestTemplateService_ValidateOptions(t *testing.T) {
+	service := services.NewTemplateService()
+
+	assert.NoError(t, service.ValidateOptions(models.LanguageGo, models.ProjectOptions{Framework: "chi"}))
+	assert.Error(t, service.ValidateOptions(models.LanguageGo, models.ProjectOptions{Framework: "rails"}))
+	assert.Error(t, service.ValidateOptions(models.ProjectLanguage("rust"), models.ProjectOptions{}))
+}
+
+func TestTemplateService_ApplyDefaults(t *testing.T) {
+	service := services.NewTemplateService()
+	project := &models.Project{Language: models.LanguagePHP, Options: models.ProjectOptions{Frontend: "tailwind"}}
+
+	service.ApplyDefaults(project)
+	assert.Equal(t, "tailwind", project.Options.Frontend)
+	assert.Equal(t, "3", project.Options.CIVersion)
+	assert.NotEmpty(t, project.Options.Features)
+}
+
+func TestSetOption(t *testing.T) {
+	var options models.ProjectOptions
+	require.NoError(t, services.SetOption(&options, "database", "mysql"))
+	require.NoError(t, services.SetOption(&options, "utilities", "logger, cache,"))
+	assert.Equal(t, "mysql", options.Database)
+	assert.Equal(t, []string{"logger", "cache"}, options.Utilities)
+	assert.Equal(t, "logger,cache", services.OptionValue(options, "utilities"))
+
+	assert.Error(t, services.SetOption(&options, "colour", "blue"))
+}
+
+func TestTemplateService_LoadTemplateDirs(t *testing.T) {
+	base, override := t.TempDir(), t.TempDir()
+	writeTemplate(t, base, "go/README.md.tmpl", "# {{.ProjectName}}\n\nBuilt on {{.Framework}}.\n")
+	writeTemplate(t, base, "go/deploy/k8s/service.yaml", "name: {{.PackageName}}\n")
+	writeTemplate(t, override, "go/deploy/k8s/service.yaml", "name: {{.PackageName}}-svc\n")
+	writeTemplate(t, base, "php/NOTICE", "{{.ProjectName}}\n")
+
+	service := services.NewTemplateService()
+	require.NoError(t, service.LoadTemplateDirs([]string{base, override}))
+
+	files, err := service.GenerateProject(context.Background(), &models.Project{
+		Name:     "shop",
+		Language: models.LanguageGo,
+		Options:  models.ProjectOptions{Framework: "chi"},
+	})
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	directories := make(map[string]bool)
+	for _, file := range files {
+		if file.IsDirectory {
+			directories[file.Path] = true
+		} else {
+			_, duplicate := contents[file.Path]
+			assert.False(t, duplicate, file.Path)
+			contents[file.Path] = file.Content
+		}
+	}
+
+	assert.Equal(t, "# shop\n\nBuilt on chi.\n", contents[filepath.Join("shop", "README.md")], "replaces the generated file")
+	assert.Equal(t, "name: shop-svc\n", contents[filepath.Join("shop", "deploy", "k8s", "service.yaml")], "later directories win")
+	assert.True(t, directories[filepath.Join("shop", "deploy")])
+	assert.True(t, directories[filepath.Join("shop", "deploy", "k8s")])
+	assert.NotContains(t, contents, filepath.Join("shop", "NOTICE"), "php templates are not used for Go projects")
+	assert.Contains(t, contents, filepath.Join("shop", "go.mod"))
+}
+
+func TestTemplateService_LoadTemplateDirs_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "go/broken.txt", "{{.ProjectName")
+	assert.Error(t, services.NewTemplateService().LoadTemplateDirs([]string{dir}))
+
+	dir = t.TempDir()
+	writeTemplate(t, dir, "go/unknown.txt", "{{.Nope}}")
+	service := services.NewTemplateService()
+	require.NoError(t, service.LoadTemplateDirs([]string{dir}))
+	_, err := service.GenerateProject(context.Background(), &models.Project{Name: "shop", Language: models.LanguageGo})
+	assert.ErrorContains(t, err, "unknown.txt")
+
+	assert.NoError(t, services.NewTemplateService().LoadTemplateDirs([]string{t.TempDir()}), "language subdirectories are optional")
+}
+
+// stoppingContext reports itself cancelled once Err has been checked checks times
+type stoppingContext struct {
+	context.Context
+	checks int
+}
+
+func (c *stoppingContext) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestTemplateService_LoadTemplateDirs_StopsBetweenFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "go/a.txt", "{{.ProjectName}}\n")
+	writeTemplate(t, dir, "go/b.txt", "{{.ProjectName}}\n")
+	service := services.NewTemplateService()
+	require.NoError(t, service.LoadTemplateDirs([]string{dir}))
+	project := &models.Project{Name: "shop", Language: models.LanguageGo}
+
+	// Give rendering one more check each time until it reaches the second file
+	stopped := false
+	for checks := 0; checks < 100 && !stopped; checks++ {
+		_, err := service.GenerateProject(&stoppingContext{Context: context.Background(), checks: checks}, project)
+		require.Error(t, err)
+		require.ErrorIs(t, err, context.Canceled)
+		stopped = strings.Contains(err.Error(), "before template b.txt")
+	}
+	assert.True(t, stopped, "cancellation is checked between template files")
+}
+
+func writeTemplate(t *testing.T, dir, path, content string) {
+	t.Helper()
+	target := filepath.Join(dir, filepath.FromSlash(path))
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte(content), 0o644))
+}
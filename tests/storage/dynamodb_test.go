@@ -0,0 +1,192 @@
+package storage_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/awsauth"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalDynamoDBStore connects to DynamoDB Local (DYNAMODB_ENDPOINT, default
+// http://localhost:8000) with a fresh table, skipping when it is not running
+func newLocalDynamoDBStore(t *testing.T) *storage.DynamoDBStore {
+	t.Helper()
+
+	endpoint := os.Getenv("DYNAMODB_ENDPOINT")
+	if endpoint == "" {
+		endpoint = "http://localhost:8000"
+	}
+	parsed, err := url.Parse(endpoint)
+	require.NoError(t, err)
+	conn, err := net.DialTimeout("tcp", parsed.Host, 500*time.Millisecond)
+	if err != nil {
+		t.Skipf("DynamoDB Local is not available at %s: %v", endpoint, err)
+	}
+	conn.Close()
+
+	store, err := storage.NewDynamoDBStore(storage.DynamoDBConfig{
+		Table:       "blueprint-test-" + uuid.New().String()[:8],
+		Region:      "us-east-1",
+		Endpoint:    endpoint,
+		Credentials: awsauth.Credentials{AccessKeyID: "local", SecretAccessKey: "local"},
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	require.NoError(t, store.EnsureTable(ctx))
+	require.NoError(t, store.EnsureTable(ctx), "creating an existing table is not an error")
+
+	return store
+}
+
+func testProject(name string, createdAt time.Time) *models.Project {
+	return &models.Project{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Language:  models.LanguageGo,
+		Options:   models.ProjectOptions{Framework: "gin"},
+		Files:     []models.ProjectFile{{Path: name + "/main.go", Content: "package main"}},
+		Revision:  1,
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+	}
+}
+
+func TestDynamoDBStore_Projects(t *testing.T) {
+	store := newLocalDynamoDBStore(t)
+	ctx := context.Background()
+
+	older := testProject("older", time.Now().Add(-time.Hour))
+	newer := testProject("newer", time.Now())
+	require.NoError(t, store.SaveProject(ctx, older, 0))
+	require.NoError(t, store.SaveProject(ctx, newer, 0))
+
+	// Saves are conditional on the revision they were made to
+	older.Revision = 2
+	require.NoError(t, store.SaveProject(ctx, older, 1))
+	err := store.SaveProject(ctx, older, 1)
+	assert.True(t, errors.Is(err, storage.ErrConflict))
+	err = store.SaveProject(ctx, newer, 0)
+	assert.True(t, errors.Is(err, storage.ErrConflict))
+
+	loaded, err := store.GetProject(ctx, older.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "older", loaded.Name)
+	assert.Equal(t, older.Files, loaded.Files)
+
+	projects, err := store.ListProjects(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 2)
+	assert.Equal(t, newer.ID, projects[0].ID)
+
+	_, err = store.GetProject(ctx, "missing")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func TestDynamoDBStore_Revisions(t *testing.T) {
+	store := newLocalDynamoDBStore(t)
+	ctx := context.Background()
+	project := testProject("revised", time.Now())
+
+	// Saved out of order to check numeric sorting past single digits
+	for _, revision := range []int{10, 2, 1} {
+		require.NoError(t, store.SaveRevision(ctx, &models.ProjectRevision{
+			ProjectID: project.ID,
+			Revision:  revision,
+			Files:     []models.ProjectFile{{Path: "rev.txt", Content: fmt.Sprint(revision)}},
+			CreatedAt: time.Now(),
+		}))
+	}
+
+	// Revisions are never replaced
+	err := store.SaveRevision(ctx, &models.ProjectRevision{ProjectID: project.ID, Revision: 2, CreatedAt: time.Now()})
+	assert.True(t, errors.Is(err, storage.ErrConflict))
+
+	revisions, err := store.ListRevisions(ctx, project.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, []int{1, 2, 10}, []int{revisions[0].Revision, revisions[1].Revision, revisions[2].Revision})
+
+	revision, err := store.GetRevision(ctx, project.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "2", revision.Files[0].Content)
+
+	_, err = store.GetRevision(ctx, project.ID, 3)
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func TestDynamoDBStore_ChatHistories(t *testing.T) {
+	store := newLocalDynamoDBStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	history := &models.ChatHistory{
+		SessionID: uuid.New().String(),
+		Title:     "Go API",
+		ProjectID: "project-1",
+		Messages:  []models.ChatMessage{{ID: "m1", Role: "user", Content: "hello", CreatedAt: now}},
+		Draft:     &models.ProjectRequest{Language: models.LanguageGo},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, store.SaveChatHistory(ctx, history, now.Add(time.Hour)))
+
+	loaded, err := store.GetChatHistory(ctx, history.SessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go API", loaded.Title)
+	assert.Len(t, loaded.Messages, 1)
+	require.NotNil(t, loaded.Draft)
+	assert.Equal(t, models.LanguageGo, loaded.Draft.Language)
+
+	linked, err := store.GetProjectChatHistory(ctx, "project-1")
+	require.NoError(t, err)
+	assert.Equal(t, history.SessionID, linked.SessionID)
+
+	sessions, err := store.ListChatSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "Go API", sessions[0].Title)
+	assert.Equal(t, 1, sessions[0].MessageCount)
+
+	// Relinking leaves a stale pointer behind that must be ignored
+	history.ProjectID = "project-2"
+	require.NoError(t, store.SaveChatHistory(ctx, history, time.Time{}))
+	_, err = store.GetProjectChatHistory(ctx, "project-1")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+	require.NoError(t, store.DeleteChatHistory(ctx, history.SessionID))
+	require.NoError(t, store.DeleteChatHistory(ctx, history.SessionID))
+	_, err = store.GetChatHistory(ctx, history.SessionID)
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+	_, err = store.GetProjectChatHistory(ctx, "project-2")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
+
+func TestDynamoDBStore_ExpiredChatHistories(t *testing.T) {
+	store := newLocalDynamoDBStore(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	history := &models.ChatHistory{SessionID: uuid.New().String(), Title: "old", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, store.SaveChatHistory(ctx, history, now.Add(-time.Minute)))
+
+	_, err := store.GetChatHistory(ctx, history.SessionID)
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+	sessions, err := store.ListChatSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
@@ -0,0 +1,242 @@
+package storage_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"boilerplate-blueprint/internal/awsauth"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedCall is one DynamoDB API request seen by the stub server
+type recordedCall struct {
+	Target        string
+	Authorization string
+	Body          map[string]interface{}
+}
+
+func newStubDynamoDB(t *testing.T, respond func(target string, body map[string]interface{}) (int, string)) (*storage.DynamoDBStore, *[]recordedCall) {
+	calls := &[]recordedCall{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(data, &body)
+
+		target := r.Header.Get("X-Amz-Target")
+		*calls = append(*calls, recordedCall{Target: target, Authorization: r.Header.Get("Authorization"), Body: body})
+
+		status, response := respond(target, body)
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(status)
+		io.WriteString(w, response)
+	}))
+	t.Cleanup(server.Close)
+
+	store, err := storage.NewDynamoDBStore(storage.DynamoDBConfig{
+		Table:       "blueprint",
+		Region:      "eu-west-1",
+		Endpoint:    server.URL,
+		Credentials: awsauth.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	require.NoError(t, err)
+	return store, calls
+}
+
+func TestNewDynamoDBStore_Validation(t *testing.T) {
+	credentials := awsauth.Credentials{AccessKeyID: "a", SecretAccessKey: "b"}
+
+	_, err := storage.NewDynamoDBStore(storage.DynamoDBConfig{Credentials: credentials})
+	assert.Error(t, err, "table is required")
+
+	_, err = storage.NewDynamoDBStore(storage.DynamoDBConfig{Table: "t"})
+	assert.Error(t, err, "credentials are required")
+
+	_, err = storage.NewDynamoDBStore(storage.DynamoDBConfig{Table: "t", Endpoint: "localhost:8000", Credentials: credentials})
+	assert.Error(t, err, "endpoint needs a scheme")
+}
+
+func TestDynamoDBStore_SaveProject_RequestShape(t *testing.T) {
+	store, calls := newStubDynamoDB(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusOK, "{}"
+	})
+
+	project := testProject("shape", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	require.NoError(t, store.SaveProject(context.Background(), project, 0))
+
+	require.Len(t, *calls, 1)
+	call := (*calls)[0]
+	assert.Equal(t, "DynamoDB_20120810.PutItem", call.Target)
+	assert.True(t, strings.HasPrefix(call.Authorization, "AWS4-HMAC-SHA256 Credential=AKID/"))
+	assert.Contains(t, call.Authorization, "/eu-west-1/dynamodb/aws4_request")
+	assert.Equal(t, "blueprint", call.Body["TableName"])
+
+	item := call.Body["Item"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"S": "PROJECT#" + project.ID}, item["pk"])
+	assert.Equal(t, map[string]interface{}{"S": "PROJECT"}, item["sk"])
+	assert.Equal(t, map[string]interface{}{"S": "PROJECTS"}, item["gsi1pk"])
+	assert.Equal(t, map[string]interface{}{"S": "2024-01-02T03:04:05.000000000Z#" + project.ID}, item["gsi1sk"])
+	assert.Equal(t, map[string]interface{}{"N": "1"}, item["revision"])
+	assert.Contains(t, item["data"], "B")
+	assert.Equal(t, "attribute_not_exists(pk)", call.Body["ConditionExpression"])
+}
+
+func TestDynamoDBStore_ConditionalWrites(t *testing.T) {
+	store, calls := newStubDynamoDB(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusBadRequest, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`
+	})
+	ctx := context.Background()
+
+	project := testProject("conditional", time.Now())
+	project.Revision = 3
+	err := store.SaveProject(ctx, project, 2)
+	assert.True(t, errors.Is(err, storage.ErrConflict))
+
+	err = store.SaveRevision(ctx, &models.ProjectRevision{ProjectID: project.ID, Revision: 3})
+	assert.True(t, errors.Is(err, storage.ErrConflict))
+
+	require.Len(t, *calls, 2)
+	update := (*calls)[0].Body
+	assert.Equal(t, "attribute_exists(pk) AND (#revision = :expected OR attribute_not_exists(#revision))", update["ConditionExpression"])
+	assert.Equal(t, map[string]interface{}{"#revision": "revision"}, update["ExpressionAttributeNames"])
+	assert.Equal(t, map[string]interface{}{":expected": map[string]interface{}{"N": "2"}}, update["ExpressionAttributeValues"])
+	assert.Equal(t, "attribute_not_exists(pk)", (*calls)[1].Body["ConditionExpression"])
+}
+
+func TestDynamoDBStore_GetProject_RoundTripsData(t *testing.T) {
+	var saved map[string]interface{}
+	store, _ := newStubDynamoDB(t, func(target string, body map[string]interface{}) (int, string) {
+		switch target {
+		case "DynamoDB_20120810.PutItem":
+			saved = body["Item"].(map[string]interface{})
+			return http.StatusOK, "{}"
+		case "DynamoDB_20120810.GetItem":
+			if saved == nil {
+				return http.StatusOK, "{}"
+			}
+			response, _ := json.Marshal(map[string]interface{}{"Item": saved})
+			return http.StatusOK, string(response)
+		}
+		return http.StatusBadRequest, "{}"
+	})
+	ctx := context.Background()
+
+	_, err := store.GetProject(ctx, "missing")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+
+	project := testProject("round-trip", time.Now())
+	require.NoError(t, store.SaveProject(ctx, project, 0))
+
+	loaded, err := store.GetProject(ctx, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, project.Name, loaded.Name)
+	assert.Equal(t, project.Files, loaded.Files)
+}
+
+func TestDynamoDBStore_ListChatSessions_FollowsPagination(t *testing.T) {
+	page := 0
+	store, calls := newStubDynamoDB(t, func(target string, body map[string]interface{}) (int, string) {
+		page++
+		if page == 1 {
+			return http.StatusOK, `{"Items":[{"session_id":{"S":"s1"},"title":{"S":"First"},"message_count":{"N":"3"},"updated_at":{"S":"2024-01-02T00:00:00Z"}}],"LastEvaluatedKey":{"pk":{"S":"CHAT#s1"}}}`
+		}
+		return http.StatusOK, `{"Items":[{"session_id":{"S":"s2"},"title":{"S":"Second"},"message_count":{"N":"1"},"expires_at":{"N":"1"}}]}`
+	})
+
+	sessions, err := store.ListChatSessions(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, *calls, 2)
+	first := (*calls)[0].Body
+	assert.Equal(t, "gsi1", first["IndexName"])
+	assert.Equal(t, false, first["ScanIndexForward"])
+	assert.Contains(t, first, "ProjectionExpression")
+	assert.Equal(t, map[string]interface{}{"pk": map[string]interface{}{"S": "CHAT#s1"}}, (*calls)[1].Body["ExclusiveStartKey"])
+
+	// The second session has expired and is skipped
+	require.Len(t, sessions, 1)
+	assert.Equal(t, models.ChatSession{
+		ID:           "s1",
+		Title:        "First",
+		MessageCount: 3,
+		UpdatedAt:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, sessions[0])
+}
+
+func TestDynamoDBStore_APIErrors(t *testing.T) {
+	store, _ := newStubDynamoDB(t, func(string, map[string]interface{}) (int, string) {
+		return http.StatusBadRequest, `{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`
+	})
+
+	err := store.SaveProject(context.Background(), testProject("broken", time.Now()), 0)
+
+	var apiErr *storage.DynamoAPIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "ResourceNotFoundException", apiErr.Type)
+	assert.Equal(t, "Requested resource not found", apiErr.Message)
+}
+
+func TestDynamoDBStore_Teams_RequestShapeAndRoundTrip(t *testing.T) {
+	items := map[string]map[string]interface{}{}
+	store, calls := newStubDynamoDB(t, func(target string, body map[string]interface{}) (int, string) {
+		switch target {
+		case "DynamoDB_20120810.PutItem":
+			item := body["Item"].(map[string]interface{})
+			items[item["pk"].(map[string]interface{})["S"].(string)] = item
+			return http.StatusOK, "{}"
+		case "DynamoDB_20120810.GetItem":
+			key := body["Key"].(map[string]interface{})
+			item, ok := items[key["pk"].(map[string]interface{})["S"].(string)]
+			if !ok {
+				return http.StatusOK, "{}"
+			}
+			response, _ := json.Marshal(map[string]interface{}{"Item": item})
+			return http.StatusOK, string(response)
+		case "DynamoDB_20120810.DeleteItem":
+			key := body["Key"].(map[string]interface{})
+			delete(items, key["pk"].(map[string]interface{})["S"].(string))
+			return http.StatusOK, "{}"
+		}
+		return http.StatusBadRequest, "{}"
+	})
+	ctx := context.Background()
+
+	team := &models.Team{
+		ID:        "team-1",
+		Name:      "Platform",
+		Members:   []models.TeamMember{{PrincipalID: "alice", Role: models.RoleOwner}},
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	require.NoError(t, store.SaveTeam(ctx, team))
+
+	item := (*calls)[0].Body["Item"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"S": "TEAM#team-1"}, item["pk"])
+	assert.Equal(t, map[string]interface{}{"S": "TEAM"}, item["sk"])
+	assert.Equal(t, map[string]interface{}{"S": "TEAMS"}, item["gsi1pk"])
+	assert.Equal(t, map[string]interface{}{"S": "2024-01-02T03:04:05.000000000Z#team-1"}, item["gsi1sk"])
+
+	loaded, err := store.GetTeam(ctx, "team-1")
+	require.NoError(t, err)
+	assert.Equal(t, team.Members, loaded.Members)
+
+	pack := &models.TemplatePack{ID: "pack-1", Name: "Gin + Postgres", Language: models.LanguageGo, TeamID: "team-1"}
+	require.NoError(t, store.SaveTemplatePack(ctx, pack))
+	assert.Contains(t, items, "PACK#pack-1")
+	loadedPack, err := store.GetTemplatePack(ctx, "pack-1")
+	require.NoError(t, err)
+	assert.Equal(t, "team-1", loadedPack.TeamID)
+
+	require.NoError(t, store.DeleteTeam(ctx, "team-1"))
+	_, err = store.GetTeam(ctx, "team-1")
+	assert.True(t, errors.Is(err, storage.ErrNotFound))
+}
@@ -0,0 +1,216 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"boilerplate-blueprint/internal/app"
+	"boilerplate-blueprint/internal/models"
+	"boilerplate-blueprint/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	incomingTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	traceparent     = "00-" + incomingTraceID + "-00f067aa0ba902b7-01"
+)
+
+// recordSpans installs a tracer provider that keeps finished spans in memory
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func newApp(t *testing.T, logs *bytes.Buffer) *app.App {
+	t.Helper()
+	cfg := app.DefaultConfig()
+	cfg.GinMode = gin.TestMode
+	cfg.StaticDir = ""
+	cfg.Metrics.Enabled = false
+	if logs != nil {
+		cfg.Logger = slog.New(slog.NewJSONHandler(logs, nil))
+	}
+	application, err := app.New(cfg)
+	require.NoError(t, err)
+	return application
+}
+
+func send(handler http.Handler, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("traceparent", traceparent)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func spansByName(recorder *tracetest.SpanRecorder) map[string]sdktrace.ReadOnlySpan {
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	return spans
+}
+
+func attributeValue(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func createProject(t *testing.T, handler http.Handler) string {
+	t.Helper()
+	w := send(handler, http.MethodPost, "/api/v1/projects", models.ProjectRequest{
+		Name: "shop", Language: models.LanguageGo, Options: models.ProjectOptions{Framework: "gin"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.ProjectResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	return created.Project.ID
+}
+
+func TestTracing_SpansGenerationSteps(t *testing.T) {
+	recorder := recordSpans(t)
+	application := newApp(t, nil)
+	projectID := createProject(t, application)
+
+	require.Equal(t, http.StatusOK, send(application, http.MethodPost, "/api/v1/projects/"+projectID+"/generate", nil).Code)
+	spans := spansByName(recorder)
+
+	handler := spans["POST /api/v1/projects/:id/generate"]
+	require.NotNil(t, handler, "each handler gets a span named by its route")
+	assert.Equal(t, trace.SpanKindServer, handler.SpanKind())
+	assert.Equal(t, incomingTraceID, handler.SpanContext().TraceID().String(), "the incoming trace is continued")
+	assert.Equal(t, "00f067aa0ba902b7", handler.Parent().SpanID().String())
+	assert.EqualValues(t, http.StatusOK, attributeValue(handler, "http.response.status_code").AsInt64())
+
+	generate := spans["project.generate"]
+	require.NotNil(t, generate)
+	assert.Equal(t, handler.SpanContext().SpanID(), generate.Parent().SpanID())
+	assert.Equal(t, projectID, attributeValue(generate, "project.id").AsString())
+	assert.Equal(t, "go", attributeValue(generate, "project.language").AsString())
+
+	render := spans["template.render"]
+	require.NotNil(t, render)
+	assert.Equal(t, generate.SpanContext().SpanID(), render.Parent().SpanID())
+	for _, step := range []string{"directories", "go_mod", "main", "routes"} {
+		span := spans["template.render."+step]
+		if assert.NotNil(t, span, step) {
+			assert.Equal(t, render.SpanContext().SpanID(), span.Parent().SpanID())
+			assert.Positive(t, attributeValue(span, "template.files").AsInt64())
+		}
+	}
+}
+
+func TestTracing_SpansArchiveAndChat(t *testing.T) {
+	recorder := recordSpans(t)
+	var logs bytes.Buffer
+	application := newApp(t, &logs)
+	projectID := createProject(t, application)
+
+	require.Equal(t, http.StatusOK, send(application, http.MethodGet, "/api/v1/projects/"+projectID+"/download", nil).Code)
+	require.Equal(t, http.StatusOK, send(application, http.MethodPost, "/api/v1/chat/message", models.ChatRequest{Message: "I want a Go API"}).Code)
+	spans := spansByName(recorder)
+
+	archive := spans["project.archive"]
+	require.NotNil(t, archive)
+	zip := spans["template.create_zip"]
+	require.NotNil(t, zip)
+	assert.Positive(t, attributeValue(zip, "archive.bytes").AsInt64())
+	assert.Equal(t, incomingTraceID, zip.SpanContext().TraceID().String())
+
+	chat := spans["chat.process_message"]
+	require.NotNil(t, chat)
+	assert.NotNil(t, spans["chat.generate_response"])
+	assert.Equal(t, chat.SpanContext().SpanID(), spans["chat.generate_response"].Parent().SpanID())
+
+	assert.Contains(t, logs.String(), `"trace_id":"`+incomingTraceID+`"`, "logs carry the trace ID")
+}
+
+func TestTracing_FailedSpansRecordTheError(t *testing.T) {
+	recorder := recordSpans(t)
+	application := newApp(t, nil)
+
+	_, err := application.ProjectService.GenerateProjectFiles(context.Background(), &models.Project{ID: "p-1", Language: "cobol"})
+	require.Error(t, err)
+
+	generate := spansByName(recorder)["project.generate"]
+	require.NotNil(t, generate)
+	assert.Equal(t, "Error", generate.Status().Code.String())
+	require.NotEmpty(t, generate.Events())
+	assert.Equal(t, "exception", generate.Events()[0].Name)
+}
+
+func TestSetup_Exporters(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterNone})
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+
+	var output bytes.Buffer
+	shutdown, err = tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterStdout, SampleRatio: 1, Output: &output})
+	require.NoError(t, err)
+	_, span := tracing.Start(context.Background(), "exported")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, output.String(), `"Name":"exported"`)
+	assert.Contains(t, output.String(), tracing.ServiceName)
+
+	_, err = tracing.Setup(context.Background(), tracing.Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+}
+
+func TestForceFlush(t *testing.T) {
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	var output bytes.Buffer
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{Exporter: tracing.ExporterStdout, SampleRatio: 1, Output: &output})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = shutdown(context.Background()) })
+
+	_, span := tracing.Start(context.Background(), "flushed")
+	span.End()
+	require.NoError(t, tracing.ForceFlush(context.Background()))
+	assert.Contains(t, output.String(), `"Name":"flushed"`, "spans are exported without shutting down")
+}
@@ -2,7 +2,7 @@ import axios from 'axios'
 
 // Create axios instance
 const api = axios.create({
-  baseURL: '/api',
+  baseURL: '/api/v1',
   timeout: 30000,
   headers: {
     'Content-Type': 'application/json'
@@ -84,6 +84,27 @@ export const chatApi = {
   getChatHistory(projectId = null) {
     const params = projectId ? { project_id: projectId } : {}
     return api.get('/chat/history', { params })
+  },
+
+  // Chat sessions
+  createSession(sessionData = {}) {
+    return api.post('/chat/sessions', sessionData)
+  },
+
+  listSessions() {
+    return api.get('/chat/sessions')
+  },
+
+  updateSession(sessionId, sessionData) {
+    return api.patch(`/chat/sessions/${sessionId}`, sessionData)
+  },
+
+  deleteSession(sessionId) {
+    return api.delete(`/chat/sessions/${sessionId}`)
+  },
+
+  getSessionMessages(sessionId, offset = 0, limit = 50) {
+    return api.get(`/chat/sessions/${sessionId}/messages`, { params: { offset, limit } })
   }
 }
 
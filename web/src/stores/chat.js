@@ -8,6 +8,7 @@ export const useChatStore = defineStore('chat', () => {
   const isLoading = ref(false)
   const error = ref(null)
   const currentProjectId = ref(null)
+  const currentSessionId = ref(null)
   const suggestions = ref([])
 
   // Getters
@@ -35,11 +36,17 @@ export const useChatStore = defineStore('chat', () => {
       // Send to API
       const response = await chatApi.sendMessage({
         message: content,
+        session_id: currentSessionId.value,
         project_id: projectId,
         context: formatContextForAPI()
       })
 
       if (response.data.success) {
+        // Continue the same session on the next message
+        if (response.data.session_id) {
+          currentSessionId.value = response.data.session_id
+        }
+
         // Add assistant response
         messages.value.push(response.data.message)
 
@@ -73,6 +80,7 @@ export const useChatStore = defineStore('chat', () => {
       if (response.data.success) {
         messages.value = response.data.history?.messages || []
         currentProjectId.value = projectId
+        currentSessionId.value = response.data.history?.session_id || null
       } else {
         throw new Error(response.data.error || 'Failed to load chat history')
       }
@@ -98,6 +106,7 @@ export const useChatStore = defineStore('chat', () => {
   function clearMessages() {
     messages.value = []
     suggestions.value = []
+    currentSessionId.value = null
   }
 
   function clearError() {
@@ -135,6 +144,7 @@ export const useChatStore = defineStore('chat', () => {
     isLoading,
     error,
     currentProjectId,
+    currentSessionId,
     suggestions,
 
     // Getters